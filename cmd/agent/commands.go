@@ -0,0 +1,800 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/VolodyaPopov923/AIBot/config"
+	"github.com/VolodyaPopov923/AIBot/internal/agent"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+	"github.com/VolodyaPopov923/AIBot/internal/credentials"
+	"github.com/VolodyaPopov923/AIBot/internal/daemon"
+	"github.com/VolodyaPopov923/AIBot/internal/server"
+	"github.com/VolodyaPopov923/AIBot/internal/telegram"
+)
+
+func runCommand(args []string) int {
+	fs := newFlagSet("run")
+	flags := addAgentFlags(fs)
+	url := fs.String("url", "", "start URL of the task")
+	fs.StringVar(&flags.resultFile, "o", "", "write the result as JSON to this file (same as -result-file)")
+	output := fs.String("output", "text", "text for people, or json for JSON lines of events and the final result on stdout")
+	tui := addTUIFlag(fs)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	task := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if task == "" {
+		return usageError(fs, "run needs a task")
+	}
+	if *tui && *output == "json" {
+		return usageError(fs, "-tui and -output json cannot be used together")
+	}
+	var encoder *json.Encoder
+	switch *output {
+	case "text":
+	case "json":
+		var err error
+		if encoder, err = jsonOutput(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to set up JSON output: %v\n", err)
+			return exitFailed
+		}
+	default:
+		return usageError(fs, fmt.Sprintf("unknown -output %q, want text or json", *output))
+	}
+
+	ctx := context.Background()
+	s := openSession(ctx, config.LoadConfig(), flags)
+	defer s.Close(ctx)
+	if encoder != nil {
+		quietLog()
+		return runJSON(ctx, s.agent, encoder, task, *url, flags.resultFile)
+	}
+	if *tui {
+		s.useDashboard()
+	}
+	return runOnce(ctx, s, task, *url, flags.resultFile)
+}
+
+func replCommand(args []string) int {
+	fs := newFlagSet("repl")
+	flags := addAgentFlags(fs)
+	clearSession := fs.Bool("clear-session", false, "delete the stored session of the profile before starting")
+	tui := addTUIFlag(fs)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() > 0 {
+		return usageError(fs, "repl takes no arguments")
+	}
+
+	ctx := context.Background()
+	reader := bufio.NewReader(os.Stdin)
+	if *clearSession {
+		clearProfile(reader, flags.profile)
+	}
+	s := openSession(ctx, config.LoadConfig(), flags)
+	defer s.Close(ctx)
+	if *tui {
+		s.useDashboard()
+	}
+	repl(ctx, s, reader, startScheduler(ctx, s), flags.resultFile)
+	return exitOK
+}
+
+func serveCommand(args []string) int {
+	fs := newFlagSet("serve")
+	flags := addAgentFlags(fs)
+	addr := fs.String("addr", "127.0.0.1:8080", "address the HTTP API listens on; it drives your browser profile, so think twice before opening it to the network")
+	cfg := config.LoadConfig()
+	fs.StringVar(&cfg.APIToken, "token", cfg.APIToken, "bearer token every API request must carry, for listening beyond localhost (default $AGENT_API_TOKEN)")
+	ui := fs.Bool("ui", false, "serve a web dashboard at / with the live page, plan and log; confirmations wait for approval there instead of halting the task")
+	fs.StringVar(&cfg.Webhooks, "webhooks", cfg.Webhooks, "comma-separated URLs that receive a JSON POST for every task event (default $AGENT_WEBHOOKS)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() > 0 {
+		return usageError(fs, "serve takes no arguments")
+	}
+	if _, err := server.ParseWebhooks(cfg.Webhooks); err != nil {
+		return usageError(fs, err.Error())
+	}
+
+	ctx := context.Background()
+	s := openSession(ctx, cfg, flags)
+	defer s.Close(ctx)
+	startScheduler(ctx, s)
+	serve(ctx, s, *addr, *ui)
+	return exitOK
+}
+
+func telegramCommand(args []string) int {
+	fs := newFlagSet("telegram")
+	flags := addAgentFlags(fs)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() > 0 {
+		return usageError(fs, "telegram takes no arguments")
+	}
+	cfg := config.LoadConfig()
+	if cfg.TelegramToken == "" {
+		return usageError(fs, "set TELEGRAM_BOT_TOKEN to the token @BotFather gave you")
+	}
+	allowed := telegram.ParseAllowedUsers(cfg.TelegramUsers)
+	if len(allowed) == 0 {
+		log.Printf("Warning: TELEGRAM_ALLOWED_USERS is empty; the bot will only tell people their user ID\n")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	s := openSession(ctx, cfg, flags)
+	defer s.Close(context.Background())
+
+	bot := telegram.New(telegram.NewClient(cfg.TelegramToken), s.agent, allowed)
+	bot.ParseRequest = func(ctx context.Context, text string) (string, string, error) {
+		parsed, err := s.ai.ParseUserRequest(ctx, text)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to parse request: %w", err)
+		}
+		if parsed.Task == "" {
+			return "", "", errors.New(parsed.Reasoning)
+		}
+		return parsed.Task, parsed.URL, nil
+	}
+	// The reply is sent after the task let go of the browser, which a
+	// scheduled task may have taken by then; the capture leaves it alone.
+	bot.Screenshot = func(ctx context.Context) ([]byte, error) {
+		return s.browser.CaptureViewport()
+	}
+	// Nobody watches the terminal: confirmations and questions go to the chat.
+	s.agent.Confirm = bot.Confirm
+	s.agent.Ask = bot.Ask
+	s.agent.ManualSteps = agent.ManualStepWait
+	s.agent.ConfirmEachStep = false
+
+	startScheduler(ctx, s)
+	fmt.Println("🤖 Telegram bot started; press Ctrl-C to stop")
+	if err := bot.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		log.Printf("Telegram bot stopped: %v\n", err)
+		return exitFailed
+	}
+	return exitOK
+}
+
+// legacyCommand runs the flags of versions without commands: -task for run,
+// -serve for serve, and the interactive prompt otherwise.
+func legacyCommand(args []string) int {
+	fs := flag.NewFlagSet("agent", flag.ContinueOnError)
+	fs.Usage = func() { usage(fs.Output()) }
+	flags := addAgentFlags(fs)
+	clearSession := fs.Bool("clear-session", false, "")
+	serveAddr := fs.String("serve", "", "")
+	task := fs.String("task", "", "")
+	url := fs.String("url", "", "")
+	output := fs.String("o", "", "")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if *task == "" && (*url != "" || *output != "") {
+		fmt.Fprintln(os.Stderr, "-url and -o need -task")
+		return exitUsage
+	}
+	if *output != "" {
+		flags.resultFile = *output
+	}
+
+	ctx := context.Background()
+	reader := bufio.NewReader(os.Stdin)
+	if *clearSession {
+		clearProfile(reader, flags.profile)
+	}
+	s := openSession(ctx, config.LoadConfig(), flags)
+	defer s.Close(ctx)
+	switch {
+	case *task != "":
+		return runOnce(ctx, s, *task, *url, flags.resultFile)
+	case *serveAddr != "":
+		startScheduler(ctx, s)
+		serve(ctx, s, *serveAddr, false)
+	default:
+		repl(ctx, s, reader, startScheduler(ctx, s), flags.resultFile)
+	}
+	return exitOK
+}
+
+// runOnce runs a single task for the run command and returns the exit code.
+// Without a terminal on stdin nobody can answer prompts, so it behaves like
+// serve: destructive actions halt the task and manual steps fail it.
+func runOnce(ctx context.Context, s *session, task, url, resultFile string) int {
+	agentInstance := s.agent
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice == 0 {
+		agentInstance.HaltOnDestructive = true
+		agentInstance.ManualSteps = agent.ManualStepFail
+		agentInstance.ConfirmEachStep = false
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("📋 Executing task: %s\n", task)
+	var result *agent.TaskResult
+	var err error
+	s.watch(ctx, task, func(ctx context.Context) {
+		result, err = agentInstance.ExecuteTask(ctx, task, url)
+	})
+	reportResult(result, err, resultFile)
+	return exitCode(err)
+}
+
+// runJSON is runOnce for -output json: the task's events and then its result
+// are written to encoder as JSON lines. Prompts would go unseen, so the task
+// runs as with serve.
+func runJSON(ctx context.Context, agentInstance *agent.Agent, encoder *json.Encoder, task, url, resultFile string) int {
+	agentInstance.HaltOnDestructive = true
+	agentInstance.ManualSteps = agent.ManualStepFail
+	agentInstance.ConfirmEachStep = false
+	agentInstance.OnEvent = func(event agent.Event) {
+		_ = encoder.Encode(event)
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result, err := agentInstance.ExecuteTask(ctx, task, url)
+	line := jsonResult{Type: "result", ExitCode: exitCode(err), Result: result}
+	if err != nil {
+		line.Error = err.Error()
+	}
+	if saveErr := saveResult(result, resultFile); saveErr != nil {
+		fmt.Fprintf(os.Stderr, "failed to save result: %v\n", saveErr)
+	}
+	_ = encoder.Encode(line)
+	return line.ExitCode
+}
+
+// serve runs the HTTP API. Nobody is at the terminal to answer prompts, so
+// destructive actions halt the task and manual steps fail it; with the web
+// dashboard, confirmations wait for someone to approve or deny them there.
+func serve(ctx context.Context, s *session, addr string, ui bool) {
+	agentInstance := s.agent
+	agentInstance.ManualSteps = agent.ManualStepFail
+
+	srv := server.New(agentInstance)
+	if ui {
+		srv.UI = true
+		// The live view is polled while a task drives the browser.
+		srv.Screenshot = func(ctx context.Context) ([]byte, error) {
+			return s.browser.CaptureViewport()
+		}
+		agentInstance.Confirm = srv.Confirm
+	} else {
+		agentInstance.HaltOnDestructive = true
+		agentInstance.ConfirmEachStep = false
+	}
+	webhooks, err := server.ParseWebhooks(s.cfg.Webhooks)
+	if err != nil {
+		log.Fatalf("Invalid AGENT_WEBHOOKS: %v\n", err)
+	}
+	srv.Webhooks = webhooks
+	srv.WebhookSecret = s.cfg.WebhookSecret
+	srv.Token = s.cfg.APIToken
+	if srv.Token == "" && !loopbackAddr(addr) {
+		log.Printf("Warning: %s is reachable from other machines and the API has no token; set -token or AGENT_API_TOKEN\n", addr)
+	}
+	go srv.Run(ctx)
+	if len(webhooks) > 0 {
+		fmt.Printf("🪝 Posting task events to %s\n", strings.Join(webhooks, ", "))
+	}
+
+	fmt.Printf("🌍 Serving API on %s (POST /tasks, GET /tasks/{id}, GET /tasks/{id}/events, POST /tasks/{id}/cancel|pause|resume)\n", addr)
+	if ui {
+		dashboard := "http://" + dashboardHost(addr) + "/"
+		if srv.Token != "" {
+			dashboard += "?token=" + url.QueryEscape(srv.Token)
+		}
+		fmt.Printf("🖥  Dashboard at %s\n", dashboard)
+	}
+	if err := http.ListenAndServe(addr, srv.Handler()); err != nil {
+		log.Fatalf("API server failed: %v\n", err)
+	}
+}
+
+// daemonStatus answers the status command of agentctl.
+type daemonStatus struct {
+	Type   string `json:"type"` // always "status"
+	PID    int    `json:"pid"`
+	Uptime string `json:"uptime"`
+	Busy   bool   `json:"busy"`
+	URL    string `json:"url,omitempty"`
+}
+
+func daemonCommand(args []string) int {
+	fs := newFlagSet("daemon")
+	flags := addAgentFlags(fs)
+	cfg := config.LoadConfig()
+	socket := fs.String("socket", cfg.Socket, "Unix socket to listen on (default $AGENT_SOCKET, else aibot.sock in $XDG_RUNTIME_DIR or the temp dir)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() > 0 {
+		return usageError(fs, "daemon takes no arguments")
+	}
+	if *socket == "" {
+		*socket = daemon.DefaultSocketPath()
+	}
+	listener, err := daemon.Listen(*socket)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitFailed
+	}
+	defer os.Remove(*socket)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	s := openSession(ctx, cfg, flags)
+	defer s.Close(context.Background())
+
+	// Tasks share the browser and the agent's OnEvent, so they run one at a
+	// time; later ones are told they wait.
+	var running sync.Mutex
+	var busy atomic.Bool
+	started := time.Now()
+	handle := func(ctx context.Context, req daemon.Request, out *json.Encoder) {
+		switch req.Command {
+		case daemon.CommandRun:
+			if strings.TrimSpace(req.Task) == "" {
+				_ = out.Encode(jsonResult{Type: "result", ExitCode: exitUsage, Error: "task is required"})
+				return
+			}
+			if !running.TryLock() {
+				_ = out.Encode(map[string]string{"type": "queued"})
+				running.Lock()
+			}
+			defer running.Unlock()
+			if ctx.Err() != nil {
+				return
+			}
+			busy.Store(true)
+			defer busy.Store(false)
+			log.Printf("Task from agentctl: %s\n", req.Task)
+			runJSON(ctx, s.agent, out, req.Task, req.URL, "")
+		case daemon.CommandStatus:
+			_ = out.Encode(daemonStatus{
+				Type:   "status",
+				PID:    os.Getpid(),
+				Uptime: time.Since(started).Round(time.Second).String(),
+				Busy:   busy.Load(),
+				URL:    s.browser.CurrentURL(),
+			})
+		case daemon.CommandStop:
+			_ = out.Encode(map[string]string{"type": "stopping"})
+			stop()
+		default:
+			_ = out.Encode(jsonResult{Type: "result", ExitCode: exitUsage, Error: fmt.Sprintf("unknown command %q", req.Command)})
+		}
+	}
+
+	fmt.Printf("🔌 Daemon listening on %s; send tasks with agentctl, stop with Ctrl-C or \"agentctl stop\"\n", *socket)
+	if err := daemon.Serve(ctx, listener, handle); err != nil {
+		log.Printf("Daemon stopped: %v\n", err)
+		return exitFailed
+	}
+	fmt.Println("👋 Daemon stopped")
+	return exitOK
+}
+
+// loopbackAddr reports whether addr listens on this machine only.
+func loopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// dashboardHost is where the dashboard of a server listening on addr can be
+// opened locally.
+func dashboardHost(addr string) string {
+	if strings.HasPrefix(addr, ":") {
+		return "localhost" + addr
+	}
+	return addr
+}
+
+func screenshotCommand(args []string) int {
+	fs := newFlagSet("screenshot")
+	flags := addBrowserFlags(fs)
+	url := fs.String("url", "", "open this page first; without it the active tab is captured")
+	selector := fs.String("selector", "", "capture only the element matching this selector")
+	viewport := fs.Bool("viewport", false, "capture only the visible part of the page")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		return usageError(fs, "screenshot needs the file to write")
+	}
+
+	ctx := context.Background()
+	browserMgr := newBrowser(ctx, config.LoadConfig(), flags)
+	defer browserMgr.Close(ctx)
+	if *url != "" {
+		if err := browserMgr.Navigate(ctx, *url); err != nil {
+			fmt.Printf("❌ Navigation failed: %v\n", err)
+			return exitFailed
+		}
+		_ = browserMgr.WaitForNavigation(ctx)
+	}
+	opts := browser.ScreenshotOptions{Path: fs.Arg(0), Selector: *selector, FullPage: *selector == "" && !*viewport}
+	if _, err := browserMgr.Screenshot(ctx, opts); err != nil {
+		fmt.Printf("❌ Screenshot failed: %v\n", err)
+		return exitFailed
+	}
+	fmt.Printf("📸 Screenshot saved to %s\n", opts.Path)
+	return exitOK
+}
+
+func tabsCommand(args []string) int {
+	fs := newFlagSet("tabs")
+	flags := addBrowserFlags(fs)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() > 0 {
+		return usageError(fs, "tabs takes no arguments")
+	}
+
+	ctx := context.Background()
+	browserMgr := newBrowser(ctx, config.LoadConfig(), flags)
+	defer browserMgr.Close(ctx)
+	for _, tab := range browserMgr.ListOpenPages() {
+		marker := " "
+		if tab.Active {
+			marker = "*"
+		}
+		fmt.Printf("%s %2d. %s\n     %s\n", marker, tab.Index, tab.Title, tab.URL)
+	}
+	return exitOK
+}
+
+func sessionCommand(args []string) int {
+	fs := newFlagSet("session")
+	flags := addBrowserFlags(fs)
+	yes := fs.Bool("yes", false, "clear or delete without asking")
+	if len(args) == 0 {
+		return usageError(fs, "session needs a subcommand")
+	}
+	sub := args[0]
+	if code, ok := parseFlags(fs, args[1:]); !ok {
+		return code
+	}
+	cfg := config.LoadConfig()
+
+	var file string
+	switch sub {
+	case "clear":
+		if fs.NArg() > 0 {
+			return usageError(fs, "session clear takes no file")
+		}
+		if *yes {
+			if err := browser.ClearProfileData(flags.profile); err != nil {
+				fmt.Printf("❌ Failed to clear session: %v\n", err)
+				return exitFailed
+			}
+			fmt.Println("🧹 Session cleared")
+			return exitOK
+		}
+		clearProfile(bufio.NewReader(os.Stdin), flags.profile)
+		return exitOK
+	case "list":
+		if fs.NArg() > 0 {
+			return usageError(fs, "session list takes no arguments")
+		}
+		return listSessions(cfg)
+	case "delete":
+		if fs.NArg() != 1 {
+			return usageError(fs, "session delete needs a name")
+		}
+		return deleteSession(cfg, fs.Arg(0), *yes)
+	case "save", "load":
+		if fs.NArg() != 1 {
+			return usageError(fs, fmt.Sprintf("session %s needs a name or file", sub))
+		}
+		var err error
+		if file, err = sessionFile(cfg, fs.Arg(0)); err != nil {
+			return usageError(fs, err.Error())
+		}
+		if _, err := os.Stat(file); sub == "load" && os.IsNotExist(err) {
+			fmt.Printf("❌ No session %s; \"agent session list\" shows the saved ones\n", fs.Arg(0))
+			return exitFailed
+		}
+	case "import-cookies":
+		if fs.NArg() != 1 {
+			return usageError(fs, "session import-cookies needs a file")
+		}
+		file = fs.Arg(0)
+	case "-h", "-help", "--help":
+		fs.Usage()
+		return exitOK
+	default:
+		return usageError(fs, fmt.Sprintf("unknown session subcommand %q", sub))
+	}
+
+	ctx := context.Background()
+	browserMgr := newBrowser(ctx, cfg, flags)
+	defer browserMgr.Close(ctx)
+	switch sub {
+	case "save":
+		if err := browserMgr.SaveStorageState(file); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return exitFailed
+		}
+		fmt.Printf("💾 Session saved to %s\n", file)
+	case "load":
+		if err := browserMgr.LoadStorageState(ctx, file); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return exitFailed
+		}
+		fmt.Printf("🔑 Session loaded from %s\n", file)
+	case "import-cookies":
+		cookies, err := browser.LoadCookiesFile(file)
+		if err == nil {
+			err = browserMgr.SetCookies(ctx, cookies)
+		}
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return exitFailed
+		}
+		fmt.Printf("🍪 Imported %d cookie(s)\n", len(cookies))
+	}
+	return exitOK
+}
+
+// listSessions prints the named sessions with the sites they are logged in to.
+func listSessions(cfg config.Config) int {
+	dir := sessionsDir(cfg)
+	sessions, err := browser.ListSessions(dir)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return exitFailed
+	}
+	if len(sessions) == 0 {
+		fmt.Printf("No sessions in %s; save one with \"agent session save <name>\"\n", dir)
+		return exitOK
+	}
+	fmt.Printf("Sessions in %s:\n", dir)
+	for _, saved := range sessions {
+		sites := strings.Join(saved.Sites, ", ")
+		if len(saved.Sites) > 5 {
+			sites = fmt.Sprintf("%s and %d more", strings.Join(saved.Sites[:5], ", "), len(saved.Sites)-5)
+		}
+		fmt.Printf("  %-16s saved %s, %d cookie(s)", saved.Name, saved.Saved.Format("2006-01-02 15:04"), saved.Cookies)
+		if sites != "" {
+			fmt.Printf(": %s", sites)
+		}
+		fmt.Println()
+	}
+	return exitOK
+}
+
+// deleteSession removes a named session once the user agrees.
+func deleteSession(cfg config.Config, name string, yes bool) int {
+	file, err := browser.SessionFile(sessionsDir(cfg), name)
+	if err != nil || file != filepath.Join(sessionsDir(cfg), name+".json") {
+		fmt.Printf("❌ %q is not a session name\n", name)
+		return exitUsage
+	}
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		fmt.Printf("❌ No session %s\n", name)
+		return exitFailed
+	}
+	if !yes && !confirm(bufio.NewReader(os.Stdin), fmt.Sprintf("Delete the logins and cookies of session %q?", name)) {
+		return exitOK
+	}
+	if err := os.Remove(file); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return exitFailed
+	}
+	fmt.Printf("🗑  Session %s deleted\n", name)
+	return exitOK
+}
+
+// clearProfile deletes the stored session of a profile once the user agrees.
+func clearProfile(reader *bufio.Reader, profile string) {
+	if !confirm(reader, fmt.Sprintf("Delete all saved logins and cookies for profile %q?", profile)) {
+		return
+	}
+	if err := browser.ClearProfileData(profile); err != nil {
+		log.Fatalf("Failed to clear session: %v\n", err)
+	}
+	fmt.Println("🧹 Session cleared")
+}
+
+// loginCommand opens a site, logs in with the stored credentials or waits
+// for the user to, checks that it worked and saves the session by name.
+func loginCommand(args []string) int {
+	fs := newFlagSet("login")
+	flags := addBrowserFlags(fs)
+	save := fs.String("save", "", "session name or file to save the login to (default: the site's host)")
+	check := fs.String("check", "", "selector found only when logged in, e.g. of the account menu (default: ask, and see the sign-in form go or a session cookie appear)")
+	manual := fs.Bool("manual", false, "log in by hand even if the credential store has the site")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		return usageError(fs, "login needs a site")
+	}
+	site := fs.Arg(0)
+	if !strings.Contains(site, "://") {
+		site = "https://" + site
+	}
+	siteURL, err := url.Parse(site)
+	if err != nil || siteURL.Hostname() == "" {
+		return usageError(fs, fmt.Sprintf("invalid site %q", fs.Arg(0)))
+	}
+	host := siteURL.Hostname()
+	cfg := config.LoadConfig()
+	name := *save
+	if name == "" {
+		name = strings.TrimPrefix(host, "www.")
+	}
+	file, err := sessionFile(cfg, name)
+	if err != nil {
+		return usageError(fs, err.Error())
+	}
+
+	var cred credentials.Credential
+	var stored bool
+	if !*manual {
+		path := cfg.Credentials
+		if path == "" {
+			path = credentials.DefaultPath()
+		}
+		if cred, stored, err = credentials.Lookup(path, host); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return exitFailed
+		}
+	}
+	interactive := false
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		interactive = true
+	}
+	if headless, _ := strconv.ParseBool(os.Getenv("BROWSER_HEADLESS")); headless && interactive && !stored {
+		fmt.Println("⚠️  BROWSER_HEADLESS is set, so there is no window to log in from")
+	}
+
+	ctx := context.Background()
+	browserMgr := newBrowser(ctx, cfg, flags)
+	defer browserMgr.Close(ctx)
+	if err := browserMgr.Navigate(ctx, site); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return exitFailed
+	}
+	watch, err := browserMgr.WatchLogin(ctx, *check)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return exitFailed
+	}
+	// Without -check nothing on the page proves a login, so the user gets
+	// to look at the browser before the session is saved.
+	prompt := fmt.Sprintf("👤 Log in to %s in the browser window, including any 2FA, then press Enter: ", host)
+	pause := *check == ""
+	if stored {
+		// The password goes from the store into the page and nowhere else:
+		// the model never sees it.
+		fmt.Printf("🔐 Logging in to %s as %s\n", host, cred.Username)
+		if err := fillLogin(ctx, browserMgr, cred); err != nil {
+			fmt.Printf("⚠️  Could not log in with the stored credential: %v\n", err)
+			pause = true
+		} else {
+			prompt = fmt.Sprintf("👤 Finish logging in to %s in the browser window if it asks for more, e.g. 2FA, then press Enter: ", host)
+		}
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		ok, err := watch.LoggedIn(ctx)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return exitFailed
+		}
+		if ok && (!pause || !interactive) {
+			break
+		}
+		if !interactive {
+			fmt.Printf("❌ Not logged in to %s; run login in a terminal to finish it by hand\n", host)
+			return exitManual
+		}
+		if !ok && !pause {
+			fmt.Println("⚠️  The page still looks logged out: no sign-in form came and went and no session cookie was set")
+		}
+		fmt.Print(prompt)
+		if _, err := reader.ReadString('\n'); err != nil {
+			fmt.Println()
+			return exitManual
+		}
+		pause = false
+	}
+
+	if err := browserMgr.SaveStorageState(file); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return exitFailed
+	}
+	fmt.Printf("💾 Logged in to %s; session saved to %s\n", host, file)
+	fmt.Printf("   Use it with: ./agent run -session %s \"<task>\"\n", name)
+	return exitOK
+}
+
+// fillLogin types a stored credential into the sign-in form of the page.
+// Sites that ask for the username first get a second round for the
+// password.
+func fillLogin(ctx context.Context, browserMgr *browser.Manager, cred credentials.Credential) error {
+	for round := 0; round < 2; round++ {
+		form, err := browserMgr.FillLogin(ctx, cred.Username, cred.Password)
+		if err != nil {
+			return err
+		}
+		if !form.Username && !form.Password {
+			if round == 0 {
+				return errors.New("no login form on the page")
+			}
+			return nil
+		}
+		if err := browserMgr.WaitForNavigation(ctx); err != nil {
+			return err
+		}
+		if form.Password {
+			return nil
+		}
+	}
+	return nil
+}
+
+func configCommand(args []string) int {
+	fs := newFlagSet("config")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() > 0 {
+		return usageError(fs, "config takes no arguments")
+	}
+
+	cfg := config.LoadConfig()
+	for _, secret := range []*string{&cfg.OpenAIAPIKey, &cfg.AnthropicKey, &cfg.LLMAPIKey, &cfg.ProxyPassword, &cfg.CaptchaKey, &cfg.TelegramToken, &cfg.WebhookSecret, &cfg.APIToken} {
+		*secret = maskSecret(*secret)
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return exitFailed
+	}
+	fmt.Println(string(data))
+	return exitOK
+}
+
+// maskSecret hides all but the last characters of a key.
+func maskSecret(secret string) string {
+	if len(secret) <= 8 {
+		return strings.Repeat("*", len(secret))
+	}
+	return strings.Repeat("*", 8) + secret[len(secret)-4:]
+}
@@ -1,11 +1,16 @@
 package main
 
 import (
-	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/joho/godotenv"
@@ -14,128 +19,454 @@ import (
 	"github.com/VolodyaPopov923/AIBot/internal/agent"
 	"github.com/VolodyaPopov923/AIBot/internal/ai"
 	"github.com/VolodyaPopov923/AIBot/internal/browser"
+	"github.com/VolodyaPopov923/AIBot/internal/captcha"
+	"github.com/VolodyaPopov923/AIBot/internal/dashboard"
+	"github.com/VolodyaPopov923/AIBot/internal/fetch"
+	"github.com/VolodyaPopov923/AIBot/internal/playbook"
+	"github.com/VolodyaPopov923/AIBot/internal/prompts"
+	"github.com/VolodyaPopov923/AIBot/internal/scheduler"
 )
 
+// command is a subcommand of the agent binary.
+type command struct {
+	name    string
+	args    string // what follows the name in the usage line
+	summary string
+	run     func(args []string) int
+}
+
+func commands() []command {
+	return []command{
+		{"run", "[flags] <task>", "Run one task and exit with a status code", runCommand},
+		{"repl", "[flags]", "Start the interactive prompt (the default without a command)", replCommand},
+		{"serve", "[flags]", "Accept tasks over the HTTP API", serveCommand},
+		{"daemon", "[flags]", "Keep a browser running and take tasks from agentctl over a Unix socket", daemonCommand},
+		{"telegram", "[flags]", "Take tasks from a Telegram bot and reply with the results", telegramCommand},
+		{"screenshot", "[flags] <file.png>", "Open a page and save a screenshot of it", screenshotCommand},
+		{"tabs", "[flags]", "List the open tabs, e.g. of a browser attached with -cdp", tabsCommand},
+		{"login", "[flags] <site>", "Log in to a site, from the credential store or by hand, and save the session", loginCommand},
+		{"session", "<save|load|list|delete|clear|import-cookies> [flags] [name|file]", "Manage the logins and cookies of a browser profile and named sessions", sessionCommand},
+		{"config", "", "Print the configuration read from the environment and .env", configCommand},
+	}
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
 func main() {
 	_ = godotenv.Load()
+	os.Exit(dispatch(os.Args[1:]))
+}
+
+// dispatch runs the command named by the first argument. Without one the
+// interactive prompt starts; flags without a command are the flags of
+// earlier versions, which had no commands.
+func dispatch(args []string) int {
+	if len(args) == 0 {
+		return replCommand(nil)
+	}
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		if len(args) > 1 {
+			if cmd, ok := findCommand(args[1]); ok {
+				return cmd.run([]string{"-h"})
+			}
+		}
+		usage(os.Stdout)
+		return exitOK
+	}
+	if strings.HasPrefix(args[0], "-") {
+		return legacyCommand(args)
+	}
+	cmd, ok := findCommand(args[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+		usage(os.Stderr)
+		return exitUsage
+	}
+	return cmd.run(args[1:])
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "AI browser automation agent")
+	fmt.Fprintln(w, "\nUsage: agent <command> [flags] [arguments]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-11s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w, "\nRun \"agent help <command>\" or \"agent <command> -h\" for its flags.")
+}
+
+// newFlagSet creates the flags of a command; its help names the command and
+// its arguments.
+func newFlagSet(name string) *flag.FlagSet {
+	cmd, _ := findCommand(name)
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: agent %s %s\n\n%s.\n", cmd.name, cmd.args, cmd.summary)
+		hasFlags := false
+		fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Fprintln(fs.Output(), "\nFlags:")
+			fs.PrintDefaults()
+		}
+	}
+	return fs
+}
+
+// parseFlags parses a command's arguments. When the command should stop, e.g.
+// after -h, it returns false and the exit code.
+func parseFlags(fs *flag.FlagSet, args []string) (int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+	return exitOK, true
+}
 
-	ctx := context.Background()
+// usageError reports wrong arguments of a command along with its help.
+func usageError(fs *flag.FlagSet, message string) int {
+	fmt.Fprintf(fs.Output(), "%s\n\n", message)
+	fs.Usage()
+	return exitUsage
+}
+
+// browserFlags choose the browser a command launches or attaches to.
+type browserFlags struct {
+	profile string
+	cdp     string
+	device  string
+	session string // named session or storage state file loaded at start
+}
+
+func addBrowserFlags(fs *flag.FlagSet) *browserFlags {
+	var f browserFlags
+	fs.StringVar(&f.profile, "profile", os.Getenv("BROWSER_PROFILE"), "browser profile name (stored under the user data dir)")
+	fs.StringVar(&f.cdp, "cdp", "", "attach to a running Chrome at this DevTools endpoint, e.g. http://localhost:9222 (overrides BROWSER_CDP_ENDPOINT)")
+	fs.StringVar(&f.device, "device", "", "emulate a device preset, e.g. \"iPhone 14\" (overrides BROWSER_DEVICE)")
+	fs.StringVar(&f.session, "session", "", "start logged in with a session saved by \"session save\", by name or file")
+	return &f
+}
+
+// agentFlags are the flags of the commands that run tasks.
+type agentFlags struct {
+	*browserFlags
+	haltOnDestructive bool
+	confirmEachStep   bool
+	events            bool
+	resultFile        string
+}
 
+func addAgentFlags(fs *flag.FlagSet) *agentFlags {
+	f := agentFlags{browserFlags: addBrowserFlags(fs)}
+	fs.BoolVar(&f.haltOnDestructive, "halt-on-destructive", false, "stop a task at the first destructive action instead of asking for confirmation")
+	fs.BoolVar(&f.confirmEachStep, "confirm-each-step", false, "show every action and run it only after you approve it")
+	fs.BoolVar(&f.events, "events", false, "write step-level progress events as JSON lines to stderr")
+	fs.StringVar(&f.resultFile, "result-file", "", "write the result of each task as JSON to this file")
+	return &f
+}
+
+// newBrowser launches the browser, or attaches to a running one, as the
+// environment and flags say.
+func newBrowser(ctx context.Context, cfg config.Config, flags *browserFlags) *browser.Manager {
 	fmt.Println("🚀 Initializing browser...")
-	browserMgr, err := browser.NewManager(ctx)
+	var launch browser.LaunchOptions
+	if cfg.ProxyServer != "" {
+		launch.Proxy = &browser.ProxyConfig{
+			Server:   cfg.ProxyServer,
+			Username: cfg.ProxyUsername,
+			Password: cfg.ProxyPassword,
+			Bypass:   browser.ParseProxyBypass(cfg.ProxyBypass),
+		}
+		fmt.Printf("🌍 Using proxy %s\n", cfg.ProxyServer)
+	}
+	launch.CDPEndpoint = cfg.CDPEndpoint
+	if flags.cdp != "" {
+		launch.CDPEndpoint = flags.cdp
+	}
+	if launch.CDPEndpoint != "" {
+		fmt.Println("🔌 Connecting to a running browser over CDP")
+	}
+	launch.Device = cfg.Device
+	if flags.device != "" {
+		launch.Device = flags.device
+	}
+	if cfg.Viewport != "" {
+		viewport, err := browser.ParseViewport(cfg.Viewport)
+		if err != nil {
+			log.Fatalf("Invalid BROWSER_VIEWPORT: %v\n", err)
+		}
+		launch.Viewport = viewport
+	}
+	launch.DeviceScaleFactor = cfg.ScaleFactor
+	launch.UserAgent = cfg.UserAgent
+	launch.Mobile = cfg.Mobile
+	launch.Stealth = cfg.Stealth
+	launch.Locale = cfg.Locale
+	launch.TimezoneID = cfg.Timezone
+	if launch.Device != "" {
+		fmt.Printf("📱 Emulating %s\n", launch.Device)
+	}
+	browserMgr, err := browser.NewManagerWithOptions(ctx, flags.profile, launch)
 	if err != nil {
 		log.Fatalf("Failed to initialize browser: %v\n", err)
 	}
-	defer browserMgr.Close(ctx)
+	if flags.session != "" {
+		file, err := sessionFile(cfg, flags.session)
+		if err == nil {
+			err = browserMgr.LoadStorageState(ctx, file)
+		}
+		if err != nil {
+			browserMgr.Close(ctx)
+			log.Fatalf("Failed to load session %s: %v\n", flags.session, err)
+		}
+		fmt.Printf("🔑 Session %s loaded\n", flags.session)
+	}
+	return browserMgr
+}
 
-	fmt.Println("🤖 Initializing AI client...")
-	cfg := config.LoadConfig()
-	if cfg.OpenAIAPIKey == "" {
-		log.Fatal("OPENAI_API_KEY not available")
+// sessionsDir is where named sessions are kept.
+func sessionsDir(cfg config.Config) string {
+	if cfg.SessionsDir != "" {
+		return cfg.SessionsDir
 	}
-	aiClient := ai.NewClient(cfg.OpenAIAPIKey)
+	return browser.DefaultSessionsDir()
+}
 
-	agentInstance := agent.NewAgent(browserMgr, aiClient, true)
+// sessionFile resolves a session name, or returns a file path as it is.
+func sessionFile(cfg config.Config, arg string) (string, error) {
+	return browser.SessionFile(sessionsDir(cfg), arg)
+}
 
-	reader := bufio.NewReader(os.Stdin)
+// session is what the task commands share: the browser, the model and the
+// agent driving both.
+type session struct {
+	cfg     config.Config
+	browser *browser.Manager
+	ai      ai.Provider
+	cache   *ai.ResponseCache // nil unless LLM_CACHE is on
+	agent   *agent.Agent
+	// dashboard, if set, shows running tasks instead of the scrolling log.
+	dashboard *dashboard.Dashboard
+}
 
-	fmt.Println("\n" + strings.Repeat("=", 60))
-	fmt.Println("AI Browser Automation Agent")
-	fmt.Println("You can:")
-	fmt.Println("  - Type natural language requests (e.g., 'зайди на яндекс карты и найди кремль')")
-	fmt.Println("  - Use commands: task <URL> <description>, go <URL>, exit")
-	fmt.Println(strings.Repeat("=", 60))
+// openSession launches the browser and sets up the model and the agent.
+func openSession(ctx context.Context, cfg config.Config, flags *agentFlags) *session {
+	s := &session{cfg: cfg, browser: newBrowser(ctx, cfg, flags.browserFlags)}
 
-	for {
-		fmt.Print("\n> ")
-		input, err := reader.ReadString('\n')
-		if err != nil {
-			log.Printf("Error reading input: %v\n", err)
-			continue
+	if cfg.PromptsDir != "" {
+		if err := prompts.LoadOverrides(cfg.PromptsDir); err != nil {
+			log.Fatalf("Failed to load prompt templates: %v\n", err)
 		}
-		input = strings.TrimSpace(input)
-		input = strings.Trim(input, "\r\n")
-		input = strings.TrimPrefix(input, ">")
-		input = strings.TrimSpace(input)
+		fmt.Printf("📝 Using prompt templates from %s\n", cfg.PromptsDir)
+	}
 
-		if input == "" {
-			continue
+	fmt.Println("🤖 Initializing AI client...")
+	if cfg.OpenAIAPIKey == "" && (cfg.AIProvider == "" || cfg.AIProvider == ai.ProviderOpenAI) {
+		log.Fatal("OPENAI_API_KEY not available")
+	}
+	apiKey := cfg.OpenAIAPIKey
+	switch cfg.AIProvider {
+	case ai.ProviderAnthropic:
+		if cfg.AnthropicKey == "" {
+			log.Fatal("ANTHROPIC_API_KEY not available")
 		}
+		apiKey = cfg.AnthropicKey
+	case ai.ProviderOllama, ai.ProviderOpenAICompatible:
+		// LLM_BASE_URL may point anywhere, so the OpenAI key is never sent there.
+		apiKey = cfg.LLMAPIKey
+	}
+	providerCfg := ai.ProviderConfig{
+		Name:    cfg.AIProvider,
+		APIKey:  apiKey,
+		BaseURL: cfg.LLMBaseURL,
+		Model:   cfg.LLMModel,
+		Timeout: cfg.LLMTimeout,
+	}
+	if cfg.LLMCache {
+		cacheDir := cfg.CacheDir
+		if cacheDir == "" {
+			cacheDir = ai.DefaultCacheDir()
+		}
+		responseCache, err := ai.NewResponseCache(cacheDir, cfg.CacheTTL)
+		if err != nil {
+			log.Printf("Warning: %v\n", err)
+		} else {
+			providerCfg.Cache = responseCache
+			s.cache = responseCache
+		}
+	}
+	aiClient, err := ai.NewProvider(providerCfg)
+	if err != nil {
+		log.Fatalf("Failed to create AI provider: %v\n", err)
+	}
+	if cfg.FastModel != "" {
+		providerCfg.Model = cfg.FastModel
+		fast, err := ai.NewProvider(providerCfg)
+		if err != nil {
+			log.Fatalf("Failed to create AI provider for LLM_FAST_MODEL: %v\n", err)
+		}
+		aiClient = ai.NewRouter(fast, aiClient)
+		fmt.Printf("🔀 Routing routine decisions to %s\n", cfg.FastModel)
+	}
+	s.ai = aiClient
 
-		parts := strings.Fields(input)
-		if len(parts) == 0 {
-			continue
+	agentInstance := agent.NewAgent(s.browser, aiClient, true)
+	agentInstance.HaltOnDestructive = flags.haltOnDestructive
+	agentInstance.ConfirmEachStep = flags.confirmEachStep
+	agentInstance.UseVision = cfg.Vision
+	agentInstance.UseElementMarks = cfg.ElementMarks
+	agentInstance.UseAccessibilityTree = cfg.A11yTree
+	agentInstance.AllowEvaluate = cfg.AllowEvaluate
+	agentInstance.RecordNetwork = cfg.RecordNetwork
+	agentInstance.VerifySteps = cfg.Verify
+	if cfg.PlaybooksDir != "" {
+		playbooks, err := playbook.LoadDir(cfg.PlaybooksDir)
+		if err != nil {
+			log.Fatalf("Failed to load playbooks: %v\n", err)
+		}
+		agentInstance.Playbooks = playbooks
+		fmt.Printf("📒 Loaded %d playbook(s) from %s\n", len(playbooks), cfg.PlaybooksDir)
+	}
+	if cfg.Selectors {
+		selectorFile := cfg.SelectorFile
+		if selectorFile == "" {
+			selectorFile = agent.DefaultSelectorMemoryPath()
+		}
+		selectors, err := agent.NewSelectorMemory(selectorFile)
+		if err != nil {
+			log.Printf("Warning: %v\n", err)
+		} else {
+			agentInstance.Selectors = selectors
+		}
+	}
+	agentInstance.MaxCrawlPages = cfg.CrawlPages
+	agentInstance.Budget = agent.Budget{MaxTokens: cfg.TaskTokens, MaxCostUSD: cfg.TaskCost, MaxDuration: cfg.TaskTimeout}
+	if _, err := browser.LookupSearchEngine(cfg.SearchEngine); err != nil {
+		log.Fatalf("Invalid SEARCH_ENGINE: %v\n", err)
+	}
+	agentInstance.SearchEngine = cfg.SearchEngine
+	if cfg.HTTPFetch {
+		fetcher, err := newFetcher(cfg)
+		if err != nil {
+			log.Fatalf("Invalid PROXY_SERVER: %v\n", err)
+		}
+		fetcher.Cookies = s.browser.HTTPCookies
+		agentInstance.Fetcher = fetcher
+	}
+	if cfg.Captcha != "" {
+		solver, err := captcha.NewSolver(cfg.Captcha, cfg.CaptchaKey)
+		if err != nil {
+			log.Fatalf("Failed to create CAPTCHA solver: %v\n", err)
+		}
+		agentInstance.CaptchaSolver = solver
+	}
+	if flags.events {
+		encoder := json.NewEncoder(os.Stderr)
+		agentInstance.OnEvent = func(event agent.Event) {
+			_ = encoder.Encode(event)
 		}
-		command := strings.ToLower(parts[0])
+	}
+	s.agent = agentInstance
+	return s
+}
 
-		switch command {
-		case "exit", "quit":
-			fmt.Println("Goodbye!")
-			return
+// Close shuts the browser down.
+func (s *session) Close(ctx context.Context) {
+	if err := s.browser.Close(ctx); err != nil {
+		log.Printf("Warning: %v\n", err)
+	}
+}
 
-		case "task":
-			if len(parts) < 3 {
-				fmt.Println("Usage: task <URL> <description>")
-				continue
-			}
-			url := parts[1]
-			taskDesc := strings.Join(parts[2:], " ")
-
-			fmt.Printf("\n📋 Executing task: %s\n", taskDesc)
-			if err := agentInstance.ExecuteTask(ctx, taskDesc, url); err != nil {
-				fmt.Printf("❌ Task failed: %v\n", err)
-			} else {
-				fmt.Println("✅ Task completed successfully!")
-			}
+func addTUIFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("tui", false, "while a task runs, show a dashboard of its plan, reasoning, tabs and cost instead of the log")
+}
 
-		case "go":
-			if len(parts) < 2 {
-				fmt.Println("Usage: go <URL>")
-				continue
-			}
-			url := parts[1]
-			fmt.Printf("🌐 Navigating to %s...\n", url)
-			if err := browserMgr.Navigate(ctx, url); err != nil {
-				fmt.Printf("❌ Navigation failed: %v\n", err)
-			} else {
-				fmt.Println("✅ Navigation successful!")
-			}
+// useDashboard makes tasks started from the terminal show the dashboard.
+// The log goes through the dashboard from now on: shown in it while a task
+// is watched, and on stderr as usual otherwise.
+func (s *session) useDashboard() {
+	s.dashboard = dashboard.New(os.Stdout)
+	s.dashboard.Tabs = s.browser.ListOpenPages
+	s.dashboard.Passthrough = os.Stderr
+	log.SetOutput(s.dashboard)
+}
 
-		default:
-			fmt.Printf("🤔 Parsing your request: %s\n", input)
-			parsed, err := aiClient.ParseUserRequest(ctx, input)
-			if err != nil {
-				fmt.Printf("❌ Failed to parse request: %v\n", err)
-				continue
-			}
+// watch runs a task, shown on the dashboard if there is one; the result is
+// printed as usual once it is gone. Only the events of the task run with the
+// ctx given to run reach the dashboard, not those of a scheduled task.
+func (s *session) watch(ctx context.Context, task string, run func(ctx context.Context)) {
+	d := s.dashboard
+	if d == nil {
+		run(ctx)
+		return
+	}
+	d.Start(task)
+	defer d.Stop()
+	run(agent.WithEvents(ctx, d.HandleEvent))
+}
 
-			if parsed.NeedsURL && parsed.URL != "" {
-				fmt.Printf("🌐 Opening: %s\n", parsed.URL)
-				if err := browserMgr.Navigate(ctx, parsed.URL); err != nil {
-					if !strings.Contains(err.Error(), "page closed") {
-						fmt.Printf("❌ Navigation failed: %v\n", err)
-						continue
-					}
-					fmt.Printf("⚠️  Page closed during navigation (possibly CAPTCHA) - continuing...\n")
-				}
-				_ = browserMgr.WaitForNavigation(ctx)
-			}
+// startScheduler loads the scheduled tasks and runs them in the background
+// until ctx is done.
+func startScheduler(ctx context.Context, s *session) *scheduler.Scheduler {
+	scheduleFile := s.cfg.ScheduleFile
+	if scheduleFile == "" {
+		scheduleFile = scheduler.DefaultPath()
+	}
+	schedules, err := scheduler.New(scheduleFile, unattendedRunner{s.agent})
+	if err != nil {
+		log.Fatalf("Failed to load schedules: %v\n", err)
+	}
+	schedules.ReportDir = s.cfg.ReportsDir
+	if schedules.ReportDir == "" {
+		schedules.ReportDir = filepath.Join(filepath.Dir(scheduleFile), "reports")
+	}
+	if jobs := schedules.Jobs(); len(jobs) > 0 {
+		fmt.Printf("⏰ %d scheduled task(s), reports go to %s\n", len(jobs), schedules.ReportDir)
+	}
+	go schedules.Run(ctx)
+	return schedules
+}
 
-			if parsed.Task != "" {
-				url := parsed.URL
-				if url == "" {
-					pageContent, _ := browserMgr.GetPageContent(ctx)
-					url = pageContent.URL
-				}
-				fmt.Printf("📋 Executing task: %s\n", parsed.Task)
-				if err := agentInstance.ExecuteTask(ctx, parsed.Task, url); err != nil {
-					fmt.Printf("❌ Task failed: %v\n", err)
-				} else {
-					fmt.Println("✅ Task completed successfully!")
-				}
-			} else {
-				fmt.Printf("ℹ️  %s\n", parsed.Reasoning)
-			}
+// unattendedRunner runs scheduled tasks without prompting: they fire in the
+// background, where a prompt would fight the interactive one for stdin.
+type unattendedRunner struct {
+	agent *agent.Agent
+}
+
+func (r unattendedRunner) ExecuteTask(ctx context.Context, task string, initialURL string) (*agent.TaskResult, error) {
+	return r.agent.ExecuteTaskUnattended(ctx, task, initialURL)
+}
+
+// newFetcher builds the HTTP fetcher of fetch actions with the browser's proxy
+// and user agent, so both reach sites the same way.
+func newFetcher(cfg config.Config) (*fetch.Fetcher, error) {
+	var proxy *url.URL
+	if cfg.ProxyServer != "" {
+		server := cfg.ProxyServer
+		if !strings.Contains(server, "://") {
+			server = "http://" + server
 		}
+		parsed, err := url.Parse(server)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.ProxyUsername != "" {
+			parsed.User = url.UserPassword(cfg.ProxyUsername, cfg.ProxyPassword)
+		}
+		proxy = parsed
+	}
+	fetcher := fetch.New(proxy)
+	if cfg.UserAgent != "" {
+		fetcher.UserAgent = cfg.UserAgent
 	}
+	return fetcher, nil
 }
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/VolodyaPopov923/AIBot/internal/agent"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+// Exit codes of the run command, so scripts can tell why a task did not
+// succeed.
+const (
+	exitOK        = 0
+	exitFailed    = 1 // the task failed
+	exitUsage     = 2 // invalid flags or arguments
+	exitHalted    = 3 // stopped before a destructive action, see pending_action
+	exitManual    = 4 // needed a person, e.g. for 2FA or a question
+	exitBudget    = 5 // ran out of tokens, money or time
+	exitAssertion = 6 // a check of an assert action failed
+	exitCancelled = 130
+)
+
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, context.Canceled):
+		return exitCancelled
+	case errors.Is(err, agent.ErrDestructiveActionHalted):
+		return exitHalted
+	case errors.Is(err, agent.ErrManualStepRequired):
+		return exitManual
+	case errors.Is(err, agent.ErrBudgetExceeded):
+		return exitBudget
+	case errors.Is(err, agent.ErrAssertionFailed):
+		return exitAssertion
+	}
+	return exitFailed
+}
+
+// reportResult prints a task result and, if resultFile is set, saves it as JSON.
+func reportResult(result *agent.TaskResult, err error, resultFile string) {
+	if errors.Is(err, context.Canceled) {
+		fmt.Println("⏹  Task cancelled")
+	} else if err != nil {
+		fmt.Printf("❌ Task failed: %v\n", err)
+	} else {
+		fmt.Println("✅ Task completed successfully!")
+	}
+	printResult(result)
+
+	if err := saveResult(result, resultFile); err != nil {
+		fmt.Printf("⚠️  Failed to save result: %v\n", err)
+	}
+}
+
+// saveResult writes a task result as JSON if resultFile is set.
+func saveResult(result *agent.TaskResult, resultFile string) error {
+	if resultFile == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(resultFile, data, 0o644)
+}
+
+// jsonResult is the last line of -output json: how the task ended.
+type jsonResult struct {
+	Type     string            `json:"type"` // always "result", events carry their own type
+	ExitCode int               `json:"exit_code"`
+	Error    string            `json:"error,omitempty"`
+	Result   *agent.TaskResult `json:"result"`
+}
+
+// jsonOutput leaves stdout to JSON lines for programs wrapping the agent:
+// the emoji output for people goes nowhere, and the returned encoder writes
+// to the real stdout. Log lines still reach stderr until quietLog.
+func jsonOutput() (*json.Encoder, error) {
+	stdout := os.Stdout
+	null, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	os.Stdout = null
+	return json.NewEncoder(stdout), nil
+}
+
+// quietLog drops the agent's log chatter once setup errors can no longer
+// happen.
+func quietLog() {
+	log.SetOutput(io.Discard)
+}
+
+// saveStructured writes extracted data as CSV if path ends in .csv, else as JSON.
+func saveStructured(path string, data browser.StructuredData) error {
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", path, err)
+		}
+		if err := data.WriteCSV(f); err != nil {
+			_ = f.Close()
+			return err
+		}
+		return f.Close()
+	}
+	raw, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode extracted data: %w", err)
+	}
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+func printResult(result *agent.TaskResult) {
+	if result.Answer != "" {
+		fmt.Printf("💬 Answer: %s\n", result.Answer)
+	}
+	for _, item := range result.Extracted {
+		fmt.Printf("   • %s\n", item)
+	}
+	for _, data := range result.Structured {
+		fmt.Printf("📊 %s: %d table(s), %d list(s), %d JSON-LD object(s)\n", data.URL, len(data.Tables), len(data.Lists), len(data.JSONLD))
+	}
+	for _, path := range result.Screenshots {
+		fmt.Printf("📸 %s\n", path)
+	}
+	for _, path := range result.Downloads {
+		fmt.Printf("📥 %s\n", path)
+	}
+	if result.PendingAction != nil {
+		fmt.Printf("⏸️  Pending action: %s %s (%s)\n", result.PendingAction.Action, result.PendingAction.Selector, result.PendingAction.Reasoning)
+	}
+	usage := result.TokenUsage
+	fmt.Printf("🔗 Final URL: %s | steps: %d | %d tokens (%d prompt + %d completion) | %s | %s\n",
+		result.FinalURL, len(result.Steps), usage.TotalTokens, usage.PromptTokens, usage.CompletionTokens,
+		formatCost(usage.CostUSD), result.Duration.Round(time.Second))
+}
+
+// formatCost prints a dollar cost; "no cost" stands for local models and
+// backends that report no usage.
+func formatCost(usd float64) string {
+	if usd == 0 {
+		return "no cost"
+	}
+	return fmt.Sprintf("$%.4f", usd)
+}
+
+// serve runs the HTTP API. Nobody is at the terminal to answer prompts, so
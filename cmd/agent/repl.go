@@ -0,0 +1,587 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/VolodyaPopov923/AIBot/internal/agent"
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+	"github.com/VolodyaPopov923/AIBot/internal/lineedit"
+	"github.com/VolodyaPopov923/AIBot/internal/scheduler"
+	"github.com/VolodyaPopov923/AIBot/pkg/utils"
+)
+
+// repl reads commands and natural language requests from stdin until exit.
+func repl(ctx context.Context, s *session, reader *bufio.Reader, schedules *scheduler.Scheduler, resultFile string) {
+	browserMgr, agentInstance, aiClient, cfg := s.browser, s.agent, s.ai, s.cfg
+	if streamer, ok := aiClient.(ai.Streamer); ok && cfg.Stream {
+		streamer.SetStream(os.Stdout)
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("AI Browser Automation Agent")
+	fmt.Println("You can:")
+	fmt.Println("  - Type natural language requests (e.g., 'зайди на яндекс карты и найди кремль')")
+	fmt.Println("  - Use commands: task [--isolated] <URL> <description>, go <URL>, search <query>, screenshot <path> [selector], save_macro <file>, replay <file>, export_script <file.go>, cookies [url], import_cookies <file>, clear_cookies [domain], save_state <name|file>, load_state <name|file>, save_har <file>, schedule <cron> <URL> <description>, schedules, unschedule <id>, extract <file.json|file.csv> [selector], stats, cache clear, switch_profile <name>, clear_session, exit")
+	fmt.Println("  - Use ↑/↓ for earlier lines, Tab to complete commands and URLs, Ctrl-D to exit")
+	fmt.Println("  - Press Ctrl-C during a task to pause it (e.g. to log in by hand), then resume or cancel it")
+	fmt.Println(strings.Repeat("=", 60))
+
+	historyFile := cfg.HistoryFile
+	if historyFile == "" {
+		historyFile = lineedit.DefaultHistoryPath()
+	}
+	editor, err := lineedit.New(reader, historyFile)
+	if err != nil {
+		log.Printf("Warning: %v\n", err)
+	}
+	// Questions of a task are answered on the same input as the prompt.
+	agentInstance.ShareStdin(reader)
+
+	var lastResult *agent.TaskResult
+	editor.Complete = func(before string) []string {
+		return completeInput(before, recentURLs(editor.History(), lastResult))
+	}
+	// release lets tasks use the browser again after a command that drives
+	// it directly.
+	release := func() {}
+	defer func() { release() }()
+	for {
+		release()
+		fmt.Println()
+		input, err := editor.ReadLine("> ")
+		if errors.Is(err, lineedit.ErrInterrupt) {
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			fmt.Println("Goodbye!")
+			return
+		}
+		if err != nil {
+			log.Printf("Error reading input: %v\n", err)
+			continue
+		}
+		if err := editor.AddHistory(input); err != nil {
+			log.Printf("Warning: %v\n", err)
+		}
+		input = strings.TrimSpace(input)
+		input = strings.Trim(input, "\r\n")
+		input = strings.TrimPrefix(input, ">")
+		input = strings.TrimSpace(input)
+
+		if input == "" {
+			continue
+		}
+
+		parts := strings.Fields(input)
+		if len(parts) == 0 {
+			continue
+		}
+		command := strings.ToLower(parts[0])
+		if browserCommands[command] {
+			// Wait for a scheduled task to be done with the browser, and
+			// keep the next one from starting under this command.
+			release = agentInstance.HoldBrowser()
+		}
+
+		switch command {
+		case "exit", "quit":
+			fmt.Println("Goodbye!")
+			return
+
+		case "task":
+			isolated := len(parts) > 1 && parts[1] == "--isolated"
+			if isolated {
+				parts = append(parts[:1], parts[2:]...)
+			}
+			if len(parts) < 3 {
+				fmt.Println("Usage: task [--isolated] <URL> <description>")
+				continue
+			}
+			url := parts[1]
+			taskDesc := strings.Join(parts[2:], " ")
+
+			fmt.Printf("\n📋 Executing task: %s\n", taskDesc)
+			lastResult = runTask(ctx, s, reader, taskDesc, url, resultFile, isolated)
+
+		case "schedule":
+			spec, rest, err := splitSchedule(parts[1:])
+			if err != nil || len(rest) < 2 {
+				fmt.Println("Usage: schedule <cron|@daily|@every 1h> <URL> <description>, e.g. schedule 0 8 * * * https://shop.example/item check the price")
+				continue
+			}
+			job, err := schedules.Add(spec, strings.Join(rest[1:], " "), rest[0])
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				continue
+			}
+			fmt.Printf("⏰ Scheduled %s (%s), next run %s\n", job.ID, job.Schedule, job.NextRun.Format(time.RFC1123))
+
+		case "schedules":
+			jobs := schedules.Jobs()
+			for _, job := range jobs {
+				status := "never run"
+				if job.LastRun != nil {
+					status = "last run " + job.LastRun.Format(time.RFC1123)
+					if job.LastError != "" {
+						status += " (failed: " + utils.TruncateText(job.LastError, 80) + ")"
+					}
+				}
+				fmt.Printf("⏰ %s  %-15s next %s, %s\n   %s %s\n", job.ID, job.Schedule, job.NextRun.Format(time.RFC1123), status, job.URL, job.Task)
+			}
+			fmt.Printf("%d scheduled task(s)\n", len(jobs))
+
+		case "unschedule":
+			if len(parts) < 2 {
+				fmt.Println("Usage: unschedule <id>")
+				continue
+			}
+			if err := schedules.Remove(parts[1]); err != nil {
+				fmt.Printf("❌ %v\n", err)
+			} else {
+				fmt.Printf("🗑️  Removed scheduled task %s\n", parts[1])
+			}
+
+		case "screenshot":
+			if len(parts) < 2 {
+				fmt.Println("Usage: screenshot <path> [selector]")
+				continue
+			}
+			opts := browser.ScreenshotOptions{Path: parts[1], FullPage: true}
+			if len(parts) > 2 {
+				opts.Selector = strings.Join(parts[2:], " ")
+				opts.FullPage = false
+			}
+			if _, err := browserMgr.Screenshot(ctx, opts); err != nil {
+				fmt.Printf("❌ Screenshot failed: %v\n", err)
+			} else {
+				fmt.Printf("📸 Screenshot saved to %s\n", opts.Path)
+			}
+
+		case "save_macro":
+			if len(parts) < 2 {
+				fmt.Println("Usage: save_macro <file>")
+				continue
+			}
+			if lastResult == nil || !lastResult.Success {
+				fmt.Println("❌ No successfully completed task to save")
+				continue
+			}
+			if err := agent.SaveMacro(parts[1], agent.NewMacro(lastResult)); err != nil {
+				fmt.Printf("❌ %v\n", err)
+			} else {
+				fmt.Printf("💾 Macro saved to %s\n", parts[1])
+			}
+
+		case "export_script":
+			if len(parts) < 2 {
+				fmt.Println("Usage: export_script <file.go>")
+				continue
+			}
+			if lastResult == nil || !lastResult.Success {
+				fmt.Println("❌ No successfully completed task to export")
+				continue
+			}
+			src, err := agent.ExportGoScript(agent.NewMacro(lastResult))
+			if err == nil {
+				err = os.WriteFile(parts[1], src, 0o644)
+			}
+			if err != nil {
+				fmt.Printf("❌ Export failed: %v\n", err)
+			} else {
+				fmt.Printf("📝 Playwright script written to %s\n", parts[1])
+			}
+
+		case "replay":
+			if len(parts) < 2 {
+				fmt.Println("Usage: replay <file>")
+				continue
+			}
+			macro, err := agent.LoadMacro(parts[1])
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				continue
+			}
+			fmt.Printf("\n🔁 Replaying %d action(s): %s\n", len(macro.Actions), macro.Task)
+			var result *agent.TaskResult
+			runControlled(ctx, agentInstance, reader, func(ctx context.Context) {
+				result, err = agentInstance.ReplayMacro(ctx, macro)
+			})
+			reportResult(result, err, resultFile)
+
+		case "cookies":
+			cookies, err := browserMgr.GetCookies(ctx, parts[1:]...)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				continue
+			}
+			for _, c := range cookies {
+				fmt.Printf("🍪 %s=%s (%s%s)\n", c.Name, utils.TruncateText(c.Value, 40), c.Domain, c.Path)
+			}
+			fmt.Printf("%d cookie(s)\n", len(cookies))
+
+		case "import_cookies":
+			if len(parts) < 2 {
+				fmt.Println("Usage: import_cookies <file.json>")
+				continue
+			}
+			cookies, err := browser.LoadCookiesFile(parts[1])
+			if err == nil {
+				err = browserMgr.SetCookies(ctx, cookies)
+			}
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+			} else {
+				fmt.Printf("🍪 Imported %d cookie(s)\n", len(cookies))
+			}
+
+		case "clear_cookies":
+			domain := ""
+			if len(parts) > 1 {
+				domain = parts[1]
+			}
+			if err := browserMgr.ClearCookies(ctx, domain); err != nil {
+				fmt.Printf("❌ %v\n", err)
+			} else {
+				fmt.Println("🧹 Cookies cleared")
+			}
+
+		case "save_state":
+			if len(parts) < 2 {
+				fmt.Println("Usage: save_state <name|file.json>")
+				continue
+			}
+			file, err := sessionFile(s.cfg, parts[1])
+			if err == nil {
+				err = browserMgr.SaveStorageState(file)
+			}
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+			} else {
+				fmt.Printf("💾 Session saved to %s\n", file)
+			}
+
+		case "save_har":
+			if len(parts) < 2 {
+				fmt.Println("Usage: save_har <file.har>")
+				continue
+			}
+			entries := browserMgr.NetworkLog()
+			if err := browser.WriteHAR(parts[1], entries); err != nil {
+				fmt.Printf("❌ %v\n", err)
+			} else {
+				fmt.Printf("🌐 %d request(s) of the last task saved to %s\n", len(entries), parts[1])
+			}
+
+		case "extract":
+			if len(parts) < 2 {
+				fmt.Println("Usage: extract <file.json|file.csv> [selector]")
+				continue
+			}
+			data, err := browserMgr.ExtractStructured(ctx, strings.Join(parts[2:], " "))
+			if err == nil {
+				err = saveStructured(parts[1], data)
+			}
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+			} else {
+				fmt.Printf("📊 %d table(s), %d list(s) and %d JSON-LD object(s) saved to %s\n", len(data.Tables), len(data.Lists), len(data.JSONLD), parts[1])
+			}
+
+		case "load_state":
+			if len(parts) < 2 {
+				fmt.Println("Usage: load_state <name|file.json>")
+				continue
+			}
+			file, err := sessionFile(s.cfg, parts[1])
+			if err == nil {
+				err = browserMgr.LoadStorageState(ctx, file)
+			}
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+			} else {
+				fmt.Printf("🔑 Session loaded from %s\n", file)
+			}
+
+		case "go":
+			if len(parts) < 2 {
+				fmt.Println("Usage: go <URL>")
+				continue
+			}
+			url := parts[1]
+			fmt.Printf("🌐 Navigating to %s...\n", url)
+			if err := browserMgr.Navigate(ctx, url); err != nil {
+				fmt.Printf("❌ Navigation failed: %v\n", err)
+			} else {
+				fmt.Println("✅ Navigation successful!")
+			}
+
+		case "search":
+			if len(parts) < 2 {
+				fmt.Println("Usage: search <query>")
+				continue
+			}
+			engine, _ := browser.LookupSearchEngine(cfg.SearchEngine)
+			results, err := browserMgr.Search(ctx, engine, strings.Join(parts[1:], " "), 0)
+			if err != nil {
+				fmt.Printf("❌ Search failed: %v\n", err)
+				continue
+			}
+			for i, result := range results {
+				fmt.Printf("%2d. %s\n    %s\n", i+1, result.Title, result.URL)
+			}
+
+		case "cache":
+			if len(parts) < 2 || parts[1] != "clear" {
+				fmt.Println("Usage: cache clear")
+				continue
+			}
+			if s.cache == nil {
+				fmt.Println("ℹ️  The response cache is disabled")
+				continue
+			}
+			removed, err := s.cache.Clear()
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				continue
+			}
+			fmt.Printf("🧹 Removed %d cached response(s)\n", removed)
+
+		case "stats":
+			totals := agentInstance.Totals()
+			fmt.Printf("📈 %d task(s) | %d tokens (%d prompt + %d completion) | %s\n",
+				totals.Tasks, totals.TotalTokens, totals.PromptTokens, totals.CompletionTokens, formatCost(totals.CostUSD))
+
+		case "switch_profile":
+			name := ""
+			if len(parts) > 1 {
+				name = parts[1]
+			}
+			fmt.Printf("👤 Switching to profile %q...\n", name)
+			if err := browserMgr.SwitchProfile(ctx, name); err != nil {
+				fmt.Printf("❌ Profile switch failed: %v\n", err)
+			} else {
+				fmt.Println("✅ Profile switched!")
+			}
+
+		case "clear_session":
+			if !confirm(reader, "Log out everywhere by clearing cookies and storage?") {
+				continue
+			}
+			if err := browserMgr.ClearSession(ctx); err != nil {
+				fmt.Printf("❌ Failed to clear session: %v\n", err)
+			} else {
+				fmt.Println("🧹 Session cleared")
+			}
+
+		default:
+			fmt.Printf("🤔 Parsing your request: %s\n", input)
+			parsed, err := aiClient.ParseUserRequest(ctx, input)
+			if err != nil {
+				fmt.Printf("❌ Failed to parse request: %v\n", err)
+				continue
+			}
+
+			release = agentInstance.HoldBrowser()
+			if parsed.NeedsURL && parsed.URL != "" {
+				fmt.Printf("🌐 Opening: %s\n", parsed.URL)
+				if err := browserMgr.Navigate(ctx, parsed.URL); err != nil {
+					if !strings.Contains(err.Error(), "page closed") {
+						fmt.Printf("❌ Navigation failed: %v\n", err)
+						continue
+					}
+					fmt.Printf("⚠️  Page closed during navigation (possibly CAPTCHA) - continuing...\n")
+				}
+				_ = browserMgr.WaitForNavigation(ctx)
+			}
+
+			if parsed.Task != "" {
+				url := parsed.URL
+				if url == "" {
+					pageContent, _ := browserMgr.GetPageContent(ctx)
+					url = pageContent.URL
+				}
+				release()
+				fmt.Printf("📋 Executing task: %s\n", parsed.Task)
+				lastResult = runTask(ctx, s, reader, parsed.Task, url, resultFile, false)
+			} else {
+				fmt.Printf("ℹ️  %s\n", parsed.Reasoning)
+			}
+		}
+	}
+}
+
+// browserCommands are the commands that drive the browser themselves rather
+// than through a task.
+var browserCommands = map[string]bool{
+	"go": true, "search": true, "screenshot": true, "cookies": true, "import_cookies": true,
+	"clear_cookies": true, "save_state": true, "load_state": true, "extract": true,
+	"switch_profile": true, "clear_session": true,
+}
+
+// replCommands are the commands completed at the start of a line.
+var replCommands = []string{
+	"task", "go", "search", "screenshot", "save_macro", "replay", "export_script",
+	"cookies", "import_cookies", "clear_cookies", "save_state", "load_state", "save_har",
+	"schedule", "schedules", "unschedule", "extract", "stats", "cache", "switch_profile",
+	"clear_session", "exit",
+}
+
+// completeInput returns the completions of the word before the cursor: a
+// command at the start of the line, else one of urls. URLs also match without
+// their scheme, so "ya" finds https://ya.ru.
+func completeInput(before string, urls []string) []string {
+	start := strings.LastIndexAny(before, " \t") + 1
+	word := before[start:]
+	var candidates []string
+	if strings.TrimSpace(before[:start]) == "" {
+		for _, cmd := range replCommands {
+			if strings.HasPrefix(cmd, strings.ToLower(word)) {
+				candidates = append(candidates, cmd)
+			}
+		}
+		return candidates
+	}
+	if strings.HasPrefix(word, "-") && strings.EqualFold(strings.Fields(before)[0], "task") {
+		return []string{"--isolated"}
+	}
+	for _, u := range urls {
+		bare := strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(u, "https://"), "http://"), "www.")
+		if strings.HasPrefix(u, word) || strings.HasPrefix(bare, word) {
+			candidates = append(candidates, u)
+		}
+	}
+	return candidates
+}
+
+// maxRecentURLs bounds the URLs offered for completion.
+const maxRecentURLs = 50
+
+// recentURLs collects the URLs of earlier lines and of the last task, most
+// recent first.
+func recentURLs(history []string, last *agent.TaskResult) []string {
+	var urls []string
+	seen := map[string]bool{}
+	add := func(u string) {
+		if u != "" && !seen[u] && len(urls) < maxRecentURLs {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+	if last != nil {
+		add(last.FinalURL)
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		for _, field := range strings.Fields(history[i]) {
+			if strings.HasPrefix(field, "http://") || strings.HasPrefix(field, "https://") {
+				add(field)
+			}
+		}
+	}
+	return urls
+}
+
+// splitSchedule splits a schedule off the front of command arguments: a
+// descriptor such as @daily, "@every <interval>" or five cron fields.
+func splitSchedule(args []string) (string, []string, error) {
+	n := 5
+	switch {
+	case len(args) > 0 && strings.EqualFold(args[0], "@every"):
+		n = 2
+	case len(args) > 0 && strings.HasPrefix(args[0], "@"):
+		n = 1
+	}
+	if len(args) < n {
+		return "", nil, fmt.Errorf("incomplete schedule")
+	}
+	return strings.Join(args[:n], " "), args[n:], nil
+}
+
+// confirm asks a yes/no question on the terminal.
+func confirm(reader *bufio.Reader, question string) bool {
+	fmt.Printf("%s (yes/no): ", question)
+	answer, err := reader.ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "yes" || answer == "y"
+}
+
+// runTask executes a task, in a fresh incognito context if isolated, and
+// prints its result, optionally saving it as JSON.
+func runTask(ctx context.Context, s *session, reader *bufio.Reader, task, url, resultFile string, isolated bool) *agent.TaskResult {
+	agentInstance := s.agent
+	execute := agentInstance.ExecuteTask
+	if isolated {
+		execute = agentInstance.ExecuteTaskIsolated
+	}
+	var result *agent.TaskResult
+	var err error
+	runControlled(ctx, agentInstance, reader, func(ctx context.Context) {
+		s.watch(ctx, task, func(ctx context.Context) {
+			result, err = execute(ctx, task, url)
+		})
+	})
+	reportResult(result, err, resultFile)
+	return result
+}
+
+// runControlled runs a task with Ctrl-C wired to it: the first press pauses
+// the task before its next step, e.g. to log in by hand, and then offers to
+// resume or cancel it; a second press while it is pausing cancels it.
+func runControlled(ctx context.Context, agentInstance *agent.Agent, reader *bufio.Reader, run func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-interrupts:
+			}
+			fmt.Println("\n⏸  Pausing after the current step (press Ctrl-C again to cancel the task)...")
+			select {
+			case <-done:
+				return
+			case <-interrupts:
+				fmt.Println("⏹  Cancelling the task...")
+				cancel()
+				return
+			case <-agentInstance.Pause():
+			}
+			if !agentInstance.Paused() {
+				return // the task ended before its next step
+			}
+			fmt.Print("⏸  Task paused. Press Enter to resume or type cancel to stop it: ")
+			// The read gives up with the task, e.g. when its time runs out
+			// while paused, instead of taking the next line typed at the prompt.
+			answer, err := lineedit.ReadLineContext(ctx, reader, os.Stdin)
+			if err != nil && ctx.Err() != nil {
+				return
+			}
+			if strings.EqualFold(strings.TrimSpace(answer), "cancel") {
+				fmt.Println("⏹  Cancelling the task...")
+				cancel()
+				return
+			}
+			agentInstance.Resume()
+		}
+	}()
+	run(ctx)
+}
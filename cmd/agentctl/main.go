@@ -0,0 +1,235 @@
+// Command agentctl sends tasks to a running "agent daemon" over its Unix
+// socket, so scripts do not wait for a browser to start on every call.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/joho/godotenv"
+
+	"github.com/VolodyaPopov923/AIBot/config"
+	"github.com/VolodyaPopov923/AIBot/internal/daemon"
+)
+
+// Exit codes besides those of the task, which are passed on.
+const (
+	exitOK        = 0
+	exitFailed    = 1
+	exitUsage     = 2
+	exitCancelled = 130
+)
+
+// line is a line the daemon sends: an agent event, the result of a task or
+// the daemon's status.
+type line struct {
+	Type     string   `json:"type"`
+	Step     int      `json:"step"`
+	Action   string   `json:"action"`
+	URL      string   `json:"url"`
+	Message  string   `json:"message"`
+	Steps    []string `json:"steps"`
+	ExitCode int      `json:"exit_code"`
+	Error    string   `json:"error"`
+	Result   *struct {
+		Answer    string   `json:"answer"`
+		Extracted []string `json:"extracted"`
+	} `json:"result"`
+	PID    int    `json:"pid"`
+	Uptime string `json:"uptime"`
+	Busy   bool   `json:"busy"`
+}
+
+func main() {
+	_ = godotenv.Load()
+	os.Exit(dispatch(os.Args[1:]))
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, `Usage: agentctl [-socket path] <command> [flags] [arguments]
+
+Commands:
+  run [-url URL] [-output text|json] <task>  Run a task in the daemon and exit with its status code
+  status                                     Show whether the daemon is busy and on which page
+  stop                                       Stop the daemon and its browser
+
+Start the daemon with "agent daemon".`)
+}
+
+func dispatch(args []string) int {
+	fs := flag.NewFlagSet("agentctl", flag.ContinueOnError)
+	fs.Usage = func() { usage(fs.Output()) }
+	socket := fs.String("socket", config.LoadConfig().Socket, "Unix socket of the daemon (default $AGENT_SOCKET, else as agent daemon)")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if *socket == "" {
+		*socket = daemon.DefaultSocketPath()
+	}
+	if fs.NArg() == 0 {
+		usage(os.Stderr)
+		return exitUsage
+	}
+
+	switch command, rest := fs.Arg(0), fs.Args()[1:]; command {
+	case daemon.CommandRun:
+		return run(*socket, rest)
+	case daemon.CommandStatus, daemon.CommandStop:
+		if len(rest) > 0 {
+			fmt.Fprintf(os.Stderr, "%s takes no arguments\n", command)
+			return exitUsage
+		}
+		return control(*socket, command)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", command)
+		usage(os.Stderr)
+		return exitUsage
+	}
+}
+
+func run(socket string, args []string) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	url := fs.String("url", "", "start URL of the task")
+	output := fs.String("output", "text", "text for people, or json for the daemon's JSON lines of events and the final result")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	task := strings.Join(fs.Args(), " ")
+	if strings.TrimSpace(task) == "" || (*output != "text" && *output != "json") {
+		fmt.Fprintln(os.Stderr, "Usage: agentctl run [-url URL] [-output text|json] <task>")
+		return exitUsage
+	}
+
+	conn, err := daemon.Dial(socket, daemon.Request{Command: daemon.CommandRun, Task: task, URL: *url})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitFailed
+	}
+	defer conn.Close()
+
+	// The first Ctrl-C asks the daemon to cancel the task, which still
+	// reports its result; the second gives up on it.
+	interrupts := make(chan os.Signal, 2)
+	signal.Notify(interrupts, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupts)
+	go func() {
+		<-interrupts
+		fmt.Fprintln(os.Stderr, "⏹  Cancelling the task...")
+		_ = conn.CloseWrite()
+		<-interrupts
+		os.Exit(exitCancelled)
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if *output == "json" {
+			fmt.Println(scanner.Text())
+		}
+		var l line
+		if err := json.Unmarshal(scanner.Bytes(), &l); err != nil {
+			continue
+		}
+		if l.Type == "result" {
+			if *output == "text" {
+				printResult(l)
+			}
+			return l.ExitCode
+		}
+		if *output == "text" {
+			printEvent(l)
+		}
+	}
+	fmt.Fprintln(os.Stderr, "❌ The daemon closed the connection without a result")
+	return exitFailed
+}
+
+// printEvent shows the progress of a task on stderr, leaving stdout to the
+// answer.
+func printEvent(l line) {
+	switch l.Type {
+	case "queued":
+		fmt.Fprintln(os.Stderr, "⏳ Waiting for the daemon's running task...")
+	case "plan_ready":
+		fmt.Fprintln(os.Stderr, "📝 Plan:")
+		for i, step := range l.Steps {
+			fmt.Fprintf(os.Stderr, "   %d. %s\n", i+1, step)
+		}
+	case "step_started":
+		fmt.Fprintf(os.Stderr, "▶ Step %d\n", l.Step)
+	case "decision":
+		fmt.Fprintf(os.Stderr, "🤔 %s: %s\n", l.Action, l.Message)
+	case "action_failed":
+		fmt.Fprintf(os.Stderr, "❌ %s failed: %s\n", l.Action, l.Message)
+	case "page_changed":
+		fmt.Fprintf(os.Stderr, "🔗 %s\n", l.URL)
+	case "captcha_wait":
+		fmt.Fprintf(os.Stderr, "🧩 %s\n", l.Message)
+	}
+}
+
+func printResult(l line) {
+	switch {
+	case l.ExitCode == exitCancelled:
+		fmt.Fprintln(os.Stderr, "⏹  Task cancelled")
+	case l.Error != "":
+		fmt.Fprintf(os.Stderr, "❌ Task failed: %s\n", l.Error)
+	default:
+		fmt.Fprintln(os.Stderr, "✅ Task completed successfully!")
+	}
+	if l.Result == nil {
+		return
+	}
+	if l.Result.Answer != "" {
+		fmt.Println(l.Result.Answer)
+	}
+	for _, item := range l.Result.Extracted {
+		fmt.Println(item)
+	}
+}
+
+// control sends a command without a task and prints the daemon's answer.
+func control(socket, command string) int {
+	conn, err := daemon.Dial(socket, daemon.Request{Command: command})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitFailed
+	}
+	defer conn.Close()
+	var l line
+	if err := json.NewDecoder(conn).Decode(&l); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ No answer from the daemon: %v\n", err)
+		return exitFailed
+	}
+	switch l.Type {
+	case "status":
+		state := "idle"
+		if l.Busy {
+			state = "running a task"
+		}
+		fmt.Printf("🟢 Daemon %d on %s, up %s, %s\n", l.PID, socket, l.Uptime, state)
+		if l.URL != "" {
+			fmt.Printf("🔗 %s\n", l.URL)
+		}
+	case "stopping":
+		fmt.Println("👋 Daemon stopping")
+	default:
+		fmt.Fprintf(os.Stderr, "❌ %s\n", l.Error)
+		return exitFailed
+	}
+	return exitOK
+}
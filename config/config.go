@@ -3,30 +3,178 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 const testOpenAIKey = ""
 
+// defaultLLMTimeout bounds a single model request; local models can be slow.
+const defaultLLMTimeout = 2 * time.Minute
+
+// defaultCacheTTL is how long cached model replies are reused.
+const defaultCacheTTL = 24 * time.Hour
+
 type Config struct {
 	OpenAIAPIKey  string
+	AnthropicKey  string
+	LLMAPIKey     string // for ollama and openai-compatible endpoints, never the OpenAI key
+	AIProvider    string
+	LLMBaseURL    string
+	LLMModel      string
+	FastModel     string // small model for routine decisions; LLMModel plans and recovers
+	LLMTimeout    time.Duration
+	LLMCache      bool
+	CacheDir      string
+	CacheTTL      time.Duration
+	PromptsDir    string
+	SelectorFile  string
+	PlaybooksDir  string
+	ScheduleFile  string
+	ReportsDir    string // reports of scheduled runs
+	HistoryFile   string // lines entered at the interactive prompt
+	SessionsDir   string // named sessions of session save and load
+	Credentials   string // logins the login command fills in by itself
+	TelegramToken string // token of the bot the telegram command runs
+	TelegramUsers string // comma-separated user IDs and usernames the bot obeys
+	Webhooks      string // comma-separated URLs told about task events in serve mode
+	WebhookSecret string // signs webhook payloads
+	APIToken      string // bearer token the HTTP API of serve requires, if set
+	Socket        string // Unix socket of the daemon command
 	BrowserPath   string
+	CDPEndpoint   string // attach to a running Chrome instead of launching one
+	ProxyServer   string
+	ProxyUsername string
+	ProxyPassword string
+	ProxyBypass   string // comma-separated hosts that skip the proxy
+	Device        string // device preset, e.g. "iPhone 14"
+	Viewport      string // WIDTHxHEIGHT
+	ScaleFactor   float64
+	UserAgent     string
+	Mobile        bool
+	Stealth       bool   // hide automation tells from bot detection
+	Locale        string // e.g. de-DE
+	Timezone      string // IANA name, e.g. Europe/Berlin
+	Captcha       string // CAPTCHA solving service: 2captcha or anti-captcha
+	SearchEngine  string // engine of search actions: duckduckgo, google or yandex
+	CaptchaKey    string // API key of the solving service
 	Debug         bool
+	Vision        bool
+	ElementMarks  bool // badge elements with numbers on vision screenshots
+	A11yTree      bool // describe pages by their accessibility tree
+	AllowEvaluate bool // let the model run JavaScript (always confirmed)
+	RecordNetwork bool // add XHR/fetch traffic to task results
+	HTTPFetch     bool // serve fetch actions over plain HTTP
+	Verify        bool // ask the model whether each plan step worked
+	Selectors     bool // reuse actions that worked for plan steps before
+	Stream        bool // print the model's output as it is generated
 	MaxTokens     int
 	MaxIterations int
+	TaskTokens    int
+	TaskCost      float64
+	TaskTimeout   time.Duration
+	CrawlPages    int // most pages one crawl action may visit
 }
 
 func LoadConfig() Config {
 	debug, _ := strconv.ParseBool(os.Getenv("DEBUG"))
+	vision, _ := strconv.ParseBool(os.Getenv("AGENT_VISION"))
+	elementMarks, _ := strconv.ParseBool(os.Getenv("AGENT_ELEMENT_MARKS"))
+	a11yTree, _ := strconv.ParseBool(os.Getenv("AGENT_ACCESSIBILITY_TREE"))
+	allowEvaluate, _ := strconv.ParseBool(os.Getenv("AGENT_ALLOW_EVALUATE"))
+	recordNetwork, _ := strconv.ParseBool(os.Getenv("AGENT_RECORD_NETWORK"))
+	httpFetch, _ := strconv.ParseBool(os.Getenv("AGENT_HTTP_FETCH"))
+	verify, _ := strconv.ParseBool(os.Getenv("AGENT_VERIFY"))
+	selectors, _ := strconv.ParseBool(os.Getenv("AGENT_SELECTOR_MEMORY"))
+	stream, _ := strconv.ParseBool(os.Getenv("LLM_STREAM"))
+	mobile, _ := strconv.ParseBool(os.Getenv("BROWSER_MOBILE"))
+	stealth, _ := strconv.ParseBool(os.Getenv("BROWSER_STEALTH"))
+	scaleFactor, _ := strconv.ParseFloat(os.Getenv("BROWSER_SCALE_FACTOR"), 64)
+	crawlPages, _ := strconv.Atoi(os.Getenv("AGENT_MAX_CRAWL_PAGES"))
+	taskTokens, _ := strconv.Atoi(os.Getenv("AGENT_BUDGET_TOKENS"))
+	taskCost, _ := strconv.ParseFloat(os.Getenv("AGENT_BUDGET_USD"), 64)
+	taskTimeout, _ := time.ParseDuration(os.Getenv("AGENT_BUDGET_TIME"))
 	apiKey := os.Getenv("OPENAI_API_KEY")
 	if apiKey == "" {
 		apiKey = testOpenAIKey
 	}
 
+	llmCache := true
+	if raw := os.Getenv("LLM_CACHE"); raw != "" {
+		llmCache, _ = strconv.ParseBool(raw)
+	}
+	cacheTTL := defaultCacheTTL
+	if raw := os.Getenv("LLM_CACHE_TTL"); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil {
+			cacheTTL = d
+		}
+	}
+
+	llmTimeout := defaultLLMTimeout
+	if raw := os.Getenv("LLM_TIMEOUT"); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil {
+			llmTimeout = d
+		}
+	}
+
 	return Config{
 		OpenAIAPIKey:  apiKey,
+		AnthropicKey:  os.Getenv("ANTHROPIC_API_KEY"),
+		LLMAPIKey:     os.Getenv("LLM_API_KEY"),
+		AIProvider:    strings.ToLower(strings.TrimSpace(os.Getenv("AI_PROVIDER"))),
+		LLMBaseURL:    os.Getenv("LLM_BASE_URL"),
+		LLMModel:      os.Getenv("LLM_MODEL"),
+		FastModel:     os.Getenv("LLM_FAST_MODEL"),
+		LLMTimeout:    llmTimeout,
+		LLMCache:      llmCache,
+		CacheDir:      os.Getenv("LLM_CACHE_DIR"),
+		CacheTTL:      cacheTTL,
 		BrowserPath:   os.Getenv("BROWSER_PATH"),
+		CDPEndpoint:   os.Getenv("BROWSER_CDP_ENDPOINT"),
+		ProxyServer:   os.Getenv("PROXY_SERVER"),
+		ProxyUsername: os.Getenv("PROXY_USERNAME"),
+		ProxyPassword: os.Getenv("PROXY_PASSWORD"),
+		ProxyBypass:   os.Getenv("PROXY_BYPASS"),
+		Device:        os.Getenv("BROWSER_DEVICE"),
+		Viewport:      os.Getenv("BROWSER_VIEWPORT"),
+		ScaleFactor:   scaleFactor,
+		UserAgent:     os.Getenv("BROWSER_USER_AGENT"),
+		Mobile:        mobile,
+		Stealth:       stealth,
+		Locale:        os.Getenv("BROWSER_LOCALE"),
+		Timezone:      os.Getenv("BROWSER_TIMEZONE"),
+		Captcha:       os.Getenv("CAPTCHA_PROVIDER"),
+		SearchEngine:  os.Getenv("SEARCH_ENGINE"),
+		CaptchaKey:    os.Getenv("CAPTCHA_API_KEY"),
 		Debug:         debug,
+		Vision:        vision,
+		ElementMarks:  elementMarks,
+		A11yTree:      a11yTree,
+		AllowEvaluate: allowEvaluate,
+		RecordNetwork: recordNetwork,
+		HTTPFetch:     httpFetch,
+		Verify:        verify,
+		Selectors:     selectors,
+		SelectorFile:  os.Getenv("AGENT_SELECTOR_FILE"),
+		PlaybooksDir:  os.Getenv("PLAYBOOKS_DIR"),
+		ScheduleFile:  os.Getenv("AGENT_SCHEDULE_FILE"),
+		ReportsDir:    os.Getenv("AGENT_REPORTS_DIR"),
+		HistoryFile:   os.Getenv("AGENT_HISTORY_FILE"),
+		SessionsDir:   os.Getenv("AGENT_SESSIONS_DIR"),
+		Credentials:   os.Getenv("AGENT_CREDENTIALS_FILE"),
+		TelegramToken: os.Getenv("TELEGRAM_BOT_TOKEN"),
+		TelegramUsers: os.Getenv("TELEGRAM_ALLOWED_USERS"),
+		Webhooks:      os.Getenv("AGENT_WEBHOOKS"),
+		WebhookSecret: os.Getenv("AGENT_WEBHOOK_SECRET"),
+		APIToken:      os.Getenv("AGENT_API_TOKEN"),
+		Socket:        os.Getenv("AGENT_SOCKET"),
+		Stream:        stream,
+		PromptsDir:    os.Getenv("PROMPTS_DIR"),
 		MaxTokens:     8000,
 		MaxIterations: 20,
+		CrawlPages:    crawlPages,
+		TaskTokens:    taskTokens,
+		TaskCost:      taskCost,
+		TaskTimeout:   taskTimeout,
 	}
 }
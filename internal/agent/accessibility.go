@@ -0,0 +1,26 @@
+package agent
+
+import (
+	"context"
+	"log"
+
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+// maxAccessibilityTree caps the accessibility tree in prompts (in runes).
+const maxAccessibilityTree = 12000
+
+// withAccessibilityTree adds the accessibility tree of the current page to pc
+// when UseAccessibilityTree is on. If the tree cannot be read the DOM-based
+// description is used as before.
+func (a *Agent) withAccessibilityTree(ctx context.Context, pc *browser.PageContent) {
+	if !a.UseAccessibilityTree || a.browserMgr == nil {
+		return
+	}
+	tree, err := a.browserMgr.GetAccessibilityTree(ctx)
+	if err != nil {
+		log.Printf("Warning: accessibility tree unavailable, describing the DOM instead: %v\n", err)
+		return
+	}
+	pc.AccessibilityTree = tree.Format(maxAccessibilityTree)
+}
@@ -1,45 +1,219 @@
 package agent
 
 import (
+	"bufio"
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
+	"os"
+	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/VolodyaPopov923/AIBot/internal/ai"
 	"github.com/VolodyaPopov923/AIBot/internal/browser"
+	"github.com/VolodyaPopov923/AIBot/internal/captcha"
 	ctxmgr "github.com/VolodyaPopov923/AIBot/internal/context"
+	"github.com/VolodyaPopov923/AIBot/internal/fetch"
+	"github.com/VolodyaPopov923/AIBot/internal/playbook"
+	"github.com/VolodyaPopov923/AIBot/internal/prompts"
 	"github.com/VolodyaPopov923/AIBot/internal/security"
 )
 
+// maxDiagnosticErrors is how many recent console errors are attached to a failed action.
+const maxDiagnosticErrors = 5
+
 type Agent struct {
 	browserMgr    *browser.Manager
-	aiClient      *ai.Client
+	aiClient      ai.Provider
 	contextMgr    *ctxmgr.ContextManager
 	securityMgr   *security.Validator
 	currentTask   string
 	maxIterations int
 	verbose       bool
+	input         *bufio.Reader
+	// inputFile is the file input reads, if it is one; inputMu guards both.
+	inputFile *os.File
+	inputMu   sync.Mutex
+
+	plans *planCache
+
+	// result accumulates the outcome of the current task.
+	result TaskResult
+	// totals adds up the usage of every finished task. totalsMu guards it,
+	// as Totals may be called while a task, e.g. a scheduled one, finishes.
+	totals   UsageTotals
+	totalsMu sync.Mutex
+	// taskEvents also receives the events of the current task; see WithEvents.
+	taskEvents func(event Event)
+	// freshDecision makes the next decision bypass the response cache, which
+	// would repeat the answer that just failed.
+	freshDecision bool
+
+	// executedDestructive holds signatures of destructive actions already run in the current task.
+	executedDestructive map[string]struct{}
+
+	// lastElements is the element list of the last page shown to the model;
+	// decisions may refer to an element by its number in it.
+	lastElements []browser.ElementInfo
+	skills       []Skill // registered with RegisterSkill
+	control      taskControl
+	// running lets one task at a time drive the browser; others wait.
+	running sync.Mutex
+
+	// OnDecision, if set, is called with every decision right after the model
+	// returns it and before execution. It may rewrite the decision in place or
+	// return an error to reject it (e.g. to route navigations through a proxy
+	// or block selectors).
+	OnDecision func(decision *ai.DecisionResponse) error
+	// OnEvent, if set, receives step-level progress events (planning,
+	// decisions, executed actions, page changes, CAPTCHA waits). It is called
+	// synchronously and should not block.
+	OnEvent func(event Event)
+	// MinConfidence forces confirmation of any action whose reported confidence
+	// is below it. Zero (the default) never forces confirmation.
+	MinConfidence float64
+	// ManualSteps controls pause actions and manual plan steps (default: wait for Enter).
+	ManualSteps ManualStepPolicy
+	// CollectDiagnostics appends recent console and page errors to failed action errors.
+	CollectDiagnostics bool
+	// UseVision attaches a viewport screenshot to every decision request so a
+	// vision-capable model can handle canvas-heavy pages.
+	UseVision bool
+	// UseElementMarks draws numbered badges over interactive elements on the
+	// vision screenshot (set-of-marks), so the model can answer with an
+	// element number instead of a selector.
+	UseElementMarks bool
+	// UseAccessibilityTree describes pages to the model by their accessibility
+	// tree instead of the Markdown rendering of the DOM.
+	UseAccessibilityTree bool
+	// RecordNetwork adds the task's document, XHR and fetch requests to its
+	// result, e.g. to find the API behind a page being scraped.
+	RecordNetwork bool
+	// Fetcher, if set, serves fetch actions over plain HTTP; without it they
+	// navigate the browser.
+	Fetcher *fetch.Fetcher
+	// SearchEngine names the engine of search actions, one of
+	// browser.SearchEngines; browser.DefaultSearchEngine if empty.
+	SearchEngine string
+	// MaxCrawlPages caps the pages a single crawl action visits, whatever the
+	// model asks for. Zero leaves the model's limit as is.
+	MaxCrawlPages int
+	// AllowEvaluate enables the evaluate action, which runs model-written
+	// JavaScript in the page. Every script still needs confirmation.
+	AllowEvaluate bool
+	// CaptchaSolver, if set, solves CAPTCHAs through a solving service
+	// before falling back to waiting for a person to solve them.
+	CaptchaSolver captcha.Solver
+	// Budget limits the tokens, cost and time of each task.
+	Budget Budget
+	// VerifySteps asks the model after each plan step's action whether the
+	// page changes achieved the step, and retries the step with the reason
+	// when they did not. It costs one extra model call per step.
+	VerifySteps bool
+	// Selectors, if set, remembers the actions that carried out plan steps
+	// per site and repeats them on later runs before asking the model.
+	Selectors *SelectorMemory
+	// Playbooks are run instead of planning when one matches the task and
+	// site; the model takes over if the playbook fails.
+	Playbooks []playbook.Playbook
+	// HaltOnDestructive stops the task at the first action that would need
+	// confirmation, returning it in a HaltedActionError instead of prompting.
+	HaltOnDestructive bool
+	// ConfirmEachStep shows every action, with the element it acts on, and
+	// runs it only once the user approves it; a denied action is reported to
+	// the model like a failed one.
+	ConfirmEachStep bool
+	// Confirm, if set, decides instead of the terminal whether an action
+	// that needs confirmation may run, e.g. by asking in a chat.
+	Confirm func(ctx context.Context, action security.DestructiveAction) (bool, error)
+	// Ask, if set, puts manual steps and clarifying questions to the user
+	// instead of the terminal and returns the answer.
+	Ask func(ctx context.Context, question string) (string, error)
 }
 
-func NewAgent(browserMgr *browser.Manager, aiClient *ai.Client, verbose bool) *Agent {
+func NewAgent(browserMgr *browser.Manager, aiClient ai.Provider, verbose bool) *Agent {
 	return &Agent{
-		browserMgr:    browserMgr,
-		aiClient:      aiClient,
-		contextMgr:    ctxmgr.NewContextManager(8000, 20),
-		securityMgr:   security.NewValidator(),
-		maxIterations: 20,
-		verbose:       verbose,
+		browserMgr:         browserMgr,
+		aiClient:           aiClient,
+		contextMgr:         ctxmgr.NewContextManager(8000, 20),
+		securityMgr:        security.NewValidator(),
+		maxIterations:      20,
+		verbose:            verbose,
+		input:              bufio.NewReader(os.Stdin),
+		inputFile:          os.Stdin,
+		plans:              newPlanCache(defaultPlanCacheTTL),
+		ManualSteps:        ManualStepWait,
+		CollectDiagnostics: true,
+	}
+}
+
+// ExecuteTask runs a task and returns what it produced. The result is returned
+// even when err is non-nil, so partial data and diagnostics are not lost.
+// Tasks share the browser, so a task started while another runs waits for it.
+func (a *Agent) ExecuteTask(ctx context.Context, task string, initialURL string) (*TaskResult, error) {
+	a.running.Lock()
+	defer a.running.Unlock()
+	return a.executeTask(ctx, task, initialURL)
+}
+
+// HoldBrowser waits until no task is using the browser and keeps tasks from
+// starting until release is called, so a command that drives the browser
+// directly does not navigate under a running task.
+func (a *Agent) HoldBrowser() (release func()) {
+	a.running.Lock()
+	var once sync.Once
+	return func() { once.Do(a.running.Unlock) }
+}
+
+func (a *Agent) executeTask(ctx context.Context, task string, initialURL string) (*TaskResult, error) {
+	a.taskEvents = eventsFrom(ctx)
+	defer func() { a.taskEvents = nil }()
+	a.result = TaskResult{Task: task, StartURL: initialURL, StartedAt: time.Now()}
+	a.emit(Event{Type: EventTaskStarted, URL: initialURL, Message: task})
+	taskCtx, explain, cancel := a.withTimeBudget(ctx)
+	err := explain(a.runTask(taskCtx, task, initialURL))
+	cancel()
+	a.endControl()
+	if err == nil {
+		err = a.assertionError()
+	}
+	result := a.finishResult(err)
+
+	finished := Event{Type: EventTaskFinished, URL: result.FinalURL, Message: "success"}
+	if err != nil {
+		finished.Message = err.Error()
+	}
+	a.emit(finished)
+	return result, err
+}
+
+// ExecuteTaskIsolated runs a task in a fresh incognito browser context, so it
+// neither sees nor changes the cookies and storage of the persistent profile.
+// The profile's tabs are restored afterwards.
+func (a *Agent) ExecuteTaskIsolated(ctx context.Context, task string, initialURL string) (*TaskResult, error) {
+	a.running.Lock()
+	defer a.running.Unlock()
+	if err := a.browserMgr.StartIsolatedContext(ctx); err != nil {
+		return &TaskResult{Task: task, StartURL: initialURL, Error: err.Error()}, fmt.Errorf("failed to start isolated context: %w", err)
 	}
+	defer func() {
+		if err := a.browserMgr.EndIsolatedContext(ctx); err != nil {
+			log.Printf("Warning: %v\n", err)
+		}
+	}()
+	return a.executeTask(ctx, task, initialURL)
 }
 
-func (a *Agent) ExecuteTask(ctx context.Context, task string, initialURL string) error {
+func (a *Agent) runTask(ctx context.Context, task string, initialURL string) error {
 	a.currentTask = task
 
 	a.contextMgr.ClearContext()
 	a.contextMgr.ResetTokenCounter()
+	a.executedDestructive = nil
+	a.freshDecision = false
+	a.browserMgr.ClearNetworkLog()
 
 	if a.verbose {
 		log.Printf("Starting task: %s\n", task)
@@ -55,28 +229,52 @@ func (a *Agent) ExecuteTask(ctx context.Context, task string, initialURL string)
 		}
 	}
 
+	// Slow pages may still be a skeleton after load; give the configured readiness check a chance first.
+	if err := a.browserMgr.WaitForReady(ctx); err != nil {
+		log.Printf("Warning: readiness wait failed: %v\n", err)
+	}
+
+	if len(a.Playbooks) > 0 {
+		if ran, err := a.runPlaybook(ctx, task, a.browserMgr.CurrentURL()); ran {
+			return err
+		}
+	}
+
 	pageContent, err := a.browserMgr.GetPageContent(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get page content for planning: %w", err)
 	}
-	pageDesc := buildPageDescription(pageContent, a.browserMgr.ListOpenPages())
+	pageDesc := buildPlanningDescription(pageContent)
 
-	steps, err := a.aiClient.PlanTask(ctx, task, pageDesc)
+	a.emit(Event{Type: EventPlanning, URL: pageContent.URL})
+	plan, cached, err := a.plans.getOrPlan(task, pageContent, func() (ai.Plan, error) {
+		return a.planTask(ctx, task, pageDesc)
+	})
+	if cached && a.verbose {
+		log.Printf("Reusing cached plan for this task and page\n")
+	}
 	if err != nil {
 		if a.verbose {
 			log.Printf("Planning failed, falling back to iterative mode: %v\n", err)
 		}
+		var progress progressTracker
 		for iteration := 0; iteration < a.maxIterations; iteration++ {
 			if a.verbose {
 				log.Printf("\n=== Iteration %d ===\n", iteration+1)
 			}
+			if err := a.checkpoint(ctx); err != nil {
+				return err
+			}
+			if err := a.checkBudget(); err != nil {
+				return err
+			}
 
 			pageContent, err := a.browserMgr.GetPageContent(ctx)
 			if err != nil {
 				return fmt.Errorf("failed to get page content: %w", err)
 			}
 			if isBlockedPage(pageContent) {
-				log.Printf("CAPTCHA detected on %s. Waiting for you to solve it...\n", pageContent.URL)
+				log.Printf("Blocked by %s on %s. Waiting for you to solve it...\n", blockReason(pageContent), pageContent.URL)
 				if err := a.waitForCaptchaSolution(ctx); err != nil {
 					return fmt.Errorf("CAPTCHA wait failed: %w", err)
 				}
@@ -88,18 +286,31 @@ func (a *Agent) ExecuteTask(ctx context.Context, task string, initialURL string)
 			if err != nil {
 				return fmt.Errorf("decision making failed: %w", err)
 			}
+			a.emit(Event{Type: EventDecision, Action: decision.Action, Message: decision.Reasoning})
 			if a.verbose {
 				log.Printf("Decision: %s\n", decision.Reasoning)
 			}
 			if decision.IsComplete {
+				a.recordAnswer(decision)
 				if a.verbose {
 					log.Printf("Task completed successfully\n")
 				}
 				return nil
 			}
+			if stuck := progress.observe(pageContent, decision); stuck != "" {
+				if progress.warned {
+					return fmt.Errorf("%w: %s after changing approach once; last actions: %s", ErrNoProgress, stuck, progress.recentActions())
+				}
+				a.changeApproach(stuck)
+				progress.restart()
+				continue
+			}
 			if err := a.executeAction(ctx, decision); err != nil {
-				if a.verbose {
-					log.Printf("Action failed, attempting recovery: %v\n", err)
+				if stopsTask(err) {
+					return err
+				}
+				if a.recordActionFailure(err, decision.Optional, "Action") && a.verbose {
+					log.Printf("Attempting recovery...\n")
 				}
 				continue
 			}
@@ -108,65 +319,259 @@ func (a *Agent) ExecuteTask(ctx context.Context, task string, initialURL string)
 		return fmt.Errorf("max iterations (%d) reached without completing task: %s", a.maxIterations, a.currentTask)
 	}
 
+	steps, doneWhen := plan.Steps, plan.DoneWhen
+	a.emit(Event{Type: EventPlanReady, Message: fmt.Sprintf("%d step(s)", len(steps)), Steps: stepDescriptions(steps)})
 	if a.verbose {
 		log.Printf("Plan generated with %d steps. Executing each step once.\n", len(steps))
 	}
 
-	for idx, step := range steps {
+	failedSteps, consecutiveFailures, replans := 0, 0, 0
+	succeeded := false
+	defer func() {
+		// A plan that failed or had to be re-planned would fail the same way
+		// when the task is retried on this page.
+		if !succeeded || replans > 0 {
+			a.plans.forget(task, pageContent)
+		}
+	}()
+	fail := func(err error, optional bool, label string) {
+		if a.recordActionFailure(err, optional, label) {
+			failedSteps++
+			consecutiveFailures++
+		}
+	}
+	var done []string
+	deviation, unmet := "", ""
+	retrying, retries, feedback := -1, 0, ""
+	for idx := 0; ; idx++ {
+		reason := replanReason(consecutiveFailures, deviation)
+		if reason == "" && idx >= len(steps) && failedSteps == 0 {
+			// Done with the plan: check the task is done too, rather than
+			// trusting that every step did what it should.
+			if unmet = a.unmetCondition(ctx, doneWhen); unmet != "" {
+				reason = "the plan is through but " + unmet
+			}
+		}
+		if reason != "" && replans < maxReplans {
+			replans++
+			if replanned, err := a.replan(ctx, task, done, reason); err != nil {
+				log.Printf("Warning: re-planning failed, continuing the current plan: %v\n", err)
+			} else {
+				// The new plan covers what the failed steps were for.
+				steps, idx, failedSteps, retrying, unmet = replanned.Steps, 0, 0, -1, ""
+				if replanned.DoneWhen != nil {
+					doneWhen = replanned.DoneWhen
+				}
+			}
+			consecutiveFailures, deviation = 0, ""
+		}
+		if idx >= len(steps) {
+			break
+		}
+		step := steps[idx]
+		if idx != retrying {
+			retries, feedback = 0, ""
+		}
+		a.emit(Event{Type: EventStepStarted, Step: idx + 1, Message: step.Description})
 		if a.verbose {
 			log.Printf("\n--- Executing plan step %d/%d: %s\n", idx+1, len(steps), step)
 		}
+		if err := a.checkpoint(ctx); err != nil {
+			return err
+		}
+		if err := a.checkBudget(); err != nil {
+			return err
+		}
 
 		pc, err := a.browserMgr.GetPageContent(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to get page content: %w", err)
 		}
 		if isBlockedPage(pc) {
-			log.Printf("CAPTCHA detected on %s. Waiting for you to solve it...\n", pc.URL)
+			log.Printf("Blocked by %s on %s. Waiting for you to solve it...\n", blockReason(pc), pc.URL)
 			if err := a.waitForCaptchaSolution(ctx); err != nil {
 				return fmt.Errorf("CAPTCHA wait failed: %w", err)
 			}
 			log.Printf("CAPTCHA solved, continuing plan...\n")
 		}
 
-		systemPrompt := `You are an intelligent web automation agent. Provide a single concise action to accomplish the given step on the current page.
-Valid actions: navigate, click, fill, focus, type, press, wait, switch_tab, complete, error.
-Use "focus" before typing if needed, "type" for freeform text entry (text field provided in the decision), and "press" for keyboard keys like Enter.
-Use "switch_tab" when you must operate on a different browser tab (specify tab index or part of the title/URL).`
-		userInput := fmt.Sprintf("Task: %s\nPlan step: %s\nCurrent page:\n%s\n\nReturn a single JSON decision as before.", a.currentTask, step, buildPageDescription(pc, a.browserMgr.ListOpenPages()))
+		if step.If != nil || step.Branches() {
+			// A step without a condition but with "then" just groups steps.
+			met := true
+			if step.If != nil {
+				var err error
+				met, err = evaluateCondition(*step.If, pc, func(selector string) (bool, error) {
+					return a.browserMgr.ElementExists(ctx, selector)
+				})
+				if err != nil && a.verbose {
+					log.Printf("Condition check for step %d failed: %v\n", idx+1, err)
+				}
+			}
+			branch := step.Else
+			if met {
+				branch = step.Then
+			}
+			if len(branch) > 0 {
+				if a.verbose {
+					log.Printf("Step %d: condition %s, taking %d step(s)\n", idx+1, conditionOutcome(met), len(branch))
+				}
+				steps = spliceSteps(steps, idx, branch)
+				continue
+			}
+			if !met {
+				if a.verbose {
+					log.Printf("Skipping step %d: condition not met\n", idx+1)
+				}
+				continue
+			}
+		}
 
-		a.contextMgr.AddMessage("system", systemPrompt)
-		a.contextMgr.AddMessage("user", userInput)
+		if step.Manual {
+			if err := a.waitForManualStep(ctx, step.Description); err != nil {
+				return fmt.Errorf("manual step %d: %w", idx+1, err)
+			}
+			done = append(done, step.Description)
+			continue
+		}
 
-		decision, err := a.aiClient.MakeDecision(ctx, systemPrompt, userInput)
-		if err != nil {
-			return fmt.Errorf("MakeDecision failed for step %d: %w", idx+1, err)
+		// A step that worked on this site before is repeated without asking
+		// the model.
+		decision, recalled := a.recallDecision(ctx, pc, step)
+		if !recalled {
+			systemPrompt := prompts.Render(prompts.DecideStep, prompts.DecideData{Actions: a.actionsPrompt(pc), Task: a.currentTask, URL: pc.URL})
+			a.withAccessibilityTree(ctx, &pc)
+			a.lastElements = pc.Elements
+			screenshots, note := a.visionInput(ctx, pc.Elements)
+			userInput := fmt.Sprintf("Task: %s\nPlan step: %s\nCurrent page:\n%s%s\n\n%s", a.currentTask, step.Description, buildPageDescription(pc, a.browserMgr.ListOpenPages()), note+a.variablesNote(), ai.DecisionFieldsPrompt())
+			if feedback != "" {
+				userInput += "\n\n" + feedback
+			}
+
+			decision, _, err = a.decide(ctx, systemPrompt, userInput, screenshots...)
+			if err != nil {
+				return fmt.Errorf("MakeDecision failed for step %d: %w", idx+1, err)
+			}
+		}
+		a.rememberDecision(pc, step.Description, decision)
+		if err := a.applyDecisionHook(&decision); err != nil {
+			fail(err, step.Optional, fmt.Sprintf("Step %d", idx+1))
+			continue
 		}
 
+		a.emit(Event{Type: EventDecision, Step: idx + 1, Action: decision.Action, Message: decision.Reasoning})
 		if a.verbose {
 			log.Printf("Decision for step %d: %v\n", idx+1, decision.Reasoning)
 		}
+		// An "error" answer means the step cannot be done on this page: the
+		// page has drifted from what the plan expected.
+		if ai.NormalizeAction(decision.Action) == ai.ActionError && !step.Optional {
+			deviation = decision.Reasoning
+			fail(fmt.Errorf("step does not fit the page: %s", decision.Reasoning), false, fmt.Sprintf("Step %d", idx+1))
+			continue
+		}
 
 		if err := a.executeAction(ctx, decision); err != nil {
-			if a.verbose {
-				log.Printf("Execution of step %d failed: %v\n", idx+1, err)
+			if stopsTask(err) {
+				return err
 			}
+			if recalled {
+				// The site changed since; ask the model this time.
+				a.forgetDecision(pc, step)
+				idx--
+				continue
+			}
+			fail(err, step.Optional || decision.Optional, fmt.Sprintf("Step %d", idx+1))
 			continue
 		}
 
 		_ = a.browserMgr.WaitForNavigation(ctx)
 		time.Sleep(1 * time.Second)
+
+		if a.VerifySteps {
+			verdict := a.verifyStep(ctx, step.Description, decision, pc)
+			a.emit(Event{Type: EventStepVerified, Step: idx + 1, Action: decision.Action, Message: verdict.Reason})
+			if !verdict.Achieved && recalled {
+				a.forgetDecision(pc, step)
+			}
+			if !verdict.Achieved && retries < maxStepRetries {
+				if a.verbose {
+					log.Printf("Step %d not achieved (%s), retrying\n", idx+1, verdict.Reason)
+				}
+				a.contextMgr.AddMessage("system", fmt.Sprintf("Step %d not achieved: %s", idx+1, verdict.Reason))
+				a.freshDecision = true
+				feedback = fmt.Sprintf("A previous attempt at this step (%s) did not achieve it: %s. Try a different approach.", decision.Action, verdict.Reason)
+				retrying, retries = idx, retries+1
+				idx--
+				continue
+			}
+			if !verdict.Achieved {
+				fail(fmt.Errorf("step not achieved: %s", verdict.Reason), step.Optional || decision.Optional, fmt.Sprintf("Step %d", idx+1))
+				continue
+			}
+		}
+		if !recalled {
+			a.memorizeDecision(pc, step, decision)
+		}
+		done = append(done, step.Description)
+		consecutiveFailures = 0
 	}
 
+	if failedSteps > 0 {
+		return fmt.Errorf("plan completed with %d failed step(s)", failedSteps)
+	}
+	if unmet != "" {
+		return fmt.Errorf("%w: %s", ErrNotDone, unmet)
+	}
 	if a.verbose {
 		log.Printf("Plan completed (all steps attempted).\n")
 	}
+	succeeded = true
 	return nil
 }
 
+// recordActionFailure logs a failed action and reports whether it counts as a
+// task failure. Optional actions are best-effort and never count.
+func (a *Agent) recordActionFailure(err error, optional bool, label string) bool {
+	if optional {
+		log.Printf("%s (optional) failed, continuing: %v\n", label, err)
+		return false
+	}
+	if a.verbose {
+		log.Printf("%s failed: %v\n", label, err)
+	}
+	if a.contextMgr != nil {
+		a.contextMgr.AddMessage("system", fmt.Sprintf("%s failed: %v", label, err))
+	}
+	if escalator, ok := a.aiClient.(modelEscalator); ok {
+		escalator.Escalate(escalatedDecisions)
+	}
+	a.freshDecision = true
+	return true
+}
+
+// escalatedDecisions is how many decisions after a failed action go to the
+// strong model when the provider routes between two models.
+const escalatedDecisions = 2
+
+// modelEscalator is implemented by providers that route routine decisions to
+// a cheaper model, such as ai.Router.
+type modelEscalator interface {
+	Escalate(n int)
+}
+
 func (a *Agent) waitForCaptchaSolution(ctx context.Context) error {
 	const checkInterval = 2 * time.Second
 	const timeout = 5 * time.Minute
+
+	if a.CaptchaSolver != nil {
+		err := a.solveCaptcha(ctx)
+		if err == nil {
+			return nil
+		}
+		log.Printf("Warning: CAPTCHA solver failed, waiting for manual solution: %v\n", err)
+	}
+
+	a.emit(Event{Type: EventCaptchaWait, URL: a.currentURL()})
 	deadline := time.Now().Add(timeout)
 
 	for {
@@ -198,64 +603,38 @@ func (a *Agent) waitForCaptchaSolution(ctx context.Context) error {
 }
 
 func (a *Agent) analyzeAndDecide(ctx context.Context, pageContent browser.PageContent) (ai.DecisionResponse, error) {
+	a.withAccessibilityTree(ctx, &pageContent)
 	pageDescription := buildPageDescription(pageContent, a.browserMgr.ListOpenPages())
 
-	systemPrompt := `You are an intelligent web automation agent. Your task is to complete user requests by interacting with web pages.
-You can:
-- Click on buttons and links (action "click")
-- Fill or type into form fields (actions "fill" or "type"; provide text to enter)
-- Focus an element before typing if necessary (action "focus")
-- Navigate to URLs (action "navigate")
-- Switch between open tabs (action "switch_tab"; specify tab index or a fragment of the tab title/URL)
-- Press keyboard keys (action "press"; set text to the key name, e.g. "Enter")
-- Read page content
-- Wait for page load or manual intervention (action "wait")
-
-IMPORTANT INSTRUCTIONS:
-- If you encounter a CAPTCHA or security challenge, use the "wait" action to give the user time to solve it manually. Do NOT use "error".
-- After waiting, try to navigate again or continue the task.
-- Be systematic, logical, and report when the task is complete.
-- If no progress can be made after several retries on the same page, only then use "error" action.`
+	systemPrompt := prompts.Render(prompts.Decide, prompts.DecideData{Actions: a.actionsPrompt(pageContent), Task: a.currentTask, URL: pageContent.URL})
 
+	a.lastElements = pageContent.Elements
+	screenshots, note := a.visionInput(ctx, pageContent.Elements)
 	userInput := fmt.Sprintf(`Current task: %s
 
 Current page state:
-%s
+%s%s
 
 Based on the page content, what should be the next action? Respond with a clear decision.
-Return a JSON object with:
-- action: the action to take (navigate, click, fill, focus, type, press, switch_tab, wait, complete, error)
-- selector: CSS selector for the element (if clicking or filling)
-- text: text to fill (if filling a form)
-- url: URL to navigate to (if navigating)
-- reasoning: explanation of your decision
-- is_complete: whether the task is complete
-- needs_confirm: whether this action needs user confirmation
-`, a.currentTask, pageDescription)
-
-	a.contextMgr.AddMessage("system", systemPrompt)
-	a.contextMgr.AddMessage("user", userInput)
-
-	needed := ctxmgr.EstimateTokens(systemPrompt) + ctxmgr.EstimateTokens(userInput) + 400
-	for !a.contextMgr.TokenCounter().CanAddTokens(needed) {
-		a.contextMgr.RemoveOldest(1)
-	}
+%s`, a.currentTask, pageDescription, note+a.variablesNote(), ai.DecisionFieldsPrompt())
 
-	decision, err := a.aiClient.MakeDecision(ctx, systemPrompt, userInput)
+	decision, usage, err := a.decide(ctx, systemPrompt, userInput, screenshots...)
 	if err != nil {
 		log.Printf("AI MakeDecision error: %v", err)
 		return ai.DecisionResponse{Action: "error", Reasoning: err.Error(), IsComplete: false}, nil
 	}
-
-	if decision.Reasoning != "" {
-		a.contextMgr.AddMessage("assistant", decision.Reasoning)
-	} else {
-		raw, _ := json.Marshal(decision)
-		a.contextMgr.AddMessage("assistant", string(raw))
+	if err := a.applyDecisionHook(&decision); err != nil {
+		log.Printf("%v\n", err)
+		return ai.DecisionResponse{Action: "error", Reasoning: err.Error(), IsComplete: false}, nil
 	}
 
-	promptTokens := ctxmgr.EstimateTokens(systemPrompt) + ctxmgr.EstimateTokens(userInput)
-	completionTokens := ctxmgr.EstimateTokens(decision.Reasoning)
+	a.rememberDecision(pageContent, "", decision)
+
+	promptTokens, completionTokens := usage.PromptTokens, usage.CompletionTokens
+	if !usage.Reported() {
+		promptTokens = ctxmgr.EstimateTokens(systemPrompt) + ctxmgr.EstimateTokens(userInput)
+		completionTokens = ctxmgr.EstimateTokens(decision.Reasoning)
+	}
 	if err := a.contextMgr.TokenCounter().Add(promptTokens, completionTokens); err != nil {
 		if a.verbose {
 			log.Printf("Token limit exceeded after add: %v. Pruning history...\n", err)
@@ -271,15 +650,65 @@ Return a JSON object with:
 	return decision, nil
 }
 
-func (a *Agent) executeAction(ctx context.Context, decision ai.DecisionResponse) error {
-	if decision.NeedsConfirm {
+func (a *Agent) executeAction(ctx context.Context, decision ai.DecisionResponse) (err error) {
+	a.expandVariables(&decision)
+	beforeURL := a.currentURL()
+	defer func() {
+		a.recordStep(decision, err)
+		a.emitActionResult(decision, beforeURL, err)
+	}()
+
+	destructive := a.isDestructiveDecision(decision)
+	// A retried destructive action (e.g. a payment after an ambiguous timeout) may
+	// already have gone through, so it always needs a fresh confirmation.
+	repeated := destructive && a.alreadyExecuted(decision)
+
+	unsure := a.belowConfidence(decision)
+	// Uploads hand local files to a website and scripts can do anything the
+	// page can, so both are always confirmed.
+	upload := ai.NormalizeAction(decision.Action) == ai.ActionUpload
+	evaluate := ai.NormalizeAction(decision.Action) == ai.ActionEvaluate
+	if evaluate && !a.AllowEvaluate {
+		return ErrEvaluateDisabled
+	}
+
+	// In safe mode anything that looks destructive stops the task for review,
+	// even if the model did not ask for confirmation.
+	if a.HaltOnDestructive && (destructive || unsure || upload || evaluate) {
+		security.LogAction(decision.Action, decision.Reasoning, false)
+		a.result.PendingAction = &decision
+		return &HaltedActionError{Decision: decision}
+	}
+
+	stepwise := a.needsStepApproval(decision)
+	if decision.NeedsConfirm || repeated || unsure || upload || evaluate || stepwise {
+		description := decision.Reasoning
+		if repeated {
+			description = "REPEAT of an action already executed in this task: " + description
+		} else if unsure {
+			description = fmt.Sprintf("LOW CONFIDENCE (%.2f): %s", decision.Confidence, description)
+		} else if upload {
+			description = fmt.Sprintf("UPLOAD of local file(s) %s: %s", strings.Join(uploadPaths(decision.Text), ", "), description)
+		} else if evaluate {
+			description = fmt.Sprintf("JAVASCRIPT %q: %s", decision.Text, description)
+		}
+		severity := "high"
+		if stepwise {
+			if details := a.stepDetails(decision); details != "" {
+				description = fmt.Sprintf("%s (%s)", description, details)
+			}
+			if !destructive && !unsure && !upload && !evaluate {
+				severity = "low"
+			}
+		}
 		destructiveAction := security.DestructiveAction{
 			Type:        decision.Action,
-			Description: decision.Reasoning,
-			Severity:    "high",
+			Description: description,
+			Target:      decision.Selector,
+			Severity:    severity,
 		}
 
-		approved, err := a.securityMgr.RequestConfirmation(destructiveAction)
+		approved, err := a.confirm(ctx, destructiveAction)
 		if err != nil {
 			return fmt.Errorf("confirmation check failed: %w", err)
 		}
@@ -290,83 +719,318 @@ func (a *Agent) executeAction(ctx context.Context, decision ai.DecisionResponse)
 		}
 	}
 
-	action := strings.ToLower(decision.Action)
+	handler, ok := a.actionHandlers()[ai.NormalizeAction(decision.Action)]
+	if !ok {
+		return fmt.Errorf("unknown action: %s", decision.Action)
+	}
+	if err := handler(ctx, decision); err != nil {
+		return a.withDiagnostics(err)
+	}
+	a.collectDownloads(ctx)
+	if destructive {
+		a.markExecuted(decision)
+	}
+	return nil
+}
 
-	switch action {
-	case "navigate":
-		if decision.URL != "" {
-			if err := a.browserMgr.Navigate(ctx, decision.URL); err != nil {
-				if strings.Contains(err.Error(), "page closed") {
-					if a.verbose {
-						log.Printf("Navigate: %v (will retry)\n", err)
-					}
-					return nil
-				}
-				return err
-			}
-			_ = a.browserMgr.WaitForNavigation(ctx)
-		}
-	case "click":
-		if decision.Selector != "" {
-			if err := a.browserMgr.Click(ctx, decision.Selector); err != nil {
-				return err
-			}
-			_ = a.browserMgr.WaitForNavigation(ctx)
-		}
-	case "fill", "input":
-		if decision.Selector != "" && decision.Text != "" {
-			if err := a.browserMgr.Fill(ctx, decision.Selector, decision.Text); err != nil {
-				return err
-			}
-		}
-	case "focus":
-		if decision.Selector != "" {
-			if err := a.browserMgr.Focus(ctx, decision.Selector); err != nil {
-				return err
-			}
-		}
-	case "type":
-		if decision.Selector != "" && decision.Text != "" {
-			if err := a.browserMgr.TypeText(ctx, decision.Selector, decision.Text); err != nil {
-				return err
-			}
-		}
-	case "press", "keypress", "key":
-		if decision.Text != "" {
-			if err := a.browserMgr.PressKey(ctx, decision.Text); err != nil {
-				return err
+// confirm asks whether an action may run, through Confirm if set.
+func (a *Agent) confirm(ctx context.Context, action security.DestructiveAction) (bool, error) {
+	a.emit(Event{Type: EventAwaitingInput, Action: action.Type, Message: action.Description})
+	defer a.emit(Event{Type: EventInputReceived})
+	if a.Confirm != nil {
+		return a.Confirm(ctx, action)
+	}
+	return a.securityMgr.RequestConfirmation(action)
+}
+
+// withDiagnostics attaches recent page JS errors to a failed action, since they
+// often explain why a click or fill did nothing.
+func (a *Agent) withDiagnostics(err error) error {
+	if !a.CollectDiagnostics || a.browserMgr == nil {
+		return err
+	}
+	return wrapWithConsoleErrors(err, a.browserMgr.RecentConsoleErrors(maxDiagnosticErrors))
+}
+
+func wrapWithConsoleErrors(err error, entries []browser.ConsoleEntry) error {
+	if len(entries) == 0 {
+		return err
+	}
+	msgs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		msgs = append(msgs, entry.Text)
+	}
+	return fmt.Errorf("%w; page had JS errors: %s", err, strings.Join(msgs, "; "))
+}
+
+// applyDecisionHook resolves an element number to its selector and runs the
+// OnDecision hook, if any.
+func (a *Agent) applyDecisionHook(decision *ai.DecisionResponse) error {
+	if err := a.resolveElementNumber(decision); err != nil {
+		return err
+	}
+	if a.OnDecision == nil {
+		return nil
+	}
+	if err := a.OnDecision(decision); err != nil {
+		return fmt.Errorf("decision rejected by hook: %w", err)
+	}
+	return nil
+}
+
+// belowConfidence reports whether an acting decision falls under MinConfidence.
+// Decisions without a reported confidence are not forced to confirm.
+func (a *Agent) belowConfidence(decision ai.DecisionResponse) bool {
+	if a.MinConfidence <= 0 || decision.Confidence <= 0 {
+		return false
+	}
+	switch ai.NormalizeAction(decision.Action) {
+	case ai.ActionWait, ai.ActionComplete, ai.ActionError, ai.ActionPause, ai.ActionClarify:
+		return false
+	}
+	return decision.Confidence < a.MinConfidence
+}
+
+// actionHandler executes a single normalized decision.
+type actionHandler func(ctx context.Context, decision ai.DecisionResponse) error
+
+// actionHandlers maps every action in the decision schema to its executor.
+func (a *Agent) actionHandlers() map[ai.ActionType]actionHandler {
+	return map[ai.ActionType]actionHandler{
+		ai.ActionNavigate:   a.doNavigate,
+		ai.ActionSearch:     a.doSearch,
+		ai.ActionFetch:      a.doFetch,
+		ai.ActionBack:       a.doHistory(a.browserMgr.GoBack),
+		ai.ActionForward:    a.doHistory(a.browserMgr.GoForward),
+		ai.ActionReload:     a.doHistory(a.browserMgr.Reload),
+		ai.ActionClick:      a.doClick,
+		ai.ActionFill:       a.doFill,
+		ai.ActionFocus:      a.doFocus,
+		ai.ActionHover:      a.doHover,
+		ai.ActionTypeText:   a.doType,
+		ai.ActionPress:      a.doPress,
+		ai.ActionSelect:     a.doSelect,
+		ai.ActionCheck:      a.doCheck,
+		ai.ActionUncheck:    a.doUncheck,
+		ai.ActionUpload:     a.doUpload,
+		ai.ActionScroll:     a.doScroll,
+		ai.ActionSwitchTab:  a.doSwitchTab,
+		ai.ActionScrape:     a.doScrape,
+		ai.ActionCrawl:      a.doCrawl,
+		ai.ActionExtract:    a.doExtract,
+		ai.ActionArticle:    a.doReadArticle,
+		ai.ActionScreenshot: a.doScreenshot,
+		ai.ActionEvaluate:   a.doEvaluate,
+		ai.ActionSkill:      a.doSkill,
+		ai.ActionSave:       a.doSave,
+		ai.ActionAssert:     a.doAssert,
+		ai.ActionWait:       a.doWait,
+		ai.ActionWaitFor:    a.doWaitFor,
+		ai.ActionPause: func(ctx context.Context, decision ai.DecisionResponse) error {
+			return a.waitForManualStep(ctx, decision.Reasoning)
+		},
+		ai.ActionClarify: a.doClarify,
+		ai.ActionComplete: func(ctx context.Context, decision ai.DecisionResponse) error {
+			a.recordAnswer(decision)
+			return nil
+		},
+		ai.ActionError: func(ctx context.Context, decision ai.DecisionResponse) error {
+			time.Sleep(1 * time.Second)
+			return nil
+		},
+	}
+}
+
+func (a *Agent) doNavigate(ctx context.Context, decision ai.DecisionResponse) error {
+	if decision.URL == "" {
+		return nil
+	}
+	if err := a.browserMgr.Navigate(ctx, decision.URL); err != nil {
+		if strings.Contains(err.Error(), "page closed") {
+			if a.verbose {
+				log.Printf("Navigate: %v (will retry)\n", err)
 			}
+			return nil
 		}
-	case "switch_tab", "switch":
-		target := decision.Text
-		if target == "" {
-			target = decision.URL
-		}
-		if err := a.browserMgr.SwitchToPage(ctx, target); err != nil {
+		return err
+	}
+	_ = a.browserMgr.WaitForNavigation(ctx)
+	return nil
+}
+
+// doHistory wraps a history navigation such as GoBack as an action handler.
+func (a *Agent) doHistory(navigate func(ctx context.Context) error) actionHandler {
+	return func(ctx context.Context, decision ai.DecisionResponse) error {
+		if err := navigate(ctx); err != nil {
 			return err
 		}
-	case "wait":
-		time.Sleep(2 * time.Second)
-	case "complete":
+		_ = a.browserMgr.WaitForNavigation(ctx)
 		return nil
-	case "error":
-		time.Sleep(1 * time.Second)
-	default:
-		return fmt.Errorf("unknown action: %s", decision.Action)
 	}
+}
 
+func (a *Agent) doClick(ctx context.Context, decision ai.DecisionResponse) error {
+	if decision.Selector == "" {
+		return nil
+	}
+	if err := a.browserMgr.Click(ctx, decision.Selector); err != nil {
+		return err
+	}
+	_ = a.browserMgr.WaitForNavigation(ctx)
 	return nil
 }
 
+func (a *Agent) doFill(ctx context.Context, decision ai.DecisionResponse) error {
+	if decision.Selector == "" || decision.Text == "" {
+		return nil
+	}
+	return a.browserMgr.Fill(ctx, decision.Selector, decision.Text)
+}
+
+func (a *Agent) doFocus(ctx context.Context, decision ai.DecisionResponse) error {
+	if decision.Selector == "" {
+		return nil
+	}
+	return a.browserMgr.Focus(ctx, decision.Selector)
+}
+
+func (a *Agent) doHover(ctx context.Context, decision ai.DecisionResponse) error {
+	if decision.Selector == "" {
+		return nil
+	}
+	return a.browserMgr.Hover(ctx, decision.Selector)
+}
+
+// maxWaitFor caps how long a single wait or wait_for may block the task.
+const maxWaitFor = 60 * time.Second
+
+// waitTimeout converts a decision's timeout in seconds, capped at maxWaitFor.
+func waitTimeout(decision ai.DecisionResponse) time.Duration {
+	timeout := time.Duration(decision.Timeout) * time.Second
+	if timeout > maxWaitFor {
+		timeout = maxWaitFor
+	}
+	return timeout
+}
+
+// doWait waits for the load state named in text, or simply pauses when no
+// state is given (e.g. while the user solves a CAPTCHA).
+func (a *Agent) doWait(ctx context.Context, decision ai.DecisionResponse) error {
+	state, ok := browser.ParseWaitStrategy(decision.Text)
+	if !ok {
+		time.Sleep(2 * time.Second)
+		return nil
+	}
+	return a.browserMgr.WaitForLoadState(ctx, state, waitTimeout(decision))
+}
+
+func (a *Agent) doWaitFor(ctx context.Context, decision ai.DecisionResponse) error {
+	if decision.Selector == "" {
+		return nil
+	}
+	return a.browserMgr.WaitForSelector(ctx, decision.Selector, waitTimeout(decision))
+}
+
+func (a *Agent) doType(ctx context.Context, decision ai.DecisionResponse) error {
+	if decision.Selector == "" || decision.Text == "" {
+		return nil
+	}
+	return a.browserMgr.TypeText(ctx, decision.Selector, decision.Text)
+}
+
+func (a *Agent) doPress(ctx context.Context, decision ai.DecisionResponse) error {
+	if decision.Text == "" {
+		return nil
+	}
+	return a.browserMgr.PressKey(ctx, decision.Text)
+}
+
+func (a *Agent) doSelect(ctx context.Context, decision ai.DecisionResponse) error {
+	if decision.Selector == "" || decision.Text == "" {
+		return nil
+	}
+	return a.browserMgr.SelectOption(ctx, decision.Selector, decision.Text)
+}
+
+func (a *Agent) doCheck(ctx context.Context, decision ai.DecisionResponse) error {
+	if decision.Selector == "" {
+		return nil
+	}
+	return a.browserMgr.Check(ctx, decision.Selector)
+}
+
+func (a *Agent) doUncheck(ctx context.Context, decision ai.DecisionResponse) error {
+	if decision.Selector == "" {
+		return nil
+	}
+	return a.browserMgr.Uncheck(ctx, decision.Selector)
+}
+
+func (a *Agent) doUpload(ctx context.Context, decision ai.DecisionResponse) error {
+	paths := uploadPaths(decision.Text)
+	if decision.Selector == "" || len(paths) == 0 {
+		return fmt.Errorf("upload requires a selector and file paths")
+	}
+	return a.browserMgr.SetInputFiles(ctx, decision.Selector, paths)
+}
+
+// uploadPaths splits the comma- or newline-separated file list of an upload decision.
+func uploadPaths(text string) []string {
+	var paths []string
+	for _, path := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+func (a *Agent) doSwitchTab(ctx context.Context, decision ai.DecisionResponse) error {
+	target := decision.Text
+	if target == "" {
+		target = decision.URL
+	}
+	return a.browserMgr.SwitchToPage(ctx, target)
+}
+
 func buildPageDescription(pageContent browser.PageContent, tabs []browser.TabInfo) string {
-	desc := fmt.Sprintf(`Title: %s
-URL: %s
+	desc := fmt.Sprintf("Title: %s\nURL: %s\n\n", pageContent.Title, pageContent.URL)
 
-Interactive Elements:
-`, pageContent.Title, pageContent.URL)
+	// The Markdown shows elements in context as [#N ...]; the list then only
+	// needs their selectors. The accessibility tree replaces it when present.
+	useMarkdown := pageContent.Markdown != "" && pageContent.AccessibilityTree == ""
+	if pageContent.AccessibilityTree != "" {
+		desc += "Accessibility Tree:\n" + pageContent.AccessibilityTree + "\n\n"
+	} else if useMarkdown {
+		desc += "Page Content:\n" + pageContent.Markdown + "\n\n"
+	}
 
+	desc += "Interactive Elements:\n"
 	for i, elem := range pageContent.Elements {
-		desc += fmt.Sprintf("%d. [%s] %s (selector: %s)\n", i+1, elem.Type, elem.Text, elem.Selector)
+		offscreen := ""
+		if elem.Offscreen {
+			offscreen = " (off-screen)"
+		}
+		if useMarkdown && elem.InMarkdown {
+			desc += fmt.Sprintf("%d. [%s] (selector: %s)%s\n", i+1, elem.Type, elem.Selector, offscreen)
+			continue
+		}
+		desc += fmt.Sprintf("%d. [%s] %s (selector: %s)%s\n", i+1, elem.Type, elem.Text, elem.Selector, offscreen)
+	}
+
+	if len(pageContent.LiveRegions) > 0 {
+		desc += "\nStatus Announcements:\n"
+		for _, announcement := range pageContent.LiveRegions {
+			desc += fmt.Sprintf("- %s\n", announcement)
+		}
+	}
+
+	// JS errors often explain why a click did nothing.
+	if len(pageContent.ConsoleErrors) > 0 {
+		desc += "\nConsole Errors:\n"
+		for _, entry := range pageContent.ConsoleErrors {
+			desc += fmt.Sprintf("- [%s] %s\n", entry.Type, entry.Text)
+		}
 	}
 
 	if len(tabs) > 0 {
@@ -383,28 +1047,102 @@ Interactive Elements:
 	return desc
 }
 
-func isBlockedPage(pageContent browser.PageContent) bool {
-	url := strings.ToLower(pageContent.URL)
-	title := strings.ToLower(pageContent.Title)
+// evaluateCondition checks a plan step condition against the current page.
+// exists reports whether a selector matches an element on the live page.
+func evaluateCondition(cond ai.StepCondition, pageContent browser.PageContent, exists func(selector string) (bool, error)) (bool, error) {
+	met := true
+	if cond.URLMatches != "" {
+		re, err := regexp.Compile(cond.URLMatches)
+		if err != nil {
+			return false, fmt.Errorf("invalid url_matches: %w", err)
+		}
+		met = re.MatchString(pageContent.URL)
+	}
+	if met && cond.TextPresent != "" {
+		met = strings.Contains(strings.ToLower(pageContent.MainText), strings.ToLower(cond.TextPresent))
+	}
+	if met && cond.SelectorPresent != "" {
+		found, err := exists(cond.SelectorPresent)
+		if err != nil {
+			return false, err
+		}
+		met = found
+	}
+	if cond.Negate {
+		met = !met
+	}
+	return met, nil
+}
+
+// spliceSteps returns steps with branch in place right after the step at
+// idx. It builds a new slice, since steps may be shared with the plan cache.
+func spliceSteps(steps []ai.PlanStep, idx int, branch []ai.PlanStep) []ai.PlanStep {
+	spliced := make([]ai.PlanStep, 0, len(steps)+len(branch))
+	spliced = append(spliced, steps[:idx+1]...)
+	spliced = append(spliced, branch...)
+	return append(spliced, steps[idx+1:]...)
+}
 
-	blockedPatterns := []string{
-		"captcha",
-		"showcaptcha",
-		"challenge",
-		"security check",
-		"verify",
-		"bot-check",
-		"robot",
-		"access denied",
-		"403",
-		"blocked",
+// conditionOutcome words a condition result for the step log.
+func conditionOutcome(met bool) string {
+	if met {
+		return "met"
 	}
+	return "not met"
+}
+
+// maxPlanningHeadings limits how many headings are included in the planning overview.
+const maxPlanningHeadings = 10
+
+// buildPlanningDescription summarizes the page for the planner: it only needs an
+// overview, so per-element selectors are left to buildPageDescription.
+func buildPlanningDescription(pageContent browser.PageContent) string {
+	desc := fmt.Sprintf("Title: %s\nURL: %s\n", pageContent.Title, pageContent.URL)
 
-	for _, pattern := range blockedPatterns {
-		if strings.Contains(url, pattern) || strings.Contains(title, pattern) {
-			return true
+	counts := make(map[string]int)
+	var types []string
+	for _, elem := range pageContent.Elements {
+		if counts[elem.Type] == 0 {
+			types = append(types, elem.Type)
+		}
+		counts[elem.Type]++
+	}
+	if len(types) > 0 {
+		desc += "\nInteractive elements:\n"
+		for _, t := range types {
+			desc += fmt.Sprintf("- %s: %d\n", t, counts[t])
+		}
+	}
+
+	if len(pageContent.Headings) > 0 {
+		desc += "\nHeadings:\n"
+		for i, heading := range pageContent.Headings {
+			if i >= maxPlanningHeadings {
+				break
+			}
+			desc += fmt.Sprintf("- %s\n", heading)
 		}
 	}
 
-	return false
+	return desc
+}
+
+// blockingStatuses are HTTP statuses sites answer bots with: the request
+// was refused or rate limited rather than served.
+var blockingStatuses = map[int]bool{403: true, 429: true}
+
+// isBlockedPage reports whether the page is a bot check instead of the content
+// asked for: a challenge widget is shown, or the server refused the request.
+// The title and URL are not consulted; pages about robots or e-mail
+// verification are ordinary pages.
+func isBlockedPage(pageContent browser.PageContent) bool {
+	return pageContent.Challenge != "" || blockingStatuses[pageContent.Status]
+}
+
+// blockReason describes why isBlockedPage reported the page as blocked.
+func blockReason(pageContent browser.PageContent) string {
+	if pageContent.Challenge != "" {
+		return pageContent.Challenge + " challenge"
+	}
+	return fmt.Sprintf("HTTP %d", pageContent.Status)
 }
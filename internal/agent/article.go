@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+)
+
+// articleCondenser is implemented by providers that can summarize text too
+// long for the model's context, such as ai.Client.
+type articleCondenser interface {
+	CondenseForAnalysis(ctx context.Context, content string, task string) (string, error)
+}
+
+// doReadArticle adds the main content of the page to the conversation, so the
+// model can answer "summarize this page" tasks from the article alone. Long
+// articles are condensed with the task in mind first.
+func (a *Agent) doReadArticle(ctx context.Context, decision ai.DecisionResponse) error {
+	article, err := a.browserMgr.ExtractArticle(ctx)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(article.Text) == "" {
+		return fmt.Errorf("no article text found on the page")
+	}
+
+	text := article.Text
+	if condenser, ok := a.aiClient.(articleCondenser); ok {
+		var usage ai.Usage
+		condensed, err := condenser.CondenseForAnalysis(ai.WithUsage(ctx, &usage), text, a.currentTask)
+		if err != nil {
+			log.Printf("Warning: failed to condense article, using it as is: %v\n", err)
+		} else if condensed != text {
+			a.result.TokenUsage.add(usage, text, condensed)
+			text = condensed
+		}
+	}
+
+	header := "Article: " + article.Title
+	if article.Byline != "" {
+		header += " by " + article.Byline
+	}
+	if article.Published != "" {
+		header += " (" + article.Published + ")"
+	}
+	a.contextMgr.AddMessage("system", header+"\n\n"+text)
+	if a.verbose {
+		log.Printf("Read article %q (%d characters)\n", article.Title, len(article.Text))
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+)
+
+// ErrAssertionFailed is returned by a task whose assert actions did not all
+// pass, however the rest of the task went.
+var ErrAssertionFailed = errors.New("assertion failed")
+
+// assertPollInterval is how often an assert with a timeout checks again.
+const assertPollInterval = 500 * time.Millisecond
+
+// AssertionResult is the outcome of one assert action.
+type AssertionResult struct {
+	Check  string `json:"check"` // e.g. text "Free shipping" present
+	Passed bool   `json:"passed"`
+	URL    string `json:"url,omitempty"` // page the check ran on
+}
+
+// doAssert checks the page and records the outcome. A failed check is a
+// result, not an action failure: recovery would have the model change the
+// page until the check passes, which defeats the check.
+func (a *Agent) doAssert(ctx context.Context, decision ai.DecisionResponse) error {
+	cond := ai.StepCondition{
+		URLMatches:      decision.URL,
+		TextPresent:     decision.Text,
+		SelectorPresent: decision.Selector,
+		Negate:          decision.Negate,
+	}
+	deadline := time.Now().Add(time.Duration(decision.Timeout) * time.Second)
+	for {
+		pc, err := a.browserMgr.GetPageContent(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get page content for the check: %w", err)
+		}
+		passed, err := evaluateCondition(cond, pc, func(selector string) (bool, error) {
+			return a.browserMgr.ElementExists(ctx, selector)
+		})
+		if err != nil {
+			return err
+		}
+		if passed || !time.Now().Before(deadline) {
+			a.recordAssertion(AssertionResult{Check: describeCondition(cond), Passed: passed, URL: pc.URL})
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(assertPollInterval):
+		}
+	}
+}
+
+func (a *Agent) recordAssertion(result AssertionResult) {
+	a.result.Assertions = append(a.result.Assertions, result)
+	outcome := "PASS"
+	if !result.Passed {
+		outcome = "FAIL"
+	}
+	log.Printf("%s: %s on %s\n", outcome, result.Check, result.URL)
+	a.emit(Event{Type: EventAssertion, URL: result.URL, Message: outcome + ": " + result.Check})
+	if a.contextMgr != nil {
+		a.contextMgr.AddMessage("system", fmt.Sprintf("Check %s: %s. Record it in the answer; do not change the page to make it pass.", outcome, result.Check))
+	}
+}
+
+// assertionError fails a task when any of its checks failed.
+func (a *Agent) assertionError() error {
+	var failed []string
+	for _, result := range a.result.Assertions {
+		if !result.Passed {
+			failed = append(failed, result.Check)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %d of %d check(s) failed: %s", ErrAssertionFailed, len(failed), len(a.result.Assertions), strings.Join(failed, "; "))
+}
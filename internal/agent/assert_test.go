@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+)
+
+func TestAssertionError(t *testing.T) {
+	a := &Agent{}
+	if err := a.assertionError(); err != nil {
+		t.Fatalf("no checks should not fail the task: %v", err)
+	}
+	a.recordAssertion(AssertionResult{Check: `text "Free shipping" present`, Passed: true, URL: "https://shop.example/checkout"})
+	if err := a.assertionError(); err != nil {
+		t.Fatalf("passing checks should not fail the task: %v", err)
+	}
+	a.recordAssertion(AssertionResult{Check: `element ".error" present`, Passed: false, URL: "https://shop.example/checkout"})
+	err := a.assertionError()
+	if !errors.Is(err, ErrAssertionFailed) || err.Error() != `assertion failed: 1 of 2 check(s) failed: element ".error" present` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateAssert(t *testing.T) {
+	valid := ai.DecisionResponse{Action: "assert", Text: "Free shipping", Reasoning: "check"}
+	if err := ai.ValidateDecision(valid); err != nil {
+		t.Fatalf("ValidateDecision: %v", err)
+	}
+	for _, d := range []ai.DecisionResponse{
+		{Action: "assert", Reasoning: "nothing to check"},
+		{Action: "assert", URL: "(", Reasoning: "bad pattern"},
+	} {
+		if err := ai.ValidateDecision(d); err == nil {
+			t.Errorf("expected %+v to be rejected", d)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+func TestIsBlockedPage(t *testing.T) {
+	tests := []struct {
+		name    string
+		page    browser.PageContent
+		blocked bool
+	}{
+		{"challenge widget", browser.PageContent{URL: "https://shop.example", Status: 200, Challenge: "recaptcha"}, true},
+		{"cloudflare interstitial", browser.PageContent{URL: "https://shop.example", Status: 403, Challenge: "cloudflare"}, true},
+		{"forbidden", browser.PageContent{URL: "https://shop.example/admin", Status: 403}, true},
+		{"rate limited", browser.PageContent{URL: "https://api.example", Status: 429}, true},
+		{"robots docs", browser.PageContent{Title: "robots.txt documentation", URL: "https://developers.example/robots", Status: 200}, false},
+		{"email verification", browser.PageContent{Title: "Verify your email", URL: "https://app.example/verify-email", Status: 200}, false},
+		{"unknown status", browser.PageContent{Title: "Access denied", URL: "https://example.com/blocked"}, false},
+	}
+	for _, tt := range tests {
+		if got := isBlockedPage(tt.page); got != tt.blocked {
+			t.Errorf("%s: isBlockedPage = %v, want %v", tt.name, got, tt.blocked)
+		}
+	}
+}
+
+func TestBlockReason(t *testing.T) {
+	if got := blockReason(browser.PageContent{Challenge: "hcaptcha", Status: 200}); got != "hcaptcha challenge" {
+		t.Errorf("blockReason = %q", got)
+	}
+	if got := blockReason(browser.PageContent{Status: 429}); got != "HTTP 429" {
+		t.Errorf("blockReason = %q", got)
+	}
+}
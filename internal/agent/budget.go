@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrBudgetExceeded is returned when a task goes over one of the limits of
+// its Budget.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// Budget holds hard per-task limits, so a task stuck on a broken page stops
+// instead of spending model calls until it runs out of iterations. Zero
+// fields are unlimited.
+type Budget struct {
+	MaxTokens   int           // prompt and completion tokens together
+	MaxCostUSD  float64       // price of the reported usage
+	MaxDuration time.Duration // wall-clock time from the start of the task
+}
+
+// checkBudget returns an ErrBudgetExceeded error once the current task has
+// used up its budget. It is checked before every model call.
+func (a *Agent) checkBudget() error {
+	usage := a.result.TokenUsage
+	switch {
+	case a.Budget.MaxTokens > 0 && usage.TotalTokens >= a.Budget.MaxTokens:
+		return fmt.Errorf("%w: used %d tokens, limit is %d", ErrBudgetExceeded, usage.TotalTokens, a.Budget.MaxTokens)
+	case a.Budget.MaxCostUSD > 0 && usage.CostUSD >= a.Budget.MaxCostUSD:
+		return fmt.Errorf("%w: spent $%.4f, limit is $%.4f", ErrBudgetExceeded, usage.CostUSD, a.Budget.MaxCostUSD)
+	case a.Budget.MaxDuration > 0 && time.Since(a.result.StartedAt) >= a.Budget.MaxDuration:
+		return a.durationExceeded()
+	}
+	return nil
+}
+
+func (a *Agent) durationExceeded() error {
+	return fmt.Errorf("%w: ran for %s, limit is %s", ErrBudgetExceeded, time.Since(a.result.StartedAt).Round(time.Second), a.Budget.MaxDuration)
+}
+
+// withTimeBudget bounds ctx by MaxDuration, so a long model call or page wait
+// is cut off too. The returned function maps the resulting deadline error to
+// ErrBudgetExceeded.
+func (a *Agent) withTimeBudget(ctx context.Context) (context.Context, func(err error) error, context.CancelFunc) {
+	if a.Budget.MaxDuration <= 0 {
+		return ctx, func(err error) error { return err }, func() {}
+	}
+	budgeted, cancel := context.WithTimeout(ctx, a.Budget.MaxDuration)
+	explain := func(err error) error {
+		if err != nil && !errors.Is(err, ErrBudgetExceeded) && budgeted.Err() != nil && ctx.Err() == nil {
+			return a.durationExceeded()
+		}
+		return err
+	}
+	return budgeted, explain, cancel
+}
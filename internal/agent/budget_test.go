@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckBudget(t *testing.T) {
+	a := &Agent{}
+	a.result = TaskResult{StartedAt: time.Now().Add(-time.Minute)}
+	a.result.TokenUsage = TokenUsage{TotalTokens: 5000, CostUSD: 0.2}
+	if err := a.checkBudget(); err != nil {
+		t.Fatalf("no budget should mean no limit: %v", err)
+	}
+
+	for _, budget := range []Budget{{MaxTokens: 5000}, {MaxCostUSD: 0.1}, {MaxDuration: 30 * time.Second}} {
+		a.Budget = budget
+		if err := a.checkBudget(); !errors.Is(err, ErrBudgetExceeded) {
+			t.Errorf("budget %+v: expected ErrBudgetExceeded, got %v", budget, err)
+		}
+	}
+	a.Budget = Budget{MaxTokens: 10000, MaxCostUSD: 1, MaxDuration: time.Hour}
+	if err := a.checkBudget(); err != nil {
+		t.Fatalf("task within budget was stopped: %v", err)
+	}
+}
+
+func TestTimeBudgetExplainsDeadline(t *testing.T) {
+	a := &Agent{Budget: Budget{MaxDuration: time.Millisecond}}
+	a.result.StartedAt = time.Now()
+	ctx, explain, cancel := a.withTimeBudget(context.Background())
+	defer cancel()
+	<-ctx.Done()
+	if err := explain(ctx.Err()); !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected the deadline to be reported as a budget error, got %v", err)
+	}
+
+	parent, stop := context.WithCancel(context.Background())
+	ctx, explain, cancel = a.withTimeBudget(parent)
+	defer cancel()
+	stop()
+	if err := explain(ctx.Err()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancellation by the caller should be kept, got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/VolodyaPopov923/AIBot/internal/captcha"
+)
+
+// solveCaptcha has the CAPTCHA of the current page solved by a.CaptchaSolver
+// and submits the token. It fails if the page is still blocked afterwards.
+func (a *Agent) solveCaptcha(ctx context.Context) error {
+	widget, err := a.browserMgr.FindCaptcha(ctx)
+	if err != nil {
+		return err
+	}
+	if widget == nil {
+		return fmt.Errorf("no supported CAPTCHA widget found on the page")
+	}
+	pageURL := a.currentURL()
+	log.Printf("Solving %s CAPTCHA on %s...\n", widget.Kind, pageURL)
+	token, err := a.CaptchaSolver.Solve(ctx, captcha.Challenge{
+		Kind:    captcha.Kind(widget.Kind),
+		SiteKey: widget.SiteKey,
+		PageURL: pageURL,
+	})
+	if err != nil {
+		return err
+	}
+	if err := a.browserMgr.SubmitCaptchaToken(ctx, *widget, token); err != nil {
+		return err
+	}
+	_ = a.browserMgr.WaitForNavigation(ctx)
+
+	pageContent, err := a.browserMgr.GetPageContent(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read page after submitting CAPTCHA token: %w", err)
+	}
+	if isBlockedPage(pageContent) {
+		return fmt.Errorf("page is still blocked after submitting the CAPTCHA token")
+	}
+	log.Printf("CAPTCHA solved by solver, now at: %s\n", pageContent.URL)
+	return nil
+}
@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+)
+
+// Clarification is a question the agent asked the user during a task.
+type Clarification struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer,omitempty"`
+}
+
+// doClarify asks the user the decision's question and adds the answer to the
+// task, so later steps and replanning see it. It follows ManualSteps: with
+// nobody at the terminal the model is told to go on with its best judgment,
+// or the task fails.
+func (a *Agent) doClarify(ctx context.Context, decision ai.DecisionResponse) error {
+	question := decision.Text
+	var answer string
+	switch a.ManualSteps {
+	case ManualStepSkip:
+		log.Printf("Question skipped (non-interactive): %s\n", question)
+	case ManualStepFail:
+		return fmt.Errorf("%w: the agent asked %q", ErrManualStepRequired, question)
+	default:
+		var err error
+		if answer, err = a.ask(ctx, "❓ "+question, "Your answer: "); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			return fmt.Errorf("failed to read the answer: %w", err)
+		}
+	}
+
+	a.result.Clarifications = append(a.result.Clarifications, Clarification{Question: question, Answer: answer})
+	if answer == "" {
+		a.contextMgr.AddMessage("system", fmt.Sprintf("Nobody answered %q. Go on with your best judgment and state the assumption in the answer.", question))
+		return nil
+	}
+	a.currentTask += fmt.Sprintf("\n(Asked %q, the user answered: %s)", question, answer)
+	a.contextMgr.AddMessage("user", fmt.Sprintf("Answer to %q: %s", question, answer))
+	return nil
+}
@@ -0,0 +1,73 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	ctxmgr "github.com/VolodyaPopov923/AIBot/internal/context"
+)
+
+func TestClarifyFeedsAnswerBack(t *testing.T) {
+	a := &Agent{ManualSteps: ManualStepWait, contextMgr: ctxmgr.NewContextManager(8000, 20), currentTask: "book a table"}
+	a.SetInput(strings.NewReader("Pushkin cafe, tomorrow at 19:00\n"))
+
+	decision := ai.DecisionResponse{Action: "ask_user", Text: "Which restaurant, and when?", Reasoning: "the task names neither"}
+	if err := a.executeAction(context.Background(), decision); err != nil {
+		t.Fatalf("clarify failed: %v", err)
+	}
+	want := Clarification{Question: "Which restaurant, and when?", Answer: "Pushkin cafe, tomorrow at 19:00"}
+	if len(a.result.Clarifications) != 1 || a.result.Clarifications[0] != want {
+		t.Fatalf("clarifications = %+v", a.result.Clarifications)
+	}
+	if !strings.Contains(a.currentTask, "Pushkin cafe, tomorrow at 19:00") {
+		t.Errorf("the answer should become part of the task, got %q", a.currentTask)
+	}
+	messages := a.contextMgr.GetMessages()
+	if len(messages) == 0 || !strings.Contains(messages[len(messages)-1].Content, "Pushkin cafe") {
+		t.Errorf("the answer should be added to the context, got %+v", messages)
+	}
+}
+
+func TestClarifyWithoutUser(t *testing.T) {
+	a := &Agent{ManualSteps: ManualStepSkip, contextMgr: ctxmgr.NewContextManager(8000, 20), currentTask: "book a table"}
+	decision := ai.DecisionResponse{Action: "clarify", Text: "Which restaurant?"}
+	if err := a.doClarify(context.Background(), decision); err != nil {
+		t.Fatalf("skip policy should continue, got %v", err)
+	}
+	if a.currentTask != "book a table" || len(a.result.Clarifications) != 1 {
+		t.Fatalf("unanswered question should only be recorded: task %q, %+v", a.currentTask, a.result.Clarifications)
+	}
+
+	a.ManualSteps = ManualStepFail
+	if err := a.doClarify(context.Background(), decision); !errors.Is(err, ErrManualStepRequired) {
+		t.Fatalf("fail policy should return ErrManualStepRequired, got %v", err)
+	}
+
+	if err := ai.ValidateDecision(ai.DecisionResponse{Action: "clarify", Reasoning: "no question"}); err == nil {
+		t.Errorf("clarify without a question should be rejected")
+	}
+}
+
+func TestClarifyAskHook(t *testing.T) {
+	var questions []string
+	a := &Agent{ManualSteps: ManualStepWait, contextMgr: ctxmgr.NewContextManager(8000, 20), currentTask: "book a table"}
+	a.Ask = func(ctx context.Context, question string) (string, error) {
+		questions = append(questions, question)
+		return "Pushkin cafe", nil
+	}
+	if err := a.doClarify(context.Background(), ai.DecisionResponse{Action: "clarify", Text: "Which restaurant?"}); err != nil {
+		t.Fatalf("clarify failed: %v", err)
+	}
+	if err := a.waitForManualStep(context.Background(), "log in"); err != nil {
+		t.Fatalf("manual step failed: %v", err)
+	}
+	if len(questions) != 2 || questions[0] != "❓ Which restaurant?" || questions[1] != "✋ Manual step required: log in" {
+		t.Errorf("Ask got %q", questions)
+	}
+	if len(a.result.Clarifications) != 1 || a.result.Clarifications[0].Answer != "Pushkin cafe" {
+		t.Errorf("clarifications = %+v", a.result.Clarifications)
+	}
+}
@@ -0,0 +1,32 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/security"
+)
+
+func TestLowConfidenceActionRequiresConfirmation(t *testing.T) {
+	input := strings.NewReader("no\n")
+	a := &Agent{securityMgr: security.NewValidatorWithReader(input), MinConfidence: 0.6}
+	ctx := context.Background()
+
+	shaky := ai.DecisionResponse{Action: "click", Reasoning: "Maybe this is the search button", Confidence: 0.3}
+	err := a.executeAction(ctx, shaky)
+	if err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Fatalf("expected low-confidence action to require confirmation, got %v", err)
+	}
+
+	sure := ai.DecisionResponse{Action: "click", Reasoning: "Search button", Confidence: 0.9}
+	if err := a.executeAction(ctx, sure); err != nil {
+		t.Fatalf("confident action should not be forced to confirm: %v", err)
+	}
+
+	a.MinConfidence = 0
+	if a.belowConfidence(shaky) {
+		t.Fatalf("default threshold should never force confirmation")
+	}
+}
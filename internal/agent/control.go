@@ -0,0 +1,111 @@
+package agent
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// taskControl lets another goroutine pause and resume the running task.
+// The task only stops between steps, never in the middle of an action.
+type taskControl struct {
+	mu      sync.Mutex
+	pausing bool
+	paused  chan struct{} // closed once the task has stopped, or has ended
+	resume  chan struct{} // closed by Resume
+}
+
+// Pause asks the running task to stop before its next step, e.g. so the user
+// can log in by hand. It returns at once; the returned channel is closed once
+// the task has stopped, or has ended without reaching another step. Paused
+// time counts toward Budget.MaxDuration. A task is cancelled through the
+// context passed to ExecuteTask, paused or not.
+func (a *Agent) Pause() <-chan struct{} {
+	c := &a.control
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.pausing {
+		c.pausing = true
+		c.paused = make(chan struct{})
+		c.resume = make(chan struct{})
+	}
+	return c.paused
+}
+
+// Resume lets a paused task continue, or withdraws a pause that has not
+// taken effect yet.
+func (a *Agent) Resume() {
+	c := &a.control
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pausing {
+		c.pausing = false
+		close(c.resume)
+	}
+}
+
+// Paused reports whether a pause is requested or in effect.
+func (a *Agent) Paused() bool {
+	a.control.mu.Lock()
+	defer a.control.mu.Unlock()
+	return a.control.pausing
+}
+
+// checkpoint is called between steps. It returns the context's error once
+// the task is cancelled, and blocks while the task is paused.
+func (a *Agent) checkpoint(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c := &a.control
+	c.mu.Lock()
+	if !c.pausing {
+		c.mu.Unlock()
+		return nil
+	}
+	paused, resume := c.paused, c.resume
+	c.mu.Unlock()
+
+	// Announce the pause before it takes effect, so that whoever waits on
+	// Pause, e.g. to prompt the user, writes after the announcement.
+	log.Printf("⏸  Task paused\n")
+	a.emit(Event{Type: EventPaused, URL: a.currentURL()})
+	c.mu.Lock()
+	select {
+	case <-paused:
+	default:
+		close(paused)
+	}
+	c.mu.Unlock()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-resume:
+	}
+	log.Printf("▶️  Task resumed\n")
+	a.emit(Event{Type: EventResumed, URL: a.currentURL()})
+	// The user may have changed the page while the task was paused.
+	if a.contextMgr != nil {
+		a.contextMgr.AddMessage("system", "The task was paused and resumed; the page may have changed meanwhile (e.g. the user logged in), so look at it afresh.")
+	}
+	a.freshDecision = true
+	return nil
+}
+
+// endControl releases anyone waiting for a pause that the finished task
+// never reached; the pause request itself is dropped with the task.
+func (a *Agent) endControl() {
+	c := &a.control
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.pausing {
+		return
+	}
+	select {
+	case <-c.paused:
+	default:
+		close(c.paused)
+	}
+	c.pausing = false
+	close(c.resume)
+}
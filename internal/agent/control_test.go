@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPauseResume(t *testing.T) {
+	var events []EventType
+	eventsCh := make(chan EventType, 4)
+	a := &Agent{OnEvent: func(e Event) { eventsCh <- e.Type }}
+
+	paused := a.Pause()
+	done := make(chan error, 1)
+	go func() { done <- a.checkpoint(context.Background()) }()
+
+	select {
+	case <-paused:
+	case <-time.After(time.Second):
+		t.Fatalf("task did not pause")
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("checkpoint returned while paused: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if !a.Paused() {
+		t.Fatalf("Paused() = false while paused")
+	}
+
+	a.Resume()
+	if err := <-done; err != nil {
+		t.Fatalf("checkpoint after resume: %v", err)
+	}
+	for len(eventsCh) > 0 {
+		events = append(events, <-eventsCh)
+	}
+	if len(events) != 2 || events[0] != EventPaused || events[1] != EventResumed {
+		t.Fatalf("events = %v", events)
+	}
+	if !a.freshDecision {
+		t.Errorf("the next decision should look at the page afresh after a pause")
+	}
+	if err := a.checkpoint(context.Background()); err != nil {
+		t.Fatalf("checkpoint without a pause: %v", err)
+	}
+}
+
+func TestCancelWhilePaused(t *testing.T) {
+	a := &Agent{}
+	ctx, cancel := context.WithCancel(context.Background())
+	paused := a.Pause()
+	done := make(chan error, 1)
+	go func() { done <- a.checkpoint(ctx) }()
+	<-paused
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the cancellation, got %v", err)
+	}
+
+	// A pause the task never reached is released when the task ends.
+	a.endControl()
+	unreached := a.Pause()
+	a.endControl()
+	select {
+	case <-unreached:
+	default:
+		t.Fatalf("ending the task should release pause waiters")
+	}
+	if a.Paused() {
+		t.Fatalf("the pause request should end with the task")
+	}
+}
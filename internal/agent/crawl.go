@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+// defaultCrawlPages is how many pages a crawl visits when the model does not say.
+const defaultCrawlPages = 5
+
+// crawlPages returns the page limit of a crawl decision, capped by
+// MaxCrawlPages.
+func (a *Agent) crawlPages(requested int) int {
+	pages := requested
+	if pages <= 0 {
+		pages = defaultCrawlPages
+	}
+	if a.MaxCrawlPages > 0 && pages > a.MaxCrawlPages {
+		pages = a.MaxCrawlPages
+	}
+	return pages
+}
+
+func (a *Agent) doCrawl(ctx context.Context, decision ai.DecisionResponse) error {
+	if decision.Selector == "" {
+		return fmt.Errorf("crawl requires a selector")
+	}
+	result, err := a.browserMgr.Crawl(ctx, browser.CrawlOptions{
+		ItemSelector: decision.Selector,
+		NextSelector: decision.Text,
+		MaxPages:     a.crawlPages(decision.MaxPages),
+	})
+	// Items of the pages crawled before a failure are still worth keeping.
+	a.result.Extracted = append(a.result.Extracted, result.Items...)
+	if err != nil {
+		return err
+	}
+	if len(result.Items) == 0 {
+		return fmt.Errorf("%w: crawl of %d page(s) found nothing matching %s", ErrTooFewResults, len(result.Pages), decision.Selector)
+	}
+
+	a.saveResult(decision, strings.Join(result.Items, "\n"))
+	a.contextMgr.AddMessage("system", fmt.Sprintf("Crawled %d page(s), %s, and collected %d item(s) from %s:\n%s",
+		len(result.Pages), result.StopReason, len(result.Items), decision.Selector, strings.Join(result.Items, "\n")))
+	if a.verbose {
+		log.Printf("Crawled %d page(s) (%s): %d item(s), %d duplicate(s) skipped\n", len(result.Pages), result.StopReason, len(result.Items), result.Duplicates)
+	}
+	return nil
+}
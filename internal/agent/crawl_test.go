@@ -0,0 +1,17 @@
+package agent
+
+import "testing"
+
+func TestCrawlPages(t *testing.T) {
+	a := &Agent{}
+	if got := a.crawlPages(0); got != defaultCrawlPages {
+		t.Errorf("crawlPages(0) = %d, want %d", got, defaultCrawlPages)
+	}
+	if got := a.crawlPages(12); got != 12 {
+		t.Errorf("crawlPages(12) = %d, want 12", got)
+	}
+	a.MaxCrawlPages = 3
+	if got := a.crawlPages(12); got != 3 {
+		t.Errorf("crawlPages(12) with a cap of 3 = %d, want 3", got)
+	}
+}
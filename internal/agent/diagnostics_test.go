@@ -0,0 +1,64 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+func TestWrapWithConsoleErrors(t *testing.T) {
+	base := errors.New("failed to click element")
+	err := wrapWithConsoleErrors(base, []browser.ConsoleEntry{{Type: "pageerror", Text: "TypeError: x is undefined"}})
+
+	if !errors.Is(err, base) {
+		t.Fatalf("diagnostics should wrap the original error")
+	}
+	if !strings.Contains(err.Error(), "page had JS errors: TypeError: x is undefined") {
+		t.Fatalf("expected JS error in message, got %q", err.Error())
+	}
+	if wrapWithConsoleErrors(base, nil) != base {
+		t.Fatalf("error without diagnostics should be returned unchanged")
+	}
+}
+
+func TestFailedActionSurfacesJSError(t *testing.T) {
+	t.Setenv("BROWSER_HEADLESS", "true")
+	t.Setenv("BROWSER_USER_DATA_DIR", t.TempDir())
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body>
+			<button id="btn" onclick="undefinedHandler()">Save</button>
+		</body></html>`))
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	mgr, err := browser.NewManager(ctx)
+	if err != nil {
+		t.Skipf("Playwright unavailable: %v", err)
+	}
+	defer mgr.Close(ctx)
+
+	ag := NewAgent(mgr, nil, false)
+	if err := mgr.Navigate(ctx, ts.URL); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+	if err := mgr.Click(ctx, "#btn"); err != nil {
+		t.Fatalf("click failed: %v", err)
+	}
+
+	// Filling a button fails immediately; the earlier JS error should be attached.
+	err = ag.executeAction(ctx, ai.DecisionResponse{Action: "fill", Selector: "#btn", Text: "x"})
+	if err == nil {
+		t.Fatalf("expected fill on a button to fail")
+	}
+	if !strings.Contains(err.Error(), "undefinedHandler") {
+		t.Fatalf("expected JS error in diagnostics, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+)
+
+// ErrNotDone is returned when a plan ran through but the page does not pass
+// the plan's completion checks.
+var ErrNotDone = errors.New("success criteria not met")
+
+// unmetCondition checks cond against the current page and describes what is
+// missing, or returns "" when it holds. A nil condition, or one that cannot
+// be checked, counts as met: the checks confirm success, they do not replace
+// the steps.
+func (a *Agent) unmetCondition(ctx context.Context, cond *ai.StepCondition) string {
+	if cond == nil {
+		return ""
+	}
+	pc, err := a.browserMgr.GetPageContent(ctx)
+	if err != nil {
+		log.Printf("Warning: failed to get page content for the completion check: %v\n", err)
+		return ""
+	}
+	met, err := evaluateCondition(*cond, pc, func(selector string) (bool, error) {
+		return a.browserMgr.ElementExists(ctx, selector)
+	})
+	if err != nil {
+		log.Printf("Warning: completion check failed: %v\n", err)
+		return ""
+	}
+	if met {
+		if a.verbose {
+			log.Printf("Completion check passed: %s\n", describeCondition(*cond))
+		}
+		return ""
+	}
+	return fmt.Sprintf("the page at %s does not pass the completion check (%s)", pc.URL, describeCondition(*cond))
+}
+
+// describeCondition spells out a condition, e.g. for the model or a log.
+func describeCondition(cond ai.StepCondition) string {
+	var checks []string
+	if cond.URLMatches != "" {
+		checks = append(checks, fmt.Sprintf("URL matches %q", cond.URLMatches))
+	}
+	if cond.TextPresent != "" {
+		checks = append(checks, fmt.Sprintf("text %q present", cond.TextPresent))
+	}
+	if cond.SelectorPresent != "" {
+		checks = append(checks, fmt.Sprintf("element %q present", cond.SelectorPresent))
+	}
+	desc := strings.Join(checks, " and ")
+	if cond.Negate {
+		desc = "not: " + desc
+	}
+	return desc
+}
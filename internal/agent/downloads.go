@@ -0,0 +1,30 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// downloadWaitTimeout bounds how long an action waits for files it started downloading.
+const downloadWaitTimeout = 30 * time.Second
+
+// collectDownloads waits for downloads started by the last action and records
+// the saved files in the task result and the conversation.
+func (a *Agent) collectDownloads(ctx context.Context) {
+	if a.browserMgr == nil {
+		return
+	}
+	if err := a.browserMgr.WaitForDownloads(ctx, downloadWaitTimeout); err != nil {
+		log.Printf("Warning: %v\n", err)
+	}
+	for _, d := range a.browserMgr.TakeDownloads() {
+		if d.Path == "" {
+			a.contextMgr.AddMessage("system", fmt.Sprintf("Download of %s failed: %s", d.URL, d.Error))
+			continue
+		}
+		a.result.Downloads = append(a.result.Downloads, d.Path)
+		a.contextMgr.AddMessage("system", fmt.Sprintf("Downloaded file saved to %s", d.Path))
+	}
+}
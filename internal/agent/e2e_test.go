@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/VolodyaPopov923/AIBot/internal/ai"
 	"github.com/VolodyaPopov923/AIBot/internal/browser"
@@ -51,7 +52,7 @@ func TestAgentE2E_LocalPage(t *testing.T) {
 	if err := mgr.Navigate(ctx, ts.URL); err != nil {
 		t.Fatalf("navigate failed: %v", err)
 	}
-	if err := mgr.WaitForSelector(ctx, "#btn", 3000); err != nil {
+	if err := mgr.WaitForSelector(ctx, "#btn", 3*time.Second); err != nil {
 		t.Fatalf("btn not visible: %v", err)
 	}
 
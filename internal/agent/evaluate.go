@@ -0,0 +1,41 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+)
+
+// maxEvaluateResult caps the script result fed back to the model (in runes).
+const maxEvaluateResult = 4000
+
+// ErrEvaluateDisabled is returned for evaluate actions unless AllowEvaluate is set.
+var ErrEvaluateDisabled = errors.New("evaluate actions are disabled")
+
+func (a *Agent) doEvaluate(ctx context.Context, decision ai.DecisionResponse) error {
+	if decision.Text == "" {
+		return fmt.Errorf("evaluate requires a script")
+	}
+	result, err := a.browserMgr.Evaluate(ctx, decision.Text)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode script result: %w", err)
+	}
+	text := string(raw)
+	a.saveResult(decision, text)
+	if runes := []rune(text); len(runes) > maxEvaluateResult {
+		text = string(runes[:maxEvaluateResult]) + "…"
+	}
+	a.contextMgr.AddMessage("system", "JavaScript result: "+text)
+	if a.verbose {
+		log.Printf("JavaScript result: %s\n", text)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/security"
+)
+
+func TestEvaluateIsGatedAndConfirmed(t *testing.T) {
+	a := &Agent{securityMgr: security.NewValidatorWithReader(strings.NewReader("no\n"))}
+	ctx := context.Background()
+	script := ai.DecisionResponse{Action: "evaluate", Text: "getComputedStyle(document.body).color", Reasoning: "Read the theme color"}
+
+	if err := a.executeAction(ctx, script); !errors.Is(err, ErrEvaluateDisabled) {
+		t.Fatalf("expected evaluate to be disabled by default, got %v", err)
+	}
+
+	a.AllowEvaluate = true
+	if err := a.executeAction(ctx, script); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Fatalf("expected evaluate to require confirmation, got %v", err)
+	}
+
+	a.HaltOnDestructive = true
+	if _, ok := HaltedAction(a.executeAction(ctx, script)); !ok {
+		t.Fatalf("expected evaluate to halt in safe mode")
+	}
+}
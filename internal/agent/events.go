@@ -0,0 +1,106 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+)
+
+// EventType identifies a step-level progress event.
+type EventType string
+
+const (
+	EventTaskStarted    EventType = "task_started"
+	EventPlanning       EventType = "planning"
+	EventPlanReady      EventType = "plan_ready"
+	EventStepStarted    EventType = "step_started"
+	EventDecision       EventType = "decision"
+	EventActionExecuted EventType = "action_executed"
+	EventActionFailed   EventType = "action_failed"
+	EventPageChanged    EventType = "page_changed"
+	EventStepVerified   EventType = "step_verified"
+	EventAssertion      EventType = "assertion"
+	EventCaptchaWait    EventType = "captcha_wait"
+	EventPaused         EventType = "paused"
+	EventResumed        EventType = "resumed"
+	EventAwaitingInput  EventType = "awaiting_input" // a question or confirmation waits for the user
+	EventInputReceived  EventType = "input_received"
+	EventTaskFinished   EventType = "task_finished"
+)
+
+// Event reports task progress to OnEvent so clients can render live updates.
+type Event struct {
+	Type    EventType `json:"type"`
+	Time    time.Time `json:"time"`
+	Step    int       `json:"step,omitempty"` // 1-based plan step, if any
+	Action  string    `json:"action,omitempty"`
+	URL     string    `json:"url,omitempty"`
+	Message string    `json:"message,omitempty"`
+	// Steps are the steps of the plan in a plan_ready event.
+	Steps []string `json:"steps,omitempty"`
+	// Usage is the model usage of the task so far.
+	Usage *TokenUsage `json:"usage,omitempty"`
+}
+
+type eventsKey struct{}
+
+// WithEvents returns a context whose task also reports its events to fn, from
+// task_started to task_finished. Unlike OnEvent, fn hears only of the task
+// started with this context, not of others sharing the agent, such as a
+// scheduled task that holds the browser while this one waits for it.
+func WithEvents(ctx context.Context, fn func(event Event)) context.Context {
+	return context.WithValue(ctx, eventsKey{}, fn)
+}
+
+// eventsFrom returns the function WithEvents attached to ctx, if any.
+func eventsFrom(ctx context.Context) func(event Event) {
+	fn, _ := ctx.Value(eventsKey{}).(func(event Event))
+	return fn
+}
+
+// Emit reports event to the function WithEvents attached to ctx, if any. It
+// lets runners that stand in for the agent, e.g. in tests, report progress
+// the way a task run by the agent does.
+func Emit(ctx context.Context, event Event) {
+	if fn := eventsFrom(ctx); fn != nil {
+		event.Time = time.Now()
+		fn(event)
+	}
+}
+
+// emit delivers an event to OnEvent and to the task's own listener, if set.
+func (a *Agent) emit(event Event) {
+	if a.OnEvent == nil && a.taskEvents == nil {
+		return
+	}
+	event.Time = time.Now()
+	usage := a.result.TokenUsage
+	event.Usage = &usage
+	if a.OnEvent != nil {
+		a.OnEvent(event)
+	}
+	if a.taskEvents != nil {
+		a.taskEvents(event)
+	}
+}
+
+// emitActionResult reports an executed or failed action and any page change it caused.
+func (a *Agent) emitActionResult(decision ai.DecisionResponse, beforeURL string, err error) {
+	if err != nil {
+		a.emit(Event{Type: EventActionFailed, Action: decision.Action, Message: err.Error()})
+		return
+	}
+	a.emit(Event{Type: EventActionExecuted, Action: decision.Action, Message: decision.Reasoning})
+	if afterURL := a.currentURL(); afterURL != beforeURL {
+		a.emit(Event{Type: EventPageChanged, URL: afterURL})
+	}
+}
+
+// currentURL returns the active page URL, or "" without a browser.
+func (a *Agent) currentURL() string {
+	if a.browserMgr == nil {
+		return ""
+	}
+	return a.browserMgr.CurrentURL()
+}
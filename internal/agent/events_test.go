@@ -0,0 +1,51 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+)
+
+func TestExecuteActionEmitsEvents(t *testing.T) {
+	var events []Event
+	a := &Agent{OnEvent: func(e Event) { events = append(events, e) }}
+	ctx := context.Background()
+
+	_ = a.executeAction(ctx, ai.DecisionResponse{Action: "complete", Reasoning: "done"})
+	_ = a.executeAction(ctx, ai.DecisionResponse{Action: "teleport"})
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %+v", events)
+	}
+	if events[0].Type != EventActionExecuted || events[0].Action != "complete" || events[0].Time.IsZero() {
+		t.Fatalf("unexpected executed event: %+v", events[0])
+	}
+	if events[1].Type != EventActionFailed || events[1].Message == "" {
+		t.Fatalf("unexpected failure event: %+v", events[1])
+	}
+}
+
+func TestWithEventsHearsOnlyItsTask(t *testing.T) {
+	provider := &scriptedProvider{steps: []ai.PlanStep{{Description: "Look"}}}
+	ag, url := newScriptedAgent(t, `<html><body>Hi</body></html>`, provider)
+	var all, mine []Event
+	ag.OnEvent = func(e Event) { all = append(all, e) }
+	ctx := WithEvents(context.Background(), func(e Event) { mine = append(mine, e) })
+
+	if _, err := ag.ExecuteTask(context.Background(), "scheduled check", url); err != nil {
+		t.Fatalf("task failed: %v", err)
+	}
+	if _, err := ag.ExecuteTask(ctx, "my task", url); err != nil {
+		t.Fatalf("task failed: %v", err)
+	}
+	if len(mine) == 0 || mine[0].Type != EventTaskStarted || mine[0].Message != "my task" {
+		t.Fatalf("the listener should hear its task from the start, got %+v", mine)
+	}
+	if last := mine[len(mine)-1]; last.Type != EventTaskFinished {
+		t.Errorf("the listener should hear its task finish, got %+v", last)
+	}
+	if len(all) <= len(mine) {
+		t.Errorf("OnEvent should hear both tasks: %d events, the listener %d", len(all), len(mine))
+	}
+}
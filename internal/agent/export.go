@@ -0,0 +1,219 @@
+package agent
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+const scriptHeader = `// Code generated by AIBot from a recorded task. Edit as needed.
+//
+// Task: %s
+package main
+
+import (
+	"log"%s
+
+	"github.com/playwright-community/playwright-go"
+)
+
+func main() {
+	pw, err := playwright.Run()
+	if err != nil {
+		log.Fatalf("could not start playwright: %%v", err)
+	}
+	defer pw.Stop()
+
+	browser, err := pw.Chromium.Launch(playwright.BrowserTypeLaunchOptions{Headless: playwright.Bool(false)})
+	if err != nil {
+		log.Fatalf("could not launch browser: %%v", err)
+	}
+	defer browser.Close()
+
+	page, err := browser.NewPage()
+	if err != nil {
+		log.Fatalf("could not create page: %%v", err)
+	}
+	check := func(step string, err error) {
+		if err != nil {
+			log.Fatalf("%%s: %%v", step, err)
+		}
+	}
+`
+
+// ExportGoScript renders a macro as a standalone playwright-go program that
+// reproduces the recorded actions, e.g. to bootstrap a test.
+func ExportGoScript(macro Macro) ([]byte, error) {
+	var b bytes.Buffer
+	imports := map[string]bool{}
+	for _, action := range macro.Actions {
+		switch ai.NormalizeAction(action.Action) {
+		case ai.ActionUpload:
+			imports["os"], imports["path/filepath"] = true, true
+		case ai.ActionAssert:
+			imports["regexp"] = imports["regexp"] || action.URL != ""
+			imports["strings"] = imports["strings"] || action.Text != ""
+		}
+	}
+	extraImports := ""
+	for _, pkg := range []string{"os", "path/filepath", "regexp", "strings"} {
+		if imports[pkg] {
+			extraImports += fmt.Sprintf("\n\t%q", pkg)
+		}
+	}
+	fmt.Fprintf(&b, scriptHeader, oneLine(macro.Task), extraImports)
+
+	if macro.StartURL != "" && macro.StartURL != "about:blank" {
+		fmt.Fprintf(&b, "\n\t_, err = page.Goto(%q)\n\tcheck(%q, err)\n", macro.StartURL, "open "+macro.StartURL)
+	}
+
+	for idx, action := range macro.Actions {
+		label := fmt.Sprintf("step %d: %s", idx+1, action.Action)
+		if action.Reasoning != "" {
+			fmt.Fprintf(&b, "\n\t// %s\n", oneLine(action.Reasoning))
+		} else {
+			b.WriteString("\n")
+		}
+
+		switch ai.NormalizeAction(action.Action) {
+		case ai.ActionNavigate, ai.ActionFetch:
+			fmt.Fprintf(&b, "\t_, err = page.Goto(%q)\n\tcheck(%q, err)\n", action.URL, label)
+		case ai.ActionSearch:
+			// Macros do not record the engine, so the script uses the default one.
+			engine := browser.SearchEngines[browser.DefaultSearchEngine]
+			fmt.Fprintf(&b, "\t_, err = page.Goto(%q)\n\tcheck(%q, err)\n", fmt.Sprintf(engine.URL, url.QueryEscape(action.Text)), label)
+		case ai.ActionBack:
+			fmt.Fprintf(&b, "\t_, err = page.GoBack()\n\tcheck(%q, err)\n", label)
+		case ai.ActionForward:
+			fmt.Fprintf(&b, "\t_, err = page.GoForward()\n\tcheck(%q, err)\n", label)
+		case ai.ActionReload:
+			fmt.Fprintf(&b, "\t_, err = page.Reload()\n\tcheck(%q, err)\n", label)
+		case ai.ActionClick:
+			fmt.Fprintf(&b, "\tcheck(%q, %s.First().Click())\n", label, locatorExpr(action.Selector))
+		case ai.ActionFill:
+			fmt.Fprintf(&b, "\tcheck(%q, %s.First().Fill(%q))\n", label, locatorExpr(action.Selector), action.Text)
+		case ai.ActionFocus:
+			fmt.Fprintf(&b, "\tcheck(%q, %s.First().Focus())\n", label, locatorExpr(action.Selector))
+		case ai.ActionHover:
+			fmt.Fprintf(&b, "\tcheck(%q, %s.First().Hover())\n", label, locatorExpr(action.Selector))
+		case ai.ActionTypeText:
+			fmt.Fprintf(&b, "\tcheck(%q, %s.First().PressSequentially(%q))\n", label, locatorExpr(action.Selector), action.Text)
+		case ai.ActionPress:
+			fmt.Fprintf(&b, "\tcheck(%q, page.Keyboard().Press(%q))\n", label, action.Text)
+		case ai.ActionSelect:
+			fmt.Fprintf(&b, "\t_, err = %s.First().SelectOption(playwright.SelectOptionValues{ValuesOrLabels: &[]string{%q}})\n\tcheck(%q, err)\n", locatorExpr(action.Selector), action.Text, label)
+		case ai.ActionCheck:
+			fmt.Fprintf(&b, "\tcheck(%q, %s.First().Check())\n", label, locatorExpr(action.Selector))
+		case ai.ActionUncheck:
+			fmt.Fprintf(&b, "\tcheck(%q, %s.First().Uncheck())\n", label, locatorExpr(action.Selector))
+		case ai.ActionUpload:
+			fmt.Fprintf(&b, "\t{\n\t\tvar files []playwright.InputFile\n\t\tfor _, path := range %#v {\n\t\t\tdata, err := os.ReadFile(path)\n\t\t\tcheck(\"read \"+path, err)\n\t\t\tfiles = append(files, playwright.InputFile{Name: filepath.Base(path), Buffer: data})\n\t\t}\n\t\tcheck(%q, %s.First().SetInputFiles(files))\n\t}\n", uploadPaths(action.Text), label, locatorExpr(action.Selector))
+		case ai.ActionScroll:
+			if action.Selector != "" && action.Text == "" {
+				fmt.Fprintf(&b, "\tcheck(%q, %s.First().ScrollIntoViewIfNeeded())\n", label, locatorExpr(action.Selector))
+			} else {
+				fmt.Fprintf(&b, "\tcheck(%q, page.Mouse().Wheel(0, 2000)) // scroll %s\n", label, oneLine(action.Text))
+			}
+		case ai.ActionWait:
+			if state, ok := loadStateExpr(action.Text); ok {
+				fmt.Fprintf(&b, "\tcheck(%q, page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{State: %s}))\n", label, state)
+			} else {
+				b.WriteString("\tpage.WaitForTimeout(2000)\n")
+			}
+		case ai.ActionWaitFor:
+			fmt.Fprintf(&b, "\tcheck(%q, %s.First().WaitFor())\n", label, locatorExpr(action.Selector))
+		case ai.ActionScrape:
+			fmt.Fprintf(&b, "\t{\n\t\titems, err := %s.AllInnerTexts()\n\t\tcheck(%q, err)\n\t\tlog.Printf(\"scraped: %%q\", items)\n\t}\n", locatorExpr(action.Selector), label)
+		case ai.ActionCrawl:
+			fmt.Fprintf(&b, "\t// %s: only the first page; follow the next-page link to collect more\n", label)
+			fmt.Fprintf(&b, "\t{\n\t\titems, err := %s.AllInnerTexts()\n\t\tcheck(%q, err)\n\t\tlog.Printf(\"crawled: %%q\", items)\n\t}\n", locatorExpr(action.Selector), label)
+		case ai.ActionExtract:
+			scope := action.Selector
+			if scope == "" {
+				scope = "table, ul, ol"
+			}
+			fmt.Fprintf(&b, "\t{\n\t\titems, err := %s.AllInnerTexts()\n\t\tcheck(%q, err)\n\t\tlog.Printf(\"extracted: %%q\", items)\n\t}\n", locatorExpr(scope), label)
+		case ai.ActionArticle:
+			fmt.Fprintf(&b, "\t{\n\t\ttext, err := page.Locator(\"article, main, body\").First().InnerText()\n\t\tcheck(%q, err)\n\t\tlog.Printf(\"article: %%s\", text)\n\t}\n", label)
+		case ai.ActionEvaluate:
+			fmt.Fprintf(&b, "\t_, err = page.Evaluate(%q)\n\tcheck(%q, err)\n", action.Text, label)
+		case ai.ActionAssert:
+			b.WriteString("\t{\n\t\tpassed := true\n")
+			if action.URL != "" {
+				fmt.Fprintf(&b, "\t\tpassed = passed && regexp.MustCompile(%q).MatchString(page.URL())\n", action.URL)
+			}
+			if action.Text != "" {
+				fmt.Fprintf(&b, "\t\tbody, err := page.Locator(\"body\").InnerText()\n\t\tcheck(%q, err)\n\t\tpassed = passed && strings.Contains(strings.ToLower(body), strings.ToLower(%q))\n", label, action.Text)
+			}
+			if action.Selector != "" {
+				fmt.Fprintf(&b, "\t\tcount, err := %s.Count()\n\t\tcheck(%q, err)\n\t\tpassed = passed && count > 0\n", locatorExpr(action.Selector), label)
+			}
+			fmt.Fprintf(&b, "\t\tif passed == %t {\n\t\t\tlog.Fatalf(\"%%s: check failed\", %q)\n\t\t}\n\t}\n", action.Negate, label)
+		case ai.ActionScreenshot:
+			fmt.Fprintf(&b, "\t_, err = page.Screenshot(playwright.PageScreenshotOptions{Path: playwright.String(%q), FullPage: playwright.Bool(true)})\n\tcheck(%q, err)\n", fmt.Sprintf("step-%d.png", idx+1), label)
+		default:
+			fmt.Fprintf(&b, "\t// TODO: %s (%s %s) has no direct Playwright equivalent\n", label, oneLine(action.Selector), oneLine(action.Text))
+		}
+		if needsLoadWait(action.Action) {
+			b.WriteString("\tcheck(\"wait for load\", page.WaitForLoadState())\n")
+		}
+	}
+	b.WriteString("}\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated script is not valid Go: %w", err)
+	}
+	return src, nil
+}
+
+// locatorExpr returns the Go expression locating selector, entering the
+// iframe named by a frame-prefixed selector and translating AIBot-only locators.
+func locatorExpr(selector string) string {
+	frameID, inner, ok := browser.SplitFrameSelector(selector)
+	if !ok {
+		return fmt.Sprintf("page.Locator(%q)", browser.TranslateLocator(selector))
+	}
+	inner = browser.TranslateLocator(inner)
+	if idx, err := strconv.Atoi(frameID); err == nil {
+		return fmt.Sprintf("page.Frames()[%d].Locator(%q)", idx, inner)
+	}
+	return fmt.Sprintf("page.FrameLocator(%q).Locator(%q)", fmt.Sprintf(`iframe[name="%s"]`, frameID), inner)
+}
+
+// needsLoadWait reports whether an action may trigger a navigation.
+func needsLoadWait(action string) bool {
+	switch ai.NormalizeAction(action) {
+	case ai.ActionNavigate, ai.ActionClick, ai.ActionPress:
+		return true
+	}
+	return false
+}
+
+// loadStateExpr returns the Playwright load state constant for a wait action's
+// text, if it names one.
+func loadStateExpr(text string) (string, bool) {
+	state, ok := browser.ParseWaitStrategy(text)
+	if !ok {
+		return "", false
+	}
+	switch state {
+	case browser.WaitNetworkIdle, browser.WaitSPA:
+		return "playwright.LoadStateNetworkidle", true
+	case browser.WaitDOMContentLoaded:
+		return "playwright.LoadStateDomcontentloaded", true
+	default:
+		return "playwright.LoadStateLoad", true
+	}
+}
+
+// oneLine flattens text for use in a line comment.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExportGoScript(t *testing.T) {
+	macro := Macro{
+		Version:  MacroVersion,
+		Task:     "search for \"kremlin\"\nand open it",
+		StartURL: "https://yandex.ru/maps",
+		Actions: []MacroAction{
+			{Action: "fill", Selector: `input[name="text"]`, Text: "kremlin", Reasoning: "Type the query"},
+			{Action: "press", Text: "Enter"},
+			{Action: "wait", Text: "networkidle"},
+			{Action: "wait_for", Selector: ".result"},
+			{Action: "scrape", Selector: ".result"},
+			{Action: "scrape", Selector: ".address"},
+			{Action: "switch_tab", Text: "2"},
+			{Action: "click", Selector: "frame=login >> #signin"},
+			{Action: "go_back"},
+			{Action: "read_article"},
+			{Action: "search", Text: "kremlin opening hours"},
+		},
+	}
+
+	src, err := ExportGoScript(macro)
+	if err != nil {
+		t.Fatalf("ExportGoScript failed: %v", err)
+	}
+	script := string(src)
+	for _, want := range []string{
+		`page.Goto("https://yandex.ru/maps")`,
+		`page.Locator("input[name=\"text\"]").First().Fill("kremlin")`,
+		`page.Keyboard().Press("Enter")`,
+		`page.Locator(".address").AllInnerTexts()`,
+		"// Type the query",
+		`page.Locator(".result").First().WaitFor()`,
+		`page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{State: playwright.LoadStateNetworkidle})`,
+		"// TODO: step 7: switch_tab",
+		`page.FrameLocator("iframe[name=\"login\"]").Locator("#signin").First().Click()`,
+		`_, err = page.GoBack()`,
+		`page.Locator("article, main, body").First().InnerText()`,
+		`page.Goto("https://html.duckduckgo.com/html/?q=kremlin+opening+hours")`,
+		`// Task: search for "kremlin" and open it`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("generated script missing %q:\n%s", want, script)
+		}
+	}
+}
+
+func TestExportGoScriptAssert(t *testing.T) {
+	macro := Macro{Version: MacroVersion, Task: "check free shipping", Actions: []MacroAction{
+		{Action: "assert", Text: "Free shipping", URL: "/checkout"},
+		{Action: "assert", Selector: ".error", Negate: true},
+	}}
+	src, err := ExportGoScript(macro)
+	if err != nil {
+		t.Fatalf("ExportGoScript failed: %v", err)
+	}
+	script := string(src)
+	for _, want := range []string{
+		"\t\"regexp\"\n\t\"strings\"\n",
+		`regexp.MustCompile("/checkout").MatchString(page.URL())`,
+		`strings.Contains(strings.ToLower(body), strings.ToLower("Free shipping"))`,
+		`count, err := page.Locator(".error").Count()`,
+		"if passed == true {",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("generated script missing %q:\n%s", want, script)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/fetch"
+)
+
+// Limits on a fetched page fed back to the model.
+const (
+	maxFetchText  = 6000 // runes
+	maxFetchLinks = 40
+)
+
+// doFetch reads a page over plain HTTP when a Fetcher is configured, leaving
+// the browser where it is. Pages that need a browser are opened in it instead.
+func (a *Agent) doFetch(ctx context.Context, decision ai.DecisionResponse) error {
+	target := decision.URL
+	if target == "" {
+		return fmt.Errorf("fetch requires a url")
+	}
+	if a.Fetcher == nil {
+		return a.openInBrowser(ctx, target, "HTTP fetch is disabled")
+	}
+
+	page, err := a.Fetcher.Fetch(ctx, target)
+	if errors.Is(err, fetch.ErrNeedsBrowser) {
+		if a.verbose {
+			log.Printf("Fetch: %v, using the browser\n", err)
+		}
+		return a.openInBrowser(ctx, target, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+	a.contextMgr.AddMessage("system", formatFetchedPage(page))
+	if a.verbose {
+		log.Printf("Fetched %s over HTTP (%d characters, %d links)\n", page.URL, len(page.Text), len(page.Links))
+	}
+	return nil
+}
+
+// openInBrowser is the fallback of doFetch.
+func (a *Agent) openInBrowser(ctx context.Context, url, reason string) error {
+	if err := a.browserMgr.Navigate(ctx, url); err != nil {
+		return err
+	}
+	_ = a.browserMgr.WaitForNavigation(ctx)
+	a.contextMgr.AddMessage("system", fmt.Sprintf("Opened %s in the browser instead of fetching it (%s); read it from the page description.", url, reason))
+	return nil
+}
+
+// formatFetchedPage describes a fetched page to the model.
+func formatFetchedPage(page *fetch.Page) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Fetched %s over HTTP (the browser did not move): %s\n\n", page.URL, page.Title)
+	text := page.Text
+	if runes := []rune(text); len(runes) > maxFetchText {
+		text = string(runes[:maxFetchText]) + "\n[truncated]"
+	}
+	b.WriteString(text)
+	if len(page.Links) > 0 {
+		b.WriteString("\n\nLinks:\n")
+		for i, link := range page.Links {
+			if i == maxFetchLinks {
+				fmt.Fprintf(&b, "... %d more\n", len(page.Links)-maxFetchLinks)
+				break
+			}
+			fmt.Fprintf(&b, "- %s: %s\n", link.Text, link.URL)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
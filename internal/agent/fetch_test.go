@@ -0,0 +1,28 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/fetch"
+)
+
+func TestFormatFetchedPage(t *testing.T) {
+	page := &fetch.Page{
+		URL:   "https://example.com/docs",
+		Title: "Docs",
+		Text:  strings.Repeat("a", maxFetchText+10),
+	}
+	for i := 0; i < maxFetchLinks+2; i++ {
+		page.Links = append(page.Links, fetch.Link{Text: "Next", URL: "https://example.com/next"})
+	}
+	got := formatFetchedPage(page)
+	for _, want := range []string{"Fetched https://example.com/docs over HTTP", "Docs", "[truncated]", "- Next: https://example.com/next", "... 2 more"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatFetchedPage missing %q", want)
+		}
+	}
+	if strings.Contains(got, strings.Repeat("a", maxFetchText+1)) {
+		t.Error("formatFetchedPage did not cap the text")
+	}
+}
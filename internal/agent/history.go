@@ -0,0 +1,38 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+// decisionReserve is the room left for the model's answer when the history
+// is trimmed to fit the context budget.
+const decisionReserve = 400
+
+// history returns the earlier turns of the task as chat messages: a short
+// note of each page the model saw, the decision it made there, and the
+// outcomes recorded since (failures, scraped data, search results, ...).
+// Full page descriptions are only sent for the current turn.
+func (a *Agent) history() []ai.Message {
+	stored := a.contextMgr.GetMessages()
+	messages := make([]ai.Message, 0, len(stored))
+	for _, msg := range stored {
+		messages = append(messages, ai.Message{Role: msg.Role, Content: msg.Content})
+	}
+	return messages
+}
+
+// rememberDecision adds a turn to the history: where the decision was made
+// and the decision itself, so later decisions know what was already tried.
+func (a *Agent) rememberDecision(pc browser.PageContent, step string, decision ai.DecisionResponse) {
+	turn := fmt.Sprintf("Page: %s (%s)", pc.Title, pc.URL)
+	if step != "" {
+		turn = "Plan step: " + step + "\n" + turn
+	}
+	a.contextMgr.AddMessage(ai.RoleUser, turn)
+	raw, _ := json.Marshal(decision)
+	a.contextMgr.AddMessage(ai.RoleAssistant, string(raw))
+}
@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+	ctxmgr "github.com/VolodyaPopov923/AIBot/internal/context"
+)
+
+// recordingBackend answers every request with the same reply and keeps the
+// requests.
+type recordingBackend struct {
+	reply    string
+	requests []ai.ChatRequest
+}
+
+func (b *recordingBackend) Chat(ctx context.Context, req ai.ChatRequest) (string, error) {
+	b.requests = append(b.requests, req)
+	return b.reply, nil
+}
+
+func TestDecideSendsHistory(t *testing.T) {
+	backend := &recordingBackend{reply: `{"action": "click", "selector": "#more", "reasoning": "open details"}`}
+	a := &Agent{aiClient: ai.NewClientWithBackend(backend), contextMgr: ctxmgr.NewContextManager(8000, 20)}
+	ctx := context.Background()
+	page := browser.PageContent{Title: "Example", URL: "https://example.com"}
+
+	decision, _, err := a.decide(ctx, "system", "first page")
+	if err != nil {
+		t.Fatalf("decide failed: %v", err)
+	}
+	a.rememberDecision(page, "", decision)
+	a.recordActionFailure(ErrDestructiveActionHalted, false, "Iteration 1")
+
+	if _, _, err := a.decide(ctx, "system", "second page"); err != nil {
+		t.Fatalf("decide failed: %v", err)
+	}
+	messages := backend.requests[1].Messages
+	if len(messages) != 5 {
+		t.Fatalf("expected system, 3 history messages and user, got %+v", messages)
+	}
+	if messages[0].Content != "system" || messages[4].Content != "second page" {
+		t.Fatalf("history should go between the system prompt and the current page: %+v", messages)
+	}
+	if !strings.Contains(messages[1].Content, "https://example.com") || strings.Contains(messages[1].Content, "first page") {
+		t.Errorf("earlier page should be summarized, got %q", messages[1].Content)
+	}
+	if messages[2].Role != ai.RoleAssistant || !strings.Contains(messages[2].Content, `"selector":"#more"`) {
+		t.Errorf("earlier decision missing: %+v", messages[2])
+	}
+	if !strings.Contains(messages[3].Content, "Iteration 1 failed") {
+		t.Errorf("failure note missing: %+v", messages[3])
+	}
+}
+
+func TestDecideTrimsHistoryToBudget(t *testing.T) {
+	backend := &recordingBackend{reply: `{"action": "wait", "reasoning": "loading"}`}
+	a := &Agent{aiClient: ai.NewClientWithBackend(backend), contextMgr: ctxmgr.NewContextManager(decisionReserve+50, 20)}
+	a.contextMgr.AddMessage("system", strings.Repeat("scraped item ", 200))
+
+	if _, _, err := a.decide(context.Background(), "system", "page"); err != nil {
+		t.Fatalf("decide failed: %v", err)
+	}
+	if n := len(backend.requests[0].Messages); n != 2 {
+		t.Fatalf("oversized history should be dropped, got %d messages", n)
+	}
+}
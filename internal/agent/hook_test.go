@@ -0,0 +1,89 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+)
+
+func TestOnDecisionHookRewritesURL(t *testing.T) {
+	a := &Agent{}
+	decision := ai.DecisionResponse{Action: "navigate", URL: "https://example.com/page"}
+
+	if err := a.applyDecisionHook(&decision); err != nil {
+		t.Fatalf("nil hook should be a no-op, got %v", err)
+	}
+
+	a.OnDecision = func(d *ai.DecisionResponse) error {
+		if ai.NormalizeAction(d.Action) == ai.ActionNavigate {
+			d.URL = "https://proxy.local/?u=" + d.URL
+		}
+		return nil
+	}
+	if err := a.applyDecisionHook(&decision); err != nil {
+		t.Fatalf("hook failed: %v", err)
+	}
+	if decision.URL != "https://proxy.local/?u=https://example.com/page" {
+		t.Fatalf("hook rewrite not applied before execution, URL is %s", decision.URL)
+	}
+}
+
+func TestOnDecisionHookRewritesBeforeNavigation(t *testing.T) {
+	var mu sync.Mutex
+	var requested []string
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.Path)
+		mu.Unlock()
+		_, _ = w.Write([]byte(`<html><body>ok</body></html>`))
+	}))
+	defer target.Close()
+
+	provider := &scriptedProvider{
+		steps: []ai.PlanStep{{Description: "Open the report"}},
+		decisions: map[string]ai.DecisionResponse{
+			"Open the report": {Action: "navigate", URL: target.URL + "/report"},
+		},
+	}
+	ag, url := newScriptedAgent(t, `<html><body>start</body></html>`, provider)
+	ag.OnDecision = func(d *ai.DecisionResponse) error {
+		d.URL = strings.Replace(d.URL, "/report", "/proxy/report", 1)
+		return nil
+	}
+
+	if _, err := ag.ExecuteTask(context.Background(), "open the report", url); err != nil {
+		t.Fatalf("task failed: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, path := range requested {
+		if path == "/report" {
+			t.Fatalf("the browser loaded the URL before the hook rewrote it: %v", requested)
+		}
+	}
+	if len(requested) == 0 || requested[0] != "/proxy/report" {
+		t.Fatalf("expected the first request to go to the rewritten URL, got %v", requested)
+	}
+}
+
+func TestOnDecisionHookRejects(t *testing.T) {
+	blocked := errors.New("selector is blocked")
+	a := &Agent{OnDecision: func(d *ai.DecisionResponse) error {
+		if strings.Contains(d.Selector, "delete") {
+			return blocked
+		}
+		return nil
+	}}
+
+	decision := ai.DecisionResponse{Action: "click", Selector: "#delete-account"}
+	err := a.applyDecisionHook(&decision)
+	if !errors.Is(err, blocked) {
+		t.Fatalf("expected hook rejection, got %v", err)
+	}
+}
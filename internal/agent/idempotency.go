@@ -0,0 +1,43 @@
+package agent
+
+import (
+	"strings"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+)
+
+// destructiveSignature identifies a destructive action so retries within a task can be detected.
+func destructiveSignature(decision ai.DecisionResponse) string {
+	return strings.Join([]string{
+		string(ai.NormalizeAction(decision.Action)),
+		decision.Selector,
+		decision.URL,
+		decision.Text,
+	}, "|")
+}
+
+// isDestructiveDecision reports whether a decision may have irreversible effects.
+func (a *Agent) isDestructiveDecision(decision ai.DecisionResponse) bool {
+	if ai.NormalizeAction(decision.Action) == ai.ActionClarify {
+		// Asking the user changes nothing, whatever the question mentions.
+		return false
+	}
+	if decision.NeedsConfirm {
+		return true
+	}
+	return a.securityMgr != nil && a.securityMgr.IsDestructive(decision.Reasoning+" "+decision.Text)
+}
+
+// alreadyExecuted reports whether an identical destructive action already ran in this task.
+func (a *Agent) alreadyExecuted(decision ai.DecisionResponse) bool {
+	_, done := a.executedDestructive[destructiveSignature(decision)]
+	return done
+}
+
+// markExecuted records a destructive action that completed in this task.
+func (a *Agent) markExecuted(decision ai.DecisionResponse) {
+	if a.executedDestructive == nil {
+		a.executedDestructive = make(map[string]struct{})
+	}
+	a.executedDestructive[destructiveSignature(decision)] = struct{}{}
+}
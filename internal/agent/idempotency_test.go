@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/security"
+)
+
+func TestRepeatedDestructiveActionRequiresReconfirmation(t *testing.T) {
+	input := strings.NewReader("no\n")
+	a := &Agent{securityMgr: security.NewValidatorWithReader(input)}
+	ctx := context.Background()
+
+	// An empty selector makes the click a no-op, so no browser is needed.
+	pay := ai.DecisionResponse{Action: "click", Reasoning: "Complete the payment for the order"}
+
+	if err := a.executeAction(ctx, pay); err != nil {
+		t.Fatalf("first destructive action should run without a prompt: %v", err)
+	}
+	if input.Len() == 0 {
+		t.Fatalf("first execution should not have consumed a confirmation")
+	}
+
+	err := a.executeAction(ctx, pay)
+	if err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Fatalf("expected repeated payment to require re-confirmation and be denied, got %v", err)
+	}
+	if input.Len() != 0 {
+		t.Fatalf("expected the repeat to prompt for confirmation")
+	}
+
+	other := ai.DecisionResponse{Action: "click", Selector: "", Text: "another", Reasoning: "Open the cart"}
+	if err := a.executeAction(ctx, other); err != nil {
+		t.Fatalf("non-destructive action should not be guarded: %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+)
+
+// MacroVersion is the version of the macro file format.
+const MacroVersion = 1
+
+// Macro is the concrete action sequence of a completed task. Replaying it
+// repeats the task without any model calls.
+type Macro struct {
+	Version  int           `json:"version"`
+	Task     string        `json:"task"`
+	StartURL string        `json:"start_url,omitempty"`
+	Actions  []MacroAction `json:"actions"`
+}
+
+// MacroAction is a single recorded action.
+type MacroAction struct {
+	Action       string `json:"action"`
+	Selector     string `json:"selector,omitempty"`
+	URL          string `json:"url,omitempty"`
+	Text         string `json:"text,omitempty"`
+	Skill        string `json:"skill,omitempty"`
+	SaveAs       string `json:"save_as,omitempty"`
+	Negate       bool   `json:"negate,omitempty"`
+	Reasoning    string `json:"reasoning,omitempty"`
+	NeedsConfirm bool   `json:"needs_confirm,omitempty"`
+	Optional     bool   `json:"optional,omitempty"`
+}
+
+// NewMacro builds a macro from the successful steps of a task result. Failed
+// attempts and steps that do nothing on replay are left out.
+func NewMacro(result *TaskResult) Macro {
+	macro := Macro{Version: MacroVersion, Task: result.Task, StartURL: result.StartURL}
+	for _, step := range result.Steps {
+		if step.Error != "" {
+			continue
+		}
+		switch ai.NormalizeAction(step.Action) {
+		case ai.ActionComplete, ai.ActionError, ai.ActionPause, ai.ActionClarify:
+			continue
+		}
+		macro.Actions = append(macro.Actions, MacroAction{
+			Action:       step.Action,
+			Selector:     step.Selector,
+			URL:          step.URL,
+			Text:         step.Text,
+			Skill:        step.Skill,
+			SaveAs:       step.SaveAs,
+			Negate:       step.Negate,
+			Reasoning:    step.Reasoning,
+			NeedsConfirm: step.NeedsConfirm,
+			Optional:     step.Optional,
+		})
+	}
+	return macro
+}
+
+// SaveMacro writes a macro as indented JSON.
+func SaveMacro(path string, macro Macro) error {
+	data, err := json.MarshalIndent(macro, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode macro: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write macro: %w", err)
+	}
+	return nil
+}
+
+// LoadMacro reads a macro saved by SaveMacro.
+func LoadMacro(path string) (Macro, error) {
+	var macro Macro
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return macro, fmt.Errorf("failed to read macro: %w", err)
+	}
+	if err := json.Unmarshal(data, &macro); err != nil {
+		return macro, fmt.Errorf("failed to parse macro: %w", err)
+	}
+	if macro.Version > MacroVersion {
+		return macro, fmt.Errorf("macro version %d is newer than supported version %d", macro.Version, MacroVersion)
+	}
+	return macro, nil
+}
+
+// ReplayMacro re-runs a recorded macro without calling the model. Confirmation
+// and safety checks still apply to every action.
+func (a *Agent) ReplayMacro(ctx context.Context, macro Macro) (*TaskResult, error) {
+	a.running.Lock()
+	defer a.running.Unlock()
+	a.result = TaskResult{Task: macro.Task, StartURL: macro.StartURL, StartedAt: time.Now()}
+	a.executedDestructive = nil
+	err := a.replay(ctx, macro)
+	a.endControl()
+	if err == nil {
+		err = a.assertionError()
+	}
+	return a.finishResult(err), err
+}
+
+func (a *Agent) replay(ctx context.Context, macro Macro) error {
+	if macro.StartURL != "" && macro.StartURL != "about:blank" {
+		if err := a.browserMgr.Navigate(ctx, macro.StartURL); err != nil {
+			return fmt.Errorf("failed to navigate to start URL: %w", err)
+		}
+		_ = a.browserMgr.WaitForNavigation(ctx)
+	}
+
+	for idx, action := range macro.Actions {
+		if err := a.checkpoint(ctx); err != nil {
+			return err
+		}
+		decision := ai.DecisionResponse{
+			Action:       action.Action,
+			Selector:     action.Selector,
+			URL:          action.URL,
+			Text:         action.Text,
+			Skill:        action.Skill,
+			SaveAs:       action.SaveAs,
+			Negate:       action.Negate,
+			Reasoning:    action.Reasoning,
+			NeedsConfirm: action.NeedsConfirm,
+			Optional:     action.Optional,
+		}
+		if err := a.executeAction(ctx, decision); err != nil {
+			if action.Optional {
+				a.recordActionFailure(err, true, fmt.Sprintf("Macro step %d", idx+1))
+				continue
+			}
+			return fmt.Errorf("macro step %d (%s) failed: %w", idx+1, action.Action, err)
+		}
+		_ = a.browserMgr.WaitForNavigation(ctx)
+	}
+	return nil
+}
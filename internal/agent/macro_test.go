@@ -0,0 +1,84 @@
+package agent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+func TestNewMacroKeepsSuccessfulActions(t *testing.T) {
+	result := &TaskResult{
+		Task:     "search for kremlin",
+		StartURL: "https://yandex.ru/maps",
+		Steps: []StepRecord{
+			{Action: "click", Selector: "#wrong", Error: "element not found"},
+			{Action: "fill", Selector: "#search", Text: "kremlin"},
+			{Action: "press", Text: "Enter"},
+			{Action: "complete", Text: "Red Square"},
+		},
+	}
+
+	macro := NewMacro(result)
+	if macro.Version != MacroVersion || macro.StartURL != result.StartURL {
+		t.Fatalf("unexpected macro header: %+v", macro)
+	}
+	if len(macro.Actions) != 2 || macro.Actions[0].Selector != "#search" || macro.Actions[1].Text != "Enter" {
+		t.Fatalf("unexpected macro actions: %+v", macro.Actions)
+	}
+
+	path := filepath.Join(t.TempDir(), "macro.json")
+	if err := SaveMacro(path, macro); err != nil {
+		t.Fatalf("SaveMacro failed: %v", err)
+	}
+	loaded, err := LoadMacro(path)
+	if err != nil {
+		t.Fatalf("LoadMacro failed: %v", err)
+	}
+	if len(loaded.Actions) != 2 || loaded.Task != macro.Task {
+		t.Fatalf("macro did not round-trip: %+v", loaded)
+	}
+}
+
+func TestReplayMacroWithoutModel(t *testing.T) {
+	t.Setenv("BROWSER_HEADLESS", "true")
+	t.Setenv("BROWSER_USER_DATA_DIR", t.TempDir())
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body>
+			<input id="q"><button id="go" onclick="document.title = 'searched ' + document.getElementById('q').value">Go</button>
+		</body></html>`))
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	mgr, err := browser.NewManager(ctx)
+	if err != nil {
+		t.Skipf("Playwright unavailable: %v", err)
+	}
+	defer mgr.Close(ctx)
+
+	// No AI client: replay must not need one.
+	ag := NewAgent(mgr, nil, false)
+	result, err := ag.ReplayMacro(ctx, Macro{
+		Version:  MacroVersion,
+		StartURL: ts.URL,
+		Actions: []MacroAction{
+			{Action: "fill", Selector: "#q", Text: "kremlin"},
+			{Action: "click", Selector: "#go"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	content, err := mgr.GetPageContent(ctx)
+	if err != nil || content.Title != "searched kremlin" {
+		t.Fatalf("replay did not perform the actions: title=%q err=%v", content.Title, err)
+	}
+	if len(result.Steps) != 2 || !result.Success {
+		t.Fatalf("unexpected replay result: %+v", result)
+	}
+}
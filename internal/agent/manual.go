@@ -0,0 +1,107 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/VolodyaPopov923/AIBot/internal/lineedit"
+	"github.com/VolodyaPopov923/AIBot/internal/security"
+)
+
+// ManualStepPolicy decides what the agent does when a step needs a human.
+type ManualStepPolicy string
+
+const (
+	// ManualStepWait blocks until the user presses Enter.
+	ManualStepWait ManualStepPolicy = "wait"
+	// ManualStepSkip logs the step and continues without waiting (non-interactive runs).
+	ManualStepSkip ManualStepPolicy = "skip"
+	// ManualStepFail aborts the task (non-interactive runs that cannot proceed unattended).
+	ManualStepFail ManualStepPolicy = "fail"
+)
+
+// ErrManualStepRequired is returned when a manual step is hit under ManualStepFail.
+var ErrManualStepRequired = errors.New("manual intervention required")
+
+// SetInput replaces the reader used to wait for the user (stdin by default).
+// Reads from a file, such as a pipe, give up when the task ends; other
+// readers are read as they are.
+func (a *Agent) SetInput(r io.Reader) {
+	a.inputMu.Lock()
+	defer a.inputMu.Unlock()
+	a.input = bufio.NewReader(r)
+	a.inputFile, _ = r.(*os.File)
+}
+
+// ShareStdin makes the agent read the user's answers, confirmations included,
+// through in, a reader over stdin that the caller reads from as well, such as
+// the interactive prompt's. Separate readers would each buffer input meant
+// for the other.
+func (a *Agent) ShareStdin(in *bufio.Reader) {
+	a.inputMu.Lock()
+	defer a.inputMu.Unlock()
+	a.input, a.inputFile = in, os.Stdin
+	a.securityMgr = security.NewValidatorWithReader(in)
+}
+
+// waitForManualStep pauses the task so the user can perform a step by hand.
+func (a *Agent) waitForManualStep(ctx context.Context, instructions string) error {
+	switch a.ManualSteps {
+	case ManualStepSkip:
+		log.Printf("Manual step skipped (non-interactive): %s\n", instructions)
+		return nil
+	case ManualStepFail:
+		return fmt.Errorf("%w: %s", ErrManualStepRequired, instructions)
+	}
+
+	if _, err := a.ask(ctx, "✋ Manual step required: "+instructions, "Press Enter when done... "); err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		return fmt.Errorf("failed to read manual step confirmation: %w", err)
+	}
+	return nil
+}
+
+// ask puts a question to the user through Ask, or else on the terminal
+// followed by prompt, and returns the answer.
+func (a *Agent) ask(ctx context.Context, question, prompt string) (string, error) {
+	a.emit(Event{Type: EventAwaitingInput, Message: question})
+	defer a.emit(Event{Type: EventInputReceived})
+	if a.Ask != nil {
+		return a.Ask(ctx, question)
+	}
+	fmt.Printf("\n%s\n", question)
+	fmt.Print(prompt)
+	return a.readLine(ctx)
+}
+
+// readLine reads a line of user input, giving up when ctx is done. A closed
+// input counts as an empty line.
+//
+// The read starts only once the user typed something, so a question given up
+// on leaves no read behind that would take the next line typed, e.g. at the
+// interactive prompt.
+func (a *Agent) readLine(ctx context.Context) (string, error) {
+	a.inputMu.Lock()
+	input, file := a.input, a.inputFile
+	a.inputMu.Unlock()
+
+	var text string
+	var err error
+	if file != nil {
+		text, err = lineedit.ReadLineContext(ctx, input, file)
+	} else if err = ctx.Err(); err == nil {
+		text, err = input.ReadString('\n')
+	}
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimSpace(text), nil
+}
@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+)
+
+func TestPauseBlocksUntilContinue(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+
+	a := &Agent{ManualSteps: ManualStepWait}
+	a.SetInput(pr)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- a.executeAction(context.Background(), ai.DecisionResponse{Action: "pause", Reasoning: "Enter the 2FA code"})
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("pause returned before the user continued: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if _, err := pw.Write([]byte("\n")); err != nil {
+		t.Fatalf("failed to signal continue: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("pause failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("pause did not return after the user continued")
+	}
+}
+
+func TestManualStepPolicies(t *testing.T) {
+	a := &Agent{ManualSteps: ManualStepSkip}
+	if err := a.waitForManualStep(context.Background(), "approve login"); err != nil {
+		t.Fatalf("skip policy should continue, got %v", err)
+	}
+
+	a.ManualSteps = ManualStepFail
+	err := a.waitForManualStep(context.Background(), "approve login")
+	if !errors.Is(err, ErrManualStepRequired) {
+		t.Fatalf("fail policy should return ErrManualStepRequired, got %v", err)
+	}
+	if !stopsTask(err) {
+		t.Fatalf("a manual step nobody can do should end the task")
+	}
+}
+
+func TestCancelledReadLeavesLine(t *testing.T) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	defer pr.Close()
+	defer pw.Close()
+
+	a := &Agent{}
+	a.SetInput(pr)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := a.readLine(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the read to give up with its task, got %v", err)
+	}
+
+	// Whoever reads the input next, e.g. the interactive prompt, gets the
+	// line typed after the question was given up on.
+	if _, err := pw.Write([]byte("yes\n")); err != nil {
+		t.Fatalf("failed to type: %v", err)
+	}
+	got, err := a.input.ReadString('\n')
+	if err != nil || got != "yes\n" {
+		t.Fatalf("the line typed after the question should be left for the next reader, got %q (%v)", got, err)
+	}
+}
@@ -0,0 +1,21 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+)
+
+// resolveElementNumber fills in the selector of a decision that names its
+// target by number in the last element list shown to the model. An explicit
+// selector wins over a number.
+func (a *Agent) resolveElementNumber(decision *ai.DecisionResponse) error {
+	if decision.Element <= 0 || decision.Selector != "" {
+		return nil
+	}
+	if decision.Element > len(a.lastElements) {
+		return fmt.Errorf("element %d is not on the page (%d elements listed)", decision.Element, len(a.lastElements))
+	}
+	decision.Selector = a.lastElements[decision.Element-1].Selector
+	return nil
+}
@@ -0,0 +1,33 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+func TestResolveElementNumber(t *testing.T) {
+	a := &Agent{lastElements: []browser.ElementInfo{
+		{Type: "input", Selector: `placeholder="Search"`},
+		{Type: "button", Selector: `role=button[name="Find"]`},
+	}}
+
+	decision := ai.DecisionResponse{Action: "click", Element: 2}
+	if err := a.applyDecisionHook(&decision); err != nil {
+		t.Fatalf("applyDecisionHook failed: %v", err)
+	}
+	if decision.Selector != `role=button[name="Find"]` {
+		t.Fatalf("expected element 2 to resolve to its selector, got %q", decision.Selector)
+	}
+
+	explicit := ai.DecisionResponse{Action: "click", Element: 1, Selector: "#other"}
+	if err := a.applyDecisionHook(&explicit); err != nil || explicit.Selector != "#other" {
+		t.Fatalf("explicit selector should win, got %q (%v)", explicit.Selector, err)
+	}
+
+	missing := ai.DecisionResponse{Action: "click", Element: 9}
+	if err := a.applyDecisionHook(&missing); err == nil {
+		t.Fatal("expected an error for an element number that is not listed")
+	}
+}
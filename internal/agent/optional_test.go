@@ -0,0 +1,59 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+)
+
+func TestOptionalStepFailureDoesNotStopTask(t *testing.T) {
+	provider := &scriptedProvider{
+		steps: []ai.PlanStep{
+			{Description: "Close the promo popup", Optional: true},
+			{Description: "Search"},
+		},
+		decisions: map[string]ai.DecisionResponse{
+			"Close the promo popup": {Action: "wait_for", Selector: "#promo-close", Timeout: 1},
+		},
+	}
+	ag, url := newScriptedAgent(t, `<html><body><input id="q"></body></html>`, provider)
+
+	result, err := ag.ExecuteTask(context.Background(), "search", url)
+	if err != nil || !result.Success {
+		t.Fatalf("optional step failure should not fail the task, got %v", err)
+	}
+	if len(result.Steps) != 2 || result.Steps[0].Error == "" {
+		t.Fatalf("expected the optional step to fail, got %+v", result.Steps)
+	}
+	if taken := stepsTaken(result); len(taken) != 1 || taken[0] != "Search" {
+		t.Fatalf("expected the task to go on to the next step, took %v", taken)
+	}
+}
+
+func TestRequiredActionFailureCounts(t *testing.T) {
+	a := &Agent{}
+	if !a.recordActionFailure(errors.New("element not found"), false, "Step 1") {
+		t.Fatalf("required action failure should count against the task")
+	}
+}
+
+func TestOptionalFlagParsed(t *testing.T) {
+	var steps []ai.PlanStep
+	if err := json.Unmarshal([]byte(`[{"step": "Close the promo popup", "optional": true}, "Search"]`), &steps); err != nil {
+		t.Fatalf("failed to parse plan: %v", err)
+	}
+	if !steps[0].Optional || steps[1].Optional {
+		t.Fatalf("unexpected optional flags: %+v", steps)
+	}
+
+	var decision ai.DecisionResponse
+	if err := json.Unmarshal([]byte(`{"action": "click", "selector": "#close", "optional": true}`), &decision); err != nil {
+		t.Fatalf("failed to parse decision: %v", err)
+	}
+	if !decision.Optional {
+		t.Fatalf("expected decision to be optional")
+	}
+}
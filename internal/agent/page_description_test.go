@@ -0,0 +1,119 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+func TestBuildPlanningDescriptionOmitsSelectors(t *testing.T) {
+	pc := browser.PageContent{
+		Title: "Search",
+		URL:   "https://example.com",
+		Elements: []browser.ElementInfo{
+			{Type: "button", Text: "Find", Selector: `[id="find-btn"]`},
+			{Type: "input", Text: "Query", Selector: `input[name="q"]`},
+			{Type: "button", Text: "Reset", Selector: "div:nth-of-type(2) > button:nth-of-type(1)"},
+		},
+		Headings: []string{"Welcome"},
+	}
+
+	desc := buildPlanningDescription(pc)
+
+	for _, elem := range pc.Elements {
+		if strings.Contains(desc, elem.Selector) {
+			t.Errorf("planning description leaked selector %q:\n%s", elem.Selector, desc)
+		}
+	}
+	if !strings.Contains(desc, "- button: 2") || !strings.Contains(desc, "- input: 1") {
+		t.Errorf("planning description missing element counts:\n%s", desc)
+	}
+	if !strings.Contains(desc, "Welcome") {
+		t.Errorf("planning description missing headings:\n%s", desc)
+	}
+
+	detailed := buildPageDescription(pc, nil)
+	if !strings.Contains(detailed, `[id="find-btn"]`) {
+		t.Errorf("executor description should keep selectors:\n%s", detailed)
+	}
+}
+
+func TestBuildPageDescriptionIncludesAnnouncements(t *testing.T) {
+	pc := browser.PageContent{Title: "Search", URL: "https://example.com", LiveRegions: []string{"3 results found"}}
+	desc := buildPageDescription(pc, nil)
+	if !strings.Contains(desc, "Status Announcements:\n- 3 results found") {
+		t.Errorf("expected live region announcement in description:\n%s", desc)
+	}
+}
+
+func TestBuildPageDescriptionIncludesConsoleErrors(t *testing.T) {
+	pc := browser.PageContent{Title: "Checkout", URL: "https://shop.example/cart", ConsoleErrors: []browser.ConsoleEntry{
+		{Type: "pageerror", Text: "TypeError: cart is undefined"},
+	}}
+	desc := buildPageDescription(pc, nil)
+	if !strings.Contains(desc, "Console Errors:\n- [pageerror] TypeError: cart is undefined") {
+		t.Errorf("expected console error in description:\n%s", desc)
+	}
+	if desc := buildPageDescription(browser.PageContent{Title: "Checkout"}, nil); strings.Contains(desc, "Console Errors") {
+		t.Errorf("expected no console section without errors:\n%s", desc)
+	}
+}
+
+func TestBuildPageDescriptionMarksOffscreenElements(t *testing.T) {
+	pc := browser.PageContent{
+		Elements: []browser.ElementInfo{
+			{Type: "button", Text: "Menu", Selector: `[id="menu"]`},
+			{Type: "link", Text: "Settings", Selector: `role=link[name="Settings"]`, Offscreen: true},
+		},
+	}
+	desc := buildPageDescription(pc, nil)
+	if !strings.Contains(desc, `[link] Settings (selector: role=link[name="Settings"]) (off-screen)`) {
+		t.Errorf("expected off-screen element to be marked:\n%s", desc)
+	}
+	if strings.Contains(desc, `[id="menu"]) (off-screen)`) {
+		t.Errorf("on-screen element marked off-screen:\n%s", desc)
+	}
+}
+
+func TestBuildPageDescriptionUsesMarkdown(t *testing.T) {
+	pc := browser.PageContent{
+		Title:    "Shop",
+		URL:      "https://example.com",
+		Markdown: "# Shop\n\n- [#1 Pricing](/pricing)",
+		Elements: []browser.ElementInfo{
+			{Type: "link", Text: "Pricing", Href: "/pricing", Selector: `role=link[name="Pricing"]`, InMarkdown: true},
+			{Type: "button", Text: "Pay", Selector: `frame=checkout >> [id="pay"]`},
+		},
+	}
+	desc := buildPageDescription(pc, nil)
+	if !strings.Contains(desc, "Page Content:\n# Shop\n\n- [#1 Pricing](/pricing)") {
+		t.Errorf("expected the Markdown page content:\n%s", desc)
+	}
+	if !strings.Contains(desc, `1. [link] (selector: role=link[name="Pricing"])`) {
+		t.Errorf("element shown inline should be listed by selector only:\n%s", desc)
+	}
+	if !strings.Contains(desc, `2. [button] Pay (selector: frame=checkout >> [id="pay"])`) {
+		t.Errorf("element outside the Markdown should keep its text:\n%s", desc)
+	}
+}
+
+func TestBuildPageDescriptionPrefersAccessibilityTree(t *testing.T) {
+	pc := browser.PageContent{
+		Markdown:          "- [#1 Pricing](/pricing)",
+		AccessibilityTree: `- link "Pricing"`,
+		Elements: []browser.ElementInfo{
+			{Type: "link", Text: "Pricing", Selector: `role=link[name="Pricing"]`, InMarkdown: true},
+		},
+	}
+	desc := buildPageDescription(pc, nil)
+	if !strings.Contains(desc, "Accessibility Tree:\n- link \"Pricing\"") {
+		t.Errorf("expected the accessibility tree:\n%s", desc)
+	}
+	if strings.Contains(desc, "[#1 Pricing]") {
+		t.Errorf("Markdown should be replaced by the tree:\n%s", desc)
+	}
+	if !strings.Contains(desc, `1. [link] Pricing (selector: role=link[name="Pricing"])`) {
+		t.Errorf("elements should keep their text without the Markdown:\n%s", desc)
+	}
+}
@@ -0,0 +1,136 @@
+package agent
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+	"github.com/VolodyaPopov923/AIBot/pkg/utils"
+)
+
+// defaultPlanCacheTTL is how long a generated plan is reused for the same task and page.
+const defaultPlanCacheTTL = 30 * time.Minute
+
+// planCache reuses plans for repeated tasks that start on the same page.
+type planCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]planCacheEntry
+	now     func() time.Time
+}
+
+type planCacheEntry struct {
+	plan    ai.Plan
+	created time.Time
+}
+
+// SetPlanCacheTTL changes how long plans are reused. Zero disables plan caching.
+func (a *Agent) SetPlanCacheTTL(ttl time.Duration) {
+	a.plans = newPlanCache(ttl)
+}
+
+func newPlanCache(ttl time.Duration) *planCache {
+	return &planCache{
+		ttl:     ttl,
+		entries: make(map[string]planCacheEntry),
+		now:     time.Now,
+	}
+}
+
+// getOrPlan returns a cached plan for (task, page signature) or calls plan and caches the result.
+func (c *planCache) getOrPlan(task string, pageContent browser.PageContent, plan func() (ai.Plan, error)) (ai.Plan, bool, error) {
+	if c == nil || c.ttl <= 0 {
+		planned, err := plan()
+		return planned, false, err
+	}
+
+	key := planKey(task, pageContent)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok && c.now().Sub(entry.created) < c.ttl {
+		c.mu.Unlock()
+		return copyPlan(entry.plan), true, nil
+	}
+	delete(c.entries, key)
+	c.mu.Unlock()
+
+	planned, err := plan()
+	if err != nil {
+		return ai.Plan{}, false, err
+	}
+
+	c.mu.Lock()
+	c.entries[key] = planCacheEntry{plan: copyPlan(planned), created: c.now()}
+	c.mu.Unlock()
+	return planned, false, nil
+}
+
+// forget drops the cached plan for (task, page signature), so the next run
+// plans afresh instead of repeating a plan that did not work.
+func (c *planCache) forget(task string, pageContent browser.PageContent) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	delete(c.entries, planKey(task, pageContent))
+	c.mu.Unlock()
+}
+
+func planKey(task string, pageContent browser.PageContent) string {
+	return utils.HashString(strings.TrimSpace(strings.ToLower(task)) + "\n" + pageSignature(pageContent))
+}
+
+// copyPlan copies the steps, so callers cannot change a cached plan.
+func copyPlan(plan ai.Plan) ai.Plan {
+	plan.Steps = append([]ai.PlanStep(nil), plan.Steps...)
+	return plan
+}
+
+// pageSignature summarizes a page's structure: host and path, headings, and element
+// counts per type rounded to buckets so that small content changes (a new ad link)
+// keep the signature while material layout changes invalidate cached plans.
+func pageSignature(pageContent browser.PageContent) string {
+	location := pageContent.URL
+	if parsed, err := url.Parse(pageContent.URL); err == nil {
+		location = parsed.Host + parsed.Path
+	}
+
+	counts := make(map[string]int)
+	for _, elem := range pageContent.Elements {
+		counts[elem.Type]++
+	}
+	types := make([]string, 0, len(counts))
+	for t := range counts {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	var b strings.Builder
+	b.WriteString(location)
+	for _, t := range types {
+		fmt.Fprintf(&b, "|%s:%d", t, countBucket(counts[t]))
+	}
+	for i, heading := range pageContent.Headings {
+		if i >= maxPlanningHeadings {
+			break
+		}
+		b.WriteString("|h:" + heading)
+	}
+	return b.String()
+}
+
+// countBucket maps a count onto a coarse logarithmic bucket (0, 1, 2-3, 4-7, ...).
+func countBucket(n int) int {
+	bucket := 0
+	for n > 0 {
+		bucket++
+		n >>= 1
+	}
+	return bucket
+}
@@ -0,0 +1,107 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+func TestPlanCacheReusesPlan(t *testing.T) {
+	cache := newPlanCache(time.Hour)
+	calls := 0
+	planner := func() (ai.Plan, error) {
+		calls++
+		return ai.Plan{Steps: []ai.PlanStep{{Description: "Search for the Kremlin"}}}, nil
+	}
+	page := browser.PageContent{
+		URL:      "https://yandex.ru/maps",
+		Elements: []browser.ElementInfo{{Type: "input"}, {Type: "button"}},
+	}
+
+	if _, cached, err := cache.getOrPlan("find kremlin", page, planner); err != nil || cached {
+		t.Fatalf("first call should plan, cached=%v err=%v", cached, err)
+	}
+	plan, cached, err := cache.getOrPlan("find kremlin", page, planner)
+	if err != nil || !cached {
+		t.Fatalf("second identical call should hit the cache, cached=%v err=%v", cached, err)
+	}
+	if calls != 1 {
+		t.Fatalf("planner called %d times, want 1", calls)
+	}
+	if len(plan.Steps) != 1 || plan.Steps[0].Description != "Search for the Kremlin" {
+		t.Fatalf("unexpected cached plan: %v", plan.Steps)
+	}
+
+	// A materially different page must not reuse the plan.
+	changed := page
+	changed.Elements = append(append([]browser.ElementInfo(nil), page.Elements...),
+		browser.ElementInfo{Type: "link"}, browser.ElementInfo{Type: "link"}, browser.ElementInfo{Type: "link"})
+	if _, cached, _ := cache.getOrPlan("find kremlin", changed, planner); cached {
+		t.Fatalf("changed page structure should invalidate the cached plan")
+	}
+	if calls != 2 {
+		t.Fatalf("planner called %d times, want 2", calls)
+	}
+}
+
+func TestPlanCacheExpires(t *testing.T) {
+	cache := newPlanCache(time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	calls := 0
+	planner := func() (ai.Plan, error) {
+		calls++
+		return ai.Plan{Steps: []ai.PlanStep{{Description: "step"}}}, nil
+	}
+	page := browser.PageContent{URL: "https://example.com"}
+
+	cache.getOrPlan("task", page, planner)
+	now = now.Add(2 * time.Minute)
+	cache.getOrPlan("task", page, planner)
+	if calls != 2 {
+		t.Fatalf("expired plan should be regenerated, planner called %d times", calls)
+	}
+}
+
+func TestPlanCacheForget(t *testing.T) {
+	cache := newPlanCache(time.Hour)
+	calls := 0
+	planner := func() (ai.Plan, error) {
+		calls++
+		return ai.Plan{Steps: []ai.PlanStep{{Description: "step"}}}, nil
+	}
+	page := browser.PageContent{URL: "https://example.com"}
+
+	cache.getOrPlan("task", page, planner)
+	cache.forget("task", page)
+	if _, cached, _ := cache.getOrPlan("task", page, planner); cached {
+		t.Fatalf("a forgotten plan should not be reused")
+	}
+	if calls != 2 {
+		t.Fatalf("planner called %d times, want 2", calls)
+	}
+}
+
+func TestFailedPlanIsNotReused(t *testing.T) {
+	provider := &scriptedProvider{
+		steps: []ai.PlanStep{{Description: "Open the account menu"}},
+		decisions: map[string]ai.DecisionResponse{
+			"Open the account menu": {Action: "wait_for", Selector: "#account", Timeout: 1},
+		},
+	}
+	ag, url := newScriptedAgent(t, `<html><body><p>Welcome</p></body></html>`, provider)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := ag.ExecuteTask(ctx, "open my account", url); err == nil {
+			t.Fatalf("run %d: expected the task to fail", i+1)
+		}
+	}
+	if provider.planned != 2 {
+		t.Fatalf("planned %d times, want a fresh plan for the retry", provider.planned)
+	}
+}
@@ -0,0 +1,184 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+// scriptedProvider plans a fixed list of steps and answers each step with
+// its scripted decision, or else by waiting for the body. The reasoning is
+// always the step, so the task result tells which steps ran.
+type scriptedProvider struct {
+	steps     []ai.PlanStep
+	decisions map[string]ai.DecisionResponse
+	planned   int
+}
+
+func (p *scriptedProvider) Chat(ctx context.Context, req ai.ChatRequest) (string, error) {
+	return "", errors.New("chat is not scripted")
+}
+
+func (p *scriptedProvider) PlanTask(ctx context.Context, task string, pageContext string) (ai.Plan, error) {
+	p.planned++
+	return ai.Plan{Steps: p.steps}, nil
+}
+
+func (p *scriptedProvider) MakeDecision(ctx context.Context, systemPrompt, userInput string, screenshots ...[]byte) (ai.DecisionResponse, error) {
+	_, rest, _ := strings.Cut(userInput, "Plan step: ")
+	step, _, _ := strings.Cut(rest, "\n")
+	decision, ok := p.decisions[step]
+	if !ok {
+		decision = ai.DecisionResponse{Action: "wait_for", Selector: "body"}
+	}
+	decision.Reasoning = step
+	return decision, nil
+}
+
+func (p *scriptedProvider) ParseUserRequest(ctx context.Context, userInput string) (ai.UserRequestParsed, error) {
+	return ai.UserRequestParsed{}, errors.New("requests are not scripted")
+}
+
+// newScriptedAgent starts a headless browser on a page serving html and
+// returns an agent driven by provider and the page's URL, skipping the test
+// when Playwright is not installed.
+func newScriptedAgent(t *testing.T, html string, provider *scriptedProvider) (*Agent, string) {
+	t.Helper()
+	t.Setenv("BROWSER_HEADLESS", "true")
+	t.Setenv("BROWSER_USER_DATA_DIR", t.TempDir())
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(html))
+	}))
+	t.Cleanup(ts.Close)
+
+	ctx := context.Background()
+	mgr, err := browser.NewManager(ctx)
+	if err != nil {
+		t.Skipf("Playwright unavailable: %v", err)
+	}
+	t.Cleanup(func() { _ = mgr.Close(ctx) })
+	return NewAgent(mgr, provider, false), ts.URL
+}
+
+// stepsTaken returns the steps whose actions ran without error.
+func stepsTaken(result *TaskResult) []string {
+	var taken []string
+	for _, step := range result.Steps {
+		if step.Error == "" {
+			taken = append(taken, step.Reasoning)
+		}
+	}
+	return taken
+}
+
+// runPlan runs a task planned as steps on a page serving html and returns the
+// steps it took.
+func runPlan(t *testing.T, steps []ai.PlanStep, html string) []string {
+	t.Helper()
+	ag, url := newScriptedAgent(t, html, &scriptedProvider{steps: steps})
+	result, err := ag.ExecuteTask(context.Background(), "test task", url)
+	if err != nil {
+		t.Fatalf("task failed: %v", err)
+	}
+	return stepsTaken(result)
+}
+
+func TestConditionalPlanStep(t *testing.T) {
+	raw := `[
+		{"step": "Accept cookies", "if": {"text_present": "We use cookies"}},
+		{"step": "Log in", "if": {"selector_present": "#login"}},
+		"Search for the Kremlin"
+	]`
+	var steps []ai.PlanStep
+	if err := json.Unmarshal([]byte(raw), &steps); err != nil {
+		t.Fatalf("failed to parse plan: %v", err)
+	}
+
+	absent := runPlan(t, steps, `<html><body><p>Maps</p></body></html>`)
+	if len(absent) != 1 || absent[0] != "Search for the Kremlin" {
+		t.Fatalf("expected conditional steps to be skipped, got %v", absent)
+	}
+
+	present := runPlan(t, steps, `<html><body><p>We use cookies on this site</p><a id="login" href="#">Log in</a></body></html>`)
+	if len(present) != 3 {
+		t.Fatalf("expected all steps to run when markers are present, got %v", present)
+	}
+}
+
+func TestBranchingPlanStep(t *testing.T) {
+	raw := `[
+		{"step": "Sign in if needed", "if": {"selector_present": "#password"},
+		 "then": [{"step": "Fill in the password"}, {"step": "Submit"}],
+		 "else": [{"step": "Open the account menu", "if": {"text_present": "Menu"}, "else": ["Reload the page"]}]},
+		"Open orders"
+	]`
+	var steps []ai.PlanStep
+	if err := json.Unmarshal([]byte(raw), &steps); err != nil {
+		t.Fatalf("failed to parse plan: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		html string
+		want []string
+	}{
+		{"then", `<html><body><input id="password" type="password"></body></html>`, []string{"Fill in the password", "Submit", "Open orders"}},
+		{"else", `<html><body><p>Menu</p></body></html>`, []string{"Open the account menu", "Open orders"}},
+		{"nested else", `<html><body><p>Orders</p></body></html>`, []string{"Reload the page", "Open orders"}},
+	}
+	for _, tt := range tests {
+		got := runPlan(t, steps, tt.html)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: took %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSpliceStepsCopies(t *testing.T) {
+	// Cached plans share their steps, so splicing must copy even when the
+	// slice has room.
+	shared := make([]ai.PlanStep, 2, 4)
+	shared[0], shared[1] = ai.PlanStep{Description: "a"}, ai.PlanStep{Description: "b"}
+	spliced := spliceSteps(shared, 0, []ai.PlanStep{{Description: "x"}})
+	if shared[1].Description != "b" || len(spliced) != 3 || spliced[1].Description != "x" || spliced[2].Description != "b" {
+		t.Fatalf("spliceSteps = %v, shared steps now %v", spliced, shared)
+	}
+}
+
+func TestNegatedCondition(t *testing.T) {
+	cond := ai.StepCondition{TextPresent: "Sign out", Negate: true}
+	met, err := evaluateCondition(cond, browser.PageContent{MainText: "Sign in"}, nil)
+	if err != nil || !met {
+		t.Fatalf("expected negated condition to hold when text is absent, got %v (%v)", met, err)
+	}
+}
+
+func TestURLCondition(t *testing.T) {
+	cond := ai.StepCondition{URLMatches: `/order/\d+/confirmation`, TextPresent: "Thank you"}
+	exists := func(string) (bool, error) { return false, nil }
+
+	done := browser.PageContent{URL: "https://shop.example/order/42/confirmation", MainText: "Thank you for your order"}
+	if met, err := evaluateCondition(cond, done, exists); err != nil || !met {
+		t.Fatalf("expected the condition to hold, met=%v err=%v", met, err)
+	}
+	cart := browser.PageContent{URL: "https://shop.example/cart", MainText: "Thank you for shopping"}
+	if met, _ := evaluateCondition(cond, cart, exists); met {
+		t.Fatalf("URL check should fail on the cart page")
+	}
+	if _, err := evaluateCondition(ai.StepCondition{URLMatches: "("}, done, exists); err == nil {
+		t.Fatalf("expected an error for an invalid pattern")
+	}
+
+	if got := describeCondition(cond); got != `URL matches "/order/\\d+/confirmation" and text "Thank you" present` {
+		t.Errorf("unexpected description: %s", got)
+	}
+}
@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/VolodyaPopov923/AIBot/internal/playbook"
+)
+
+// runPlaybook runs the first playbook matching the task on the current page,
+// without model calls. It reports whether one ran; a playbook that fails is
+// logged and left to model planning, which picks up from wherever the
+// playbook stopped.
+func (a *Agent) runPlaybook(ctx context.Context, task, pageURL string) (bool, error) {
+	pb, params, ok := playbook.Match(a.Playbooks, task, pageURL)
+	if !ok {
+		return false, nil
+	}
+	log.Printf("📒 Using playbook %s\n", pb.Name)
+	steps := make([]string, 0, len(pb.Steps))
+	for _, step := range pb.Steps {
+		if step.Description != "" {
+			steps = append(steps, step.Description)
+		} else {
+			steps = append(steps, step.Action)
+		}
+	}
+	a.emit(Event{Type: EventPlanReady, Message: fmt.Sprintf("playbook %s: %d step(s)", pb.Name, len(pb.Steps)), Steps: steps})
+
+	err := a.executePlaybook(ctx, pb, params)
+	if err == nil {
+		if unmet := a.unmetCondition(ctx, pb.DoneWhen); unmet != "" {
+			err = fmt.Errorf("%w: %s", ErrNotDone, unmet)
+		}
+	}
+	if err == nil {
+		return true, nil
+	}
+	if stopsTask(err) || errors.Is(err, ErrBudgetExceeded) || ctx.Err() != nil {
+		return true, err
+	}
+	log.Printf("Playbook %s did not finish the task (%v). Falling back to planning...\n", pb.Name, err)
+	if a.contextMgr != nil {
+		a.contextMgr.AddMessage("system", fmt.Sprintf("Playbook %s did not finish the task: %v", pb.Name, err))
+	}
+	return false, nil
+}
+
+func (a *Agent) executePlaybook(ctx context.Context, pb playbook.Playbook, params map[string]string) error {
+	decisions, err := pb.Decisions(params)
+	if err != nil {
+		return err
+	}
+	for idx, decision := range decisions {
+		if err := a.checkpoint(ctx); err != nil {
+			return err
+		}
+		if err := a.checkBudget(); err != nil {
+			return err
+		}
+		a.emit(Event{Type: EventStepStarted, Step: idx + 1, Message: decision.Reasoning})
+		if a.verbose {
+			log.Printf("Playbook step %d/%d: %s\n", idx+1, len(decisions), describeAction(decision))
+		}
+		if err := a.executeAction(ctx, decision); err != nil {
+			if stopsTask(err) {
+				return err
+			}
+			if decision.Optional {
+				a.recordActionFailure(err, true, fmt.Sprintf("Playbook step %d", idx+1))
+				continue
+			}
+			return fmt.Errorf("step %d (%s) failed: %w", idx+1, decision.Action, err)
+		}
+		_ = a.browserMgr.WaitForNavigation(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package agent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+// ErrNoProgress is returned when a task keeps repeating itself or leaves the
+// page unchanged even after being told to change its approach.
+var ErrNoProgress = errors.New("no progress")
+
+// noProgressLimit is how many identical actions on an unchanged page, or
+// acting iterations that leave the page unchanged, count as being stuck.
+const noProgressLimit = 3
+
+// passiveActions leave the page as it is by design, so an unchanged page
+// after them is no sign of being stuck.
+var passiveActions = map[ai.ActionType]bool{
+	ai.ActionWait:       true,
+	ai.ActionWaitFor:    true,
+	ai.ActionPause:      true,
+	ai.ActionClarify:    true,
+	ai.ActionFetch:      true,
+	ai.ActionScrape:     true,
+	ai.ActionExtract:    true,
+	ai.ActionArticle:    true,
+	ai.ActionScreenshot: true,
+	ai.ActionEvaluate:   true,
+	ai.ActionSave:       true,
+	ai.ActionAssert:     true,
+}
+
+// progressTracker notices when the iterative loop goes in circles: the same
+// action on the same page state again and again, or actions that never
+// change the page.
+type progressTracker struct {
+	recent    []progressRecord // newest last, at most noProgressLimit
+	lastHash  string
+	lastActed bool // the previous action was expected to change the page
+	unchanged int  // acting iterations in a row that left the page as it was
+	warned    bool // the model was already told to change its approach
+}
+
+type progressRecord struct {
+	action string // action and target, e.g. click #submit
+	page   string // URL the action was taken on
+	hash   string // page state it was taken on
+}
+
+// observe records the decision made on a page and describes how the task is
+// stuck, or returns "" while it makes progress.
+func (t *progressTracker) observe(pc browser.PageContent, decision ai.DecisionResponse) string {
+	hash := pageStateHash(pc)
+	switch {
+	case hash != t.lastHash:
+		t.unchanged = 0
+	case t.lastActed:
+		t.unchanged++
+	}
+	action := ai.NormalizeAction(decision.Action)
+	t.lastHash, t.lastActed = hash, !passiveActions[action]
+	if action == ai.ActionWait || action == ai.ActionPause {
+		// Waiting for the user or a slow page repeats by nature.
+		return ""
+	}
+
+	t.recent = append(t.recent, progressRecord{action: describeAction(decision), page: pc.URL, hash: hash})
+	if len(t.recent) > noProgressLimit {
+		t.recent = t.recent[len(t.recent)-noProgressLimit:]
+	}
+	if len(t.recent) == noProgressLimit && t.repeating() {
+		return fmt.Sprintf("%q was chosen %d times in a row on an unchanged page", t.recent[0].action, noProgressLimit)
+	}
+	if t.unchanged >= noProgressLimit {
+		return fmt.Sprintf("the page has not changed after %d actions", t.unchanged)
+	}
+	return ""
+}
+
+func (t *progressTracker) repeating() bool {
+	for _, record := range t.recent[1:] {
+		if record != t.recent[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// restart gives the model a fresh run of noProgressLimit iterations after it
+// was told to change its approach.
+func (t *progressTracker) restart() {
+	t.recent = nil
+	t.unchanged = 0
+	t.warned = true
+}
+
+// recentActions lists the recorded actions for diagnostics.
+func (t *progressTracker) recentActions() string {
+	actions := make([]string, 0, len(t.recent))
+	for _, record := range t.recent {
+		actions = append(actions, fmt.Sprintf("%s on %s", record.action, record.page))
+	}
+	return strings.Join(actions, "; ")
+}
+
+// describeAction names a decision by its action and target.
+func describeAction(decision ai.DecisionResponse) string {
+	target := decision.Selector
+	if target == "" {
+		target = decision.URL
+	}
+	if target == "" {
+		target = decision.Text
+	}
+	return strings.TrimSpace(decision.Action + " " + target)
+}
+
+// pageStateHash fingerprints what the model sees of a page.
+func pageStateHash(pc browser.PageContent) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n%s\n", pc.URL, pc.Title, pc.Markdown, pc.MainText)
+	for _, elem := range pc.Elements {
+		fmt.Fprintf(h, "%s|%s|%s\n", elem.Type, elem.Text, elem.Selector)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// changeApproach tells the model it is stuck instead of running the action
+// it chose, and has the next decision made fresh, by the strong model when
+// the provider routes between two.
+func (a *Agent) changeApproach(stuck string) {
+	log.Printf("No progress: %s. Asking for a different approach...\n", stuck)
+	a.contextMgr.AddMessage("system", fmt.Sprintf("No progress: %s. That approach does not work here; choose a different one, e.g. another element, another page or a search, or use \"error\" if the task cannot be done.", stuck))
+	if escalator, ok := a.aiClient.(modelEscalator); ok {
+		escalator.Escalate(escalatedDecisions)
+	}
+	a.freshDecision = true
+}
@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+func TestProgressTrackerDetectsRepeatedAction(t *testing.T) {
+	var tracker progressTracker
+	page := browser.PageContent{URL: "https://shop.example", Markdown: "Buy"}
+	click := ai.DecisionResponse{Action: "click", Selector: "#dead"}
+
+	for i := 1; i < noProgressLimit; i++ {
+		if stuck := tracker.observe(page, click); stuck != "" {
+			t.Fatalf("stuck after %d clicks: %s", i, stuck)
+		}
+	}
+	stuck := tracker.observe(page, click)
+	if !strings.Contains(stuck, `"click #dead"`) {
+		t.Fatalf("expected the repeated click to be detected, got %q", stuck)
+	}
+	if actions := tracker.recentActions(); !strings.Contains(actions, "click #dead on https://shop.example") {
+		t.Errorf("unexpected diagnostics: %s", actions)
+	}
+
+	tracker.restart()
+	if !tracker.warned || tracker.observe(page, click) != "" {
+		t.Fatalf("restart should allow another %d attempts", noProgressLimit)
+	}
+}
+
+func TestProgressTrackerAllowsChangingPages(t *testing.T) {
+	var tracker progressTracker
+	next := ai.DecisionResponse{Action: "click", Selector: "a.next"}
+	for i := 0; i < 2*noProgressLimit; i++ {
+		page := browser.PageContent{URL: "https://shop.example/list", Markdown: strings.Repeat("item ", i+1)}
+		if stuck := tracker.observe(page, next); stuck != "" {
+			t.Fatalf("paging through results is progress, got %q", stuck)
+		}
+	}
+}
+
+func TestProgressTrackerDetectsUnchangedPage(t *testing.T) {
+	var tracker progressTracker
+	page := browser.PageContent{URL: "https://shop.example", Markdown: "Buy"}
+	actions := []ai.DecisionResponse{
+		{Action: "click", Selector: "#a"},
+		{Action: "click", Selector: "#b"},
+		{Action: "fill", Selector: "#q", Text: "milk"},
+		{Action: "press", Text: "Enter"},
+	}
+	var stuck string
+	for _, decision := range actions {
+		stuck = tracker.observe(page, decision)
+	}
+	if !strings.Contains(stuck, "has not changed") {
+		t.Fatalf("expected an unchanged page to be detected, got %q", stuck)
+	}
+}
+
+func TestProgressTrackerIgnoresWaiting(t *testing.T) {
+	var tracker progressTracker
+	page := browser.PageContent{URL: "https://shop.example", Markdown: "Checking your browser"}
+	for i := 0; i < 2*noProgressLimit; i++ {
+		if stuck := tracker.observe(page, ai.DecisionResponse{Action: "wait"}); stuck != "" {
+			t.Fatalf("waiting is not being stuck, got %q", stuck)
+		}
+	}
+	if stuck := tracker.observe(page, ai.DecisionResponse{Action: "scrape", Selector: ".price"}); stuck != "" {
+		t.Fatalf("a read after waiting is not being stuck, got %q", stuck)
+	}
+}
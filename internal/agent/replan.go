@@ -0,0 +1,92 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+)
+
+// Re-planning limits: a plan is replaced after this many consecutive failed
+// steps, at most maxReplans times per task.
+const (
+	replanAfterFailures = 2
+	maxReplans          = 2
+)
+
+// planTask asks the model for a plan and accounts for its token usage.
+func (a *Agent) planTask(ctx context.Context, task, pageDesc string) (ai.Plan, error) {
+	var usage ai.Usage
+	plan, err := a.aiClient.PlanTask(ai.WithUsage(ctx, &usage), task, pageDesc)
+	planned := make([]string, 0, len(plan.Steps))
+	for _, step := range plan.Steps {
+		planned = append(planned, step.Description)
+	}
+	a.result.TokenUsage.add(usage, task+pageDesc, strings.Join(planned, "\n"))
+	return plan, err
+}
+
+// replanReason explains why the current plan should be replaced, or returns
+// "" while it still fits.
+func replanReason(consecutiveFailures int, deviation string) string {
+	switch {
+	case deviation != "":
+		return "the page no longer matches the plan: " + deviation
+	case consecutiveFailures >= replanAfterFailures:
+		return fmt.Sprintf("%d steps in a row failed", consecutiveFailures)
+	}
+	return ""
+}
+
+// replan plans the rest of task from the current page, telling the model
+// which steps are done and why the previous plan was dropped.
+func (a *Agent) replan(ctx context.Context, task string, done []string, reason string) (ai.Plan, error) {
+	pc, err := a.browserMgr.GetPageContent(ctx)
+	if err != nil {
+		return ai.Plan{}, fmt.Errorf("failed to get page content: %w", err)
+	}
+	a.emit(Event{Type: EventPlanning, URL: pc.URL, Message: "re-planning: " + reason})
+	if a.verbose {
+		log.Printf("Re-planning because %s\n", reason)
+	}
+
+	plan, err := a.planTask(ctx, remainingGoal(task, done, reason), buildPlanningDescription(pc))
+	if err != nil {
+		return ai.Plan{}, err
+	}
+	if len(plan.Steps) == 0 {
+		return ai.Plan{}, errors.New("the new plan is empty")
+	}
+	a.contextMgr.AddMessage("system", fmt.Sprintf("Re-planned because %s. New plan: %s", reason, joinSteps(plan.Steps)))
+	a.emit(Event{Type: EventPlanReady, Message: fmt.Sprintf("%d step(s)", len(plan.Steps)), Steps: stepDescriptions(plan.Steps)})
+	return plan, nil
+}
+
+// remainingGoal restates task for re-planning.
+func remainingGoal(task string, done []string, reason string) string {
+	completed := "nothing yet"
+	if len(done) > 0 {
+		completed = strings.Join(done, "; ")
+	}
+	return fmt.Sprintf("%s\n\nAlready done: %s.\nThe previous plan was dropped because %s. Plan only the steps still needed, starting from the current page.", task, completed, reason)
+}
+
+// stepDescriptions lists what the steps of a plan are for.
+func stepDescriptions(steps []ai.PlanStep) []string {
+	descriptions := make([]string, 0, len(steps))
+	for _, step := range steps {
+		descriptions = append(descriptions, step.Description)
+	}
+	return descriptions
+}
+
+func joinSteps(steps []ai.PlanStep) string {
+	descriptions := make([]string, 0, len(steps))
+	for i, step := range steps {
+		descriptions = append(descriptions, fmt.Sprintf("%d. %s", i+1, step.Description))
+	}
+	return strings.Join(descriptions, " ")
+}
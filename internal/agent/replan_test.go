@@ -0,0 +1,30 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReplanReason(t *testing.T) {
+	if reason := replanReason(replanAfterFailures-1, ""); reason != "" {
+		t.Errorf("a single failure should not re-plan, got %q", reason)
+	}
+	if reason := replanReason(replanAfterFailures, ""); !strings.Contains(reason, "in a row failed") {
+		t.Errorf("consecutive failures should re-plan, got %q", reason)
+	}
+	if reason := replanReason(0, "no search box here"); !strings.Contains(reason, "no search box here") {
+		t.Errorf("a deviation should re-plan with its reason, got %q", reason)
+	}
+}
+
+func TestRemainingGoal(t *testing.T) {
+	goal := remainingGoal("Buy milk", []string{"Open the shop", "Search for milk"}, "2 steps in a row failed")
+	for _, want := range []string{"Buy milk", "Already done: Open the shop; Search for milk.", "because 2 steps in a row failed"} {
+		if !strings.Contains(goal, want) {
+			t.Errorf("goal missing %q:\n%s", want, goal)
+		}
+	}
+	if goal := remainingGoal("Buy milk", nil, "x"); !strings.Contains(goal, "Already done: nothing yet.") {
+		t.Errorf("unexpected goal without done steps:\n%s", goal)
+	}
+}
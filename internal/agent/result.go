@@ -0,0 +1,174 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+	ctxmgr "github.com/VolodyaPopov923/AIBot/internal/context"
+)
+
+// TaskResult is what a task produced, returned by ExecuteTask even when the
+// task fails so partial results are not lost.
+type TaskResult struct {
+	Task        string       `json:"task"`
+	StartURL    string       `json:"start_url,omitempty"`
+	Success     bool         `json:"success"`
+	Error       string       `json:"error,omitempty"`
+	Answer      string       `json:"answer,omitempty"`    // final answer reported with the "complete" action
+	Extracted   []string     `json:"extracted,omitempty"` // results of scrape actions
+	FinalURL    string       `json:"final_url,omitempty"`
+	Steps       []StepRecord `json:"steps,omitempty"`
+	Screenshots []string     `json:"screenshots,omitempty"`
+	Downloads   []string     `json:"downloads,omitempty"` // paths of files the site sent
+	TokenUsage  TokenUsage   `json:"token_usage"`
+	// PendingAction is the action that stopped the task under HaltOnDestructive.
+	PendingAction *ai.DecisionResponse `json:"pending_action,omitempty"`
+	StartedAt     time.Time            `json:"started_at"`
+	Duration      time.Duration        `json:"duration"`
+
+	// Network holds the document, XHR and fetch requests of the task when
+	// Agent.RecordNetwork is set.
+	Network []browser.NetworkEntry `json:"network,omitempty"`
+	// Structured holds the tables, lists and JSON-LD of extract actions.
+	Structured []browser.StructuredData `json:"structured,omitempty"`
+	// SkillOutputs holds what skills returned.
+	SkillOutputs []SkillOutput `json:"skill_outputs,omitempty"`
+	// Assertions holds the outcome of each assert action; a task with a
+	// failed one does not succeed.
+	Assertions []AssertionResult `json:"assertions,omitempty"`
+	// Variables holds the values steps saved for later steps, by name.
+	Variables map[string]string `json:"variables,omitempty"`
+	// Clarifications holds the questions asked with clarify and their answers.
+	Clarifications []Clarification `json:"clarifications,omitempty"`
+}
+
+// StepRecord is a single action the agent executed.
+type StepRecord struct {
+	Action    string `json:"action"`
+	Selector  string `json:"selector,omitempty"`
+	URL       string `json:"url,omitempty"`
+	Text      string `json:"text,omitempty"`
+	Skill     string `json:"skill,omitempty"`
+	SaveAs    string `json:"save_as,omitempty"`
+	Negate    bool   `json:"negate,omitempty"`
+	Reasoning string `json:"reasoning,omitempty"`
+	// NeedsConfirm and Optional are kept so a replay treats the step the same way.
+	NeedsConfirm bool   `json:"needs_confirm,omitempty"`
+	Optional     bool   `json:"optional,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// TokenUsage is the tokens sent to and received from the model, as reported by
+// the API or, for backends that report no usage, estimated.
+type TokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+
+	// CostUSD is the price of the reported usage; estimated usage and unknown
+	// or local models cost nothing.
+	CostUSD float64 `json:"cost_usd,omitempty"`
+}
+
+// Plus returns the sum of two usages.
+func (u TokenUsage) Plus(other TokenUsage) TokenUsage {
+	return TokenUsage{
+		PromptTokens:     u.PromptTokens + other.PromptTokens,
+		CompletionTokens: u.CompletionTokens + other.CompletionTokens,
+		TotalTokens:      u.TotalTokens + other.TotalTokens,
+		CostUSD:          u.CostUSD + other.CostUSD,
+	}
+}
+
+// UsageTotals are the tasks an Agent ran and the model usage they added up to.
+type UsageTotals struct {
+	Tasks int `json:"tasks"`
+	TokenUsage
+}
+
+// add accounts for a model call: the reported usage if there is any,
+// otherwise an estimate from the prompt and completion text.
+func (u *TokenUsage) add(reported ai.Usage, prompt, completion string) {
+	if !reported.Reported() {
+		reported = ai.Usage{PromptTokens: ctxmgr.EstimateTokens(prompt), CompletionTokens: ctxmgr.EstimateTokens(completion)}
+	}
+	u.PromptTokens += reported.PromptTokens
+	u.CompletionTokens += reported.CompletionTokens
+	u.CostUSD += reported.CostUSD
+	u.TotalTokens = u.PromptTokens + u.CompletionTokens
+}
+
+// recordStep appends an executed action to the task result.
+func (a *Agent) recordStep(decision ai.DecisionResponse, err error) {
+	step := StepRecord{
+		Action:       string(ai.NormalizeAction(decision.Action)),
+		Selector:     decision.Selector,
+		URL:          decision.URL,
+		Text:         decision.Text,
+		Skill:        decision.Skill,
+		SaveAs:       decision.SaveAs,
+		Negate:       decision.Negate,
+		Reasoning:    decision.Reasoning,
+		NeedsConfirm: decision.NeedsConfirm,
+		Optional:     decision.Optional,
+	}
+	if err != nil {
+		step.Error = err.Error()
+	}
+	a.result.Steps = append(a.result.Steps, step)
+}
+
+// recordAnswer keeps the answer reported with a completing decision.
+func (a *Agent) recordAnswer(decision ai.DecisionResponse) {
+	if decision.Text != "" {
+		a.result.Answer = decision.Text
+	}
+}
+
+// decide asks the model for a decision, passing the history of the task
+// trimmed to the context budget, and accounts for its token usage, which it
+// also returns.
+func (a *Agent) decide(ctx context.Context, systemPrompt, userInput string, screenshots ...[]byte) (ai.DecisionResponse, ai.Usage, error) {
+	var usage ai.Usage
+	ctx = ai.WithUsage(ctx, &usage)
+	if a.freshDecision {
+		ctx = ai.WithoutCache(ctx)
+		a.freshDecision = false
+	}
+	needed := ctxmgr.EstimateTokens(systemPrompt) + ctxmgr.EstimateTokens(userInput) + decisionReserve
+	a.contextMgr.TrimToTokens(a.contextMgr.TokenCounter().MaxTokens - needed)
+	ctx = ai.WithHistory(ctx, a.history())
+	decision, err := a.aiClient.MakeDecision(ctx, systemPrompt, userInput, screenshots...)
+	a.result.TokenUsage.add(usage, systemPrompt+userInput, decision.Reasoning+decision.Text)
+	return decision, usage, err
+}
+
+// finishResult fills in the final fields of the task result.
+func (a *Agent) finishResult(err error) *TaskResult {
+	result := a.result
+	result.Success = err == nil
+	if err != nil {
+		result.Error = err.Error()
+	}
+	if a.browserMgr != nil {
+		result.FinalURL = a.browserMgr.CurrentURL()
+		if a.RecordNetwork {
+			result.Network = a.browserMgr.NetworkLog("document", "xhr", "fetch")
+		}
+	}
+	result.Duration = time.Since(result.StartedAt)
+	a.totalsMu.Lock()
+	a.totals.Tasks++
+	a.totals.TokenUsage = a.totals.TokenUsage.Plus(result.TokenUsage)
+	a.totalsMu.Unlock()
+	return &result
+}
+
+// Totals returns the usage of all tasks the agent ran so far.
+func (a *Agent) Totals() UsageTotals {
+	a.totalsMu.Lock()
+	defer a.totalsMu.Unlock()
+	return a.totals
+}
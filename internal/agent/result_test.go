@@ -0,0 +1,87 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/security"
+)
+
+func TestTaskResultRecordsStepsAndAnswer(t *testing.T) {
+	a := &Agent{securityMgr: security.NewValidatorWithReader(strings.NewReader("")), HaltOnDestructive: true}
+	a.result = TaskResult{Task: "find the Kremlin's address", StartedAt: time.Now()}
+	ctx := context.Background()
+
+	if err := a.executeAction(ctx, ai.DecisionResponse{Action: "teleport"}); err == nil {
+		t.Fatalf("expected unknown action to fail")
+	}
+	pay := ai.DecisionResponse{Action: "click", Selector: "#pay", Reasoning: "Pay", NeedsConfirm: true}
+	haltErr := a.executeAction(ctx, pay)
+	if err := a.executeAction(ctx, ai.DecisionResponse{Action: "complete", Text: "Moscow, Red Square"}); err != nil {
+		t.Fatalf("complete failed: %v", err)
+	}
+
+	result := a.finishResult(haltErr)
+	if result.Success || !errors.Is(haltErr, ErrDestructiveActionHalted) || result.Error == "" {
+		t.Fatalf("expected failed result with halt error, got %+v", result)
+	}
+	if result.Answer != "Moscow, Red Square" {
+		t.Fatalf("answer not recorded: %q", result.Answer)
+	}
+	if len(result.Steps) != 3 || result.Steps[0].Error == "" || result.Steps[2].Action != "complete" {
+		t.Fatalf("unexpected steps: %+v", result.Steps)
+	}
+	if result.PendingAction == nil || result.PendingAction.Selector != "#pay" {
+		t.Fatalf("pending action not recorded: %+v", result.PendingAction)
+	}
+}
+
+func TestTokenUsageAdd(t *testing.T) {
+	var usage TokenUsage
+	usage.add(ai.Usage{}, "abcdefgh", "abcd")
+	if usage.PromptTokens == 0 || usage.CompletionTokens == 0 || usage.TotalTokens != usage.PromptTokens+usage.CompletionTokens {
+		t.Fatalf("unexpected usage: %+v", usage)
+	}
+
+	estimated := usage
+	usage.add(ai.Usage{PromptTokens: 1200, CompletionTokens: 30}, "abcdefgh", "abcd")
+	if usage.PromptTokens != estimated.PromptTokens+1200 || usage.CompletionTokens != estimated.CompletionTokens+30 {
+		t.Fatalf("reported usage should replace the estimate: %+v", usage)
+	}
+}
+
+func TestAgentTotals(t *testing.T) {
+	a := &Agent{}
+	for _, cost := range []float64{0.25, 0.5} {
+		a.result = TaskResult{StartedAt: time.Now()}
+		a.result.TokenUsage.add(ai.Usage{PromptTokens: 100, CompletionTokens: 10, CostUSD: cost}, "", "")
+		a.finishResult(nil)
+	}
+	totals := a.Totals()
+	if totals.Tasks != 2 || totals.TotalTokens != 220 || totals.CostUSD != 0.75 {
+		t.Fatalf("unexpected totals: %+v", totals)
+	}
+}
+
+func TestTotalsWhileATaskFinishes(t *testing.T) {
+	a := &Agent{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			a.result = TaskResult{StartedAt: time.Now()}
+			a.finishResult(nil)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		_ = a.Totals()
+	}
+	<-done
+	if got := a.Totals().Tasks; got != 100 {
+		t.Fatalf("counted %d tasks, want 100", got)
+	}
+}
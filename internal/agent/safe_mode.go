@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/security"
+)
+
+// ErrDestructiveActionHalted is returned when HaltOnDestructive stops a task
+// instead of asking for confirmation.
+var ErrDestructiveActionHalted = errors.New("halted before destructive action")
+
+// HaltedActionError carries the pending action that stopped the task so it
+// can be reviewed. It matches ErrDestructiveActionHalted with errors.Is.
+type HaltedActionError struct {
+	Decision ai.DecisionResponse
+}
+
+func (e *HaltedActionError) Error() string {
+	return fmt.Sprintf("%v: %s %s (%s)", ErrDestructiveActionHalted, e.Decision.Action, e.Decision.Selector, e.Decision.Reasoning)
+}
+
+func (e *HaltedActionError) Unwrap() error {
+	return ErrDestructiveActionHalted
+}
+
+// stopsTask reports whether an action error ends the task instead of being
+// recovered from: the action needs a person and none is there to help.
+func stopsTask(err error) bool {
+	return errors.Is(err, ErrDestructiveActionHalted) || errors.Is(err, ErrManualStepRequired)
+}
+
+// HaltedAction returns the pending action from an error returned by
+// ExecuteTask, if the task was stopped by HaltOnDestructive.
+func HaltedAction(err error) (ai.DecisionResponse, bool) {
+	var halted *HaltedActionError
+	if errors.As(err, &halted) {
+		return halted.Decision, true
+	}
+	return ai.DecisionResponse{}, false
+}
+
+// ExecuteTaskUnattended runs a task nobody is watching, such as a scheduled
+// one firing in the background of the interactive prompt: destructive actions
+// halt it, other confirmations are refused, and manual steps and questions
+// fail it, so it never reads the terminal the prompt is reading.
+func (a *Agent) ExecuteTaskUnattended(ctx context.Context, task string, initialURL string) (*TaskResult, error) {
+	a.running.Lock()
+	defer a.running.Unlock()
+
+	halt, eachStep, manual, confirm, ask := a.HaltOnDestructive, a.ConfirmEachStep, a.ManualSteps, a.Confirm, a.Ask
+	defer func() {
+		a.HaltOnDestructive, a.ConfirmEachStep, a.ManualSteps, a.Confirm, a.Ask = halt, eachStep, manual, confirm, ask
+	}()
+	a.HaltOnDestructive, a.ConfirmEachStep, a.ManualSteps = true, false, ManualStepFail
+	a.Confirm = func(ctx context.Context, action security.DestructiveAction) (bool, error) {
+		return false, nil
+	}
+	a.Ask = func(ctx context.Context, question string) (string, error) {
+		return "", fmt.Errorf("%w: %s", ErrManualStepRequired, question)
+	}
+	return a.executeTask(ctx, task, initialURL)
+}
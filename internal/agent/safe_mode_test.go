@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/security"
+)
+
+func TestHaltOnDestructiveStopsWithoutPrompting(t *testing.T) {
+	// An empty reader makes any confirmation prompt fail, so a prompt would show up as a different error.
+	a := &Agent{securityMgr: security.NewValidatorWithReader(strings.NewReader("")), HaltOnDestructive: true}
+	ctx := context.Background()
+
+	pay := ai.DecisionResponse{Action: "click", Selector: "#pay", Reasoning: "Confirm the payment", NeedsConfirm: true}
+	err := a.executeAction(ctx, pay)
+	if !errors.Is(err, ErrDestructiveActionHalted) {
+		t.Fatalf("expected ErrDestructiveActionHalted, got %v", err)
+	}
+	pending, ok := HaltedAction(err)
+	if !ok || pending.Selector != "#pay" || pending.Reasoning != pay.Reasoning {
+		t.Fatalf("halted action not recorded: %+v (ok=%v)", pending, ok)
+	}
+	if a.result.PendingAction == nil || a.result.PendingAction.Selector != "#pay" {
+		t.Fatalf("pending action not recorded in the task result: %+v", a.result.PendingAction)
+	}
+	if a.alreadyExecuted(pay) {
+		t.Fatalf("halted action must not be marked as executed")
+	}
+
+	// Destructive wording halts even when the model did not ask for confirmation.
+	remove := ai.DecisionResponse{Action: "click", Selector: "#remove", Reasoning: "Delete the account"}
+	if _, ok := HaltedAction(a.executeAction(ctx, remove)); !ok {
+		t.Fatalf("expected destructive wording to halt the task")
+	}
+
+	safe := ai.DecisionResponse{Action: "click", Reasoning: "Open search results"}
+	if err := a.executeAction(ctx, safe); err != nil {
+		t.Fatalf("non-destructive action should run in safe mode: %v", err)
+	}
+}
+
+func TestUnattendedTaskNeverPrompts(t *testing.T) {
+	provider := &scriptedProvider{steps: []ai.PlanStep{{Description: "Enter the 2FA code", Manual: true}}}
+	ag, url := newScriptedAgent(t, `<html><body>Code:</body></html>`, provider)
+	ag.ManualSteps = ManualStepWait
+	// A prompt would block on this reader, which never gets a line.
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	ag.SetInput(pr)
+
+	_, err := ag.ExecuteTaskUnattended(context.Background(), "log in", url)
+	if !errors.Is(err, ErrManualStepRequired) {
+		t.Fatalf("an unattended manual step should fail the task, got %v", err)
+	}
+	if ag.ManualSteps != ManualStepWait || ag.HaltOnDestructive || ag.Confirm != nil || ag.Ask != nil {
+		t.Fatalf("the interactive settings should be restored after the task")
+	}
+}
@@ -0,0 +1,48 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+)
+
+// TestPromptActionsMatchHandlers verifies that every action advertised in the
+// decision prompt has an executor and that no executor is missing from the prompt.
+func TestPromptActionsMatchHandlers(t *testing.T) {
+	a := &Agent{}
+	handlers := a.actionHandlers()
+	prompt := ai.DecisionPrompt()
+
+	for _, action := range ai.Actions() {
+		if _, ok := handlers[action]; !ok {
+			t.Errorf("action %q is in the prompt but has no handler", action)
+		}
+		if !strings.Contains(prompt, "- "+string(action)+":") {
+			t.Errorf("action %q is missing from the generated prompt", action)
+		}
+	}
+
+	known := make(map[ai.ActionType]bool)
+	for _, action := range ai.Actions() {
+		known[action] = true
+	}
+	for action := range handlers {
+		if !known[action] {
+			t.Errorf("handler for %q is not advertised in the prompt", action)
+		}
+	}
+}
+
+// TestDecisionFieldsPromptIncludesSchemaVersion verifies the field list is generated from the struct tags.
+func TestDecisionFieldsPromptIncludesSchemaVersion(t *testing.T) {
+	prompt := ai.DecisionFieldsPrompt()
+	for _, field := range []string{"schema_version", "action", "selector", "text", "url", "reasoning", "is_complete", "needs_confirm"} {
+		if !strings.Contains(prompt, "- "+field+":") {
+			t.Errorf("field %q missing from decision prompt:\n%s", field, prompt)
+		}
+	}
+	if strings.Contains(prompt, "next_step") {
+		t.Errorf("undocumented field next_step should not be advertised")
+	}
+}
@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+// scrapeAttempts is how many times a scrape is tried before too few results count as a failure.
+const scrapeAttempts = 3
+
+// scrapeRetryDelay gives late content time to load between attempts.
+const scrapeRetryDelay = 1 * time.Second
+
+// ErrTooFewResults is returned when a scrape keeps finding fewer items than expected.
+var ErrTooFewResults = errors.New("scrape returned too few results")
+
+func (a *Agent) doScrape(ctx context.Context, decision ai.DecisionResponse) error {
+	if decision.Selector == "" {
+		return fmt.Errorf("scrape requires a selector")
+	}
+
+	items, err := scrapeWithRetry(ctx, decision.MinCount,
+		func() ([]string, error) {
+			return a.browserMgr.ScrapeList(ctx, decision.Selector)
+		},
+		func() {
+			a.prepareRescrape(ctx, decision.Selector, decision.MinCount)
+		})
+	if err != nil {
+		return err
+	}
+
+	a.result.Extracted = append(a.result.Extracted, items...)
+	a.saveResult(decision, strings.Join(items, "\n"))
+	a.contextMgr.AddMessage("system", fmt.Sprintf("Scraped %d item(s) from %s:\n%s", len(items), decision.Selector, strings.Join(items, "\n")))
+	if a.verbose {
+		log.Printf("Scraped %d item(s) from %s\n", len(items), decision.Selector)
+	}
+	return nil
+}
+
+// prepareRescrape tries the usual causes of an empty scrape before the next
+// attempt: a consent overlay, lazily loaded content, and slow rendering.
+func (a *Agent) prepareRescrape(ctx context.Context, selector string, minCount int) {
+	if dismissed, err := a.browserMgr.DismissConsent(ctx); err != nil {
+		log.Printf("Warning: failed to dismiss consent banner: %v\n", err)
+	} else if dismissed && a.verbose {
+		log.Printf("Dismissed a consent banner before retrying scrape\n")
+	}
+	if _, err := a.browserMgr.ScrollUntilLoaded(ctx, browser.InfiniteScrollOptions{ItemSelector: selector, MinItems: minCount}); err != nil {
+		log.Printf("Warning: %v\n", err)
+	}
+	if err := a.browserMgr.WaitForReady(ctx); err != nil {
+		log.Printf("Warning: readiness wait failed: %v\n", err)
+	}
+}
+
+// scrapeWithRetry runs scrape until it returns at least minCount items,
+// calling prepare between attempts. A minCount of zero expects at least one
+// item, since an empty scrape is almost never the intended result.
+func scrapeWithRetry(ctx context.Context, minCount int, scrape func() ([]string, error), prepare func()) ([]string, error) {
+	if minCount <= 0 {
+		minCount = 1
+	}
+
+	var items []string
+	for attempt := 1; attempt <= scrapeAttempts; attempt++ {
+		var err error
+		items, err = scrape()
+		if err != nil {
+			return nil, err
+		}
+		if len(items) >= minCount {
+			return items, nil
+		}
+		if attempt == scrapeAttempts {
+			break
+		}
+
+		log.Printf("Scrape attempt %d found %d item(s), expected at least %d; retrying\n", attempt, len(items), minCount)
+		prepare()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(scrapeRetryDelay):
+		}
+	}
+	return items, fmt.Errorf("%w: got %d, expected at least %d", ErrTooFewResults, len(items), minCount)
+}
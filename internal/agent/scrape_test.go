@@ -0,0 +1,40 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestScrapeRetriesUntilContentLoads(t *testing.T) {
+	loaded := false
+	calls, prepared := 0, 0
+
+	items, err := scrapeWithRetry(context.Background(), 2,
+		func() ([]string, error) {
+			calls++
+			if !loaded {
+				return nil, nil
+			}
+			return []string{"Result 1", "Result 2", "Result 3"}, nil
+		},
+		func() {
+			prepared++
+			loaded = true // simulated lazy content appearing after scroll/wait
+		})
+	if err != nil {
+		t.Fatalf("expected scrape to succeed after retry, got %v", err)
+	}
+	if len(items) != 3 || calls != 2 || prepared != 1 {
+		t.Fatalf("unexpected result: items=%v calls=%d prepared=%d", items, calls, prepared)
+	}
+}
+
+func TestScrapeGivesUpOnTooFewResults(t *testing.T) {
+	items, err := scrapeWithRetry(context.Background(), 0,
+		func() ([]string, error) { return nil, nil },
+		func() {})
+	if !errors.Is(err, ErrTooFewResults) {
+		t.Fatalf("expected ErrTooFewResults for an empty scrape, got %v (items=%v)", err, items)
+	}
+}
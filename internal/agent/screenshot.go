@@ -0,0 +1,40 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+// defaultScreenshotDir is used when SCREENSHOT_DIR is not set.
+const defaultScreenshotDir = "screenshots"
+
+// screenshotPath returns a new file name in the screenshot directory.
+func screenshotPath(now time.Time, seq int) string {
+	dir := os.Getenv("SCREENSHOT_DIR")
+	if dir == "" {
+		dir = defaultScreenshotDir
+	}
+	return filepath.Join(dir, fmt.Sprintf("%s-%02d.png", now.Format("20060102-150405"), seq))
+}
+
+func (a *Agent) doScreenshot(ctx context.Context, decision ai.DecisionResponse) error {
+	path := screenshotPath(time.Now(), len(a.result.Screenshots)+1)
+	if _, err := a.browserMgr.Screenshot(ctx, browser.ScreenshotOptions{
+		FullPage: decision.Selector == "",
+		Selector: decision.Selector,
+		Path:     path,
+	}); err != nil {
+		return err
+	}
+
+	a.result.Screenshots = append(a.result.Screenshots, path)
+	log.Printf("Screenshot saved to %s\n", path)
+	return nil
+}
@@ -0,0 +1,16 @@
+package agent
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScreenshotPath(t *testing.T) {
+	t.Setenv("SCREENSHOT_DIR", "evidence")
+	now := time.Date(2024, 3, 1, 12, 30, 5, 0, time.UTC)
+
+	if got, want := screenshotPath(now, 2), filepath.Join("evidence", "20240301-123005-02.png"); got != want {
+		t.Fatalf("screenshotPath() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,94 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+// scrollKind is how a scroll decision moves the page.
+type scrollKind int
+
+const (
+	scrollToElement scrollKind = iota
+	scrollToBottom
+	scrollToTop
+	scrollScreens
+	scrollPixels
+	scrollInfinite
+)
+
+// scrollTarget is a parsed scroll decision.
+type scrollTarget struct {
+	kind   scrollKind
+	amount int // screens or pixels
+}
+
+// parseScroll reads the text of a scroll decision: "bottom", "top", "down",
+// "up", a pixel offset such as "800" or "-400", or "more" to keep loading an
+// infinite feed. Without text the decision scrolls to its selector.
+func parseScroll(decision ai.DecisionResponse) (scrollTarget, error) {
+	text := strings.ToLower(strings.TrimSpace(decision.Text))
+	switch text {
+	case "":
+		if decision.Selector == "" {
+			return scrollTarget{kind: scrollScreens, amount: 1}, nil
+		}
+		return scrollTarget{kind: scrollToElement}, nil
+	case "bottom", "end":
+		return scrollTarget{kind: scrollToBottom}, nil
+	case "top", "start":
+		return scrollTarget{kind: scrollToTop}, nil
+	case "down":
+		return scrollTarget{kind: scrollScreens, amount: 1}, nil
+	case "up":
+		return scrollTarget{kind: scrollScreens, amount: -1}, nil
+	case "more", "infinite", "load_more":
+		return scrollTarget{kind: scrollInfinite}, nil
+	}
+	pixels, err := strconv.Atoi(strings.TrimSuffix(text, "px"))
+	if err != nil {
+		return scrollTarget{}, fmt.Errorf("unknown scroll target %q", decision.Text)
+	}
+	return scrollTarget{kind: scrollPixels, amount: pixels}, nil
+}
+
+func (a *Agent) doScroll(ctx context.Context, decision ai.DecisionResponse) error {
+	target, err := parseScroll(decision)
+	if err != nil {
+		return err
+	}
+
+	switch target.kind {
+	case scrollToElement:
+		return a.browserMgr.ScrollIntoView(ctx, decision.Selector)
+	case scrollToBottom:
+		return a.browserMgr.ScrollToBottom(ctx)
+	case scrollToTop:
+		return a.browserMgr.ScrollToTop(ctx)
+	case scrollScreens:
+		return a.browserMgr.ScrollByViewport(ctx, target.amount)
+	case scrollPixels:
+		return a.browserMgr.ScrollBy(ctx, target.amount)
+	}
+
+	count, err := a.browserMgr.ScrollUntilLoaded(ctx, browser.InfiniteScrollOptions{
+		ItemSelector: decision.Selector,
+		MinItems:     decision.MinCount,
+	})
+	if err != nil {
+		return err
+	}
+	if decision.Selector != "" {
+		a.contextMgr.AddMessage("system", fmt.Sprintf("Scrolled the feed: %d item(s) match %s", count, decision.Selector))
+		if a.verbose {
+			log.Printf("Infinite scroll loaded %d item(s) for %s\n", count, decision.Selector)
+		}
+	}
+	return nil
+}
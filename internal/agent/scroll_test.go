@@ -0,0 +1,35 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+)
+
+func TestParseScroll(t *testing.T) {
+	tests := []struct {
+		decision ai.DecisionResponse
+		want     scrollTarget
+	}{
+		{ai.DecisionResponse{Selector: "#footer"}, scrollTarget{kind: scrollToElement}},
+		{ai.DecisionResponse{}, scrollTarget{kind: scrollScreens, amount: 1}},
+		{ai.DecisionResponse{Text: "Bottom"}, scrollTarget{kind: scrollToBottom}},
+		{ai.DecisionResponse{Text: "up"}, scrollTarget{kind: scrollScreens, amount: -1}},
+		{ai.DecisionResponse{Text: "-400px"}, scrollTarget{kind: scrollPixels, amount: -400}},
+		{ai.DecisionResponse{Text: "more", Selector: ".result"}, scrollTarget{kind: scrollInfinite}},
+	}
+	for _, tt := range tests {
+		got, err := parseScroll(tt.decision)
+		if err != nil {
+			t.Errorf("parseScroll(%+v) failed: %v", tt.decision, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseScroll(%+v) = %+v, want %+v", tt.decision, got, tt.want)
+		}
+	}
+
+	if _, err := parseScroll(ai.DecisionResponse{Text: "sideways"}); err == nil {
+		t.Error("expected an error for an unknown scroll target")
+	}
+}
@@ -0,0 +1,48 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+// maxSearchResults is how many search results are shown to the model.
+const maxSearchResults = 8
+
+func (a *Agent) doSearch(ctx context.Context, decision ai.DecisionResponse) error {
+	engine, err := browser.LookupSearchEngine(a.SearchEngine)
+	if err != nil {
+		return err
+	}
+	results, err := a.browserMgr.Search(ctx, engine, decision.Text, maxSearchResults)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("%s found no results for %q (the result page may be a CAPTCHA or use new markup)", engine.Name, decision.Text)
+	}
+	a.saveResult(decision, results[0].URL)
+	a.contextMgr.AddMessage("system", formatSearchResults(engine.Name, decision.Text, results))
+	if a.verbose {
+		log.Printf("Search %q on %s returned %d result(s)\n", decision.Text, engine.Name, len(results))
+	}
+	return nil
+}
+
+// formatSearchResults lists results for the model, which picks one and
+// navigates to its URL.
+func formatSearchResults(engine, query string, results []browser.SearchResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Search results for %q (%s):\n", query, engine)
+	for i, result := range results {
+		fmt.Fprintf(&b, "%d. %s\n   %s\n", i+1, result.Title, result.URL)
+		if result.Snippet != "" {
+			fmt.Fprintf(&b, "   %s\n", result.Snippet)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,24 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+func TestFormatSearchResults(t *testing.T) {
+	got := formatSearchResults("duckduckgo", "kremlin", []browser.SearchResult{
+		{Title: "Kremlin", URL: "https://example.com/kremlin", Snippet: "Opening hours"},
+		{Title: "Moscow", URL: "https://example.org/moscow"},
+	})
+	for _, want := range []string{
+		`Search results for "kremlin" (duckduckgo):`,
+		"1. Kremlin\n   https://example.com/kremlin\n   Opening hours",
+		"2. Moscow\n   https://example.org/moscow",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatSearchResults missing %q:\n%s", want, got)
+		}
+	}
+}
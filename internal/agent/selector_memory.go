@@ -0,0 +1,179 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+// SelectorMemory remembers, per site, the action that carried out a plan
+// step, so a recurring task can repeat it without asking the model. It is
+// kept in a JSON file and is safe for concurrent use.
+type SelectorMemory struct {
+	mu    sync.Mutex
+	path  string
+	sites map[string]map[string]rememberedAction // site -> intent -> action
+}
+
+// rememberedAction is a decision that worked, as stored on disk.
+type rememberedAction struct {
+	Action   string    `json:"action"`
+	Selector string    `json:"selector"`
+	Text     string    `json:"text,omitempty"`
+	Updated  time.Time `json:"updated"`
+}
+
+// NewSelectorMemory loads the memory stored at path; a missing file is an
+// empty memory.
+func NewSelectorMemory(path string) (*SelectorMemory, error) {
+	m := &SelectorMemory{path: path, sites: make(map[string]map[string]rememberedAction)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read selector memory: %w", err)
+	}
+	if err := json.Unmarshal(data, &m.sites); err != nil {
+		return nil, fmt.Errorf("failed to parse selector memory %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// DefaultSelectorMemoryPath is the selector memory file under the user's
+// cache dir.
+func DefaultSelectorMemoryPath() string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, "aibot", "selectors.json")
+}
+
+// Lookup returns the action remembered for intent on site.
+func (m *SelectorMemory) Lookup(site, intent string) (ai.DecisionResponse, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.sites[site][normalizeIntent(intent)]
+	if !ok {
+		return ai.DecisionResponse{}, false
+	}
+	return ai.DecisionResponse{Action: entry.Action, Selector: entry.Selector, Text: entry.Text}, true
+}
+
+// Remember stores the action that carried out intent on site.
+func (m *SelectorMemory) Remember(site, intent string, decision ai.DecisionResponse) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sites[site] == nil {
+		m.sites[site] = make(map[string]rememberedAction)
+	}
+	m.sites[site][normalizeIntent(intent)] = rememberedAction{
+		Action:   decision.Action,
+		Selector: decision.Selector,
+		Text:     decision.Text,
+		Updated:  time.Now(),
+	}
+	return m.save()
+}
+
+// Forget drops the action remembered for intent on site, e.g. after it
+// stopped working.
+func (m *SelectorMemory) Forget(site, intent string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := normalizeIntent(intent)
+	if _, ok := m.sites[site][key]; !ok {
+		return nil
+	}
+	delete(m.sites[site], key)
+	if len(m.sites[site]) == 0 {
+		delete(m.sites, site)
+	}
+	return m.save()
+}
+
+// save writes the memory atomically; the caller holds mu.
+func (m *SelectorMemory) save() error {
+	data, err := json.MarshalIndent(m.sites, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0o700); err != nil {
+		return fmt.Errorf("failed to save selector memory: %w", err)
+	}
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to save selector memory: %w", err)
+	}
+	if err := os.Rename(tmp, m.path); err != nil {
+		return fmt.Errorf("failed to save selector memory: %w", err)
+	}
+	return nil
+}
+
+// normalizeIntent makes "Click  the Search button." and "click the search
+// button" the same intent.
+func normalizeIntent(intent string) string {
+	return strings.TrimRight(strings.Join(strings.Fields(strings.ToLower(intent)), " "), ".!")
+}
+
+// siteOf returns the host of rawURL without a leading "www.".
+func siteOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(parsed.Hostname(), "www.")
+}
+
+// recallDecision returns the remembered action for a plan step when there is
+// one and its element is on the page. An action whose element is gone is
+// forgotten.
+func (a *Agent) recallDecision(ctx context.Context, pc browser.PageContent, step ai.PlanStep) (ai.DecisionResponse, bool) {
+	site := siteOf(pc.URL)
+	if a.Selectors == nil || site == "" {
+		return ai.DecisionResponse{}, false
+	}
+	decision, ok := a.Selectors.Lookup(site, step.Description)
+	if !ok {
+		return ai.DecisionResponse{}, false
+	}
+	if found, err := a.browserMgr.ElementExists(ctx, decision.Selector); err != nil || !found {
+		a.forgetDecision(pc, step)
+		return ai.DecisionResponse{}, false
+	}
+	if a.verbose {
+		log.Printf("Using remembered %s on %s for this step\n", decision.Action, decision.Selector)
+	}
+	decision.Reasoning = "Worked for this step on " + site + " before"
+	return decision, true
+}
+
+// memorizeDecision remembers a decision that carried out a plan step, if it
+// acted on an element.
+func (a *Agent) memorizeDecision(pc browser.PageContent, step ai.PlanStep, decision ai.DecisionResponse) {
+	site := siteOf(pc.URL)
+	if a.Selectors == nil || site == "" || decision.Selector == "" {
+		return
+	}
+	if err := a.Selectors.Remember(site, step.Description, decision); err != nil {
+		log.Printf("Warning: %v\n", err)
+	}
+}
+
+func (a *Agent) forgetDecision(pc browser.PageContent, step ai.PlanStep) {
+	if err := a.Selectors.Forget(siteOf(pc.URL), step.Description); err != nil {
+		log.Printf("Warning: %v\n", err)
+	}
+}
@@ -0,0 +1,64 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+)
+
+func TestSelectorMemoryPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory", "selectors.json")
+	memory, err := NewSelectorMemory(path)
+	if err != nil {
+		t.Fatalf("NewSelectorMemory: %v", err)
+	}
+	decision := ai.DecisionResponse{Action: "fill", Selector: "#q", Text: "milk", Reasoning: "search box"}
+	if err := memory.Remember("shop.example", "Search for milk.", decision); err != nil {
+		t.Fatalf("Remember: %v", err)
+	}
+
+	reloaded, err := NewSelectorMemory(path)
+	if err != nil {
+		t.Fatalf("NewSelectorMemory after save: %v", err)
+	}
+	got, ok := reloaded.Lookup("shop.example", "search  for MILK")
+	if !ok || got.Action != "fill" || got.Selector != "#q" || got.Text != "milk" {
+		t.Fatalf("Lookup = %+v, %v", got, ok)
+	}
+	if _, ok := reloaded.Lookup("other.example", "search for milk"); ok {
+		t.Fatal("an action should only be remembered for its own site")
+	}
+
+	if err := reloaded.Forget("shop.example", "search for milk"); err != nil {
+		t.Fatalf("Forget: %v", err)
+	}
+	reloaded, _ = NewSelectorMemory(path)
+	if _, ok := reloaded.Lookup("shop.example", "search for milk"); ok {
+		t.Fatal("forgotten action is still remembered")
+	}
+}
+
+func TestSelectorMemoryRejectsCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "selectors.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewSelectorMemory(path); err == nil {
+		t.Fatal("expected an error for a corrupt file")
+	}
+}
+
+func TestSiteOf(t *testing.T) {
+	tests := map[string]string{
+		"https://www.shop.example/cart?x=1": "shop.example",
+		"http://localhost:8080/":            "localhost",
+		"about:blank":                       "",
+	}
+	for in, want := range tests {
+		if got := siteOf(in); got != want {
+			t.Errorf("siteOf(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+// Skill is a custom action, such as exporting a Jira board or posting to an
+// internal wiki, that Go code registers with Agent.RegisterSkill. The model
+// runs it with the "skill" action; it goes through the same confirmation and
+// result recording as the built-in actions.
+type Skill interface {
+	// Name identifies the skill to the model, e.g. "export_jira_board".
+	Name() string
+	// Match reports whether the skill is useful for the task on the page.
+	// Only matching skills are offered to the model.
+	Match(task string, page browser.PageContent) bool
+	// Execute runs the skill. decision.Text holds the model's input for it;
+	// the returned output, if any, is shown to the model and kept in the
+	// task result.
+	Execute(ctx context.Context, env SkillEnv, decision ai.DecisionResponse) (string, error)
+}
+
+// skillDescriber is implemented by skills that tell the model what they do
+// and what input they take; others are offered by name only.
+type skillDescriber interface {
+	Description() string
+}
+
+// SkillEnv is what a skill gets to work with.
+type SkillEnv struct {
+	Browser *browser.Manager
+	Task    string
+}
+
+// SkillOutput is what a skill returned during a task.
+type SkillOutput struct {
+	Skill  string `json:"skill"`
+	Output string `json:"output"`
+}
+
+// RegisterSkill adds a skill. Names must be unique and must not shadow a
+// built-in action.
+func (a *Agent) RegisterSkill(skill Skill) error {
+	name := skill.Name()
+	if name == "" || strings.ContainsAny(name, " \t\n") {
+		return fmt.Errorf("invalid skill name %q", name)
+	}
+	for _, action := range ai.Actions() {
+		if ai.NormalizeAction(name) == action {
+			return fmt.Errorf("skill %q shadows a built-in action", name)
+		}
+	}
+	if a.findSkill(name) != nil {
+		return fmt.Errorf("skill %q is already registered", name)
+	}
+	a.skills = append(a.skills, skill)
+	return nil
+}
+
+func (a *Agent) findSkill(name string) Skill {
+	for _, skill := range a.skills {
+		if strings.EqualFold(skill.Name(), name) {
+			return skill
+		}
+	}
+	return nil
+}
+
+// actionsPrompt lists the valid actions and the skills matching the task on
+// the page.
+func (a *Agent) actionsPrompt(pc browser.PageContent) string {
+	actions := ai.ActionsPrompt()
+	var b strings.Builder
+	for _, skill := range a.skills {
+		if !skill.Match(a.currentTask, pc) {
+			continue
+		}
+		fmt.Fprintf(&b, "- %s", skill.Name())
+		if describer, ok := skill.(skillDescriber); ok {
+			fmt.Fprintf(&b, ": %s", describer.Description())
+		}
+		b.WriteString("\n")
+	}
+	if b.Len() == 0 {
+		return actions
+	}
+	return actions + "\nSkills (run with action \"skill\"):\n" + b.String()
+}
+
+func (a *Agent) doSkill(ctx context.Context, decision ai.DecisionResponse) error {
+	skill := a.findSkill(decision.Skill)
+	if skill == nil {
+		return fmt.Errorf("unknown skill %q", decision.Skill)
+	}
+	output, err := skill.Execute(ctx, SkillEnv{Browser: a.browserMgr, Task: a.currentTask}, decision)
+	if err != nil {
+		return fmt.Errorf("skill %s: %w", skill.Name(), err)
+	}
+	if output == "" {
+		return nil
+	}
+	a.saveResult(decision, output)
+	a.result.SkillOutputs = append(a.result.SkillOutputs, SkillOutput{Skill: skill.Name(), Output: output})
+	a.contextMgr.AddMessage("system", fmt.Sprintf("Skill %s returned:\n%s", skill.Name(), output))
+	if a.verbose {
+		log.Printf("Skill %s returned %d characters\n", skill.Name(), len(output))
+	}
+	return nil
+}
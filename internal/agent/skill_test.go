@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+	ctxmgr "github.com/VolodyaPopov923/AIBot/internal/context"
+)
+
+type fakeSkill struct {
+	name  string
+	site  string
+	input string
+}
+
+func (s *fakeSkill) Name() string        { return s.name }
+func (s *fakeSkill) Description() string { return "export the board as CSV (text: board name)" }
+
+func (s *fakeSkill) Match(task string, page browser.PageContent) bool {
+	return strings.Contains(page.URL, s.site)
+}
+
+func (s *fakeSkill) Execute(ctx context.Context, env SkillEnv, decision ai.DecisionResponse) (string, error) {
+	s.input = decision.Text
+	return "key,summary\nOPS-1,Fix login", nil
+}
+
+func TestRegisterSkill(t *testing.T) {
+	a := &Agent{}
+	if err := a.RegisterSkill(&fakeSkill{name: "export_jira_board"}); err != nil {
+		t.Fatalf("RegisterSkill: %v", err)
+	}
+	for _, name := range []string{"export_jira_board", "click", "eval", "two words", ""} {
+		if err := a.RegisterSkill(&fakeSkill{name: name}); err == nil {
+			t.Errorf("RegisterSkill(%q) should fail", name)
+		}
+	}
+}
+
+func TestSkillIsOfferedAndRun(t *testing.T) {
+	skill := &fakeSkill{name: "export_jira_board", site: "jira.example"}
+	a := &Agent{contextMgr: ctxmgr.NewContextManager(8000, 20), currentTask: "export the OPS board"}
+	if err := a.RegisterSkill(skill); err != nil {
+		t.Fatal(err)
+	}
+
+	if prompt := a.actionsPrompt(browser.PageContent{URL: "https://wiki.example"}); strings.Contains(prompt, "export_jira_board") {
+		t.Fatalf("a skill that does not match should not be offered:\n%s", prompt)
+	}
+	prompt := a.actionsPrompt(browser.PageContent{URL: "https://jira.example/board/OPS"})
+	if !strings.Contains(prompt, "- export_jira_board: export the board as CSV") {
+		t.Fatalf("matching skill missing from prompt:\n%s", prompt)
+	}
+
+	decision := ai.DecisionResponse{Action: "skill", Skill: "export_jira_board", Text: "OPS", Reasoning: "export"}
+	if err := ai.ValidateDecision(decision); err != nil {
+		t.Fatalf("ValidateDecision: %v", err)
+	}
+	if err := a.doSkill(context.Background(), decision); err != nil {
+		t.Fatalf("doSkill: %v", err)
+	}
+	if skill.input != "OPS" || len(a.result.SkillOutputs) != 1 || !strings.Contains(a.result.SkillOutputs[0].Output, "OPS-1") {
+		t.Fatalf("unexpected skill run: input %q, outputs %+v", skill.input, a.result.SkillOutputs)
+	}
+	if err := a.doSkill(context.Background(), ai.DecisionResponse{Action: "skill", Skill: "missing"}); err == nil {
+		t.Fatal("expected an error for an unknown skill")
+	}
+}
@@ -0,0 +1,43 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+)
+
+// needsStepApproval reports whether ConfirmEachStep holds the decision for
+// the user. Finishing, giving up and the actions that already wait for the
+// user are not asked about.
+func (a *Agent) needsStepApproval(decision ai.DecisionResponse) bool {
+	if !a.ConfirmEachStep {
+		return false
+	}
+	switch ai.NormalizeAction(decision.Action) {
+	case ai.ActionComplete, ai.ActionError, ai.ActionPause, ai.ActionClarify:
+		return false
+	}
+	return true
+}
+
+// stepDetails describes what a decision acts on for the user approving it:
+// the element's text as listed on the page, the URL and the text it enters.
+func (a *Agent) stepDetails(decision ai.DecisionResponse) string {
+	var details []string
+	if decision.Selector != "" {
+		for _, elem := range a.lastElements {
+			if elem.Selector == decision.Selector && strings.TrimSpace(elem.Text) != "" {
+				details = append(details, fmt.Sprintf("%s %q", elem.Type, truncateRunes(strings.TrimSpace(elem.Text), 80)))
+				break
+			}
+		}
+	}
+	if decision.URL != "" {
+		details = append(details, "url "+decision.URL)
+	}
+	if decision.Text != "" {
+		details = append(details, fmt.Sprintf("text %q", truncateRunes(decision.Text, 200)))
+	}
+	return strings.Join(details, ", ")
+}
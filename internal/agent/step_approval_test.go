@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+	"github.com/VolodyaPopov923/AIBot/internal/security"
+)
+
+func TestConfirmEachStep(t *testing.T) {
+	input := strings.NewReader("no\nyes\n")
+	a := &Agent{securityMgr: security.NewValidatorWithReader(input), ConfirmEachStep: true}
+	ctx := context.Background()
+
+	save := ai.DecisionResponse{Action: "save", SaveAs: "city", Text: "Moscow", Reasoning: "Remember the city"}
+	if err := a.executeAction(ctx, save); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Fatalf("expected the denied step not to run, got %v", err)
+	}
+	if _, ok := a.result.Variables["city"]; ok {
+		t.Fatalf("denied step ran")
+	}
+	if err := a.executeAction(ctx, save); err != nil || a.result.Variables["city"] != "Moscow" {
+		t.Fatalf("approved step should run: %v, %v", err, a.result.Variables)
+	}
+
+	// Nothing is left to read, so any further prompt would fail the action.
+	if err := a.executeAction(ctx, ai.DecisionResponse{Action: "complete", Text: "Moscow"}); err != nil {
+		t.Fatalf("completing should not need approval: %v", err)
+	}
+}
+
+func TestStepDetails(t *testing.T) {
+	a := &Agent{lastElements: []browser.ElementInfo{{Type: "button", Text: " Place order ", Selector: "#buy"}}}
+	got := a.stepDetails(ai.DecisionResponse{Action: "click", Selector: "#buy"})
+	if got != `button "Place order"` {
+		t.Errorf("click details = %q", got)
+	}
+	got = a.stepDetails(ai.DecisionResponse{Action: "fill", Selector: "#email", Text: "me@example.com"})
+	if got != `text "me@example.com"` {
+		t.Errorf("fill details = %q", got)
+	}
+}
+
+func TestConfirmHook(t *testing.T) {
+	var asked []security.DestructiveAction
+	a := &Agent{ConfirmEachStep: true, Confirm: func(ctx context.Context, action security.DestructiveAction) (bool, error) {
+		asked = append(asked, action)
+		return len(asked) > 1, nil
+	}}
+	save := ai.DecisionResponse{Action: "save", SaveAs: "city", Text: "Moscow", Reasoning: "Remember the city"}
+	if err := a.executeAction(context.Background(), save); err == nil {
+		t.Fatalf("a step Confirm denies should not run")
+	}
+	if err := a.executeAction(context.Background(), save); err != nil || a.result.Variables["city"] != "Moscow" {
+		t.Fatalf("a step Confirm approves should run: %v", err)
+	}
+	if len(asked) != 2 || asked[0].Type != "save" || asked[0].Severity != "low" {
+		t.Errorf("Confirm got %+v", asked)
+	}
+}
@@ -0,0 +1,67 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+// maxStructuredMessage caps the extracted data fed back to the model (in runes).
+const maxStructuredMessage = 4000
+
+// structuredKinds parses the text of an extract decision: a comma or space
+// separated list of "tables", "lists" and "json_ld". Empty or "all" selects
+// everything.
+func structuredKinds(text string) ([]string, error) {
+	var kinds []string
+	for _, field := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return r == ',' || r == ' ' }) {
+		switch field {
+		case "all":
+			return nil, nil
+		case browser.StructuredTables, "table":
+			kinds = append(kinds, browser.StructuredTables)
+		case browser.StructuredLists, "list":
+			kinds = append(kinds, browser.StructuredLists)
+		case browser.StructuredJSONLD, "jsonld", "json-ld", "schema":
+			kinds = append(kinds, browser.StructuredJSONLD)
+		default:
+			return nil, fmt.Errorf("unknown extract kind %q (use tables, lists or json_ld)", field)
+		}
+	}
+	return kinds, nil
+}
+
+func (a *Agent) doExtract(ctx context.Context, decision ai.DecisionResponse) error {
+	kinds, err := structuredKinds(decision.Text)
+	if err != nil {
+		return err
+	}
+	data, err := a.browserMgr.ExtractStructured(ctx, decision.Selector, kinds...)
+	if err != nil {
+		return err
+	}
+	if data.Empty() {
+		return fmt.Errorf("no tables, lists or JSON-LD found on the page")
+	}
+	a.result.Structured = append(a.result.Structured, data)
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode extracted data: %w", err)
+	}
+	text := string(raw)
+	if runes := []rune(text); len(runes) > maxStructuredMessage {
+		text = string(runes[:maxStructuredMessage]) + "…"
+	}
+	a.contextMgr.AddMessage("system", fmt.Sprintf("Extracted %d table(s), %d list(s) and %d JSON-LD object(s): %s",
+		len(data.Tables), len(data.Lists), len(data.JSONLD), text))
+	if a.verbose {
+		log.Printf("Extracted %d table(s), %d list(s) and %d JSON-LD object(s) from %s\n", len(data.Tables), len(data.Lists), len(data.JSONLD), data.URL)
+	}
+	return nil
+}
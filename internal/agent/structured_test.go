@@ -0,0 +1,31 @@
+package agent
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStructuredKinds(t *testing.T) {
+	tests := []struct {
+		text  string
+		kinds []string
+		ok    bool
+	}{
+		{"", nil, true},
+		{"all", nil, true},
+		{"tables", []string{"tables"}, true},
+		{"Table, JSON-LD", []string{"tables", "json_ld"}, true},
+		{"lists json_ld", []string{"lists", "json_ld"}, true},
+		{"images", nil, false},
+	}
+	for _, tt := range tests {
+		kinds, err := structuredKinds(tt.text)
+		if (err == nil) != tt.ok {
+			t.Errorf("structuredKinds(%q) error = %v, want ok=%v", tt.text, err, tt.ok)
+			continue
+		}
+		if !reflect.DeepEqual(kinds, tt.kinds) {
+			t.Errorf("structuredKinds(%q) = %v, want %v", tt.text, kinds, tt.kinds)
+		}
+	}
+}
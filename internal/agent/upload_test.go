@@ -0,0 +1,35 @@
+package agent
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/security"
+)
+
+func TestUploadPaths(t *testing.T) {
+	got := uploadPaths(" resume.pdf,\n/tmp/photo.png ,, ")
+	want := []string{"resume.pdf", "/tmp/photo.png"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("uploadPaths = %v, want %v", got, want)
+	}
+}
+
+func TestUploadAlwaysRequiresConfirmation(t *testing.T) {
+	a := &Agent{securityMgr: security.NewValidatorWithReader(strings.NewReader("no\n"))}
+	ctx := context.Background()
+
+	upload := ai.DecisionResponse{Action: "upload", Selector: "#cv", Text: "resume.pdf", Reasoning: "Attach the resume"}
+	err := a.executeAction(ctx, upload)
+	if err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Fatalf("expected upload to require confirmation, got %v", err)
+	}
+
+	a.HaltOnDestructive = true
+	if _, ok := HaltedAction(a.executeAction(ctx, upload)); !ok {
+		t.Fatalf("expected upload to halt in safe mode")
+	}
+}
@@ -0,0 +1,105 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+)
+
+// maxVariableNote caps each variable value shown to the model (in runes);
+// actions still get the whole value.
+const maxVariableNote = 300
+
+// variableRef matches $name and ${name}.
+var variableRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// setVariable keeps a value for later steps of the task. Variables live in
+// the task result, so they start empty with every task.
+func (a *Agent) setVariable(name, value string) {
+	if a.result.Variables == nil {
+		a.result.Variables = make(map[string]string)
+	}
+	a.result.Variables[name] = value
+	if a.verbose {
+		log.Printf("Saved $%s = %s\n", name, truncateRunes(value, maxVariableNote))
+	}
+}
+
+// saveResult keeps an action's result under the decision's save_as name, if
+// it has one.
+func (a *Agent) saveResult(decision ai.DecisionResponse, value string) {
+	if decision.SaveAs != "" {
+		a.setVariable(decision.SaveAs, value)
+	}
+}
+
+// expandVariables replaces $name and ${name} in the selector, URL and text of
+// a decision with saved values. References to unknown names are left as they
+// are, since "$" also appears in prices and scripts.
+func (a *Agent) expandVariables(decision *ai.DecisionResponse) {
+	if len(a.result.Variables) == 0 {
+		return
+	}
+	for _, field := range []*string{&decision.Selector, &decision.URL, &decision.Text} {
+		*field = expandVariables(*field, a.result.Variables)
+	}
+}
+
+func expandVariables(s string, vars map[string]string) string {
+	if !strings.Contains(s, "$") {
+		return s
+	}
+	return variableRef.ReplaceAllStringFunc(s, func(ref string) string {
+		name := strings.Trim(ref, "${}")
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return ref
+	})
+}
+
+// variablesNote lists the saved variables for the model, or returns "" when
+// there are none.
+func (a *Agent) variablesNote() string {
+	if len(a.result.Variables) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(a.result.Variables))
+	for name := range a.result.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteString("\n\nSaved variables (use them as $name in later actions):")
+	for _, name := range names {
+		fmt.Fprintf(&b, "\n$%s = %s", name, truncateRunes(a.result.Variables[name], maxVariableNote))
+	}
+	return b.String()
+}
+
+func (a *Agent) doSave(ctx context.Context, decision ai.DecisionResponse) error {
+	value := decision.Text
+	if decision.Selector != "" {
+		var err error
+		if value, err = a.browserMgr.ElementValue(ctx, decision.Selector); err != nil {
+			return err
+		}
+	}
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("nothing to save as $%s", decision.SaveAs)
+	}
+	a.setVariable(decision.SaveAs, strings.TrimSpace(value))
+	return nil
+}
+
+func truncateRunes(s string, limit int) string {
+	if runes := []rune(s); len(runes) > limit {
+		return string(runes[:limit]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+)
+
+func TestExpandVariables(t *testing.T) {
+	vars := map[string]string{"link": "https://shop.example/item/7", "id": "7"}
+	tests := map[string]string{
+		"$link":                     "https://shop.example/item/7",
+		"${link}?ref=bot":           "https://shop.example/item/7?ref=bot",
+		"order $id costs $5":        "order 7 costs $5",
+		"$(\".cart\") and $unknown": "$(\".cart\") and $unknown",
+		"#row-${id} .price":         "#row-7 .price",
+		"no variables here":         "no variables here",
+	}
+	for in, want := range tests {
+		if got := expandVariables(in, vars); got != want {
+			t.Errorf("expandVariables(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSaveAndUseVariable(t *testing.T) {
+	a := &Agent{}
+	save := ai.DecisionResponse{Action: "save", SaveAs: "order", Text: " A-1042 ", Reasoning: "keep the order number"}
+	if err := ai.ValidateDecision(save); err != nil {
+		t.Fatalf("ValidateDecision: %v", err)
+	}
+	if err := a.doSave(context.Background(), save); err != nil {
+		t.Fatalf("doSave: %v", err)
+	}
+	if a.result.Variables["order"] != "A-1042" {
+		t.Fatalf("variables = %v", a.result.Variables)
+	}
+
+	decision := ai.DecisionResponse{Action: "fill", Selector: "#q", Text: "status of $order"}
+	a.expandVariables(&decision)
+	if decision.Text != "status of A-1042" {
+		t.Fatalf("expanded text = %q", decision.Text)
+	}
+	if note := a.variablesNote(); !strings.Contains(note, "$order = A-1042") {
+		t.Fatalf("variables note = %q", note)
+	}
+
+	if err := ai.ValidateDecision(ai.DecisionResponse{Action: "save", SaveAs: "bad name", Text: "x", Reasoning: "r"}); err == nil {
+		t.Fatal("expected an invalid save_as name to be rejected")
+	}
+}
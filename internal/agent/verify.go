@@ -0,0 +1,146 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+// maxStepRetries is how many more times a plan step is attempted after the
+// verifier finds it was not achieved.
+const maxStepRetries = 2
+
+// Caps of the page diff sent to the verifier.
+const (
+	maxDiffLines    = 30
+	maxDiffElements = 20
+)
+
+// stepVerifier is implemented by providers that can check whether an action
+// achieved its plan step, such as ai.Client.
+type stepVerifier interface {
+	VerifyStep(ctx context.Context, step, action, pageDiff string) (ai.Verification, error)
+}
+
+// verifyStep asks the model whether decision, taken on the page before,
+// achieved step. It trusts the step when the provider cannot verify or the
+// check itself fails, so verification never fails a task on its own.
+func (a *Agent) verifyStep(ctx context.Context, step string, decision ai.DecisionResponse, before browser.PageContent) ai.Verification {
+	verifier, ok := a.aiClient.(stepVerifier)
+	if !ok {
+		return ai.Verification{Achieved: true}
+	}
+	after, err := a.browserMgr.GetPageContent(ctx)
+	if err != nil {
+		log.Printf("Warning: failed to get page content for verification: %v\n", err)
+		return ai.Verification{Achieved: true}
+	}
+	action, _ := json.Marshal(decision)
+	diff := pageDiff(before, after)
+
+	var usage ai.Usage
+	verdict, err := verifier.VerifyStep(ai.WithUsage(ctx, &usage), step, string(action), diff)
+	if err != nil {
+		log.Printf("Warning: %v\n", err)
+		return ai.Verification{Achieved: true}
+	}
+	a.result.TokenUsage.add(usage, step+string(action)+diff, verdict.Reason)
+	if verdict.Reason == "" && !verdict.Achieved {
+		verdict.Reason = "the page did not change as the step requires"
+	}
+	return verdict
+}
+
+// pageDiff describes how a page changed: its URL and title, lines of text
+// and interactive elements that appeared or disappeared, and new status
+// announcements.
+func pageDiff(before, after browser.PageContent) string {
+	var b strings.Builder
+	if before.URL != after.URL {
+		fmt.Fprintf(&b, "URL: %s -> %s\n", before.URL, after.URL)
+	}
+	if before.Title != after.Title {
+		fmt.Fprintf(&b, "Title: %q -> %q\n", before.Title, after.Title)
+	}
+
+	added, removed := diffLines(pageLines(before), pageLines(after))
+	writeDiffSection(&b, "Text added", added, maxDiffLines)
+	writeDiffSection(&b, "Text removed", removed, maxDiffLines)
+
+	added, removed = diffLines(elementLines(before.Elements), elementLines(after.Elements))
+	writeDiffSection(&b, "Elements added", added, maxDiffElements)
+	writeDiffSection(&b, "Elements removed", removed, maxDiffElements)
+
+	announced, _ := diffLines(before.LiveRegions, after.LiveRegions)
+	writeDiffSection(&b, "Status announcements", announced, maxDiffLines)
+
+	if b.Len() == 0 {
+		return "No visible change: same URL, title, text and elements."
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// pageLines returns the non-empty lines of the page's text.
+func pageLines(pc browser.PageContent) []string {
+	text := pc.Markdown
+	if text == "" {
+		text = pc.MainText
+	}
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func elementLines(elements []browser.ElementInfo) []string {
+	lines := make([]string, 0, len(elements))
+	for _, elem := range elements {
+		lines = append(lines, fmt.Sprintf("[%s] %s", elem.Type, strings.TrimSpace(elem.Text)))
+	}
+	return lines
+}
+
+// diffLines returns the lines only in after and the lines only in before,
+// in their order.
+func diffLines(before, after []string) (added, removed []string) {
+	seen := make(map[string]int, len(before))
+	for _, line := range before {
+		seen[line]++
+	}
+	for _, line := range after {
+		if seen[line] > 0 {
+			seen[line]--
+			continue
+		}
+		added = append(added, line)
+	}
+	for _, line := range before {
+		if seen[line] > 0 {
+			seen[line]--
+			removed = append(removed, line)
+		}
+	}
+	return added, removed
+}
+
+func writeDiffSection(b *strings.Builder, title string, lines []string, limit int) {
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s:\n", title)
+	for i, line := range lines {
+		if i == limit {
+			fmt.Fprintf(b, "... and %d more\n", len(lines)-limit)
+			break
+		}
+		fmt.Fprintf(b, "- %s\n", line)
+	}
+}
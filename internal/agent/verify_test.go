@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+func TestPageDiff(t *testing.T) {
+	before := browser.PageContent{
+		Title:    "Search",
+		URL:      "https://shop.example/search",
+		Markdown: "# Search\nNo results yet\nFooter",
+		Elements: []browser.ElementInfo{{Type: "input", Text: "Query"}, {Type: "button", Text: "Go"}},
+	}
+	after := browser.PageContent{
+		Title:       "Results for milk",
+		URL:         "https://shop.example/search?q=milk",
+		Markdown:    "# Search\nMilk 1L\nMilk 2L\nFooter",
+		Elements:    []browser.ElementInfo{{Type: "input", Text: "Query"}, {Type: "button", Text: "Go"}, {Type: "link", Text: "Milk 1L"}},
+		LiveRegions: []string{"2 results"},
+	}
+
+	diff := pageDiff(before, after)
+	for _, want := range []string{
+		"URL: https://shop.example/search -> https://shop.example/search?q=milk",
+		`Title: "Search" -> "Results for milk"`,
+		"Text added:\n- Milk 1L\n- Milk 2L",
+		"Text removed:\n- No results yet",
+		"Elements added:\n- [link] Milk 1L",
+		"Status announcements:\n- 2 results",
+	} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff missing %q:\n%s", want, diff)
+		}
+	}
+	if strings.Contains(diff, "Footer") || strings.Contains(diff, "Elements removed") {
+		t.Errorf("unchanged content in diff:\n%s", diff)
+	}
+
+	if diff := pageDiff(before, before); !strings.HasPrefix(diff, "No visible change") {
+		t.Errorf("expected no change, got:\n%s", diff)
+	}
+}
+
+func TestPageDiffCapsSections(t *testing.T) {
+	var lines []string
+	for i := 0; i < maxDiffLines+5; i++ {
+		lines = append(lines, strings.Repeat("x", i+1))
+	}
+	diff := pageDiff(browser.PageContent{}, browser.PageContent{MainText: strings.Join(lines, "\n")})
+	if !strings.Contains(diff, "... and 5 more") {
+		t.Errorf("expected a capped section:\n%s", diff)
+	}
+}
@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"context"
+	"log"
+
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+// visionNote is appended to the prompt when a screenshot is attached.
+const visionNote = "\nA screenshot of the current viewport is attached; use it when the element list above is empty or unhelpful (e.g. maps, canvases, dashboards)."
+
+// marksNote is appended instead of visionNote when the screenshot carries
+// element badges.
+const marksNote = "\nA screenshot of the current viewport is attached. Interactive elements carry numbered badges matching the Interactive Elements list; to act on one, set element to its number."
+
+// visionInput returns the screenshots to send with a decision request and the
+// matching prompt note. Both are empty unless UseVision is on; a failed
+// screenshot falls back to a text-only decision. With UseElementMarks the
+// elements are badged for the screenshot only.
+func (a *Agent) visionInput(ctx context.Context, elements []browser.ElementInfo) ([][]byte, string) {
+	if !a.UseVision || a.browserMgr == nil {
+		return nil, ""
+	}
+	note := visionNote
+	if a.UseElementMarks && len(elements) > 0 {
+		if err := a.browserMgr.ShowElementMarks(ctx, elements); err != nil {
+			log.Printf("Warning: failed to mark elements: %v\n", err)
+		} else {
+			note = marksNote
+			defer func() {
+				if err := a.browserMgr.HideElementMarks(ctx); err != nil {
+					log.Printf("Warning: failed to remove element marks: %v\n", err)
+				}
+			}()
+		}
+	}
+	shot, err := a.browserMgr.Screenshot(ctx, browser.ScreenshotOptions{})
+	if err != nil {
+		log.Printf("Warning: screenshot for vision failed, deciding from DOM only: %v\n", err)
+		return nil, ""
+	}
+	return [][]byte{shot}, note
+}
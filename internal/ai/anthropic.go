@@ -0,0 +1,306 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Anthropic API defaults.
+const (
+	defaultAnthropicURL   = "https://api.anthropic.com"
+	defaultAnthropicModel = "claude-sonnet-4-5"
+	anthropicVersion      = "2023-06-01"
+	// defaultAnthropicMaxTokens is sent when the request sets no limit; the
+	// Messages API requires one.
+	defaultAnthropicMaxTokens = 4096
+)
+
+// AnthropicBackend sends chat requests to Anthropic's Messages API.
+type AnthropicBackend struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+	model   string
+}
+
+// NewAnthropicBackend creates a backend for the given key, falling back to
+// ANTHROPIC_API_KEY. Empty baseURL and model select the public API and
+// defaultAnthropicModel. The timeout applies to each attempt; zero means none.
+func NewAnthropicBackend(baseURL, apiKey, model string, timeout time.Duration) *AnthropicBackend {
+	if apiKey == "" {
+		apiKey = os.Getenv("ANTHROPIC_API_KEY")
+	}
+	if baseURL == "" {
+		baseURL = defaultAnthropicURL
+	}
+	if model == "" {
+		model = defaultAnthropicModel
+	}
+	return &AnthropicBackend{
+		client:  newHTTPClient(timeout, DefaultRetryPolicy),
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   model,
+	}
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float32            `json:"temperature"`
+	Tools       []anthropicTool    `json:"tools,omitempty"`
+	ToolChoice  *anthropicChoice   `json:"tool_choice,omitempty"`
+	Stream      bool               `json:"stream,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string             `json:"role"`
+	Content []anthropicContent `json:"content"`
+}
+
+type anthropicContent struct {
+	Type   string           `json:"type"`
+	Text   string           `json:"text,omitempty"`
+	Source *anthropicSource `json:"source,omitempty"`
+	// Name and Input are set on tool_use blocks of a response.
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+}
+
+type anthropicSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
+}
+
+type anthropicTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+type anthropicChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type anthropicResponse struct {
+	Content []anthropicContent `json:"content"`
+	Usage   anthropicUsage     `json:"usage"`
+	Error   *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (b *AnthropicBackend) Chat(ctx context.Context, req ChatRequest) (string, error) {
+	request := anthropicRequest{
+		Model:       b.model,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		Stream:      req.Stream != nil,
+	}
+	if request.MaxTokens <= 0 {
+		request.MaxTokens = defaultAnthropicMaxTokens
+	}
+	request.System, request.Messages = toAnthropicMessages(req.Messages)
+	if fn := req.Function; fn != nil {
+		request.Tools = []anthropicTool{{Name: fn.Name, Description: fn.Description, InputSchema: fn.Parameters}}
+		request.ToolChoice = &anthropicChoice{Type: "tool", Name: fn.Name}
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode Anthropic request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, b.baseURL+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to call Anthropic: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", b.apiKey)
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+
+	resp, err := b.client.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to call Anthropic: %w", err)
+	}
+	defer resp.Body.Close()
+	if req.Stream != nil && resp.StatusCode == http.StatusOK {
+		return readAnthropicStream(ctx, b.model, resp.Body, req)
+	}
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Anthropic response: %w", err)
+	}
+	var parsed anthropicResponse
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return "", fmt.Errorf("failed to call Anthropic: status %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+	if parsed.Error != nil {
+		return "", fmt.Errorf("failed to call Anthropic: %s: %s", parsed.Error.Type, parsed.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to call Anthropic: status %d", resp.StatusCode)
+	}
+	recordUsage(ctx, b.model, parsed.Usage.InputTokens, parsed.Usage.OutputTokens)
+	return anthropicReply(parsed.Content, req)
+}
+
+// anthropicReply returns the arguments of the requested tool call, or else
+// the text of the reply.
+func anthropicReply(content []anthropicContent, req ChatRequest) (string, error) {
+	var text strings.Builder
+	for _, block := range content {
+		switch block.Type {
+		case "tool_use":
+			if req.Function != nil && block.Name == req.Function.Name {
+				return string(block.Input), nil
+			}
+		case "text":
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return "", fmt.Errorf("Anthropic: %w", ErrEmptyResponse)
+	}
+	return text.String(), nil
+}
+
+// anthropicEvent is a server-sent event of a streamed reply.
+type anthropicEvent struct {
+	Type         string            `json:"type"`
+	Index        int               `json:"index"`
+	ContentBlock *anthropicContent `json:"content_block"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+	} `json:"delta"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+	// Usage is on message_delta events; message_start has it in Message.
+	Usage   anthropicUsage `json:"usage"`
+	Message struct {
+		Usage anthropicUsage `json:"usage"`
+	} `json:"message"`
+}
+
+// readAnthropicStream assembles a streamed reply from its server-sent events,
+// passing text and tool input to req.Stream as they arrive.
+func readAnthropicStream(ctx context.Context, model string, body io.Reader, req ChatRequest) (string, error) {
+	var blocks []anthropicContent
+	var inputs []string
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		var event anthropicEvent
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &event); err != nil {
+			continue
+		}
+		switch event.Type {
+		case "message_start":
+			recordUsage(ctx, model, event.Message.Usage.InputTokens, 0)
+		case "message_delta":
+			// The final output count, not an increment.
+			recordUsage(ctx, model, 0, event.Usage.OutputTokens)
+		case "error":
+			if event.Error != nil {
+				return "", fmt.Errorf("failed to call Anthropic: %s: %s", event.Error.Type, event.Error.Message)
+			}
+		case "content_block_start":
+			for len(blocks) <= event.Index {
+				blocks = append(blocks, anthropicContent{})
+				inputs = append(inputs, "")
+			}
+			if event.ContentBlock != nil {
+				blocks[event.Index] = anthropicContent{Type: event.ContentBlock.Type, Name: event.ContentBlock.Name, Text: event.ContentBlock.Text}
+			}
+		case "content_block_delta":
+			if event.Index >= len(blocks) {
+				continue
+			}
+			switch event.Delta.Type {
+			case "text_delta":
+				blocks[event.Index].Text += event.Delta.Text
+				req.Stream(event.Delta.Text)
+			case "input_json_delta":
+				inputs[event.Index] += event.Delta.PartialJSON
+				req.Stream(event.Delta.PartialJSON)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read Anthropic stream: %w", err)
+	}
+	for i := range blocks {
+		if blocks[i].Type == "tool_use" {
+			if inputs[i] == "" {
+				inputs[i] = "{}"
+			}
+			blocks[i].Input = json.RawMessage(inputs[i])
+		}
+	}
+	return anthropicReply(blocks, req)
+}
+
+// toAnthropicMessages converts messages to the Messages API form: system
+// messages become the separate system prompt, and consecutive messages of the
+// same role are merged, since the API wants user and assistant turns to
+// alternate.
+func toAnthropicMessages(messages []Message) (string, []anthropicMessage) {
+	var system []string
+	var out []anthropicMessage
+	for _, msg := range messages {
+		if msg.Role == RoleSystem {
+			system = append(system, msg.Content)
+			continue
+		}
+		role := RoleUser
+		if msg.Role == RoleAssistant {
+			role = RoleAssistant
+		}
+		var content []anthropicContent
+		if msg.Content != "" {
+			content = append(content, anthropicContent{Type: "text", Text: msg.Content})
+		}
+		for _, img := range msg.Images {
+			content = append(content, anthropicContent{Type: "image", Source: &anthropicSource{
+				Type:      "base64",
+				MediaType: "image/png",
+				Data:      base64.StdEncoding.EncodeToString(img),
+			}})
+		}
+		if len(content) == 0 {
+			continue
+		}
+		if n := len(out); n > 0 && out[n-1].Role == role {
+			out[n-1].Content = append(out[n-1].Content, content...)
+			continue
+		}
+		out = append(out, anthropicMessage{Role: role, Content: content})
+	}
+	return strings.Join(system, "\n\n"), out
+}
@@ -0,0 +1,132 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAnthropicBackendUsesToolUse(t *testing.T) {
+	var body anthropicRequest
+	var gotKey, gotPath string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey, gotPath = r.Header.Get("x-api-key"), r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"content": [{"type": "text", "text": "Pressing Enter"},
+			{"type": "tool_use", "id": "1", "name": "decide", "input": {"action": "press", "text": "Enter", "reasoning": "submit"}}]}`)
+	}))
+	defer ts.Close()
+
+	provider, err := NewProvider(ProviderConfig{Name: ProviderAnthropic, APIKey: "key", BaseURL: ts.URL})
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	decision, err := provider.MakeDecision(context.Background(), "system", "user", []byte("png-bytes"))
+	if err != nil {
+		t.Fatalf("MakeDecision failed: %v", err)
+	}
+	if decision.Action != "press" || decision.Text != "Enter" {
+		t.Fatalf("unexpected decision: %+v", decision)
+	}
+	if gotKey != "key" || gotPath != "/v1/messages" || body.Model != defaultAnthropicModel || body.MaxTokens <= 0 {
+		t.Fatalf("unexpected request: key=%q path=%q body=%+v", gotKey, gotPath, body)
+	}
+	if body.System != "system" || len(body.Messages) != 1 || body.Messages[0].Role != RoleUser {
+		t.Fatalf("system prompt should be sent separately: %+v", body)
+	}
+	if content := body.Messages[0].Content; len(content) != 2 || content[1].Type != "image" || content[1].Source.MediaType != "image/png" {
+		t.Fatalf("screenshot not attached as an image block: %+v", content)
+	}
+	if len(body.Tools) != 1 || body.ToolChoice == nil || body.ToolChoice.Name != body.Tools[0].Name {
+		t.Fatalf("expected the decision tool to be forced, got %+v / %+v", body.Tools, body.ToolChoice)
+	}
+}
+
+func TestAnthropicBackendReportsErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"type": "error", "error": {"type": "authentication_error", "message": "invalid x-api-key"}}`)
+	}))
+	defer ts.Close()
+
+	backend := NewAnthropicBackend(ts.URL, "bad", "", 0)
+	if _, err := backend.Chat(context.Background(), ChatRequest{Messages: []Message{{Role: RoleUser, Content: "hi"}}}); err == nil {
+		t.Fatal("expected an authentication error")
+	}
+}
+
+func TestToAnthropicMessagesMergesRoles(t *testing.T) {
+	system, messages := toAnthropicMessages([]Message{
+		{Role: RoleSystem, Content: "rules"},
+		{Role: RoleUser, Content: "a"},
+		{Role: RoleUser, Content: "b"},
+		{Role: RoleAssistant, Content: "c"},
+		{Role: RoleSystem, Content: "more rules"},
+	})
+	if system != "rules\n\nmore rules" {
+		t.Fatalf("unexpected system prompt %q", system)
+	}
+	if len(messages) != 2 || len(messages[0].Content) != 2 || messages[1].Role != RoleAssistant {
+		t.Fatalf("unexpected messages: %+v", messages)
+	}
+}
+
+func TestAnthropicBackendStreams(t *testing.T) {
+	var body anthropicRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `event: message_start
+data: {"type": "message_start", "message": {"content": []}}
+
+event: content_block_start
+data: {"type": "content_block_start", "index": 0, "content_block": {"type": "tool_use", "id": "1", "name": "decide", "input": {}}}
+
+event: content_block_delta
+data: {"type": "content_block_delta", "index": 0, "delta": {"type": "input_json_delta", "partial_json": "{\"action\": \"wait\", "}}
+
+event: content_block_delta
+data: {"type": "content_block_delta", "index": 0, "delta": {"type": "input_json_delta", "partial_json": "\"reasoning\": \"loading\"}"}}
+
+event: message_stop
+data: {"type": "message_stop"}
+
+`)
+	}))
+	defer ts.Close()
+
+	var streamed []string
+	backend := NewAnthropicBackend(ts.URL, "key", "", 0)
+	reply, err := backend.Chat(context.Background(), ChatRequest{
+		Messages: []Message{{Role: RoleUser, Content: "next?"}},
+		Function: decisionFunction,
+		Stream:   func(delta string) { streamed = append(streamed, delta) },
+	})
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+	if !body.Stream || reply != `{"action": "wait", "reasoning": "loading"}` || len(streamed) != 2 {
+		t.Fatalf("unexpected stream: stream=%v reply=%q deltas=%q", body.Stream, reply, streamed)
+	}
+}
+
+func TestAnthropicBackendReportsUsage(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"content": [{"type": "text", "text": "hi"}], "usage": {"input_tokens": 120, "output_tokens": 4}}`)
+	}))
+	defer ts.Close()
+
+	var usage Usage
+	backend := NewAnthropicBackend(ts.URL, "key", "", 0)
+	if _, err := backend.Chat(WithUsage(context.Background(), &usage), ChatRequest{Messages: []Message{{Role: RoleUser, Content: "hi"}}}); err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+	if usage.PromptTokens != 120 || usage.CompletionTokens != 4 {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+}
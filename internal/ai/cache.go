@@ -0,0 +1,166 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ResponseCache stores model replies on disk by a hash of the request, so
+// replayed or repeated tasks on unchanged pages skip the API call. Entries
+// older than the TTL are ignored and overwritten.
+type ResponseCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// NewResponseCache returns a cache in dir, which is created if missing. A
+// zero ttl keeps entries forever.
+func NewResponseCache(dir string, ttl time.Duration) (*ResponseCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create response cache: %w", err)
+	}
+	return &ResponseCache{dir: dir, ttl: ttl}, nil
+}
+
+// DefaultCacheDir is the response cache directory under the user's cache dir.
+func DefaultCacheDir() string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, "aibot", "llm")
+}
+
+// Clear removes every cached reply and returns how many there were.
+func (c *ResponseCache) Clear() (int, error) {
+	entries, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, path := range entries {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to clear response cache: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// Wrap returns a backend that answers from the cache when it can and caches
+// the replies of backend otherwise. Namespace separates the entries of
+// different providers and models.
+func (c *ResponseCache) Wrap(backend ChatBackend, namespace string) ChatBackend {
+	return &cachedBackend{cache: c, backend: backend, namespace: namespace}
+}
+
+type cachedBackend struct {
+	cache     *ResponseCache
+	backend   ChatBackend
+	namespace string
+}
+
+// cacheEntry is a cached reply as stored on disk.
+type cacheEntry struct {
+	Created time.Time `json:"created"`
+	Reply   string    `json:"reply"`
+}
+
+func (b *cachedBackend) Chat(ctx context.Context, req ChatRequest) (string, error) {
+	key := b.key(req)
+	if !cacheSkipped(ctx) {
+		if reply, ok := b.cache.get(key); ok {
+			recordCacheHit(ctx)
+			if req.Stream != nil {
+				req.Stream(reply)
+			}
+			return reply, nil
+		}
+	}
+	reply, err := b.backend.Chat(ctx, req)
+	if err == nil && strings.TrimSpace(reply) != "" {
+		b.cache.put(key, reply)
+	}
+	return reply, err
+}
+
+// key hashes everything that shapes the reply.
+func (b *cachedBackend) key(req ChatRequest) string {
+	h := sha256.New()
+	_ = json.NewEncoder(h).Encode(struct {
+		Namespace   string
+		Messages    []Message
+		Images      [][][]byte
+		Temperature float32
+		MaxTokens   int
+		Function    *FunctionSpec
+	}{b.namespace, req.Messages, messageImages(req.Messages), req.Temperature, req.MaxTokens, req.Function})
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// messageImages collects the screenshots Message leaves out of its JSON.
+func messageImages(messages []Message) [][][]byte {
+	var images [][][]byte
+	for _, msg := range messages {
+		images = append(images, msg.Images)
+	}
+	return images
+}
+
+func (c *ResponseCache) get(key string) (string, bool) {
+	data, err := os.ReadFile(filepath.Join(c.dir, key+".json"))
+	if err != nil {
+		return "", false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return "", false
+	}
+	if c.ttl > 0 && time.Since(entry.Created) > c.ttl {
+		return "", false
+	}
+	return entry.Reply, true
+}
+
+// put stores a reply; the cache is best-effort, so failures are ignored.
+func (c *ResponseCache) put(key, reply string) {
+	data, err := json.Marshal(cacheEntry{Created: time.Now(), Reply: reply})
+	if err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(c.dir, key+".json"))
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
+
+type skipCacheKey struct{}
+
+// WithoutCache returns a context whose requests bypass the response cache,
+// e.g. to get a fresh answer after a cached one led to a failed action. The
+// fresh reply still replaces the cached one.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipCacheKey{}, true)
+}
+
+func cacheSkipped(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipCacheKey{}).(bool)
+	return skip
+}
@@ -0,0 +1,57 @@
+package ai
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestResponseCache(t *testing.T) {
+	cache, err := NewResponseCache(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("NewResponseCache failed: %v", err)
+	}
+	backend := &fakeBackend{replies: []string{"first", "second", "third"}}
+	cached := cache.Wrap(backend, "test|model")
+	req := ChatRequest{Messages: []Message{{Role: RoleUser, Content: "hello"}}}
+
+	var usage Usage
+	ctx := WithUsage(context.Background(), &usage)
+	for i := 0; i < 2; i++ {
+		reply, err := cached.Chat(ctx, req)
+		if err != nil || reply != "first" {
+			t.Fatalf("call %d: got %q, %v", i+1, reply, err)
+		}
+	}
+	if len(backend.requests) != 1 || usage.CachedCalls != 1 || !usage.Reported() {
+		t.Fatalf("second identical call should come from the cache: backend calls %d, usage %+v", len(backend.requests), usage)
+	}
+
+	withImage := ChatRequest{Messages: []Message{{Role: RoleUser, Content: "hello", Images: [][]byte{[]byte("png")}}}}
+	if reply, _ := cached.Chat(ctx, withImage); reply != "second" {
+		t.Fatalf("a different screenshot should miss the cache, got %q", reply)
+	}
+	if reply, _ := cached.Chat(WithoutCache(ctx), req); reply != "third" {
+		t.Fatalf("WithoutCache should call the backend, got %q", reply)
+	}
+
+	removed, err := cache.Clear()
+	if err != nil || removed != 2 {
+		t.Fatalf("Clear removed %d entries, err %v", removed, err)
+	}
+}
+
+func TestResponseCacheExpires(t *testing.T) {
+	cache, err := NewResponseCache(t.TempDir(), time.Nanosecond)
+	if err != nil {
+		t.Fatalf("NewResponseCache failed: %v", err)
+	}
+	backend := &fakeBackend{replies: []string{"first", "second"}}
+	cached := cache.Wrap(backend, "test|model")
+	req := ChatRequest{Messages: []Message{{Role: RoleUser, Content: "hello"}}}
+	_, _ = cached.Chat(context.Background(), req)
+	time.Sleep(time.Millisecond)
+	if reply, _ := cached.Chat(context.Background(), req); reply != "second" {
+		t.Fatalf("expired entry should not be used, got %q", reply)
+	}
+}
@@ -3,45 +3,106 @@ package ai
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"io"
 	"strings"
 
-	"github.com/sashabaranov/go-openai"
+	"github.com/VolodyaPopov923/AIBot/internal/prompts"
 )
 
+// Client implements Provider: it builds the prompts and parses the replies,
+// delegating the actual completion to a ChatBackend.
 type Client struct {
-	openaiClient *openai.Client
-	model        string
-	maxTokens    int
+	backend   ChatBackend
+	maxTokens int
+	stream    io.Writer
 }
 
+// NewClient returns a Client backed by OpenAI.
 func NewClient(apiKey string) *Client {
-	if apiKey == "" {
-		apiKey = os.Getenv("OPENAI_API_KEY")
-	}
+	return NewClientWithBackend(NewOpenAIBackend(apiKey))
+}
 
+// NewClientWithBackend returns a Client that sends requests to backend.
+func NewClientWithBackend(backend ChatBackend) *Client {
 	return &Client{
-		openaiClient: openai.NewClient(apiKey),
-		model:        "gpt-4-turbo-preview",
-		maxTokens:    3000,
+		backend:   backend,
+		maxTokens: 3000,
+	}
+}
+
+// Chat sends a raw chat request to the backend.
+func (c *Client) Chat(ctx context.Context, req ChatRequest) (string, error) {
+	return c.backend.Chat(ctx, req)
+}
+
+// Streamer is implemented by providers that can show the model's output as
+// it is generated.
+type Streamer interface {
+	// SetStream makes planning and decision requests stream the model's
+	// output to w, so long calls show progress instead of looking hung. Nil
+	// turns streaming off.
+	SetStream(w io.Writer)
+}
+
+// SetStream implements Streamer.
+func (c *Client) SetStream(w io.Writer) {
+	c.stream = w
+}
+
+// streamFunc returns the Stream handler of a request, nil when not streaming.
+func (c *Client) streamFunc() func(delta string) {
+	if c.stream == nil {
+		return nil
+	}
+	w := c.stream
+	return func(delta string) {
+		_, _ = io.WriteString(w, delta)
+	}
+}
+
+// endStream ends the line of a streamed reply.
+func (c *Client) endStream() {
+	if c.stream != nil {
+		_, _ = io.WriteString(c.stream, "\n")
 	}
 }
 
+// Message roles understood by every backend.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
 type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role    string   `json:"role"`
+	Content string   `json:"content"`
+	Images  [][]byte `json:"-"` // PNG screenshots for vision-capable models
 }
 
+// DecisionResponse is a single action chosen by the model. The desc tags are
+// used to generate the field list in the decision prompt (see DecisionFieldsPrompt).
 type DecisionResponse struct {
-	Action       string `json:"action"`
-	Selector     string `json:"selector,omitempty"`
-	Text         string `json:"text,omitempty"`
-	URL          string `json:"url,omitempty"`
-	Reasoning    string `json:"reasoning"`
-	IsComplete   bool   `json:"is_complete"`
-	NextStep     string `json:"next_step,omitempty"`
-	NeedsConfirm bool   `json:"needs_confirm"`
+	SchemaVersion int     `json:"schema_version,omitempty" desc:"the schema version you are following"`
+	Action        string  `json:"action" desc:"the action to take (one of the valid actions)"`
+	Selector      string  `json:"selector,omitempty" desc:"selector for the element exactly as listed in the page description: CSS or a locator such as role=button[name=\"Sign in\"], text=\"Sign in\", placeholder=\"Search\" or label=\"Email\""`
+	Element       int     `json:"element,omitempty" desc:"number of the target in the Interactive Elements list (or on its screenshot badge), instead of selector"`
+	Text          string  `json:"text,omitempty" desc:"text to fill or type, option to select, key name to press, tab to switch to, or the final answer when completing"`
+	URL           string  `json:"url,omitempty" desc:"URL to navigate to (if navigating)"`
+	Reasoning     string  `json:"reasoning" desc:"explanation of your decision"`
+	IsComplete    bool    `json:"is_complete" desc:"whether the task is complete"`
+	NextStep      string  `json:"next_step,omitempty"`
+	NeedsConfirm  bool    `json:"needs_confirm" desc:"whether this action needs user confirmation"`
+	Optional      bool    `json:"optional,omitempty" desc:"true if the action is best-effort (e.g. dismissing a banner) and the task may continue if it fails"`
+	Confidence    float64 `json:"confidence,omitempty" desc:"how sure you are that this action is right, from 0.0 to 1.0"`
+	MinCount      int     `json:"min_count,omitempty" desc:"for scrape: the minimum number of results expected"`
+	MaxPages      int     `json:"max_pages,omitempty" desc:"for crawl: the most result pages to visit, the current one included"`
+	Timeout       int     `json:"timeout,omitempty" desc:"for wait_for and wait: the most seconds to wait (wait_for defaults to 10)"`
+	Skill         string  `json:"skill,omitempty" desc:"for skill: the name of the skill to run"`
+	Negate        bool    `json:"negate,omitempty" desc:"for assert: true to check that the text, element or URL is absent"`
+	SaveAs        string  `json:"save_as,omitempty" desc:"for save, scrape, crawl, search, evaluate and skill: a name to keep the result under for later steps, which use it as $name"`
 }
 
 type UserRequestParsed struct {
@@ -51,23 +112,72 @@ type UserRequestParsed struct {
 	Reasoning string `json:"reasoning"`
 }
 
-func (c *Client) MakeDecision(ctx context.Context, systemPrompt, userInput string) (DecisionResponse, error) {
-	resp, err := c.openaiClient.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model:       c.model,
-		Temperature: 0.7,
-		Messages: []openai.ChatCompletionMessage{
-			{Role: openai.ChatMessageRoleSystem, Content: systemPrompt},
-			{Role: openai.ChatMessageRoleUser, Content: userInput},
-		},
-	})
-	if err != nil {
-		return DecisionResponse{}, fmt.Errorf("failed to call OpenAI: %w", err)
-	}
-	if len(resp.Choices) == 0 {
-		return DecisionResponse{}, fmt.Errorf("empty response from OpenAI")
+// decisionFunction is the function the model calls to return a typed decision.
+var decisionFunction = &FunctionSpec{
+	Name:        "decide",
+	Description: "Choose the next browser action.",
+	Parameters:  DecisionJSONSchema(),
+}
+
+// decisionAttempts is how many times the model may answer before an invalid
+// decision is returned as an error.
+const decisionAttempts = 2
+
+// MakeDecision asks the model for the next action. Screenshots, if given, are
+// attached to the user message for vision-capable models, and earlier turns
+// from WithHistory go before it. The decision is requested via function
+// calling and validated; an invalid one is sent back to the model once for
+// correction.
+func (c *Client) MakeDecision(ctx context.Context, systemPrompt, userInput string, screenshots ...[]byte) (DecisionResponse, error) {
+	messages := []Message{{Role: RoleSystem, Content: systemPrompt}}
+	messages = append(messages, historyFrom(ctx)...)
+	messages = append(messages, Message{Role: RoleUser, Content: userInput, Images: screenshots})
+
+	var raw string
+	var lastErr error
+	for attempt := 0; attempt < decisionAttempts; attempt++ {
+		var err error
+		raw, err = c.backend.Chat(ctx, ChatRequest{
+			Temperature: 0.7,
+			Messages:    messages,
+			Function:    decisionFunction,
+			Stream:      c.streamFunc(),
+		})
+		c.endStream()
+		if err != nil {
+			return DecisionResponse{}, err
+		}
+
+		decision, err := parseDecision(raw)
+		if err == nil {
+			return decision, nil
+		}
+		lastErr = err
+		correction := fmt.Sprintf("That decision was rejected: %v. Reply with a corrected decision.", err)
+		if errors.Is(err, errMalformedDecision) {
+			correction = fmt.Sprintf("That reply was rejected: %v. Reply with a single JSON decision object and nothing else.", err)
+		}
+		messages = append(messages,
+			Message{Role: RoleAssistant, Content: raw},
+			Message{Role: RoleUser, Content: correction},
+		)
 	}
 
-	raw := resp.Choices[0].Message.Content
+	return DecisionResponse{
+		Action:     "error",
+		Reasoning:  raw,
+		IsComplete: false,
+	}, lastErr
+}
+
+// errMalformedDecision marks replies that are not decision JSON even after
+// repair, as opposed to well-formed but invalid decisions.
+var errMalformedDecision = errors.New("failed to parse decision JSON")
+
+// parseDecision decodes and validates a decision from function-call arguments
+// or, for backends without function calling, a (possibly fenced) JSON reply.
+// JSON that does not parse is repaired where possible (see repairJSON).
+func parseDecision(raw string) (DecisionResponse, error) {
 	content := strings.TrimSpace(raw)
 	if strings.HasPrefix(content, "```") {
 		parts := strings.SplitN(content, "\n", 2)
@@ -81,13 +191,25 @@ func (c *Client) MakeDecision(ctx context.Context, systemPrompt, userInput strin
 
 	var decision DecisionResponse
 	if err := json.Unmarshal([]byte(content), &decision); err != nil {
-		return DecisionResponse{
-			Action:     "error",
-			Reasoning:  raw,
-			IsComplete: false,
-		}, fmt.Errorf("failed to parse decision JSON: %w", err)
+		repaired, ok := repairJSON(content)
+		if !ok {
+			return decision, fmt.Errorf("%w: %w", errMalformedDecision, err)
+		}
+		decision = DecisionResponse{}
+		if repairErr := json.Unmarshal([]byte(repaired), &decision); repairErr != nil {
+			return decision, fmt.Errorf("%w: %w", errMalformedDecision, err)
+		}
 	}
 
+	if decision.SchemaVersion > DecisionSchemaVersion {
+		return decision, fmt.Errorf("decision schema version %d is newer than supported version %d", decision.SchemaVersion, DecisionSchemaVersion)
+	}
+	if decision.SchemaVersion == 0 {
+		decision.SchemaVersion = DecisionSchemaVersion
+	}
+	if err := ValidateDecision(decision); err != nil {
+		return decision, fmt.Errorf("invalid decision: %w", err)
+	}
 	return decision, nil
 }
 
@@ -97,21 +219,13 @@ func (c *Client) GetAnalysis(ctx context.Context, pageContent string, task strin
 		return "", fmt.Errorf("failed to condense content: %w", err)
 	}
 
-	resp, err := c.openaiClient.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model:       c.model,
+	return c.backend.Chat(ctx, ChatRequest{
 		Temperature: 0.7,
-		Messages: []openai.ChatCompletionMessage{
-			{Role: openai.ChatMessageRoleSystem, Content: "You are an intelligent web automation agent."},
-			{Role: openai.ChatMessageRoleUser, Content: fmt.Sprintf("Task: %s\n\nRelevant page content (condensed):\n%s", task, condensed)},
+		Messages: []Message{
+			{Role: RoleSystem, Content: "You are an intelligent web automation agent."},
+			{Role: RoleUser, Content: fmt.Sprintf("Task: %s\n\nRelevant page content (condensed):\n%s", task, condensed)},
 		},
 	})
-	if err != nil {
-		return "", fmt.Errorf("failed to call OpenAI: %w", err)
-	}
-	if len(resp.Choices) == 0 {
-		return "", fmt.Errorf("empty response from OpenAI")
-	}
-	return resp.Choices[0].Message.Content, nil
 }
 
 func (c *Client) CondenseForAnalysis(ctx context.Context, content string, task string) (string, error) {
@@ -129,41 +243,39 @@ func (c *Client) CondenseForAnalysis(ctx context.Context, content string, task s
 	var summaries []string
 	for _, ch := range chunks {
 		prompt := fmt.Sprintf("Summarize the following page segment into concise bullets focused on the task '%s'. Keep only information useful for accomplishing the task.\n\nSegment:\n%s", task, ch)
-		resp, err := c.openaiClient.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-			Model:       c.model,
+		summary, err := c.backend.Chat(ctx, ChatRequest{
 			Temperature: 0.0,
-			Messages: []openai.ChatCompletionMessage{
-				{Role: openai.ChatMessageRoleSystem, Content: "You are a concise summarizer that preserves task-relevant facts."},
-				{Role: openai.ChatMessageRoleUser, Content: prompt},
+			Messages: []Message{
+				{Role: RoleSystem, Content: "You are a concise summarizer that preserves task-relevant facts."},
+				{Role: RoleUser, Content: prompt},
 			},
 			MaxTokens: 400,
 		})
+		if errors.Is(err, ErrEmptyResponse) {
+			continue
+		}
 		if err != nil {
 			return "", fmt.Errorf("failed to summarize chunk: %w", err)
 		}
-		if len(resp.Choices) == 0 {
-			continue
-		}
-		summaries = append(summaries, resp.Choices[0].Message.Content)
+		summaries = append(summaries, summary)
 	}
 
 	combined := strings.Join(summaries, "\n\n")
 	if approxTokens(combined) > c.maxTokens {
 		prompt := fmt.Sprintf("The following are summaries of segments from a page. Please further condense into a short list of facts strictly relevant to the task '%s'. Prioritize actionable information and key findings.\n\nSummaries:\n%s", task, combined)
-		resp, err := c.openaiClient.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-			Model:       c.model,
+		summary, err := c.backend.Chat(ctx, ChatRequest{
 			Temperature: 0.0,
-			Messages: []openai.ChatCompletionMessage{
-				{Role: openai.ChatMessageRoleSystem, Content: "You are a concise summarizer that preserves task-relevant facts."},
-				{Role: openai.ChatMessageRoleUser, Content: prompt},
+			Messages: []Message{
+				{Role: RoleSystem, Content: "You are a concise summarizer that preserves task-relevant facts."},
+				{Role: RoleUser, Content: prompt},
 			},
 			MaxTokens: 600,
 		})
-		if err != nil {
+		if err != nil && !errors.Is(err, ErrEmptyResponse) {
 			return "", fmt.Errorf("failed to summarize combined summaries: %w", err)
 		}
-		if len(resp.Choices) > 0 {
-			combined = resp.Choices[0].Message.Content
+		if summary != "" {
+			combined = summary
 		}
 	}
 
@@ -171,30 +283,19 @@ func (c *Client) CondenseForAnalysis(ctx context.Context, content string, task s
 }
 
 func (c *Client) ParseUserRequest(ctx context.Context, userInput string) (UserRequestParsed, error) {
-	systemPrompt := `You are a request parser for a web automation agent. Parse the user's request and extract:
-1. Whether a URL is needed or should be extracted
-2. The actual task to perform
-3. Any URLs mentioned
-4. Your reasoning
+	systemPrompt := prompts.Render(prompts.ParseRequest, nil)
 
-Respond as valid JSON with: {"task": "...", "url": "...", "needs_url": boolean, "reasoning": "..."}`
-
-	resp, err := c.openaiClient.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model:       c.model,
+	raw, err := c.backend.Chat(ctx, ChatRequest{
 		Temperature: 0.0,
-		Messages: []openai.ChatCompletionMessage{
-			{Role: openai.ChatMessageRoleSystem, Content: systemPrompt},
-			{Role: openai.ChatMessageRoleUser, Content: userInput},
+		Messages: []Message{
+			{Role: RoleSystem, Content: systemPrompt},
+			{Role: RoleUser, Content: userInput},
 		},
 	})
 	if err != nil {
 		return UserRequestParsed{}, fmt.Errorf("failed to parse request: %w", err)
 	}
-	if len(resp.Choices) == 0 {
-		return UserRequestParsed{}, fmt.Errorf("empty response from OpenAI")
-	}
 
-	raw := resp.Choices[0].Message.Content
 	content := strings.TrimSpace(raw)
 	if strings.HasPrefix(content, "```") {
 		parts := strings.SplitN(content, "\n", 2)
@@ -217,33 +318,30 @@ Respond as valid JSON with: {"task": "...", "url": "...", "needs_url": boolean,
 	return parsed, nil
 }
 
-func (c *Client) PlanTask(ctx context.Context, task string, pageContext string) ([]string, error) {
-	prompt := fmt.Sprintf(`You are a planner for a web automation agent.
-Given the high-level task: "%s"
-and the current page context (brief):
-%s
-
-Break the task into a concise, ordered list of concrete steps that an automated agent can perform in sequence. Each step should be a single short sentence or instruction. Return the result as a JSON array of strings only. Example:
-["Open the images tab", "Click the first image", "Save image URL"]
-`, task, pageContext)
+func (c *Client) PlanTask(ctx context.Context, task string, pageContext string) (Plan, error) {
+	data := prompts.PlanData{Task: task, Page: pageContext}
+	prompt := prompts.Render(prompts.Plan, data)
 
-	resp, err := c.openaiClient.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model:       c.model,
+	reply, err := c.backend.Chat(ctx, ChatRequest{
 		Temperature: 0.0,
-		Messages: []openai.ChatCompletionMessage{
-			{Role: openai.ChatMessageRoleSystem, Content: "You convert user tasks into step-by-step actionable plans for a browser automation agent."},
-			{Role: openai.ChatMessageRoleUser, Content: prompt},
+		Messages: []Message{
+			{Role: RoleSystem, Content: prompts.Render(prompts.PlanSystem, data)},
+			{Role: RoleUser, Content: prompt},
 		},
 		MaxTokens: 800,
+		Stream:    c.streamFunc(),
 	})
+	c.endStream()
 	if err != nil {
-		return nil, fmt.Errorf("failed to call OpenAI for planning: %w", err)
-	}
-	if len(resp.Choices) == 0 {
-		return nil, fmt.Errorf("empty planning response from OpenAI")
+		return Plan{}, fmt.Errorf("planning request failed: %w", err)
 	}
+	return parsePlan(reply)
+}
 
-	raw := strings.TrimSpace(resp.Choices[0].Message.Content)
+// parsePlan reads a plan object, a bare JSON array of steps, or failing both
+// a plain list with one step per line.
+func parsePlan(reply string) (Plan, error) {
+	raw := strings.TrimSpace(reply)
 	if strings.HasPrefix(raw, "```") {
 		parts := strings.SplitN(raw, "\n", 2)
 		if len(parts) == 2 {
@@ -254,7 +352,11 @@ Break the task into a concise, ordered list of concrete steps that an automated
 		}
 	}
 
-	var steps []string
+	var plan Plan
+	if err := json.Unmarshal([]byte(raw), &plan); err == nil && len(plan.Steps) > 0 {
+		return plan, nil
+	}
+	var steps []PlanStep
 	if err := json.Unmarshal([]byte(raw), &steps); err != nil {
 		lines := strings.Split(raw, "\n")
 		for _, l := range lines {
@@ -267,12 +369,12 @@ Break the task into a concise, ordered list of concrete steps that an automated
 			if len(l) > 2 && l[1] == '.' && l[0] >= '0' && l[0] <= '9' {
 				l = strings.TrimSpace(l[2:])
 			}
-			steps = append(steps, l)
+			steps = append(steps, PlanStep{Description: l})
 		}
 		if len(steps) == 0 {
-			return nil, fmt.Errorf("failed to parse plan JSON: %w", err)
+			return Plan{}, fmt.Errorf("failed to parse plan JSON: %w", err)
 		}
 	}
 
-	return steps, nil
+	return Plan{Steps: steps}, nil
 }
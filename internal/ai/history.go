@@ -0,0 +1,18 @@
+package ai
+
+import "context"
+
+type historyKey struct{}
+
+// WithHistory returns a context whose decisions are made with the earlier
+// turns of the conversation, so the model sees what it already tried. The
+// messages go between the system prompt and the current user message.
+func WithHistory(ctx context.Context, messages []Message) context.Context {
+	return context.WithValue(ctx, historyKey{}, messages)
+}
+
+// historyFrom returns the messages of WithHistory, if any.
+func historyFrom(ctx context.Context) []Message {
+	messages, _ := ctx.Value(historyKey{}).([]Message)
+	return messages
+}
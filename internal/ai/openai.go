@@ -0,0 +1,190 @@
+package ai
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// defaultOpenAIModel is used when no model is configured.
+const defaultOpenAIModel = "gpt-4-turbo-preview"
+
+// OpenAIBackend sends chat requests to the OpenAI API or any server that
+// speaks the same protocol (Ollama, vLLM, LM Studio, ...).
+type OpenAIBackend struct {
+	client *openai.Client
+	model  string
+	name   string
+	// streamUsage asks for usage in streamed replies; not every compatible
+	// server accepts the option.
+	streamUsage bool
+}
+
+// NewOpenAIBackend creates a backend for the given key, falling back to OPENAI_API_KEY.
+func NewOpenAIBackend(apiKey string) *OpenAIBackend {
+	if apiKey == "" {
+		apiKey = os.Getenv("OPENAI_API_KEY")
+	}
+	model := os.Getenv("OPENAI_MODEL")
+	if model == "" {
+		model = defaultOpenAIModel
+	}
+	cfg := openai.DefaultConfig(apiKey)
+	cfg.HTTPClient = newHTTPClient(0, DefaultRetryPolicy)
+	return &OpenAIBackend{
+		client:      openai.NewClientWithConfig(cfg),
+		model:       model,
+		name:        "OpenAI",
+		streamUsage: true,
+	}
+}
+
+// NewOpenAICompatibleBackend creates a backend for an OpenAI-compatible
+// endpoint such as a local Ollama server. The timeout applies to each attempt;
+// zero means no timeout.
+func NewOpenAICompatibleBackend(baseURL, apiKey, model string, timeout time.Duration) *OpenAIBackend {
+	cfg := openai.DefaultConfig(apiKey)
+	cfg.BaseURL = baseURL
+	cfg.HTTPClient = newHTTPClient(timeout, DefaultRetryPolicy)
+	return &OpenAIBackend{
+		client: openai.NewClientWithConfig(cfg),
+		model:  model,
+		name:   baseURL,
+	}
+}
+
+func (b *OpenAIBackend) Chat(ctx context.Context, req ChatRequest) (string, error) {
+	messages := make([]openai.ChatCompletionMessage, 0, len(req.Messages))
+	for _, msg := range req.Messages {
+		messages = append(messages, toOpenAIMessage(msg))
+	}
+
+	request := openai.ChatCompletionRequest{
+		Model:       b.model,
+		Temperature: req.Temperature,
+		Messages:    messages,
+		MaxTokens:   req.MaxTokens,
+	}
+	if fn := req.Function; fn != nil {
+		request.Tools = []openai.Tool{{
+			Type: openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{
+				Name:        fn.Name,
+				Description: fn.Description,
+				Parameters:  fn.Parameters,
+			},
+		}}
+		request.ToolChoice = openai.ToolChoice{
+			Type:     openai.ToolTypeFunction,
+			Function: openai.ToolFunction{Name: fn.Name},
+		}
+	}
+
+	if req.Stream != nil {
+		return b.chatStream(ctx, request, req)
+	}
+
+	resp, err := b.client.CreateChatCompletion(ctx, request)
+	if err != nil {
+		return "", fmt.Errorf("failed to call %s: %w", b.name, err)
+	}
+	recordUsage(ctx, b.model, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("%s: %w", b.name, ErrEmptyResponse)
+	}
+	msg := resp.Choices[0].Message
+	for _, call := range msg.ToolCalls {
+		if req.Function != nil && call.Function.Name == req.Function.Name {
+			return call.Function.Arguments, nil
+		}
+	}
+	return msg.Content, nil
+}
+
+// chatStream sends request as a streaming completion, passing text and
+// function arguments to req.Stream as they arrive.
+func (b *OpenAIBackend) chatStream(ctx context.Context, request openai.ChatCompletionRequest, req ChatRequest) (string, error) {
+	if b.streamUsage {
+		request.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+	}
+	stream, err := b.client.CreateChatCompletionStream(ctx, request)
+	if err != nil {
+		return "", fmt.Errorf("failed to call %s: %w", b.name, err)
+	}
+	defer stream.Close()
+
+	var content strings.Builder
+	var calls []openai.ToolCall
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s stream: %w", b.name, err)
+		}
+		if chunk.Usage != nil {
+			recordUsage(ctx, b.model, chunk.Usage.PromptTokens, chunk.Usage.CompletionTokens)
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		delta := chunk.Choices[0].Delta
+		if delta.Content != "" {
+			content.WriteString(delta.Content)
+			req.Stream(delta.Content)
+		}
+		for _, call := range delta.ToolCalls {
+			i := len(calls) - 1
+			if call.Index != nil {
+				i = *call.Index
+			}
+			for i >= len(calls) {
+				calls = append(calls, openai.ToolCall{})
+			}
+			if call.Function.Name != "" {
+				calls[i].Function.Name = call.Function.Name
+			}
+			calls[i].Function.Arguments += call.Function.Arguments
+			if call.Function.Arguments != "" {
+				req.Stream(call.Function.Arguments)
+			}
+		}
+	}
+
+	for _, call := range calls {
+		if req.Function != nil && call.Function.Name == req.Function.Name {
+			return call.Function.Arguments, nil
+		}
+	}
+	if content.Len() == 0 {
+		return "", fmt.Errorf("%s: %w", b.name, ErrEmptyResponse)
+	}
+	return content.String(), nil
+}
+
+// toOpenAIMessage converts a message, sending attached images as inline data URLs.
+func toOpenAIMessage(msg Message) openai.ChatCompletionMessage {
+	if len(msg.Images) == 0 {
+		return openai.ChatCompletionMessage{Role: msg.Role, Content: msg.Content}
+	}
+
+	parts := []openai.ChatMessagePart{{Type: openai.ChatMessagePartTypeText, Text: msg.Content}}
+	for _, img := range msg.Images {
+		parts = append(parts, openai.ChatMessagePart{
+			Type: openai.ChatMessagePartTypeImageURL,
+			ImageURL: &openai.ChatMessageImageURL{
+				URL:    "data:image/png;base64," + base64.StdEncoding.EncodeToString(img),
+				Detail: openai.ImageURLDetailAuto,
+			},
+		})
+	}
+	return openai.ChatCompletionMessage{Role: msg.Role, MultiContent: parts}
+}
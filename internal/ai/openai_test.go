@@ -0,0 +1,104 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAIBackendSendsScreenshots(t *testing.T) {
+	var body struct {
+		Messages []struct {
+			Role    string          `json:"role"`
+			Content json.RawMessage `json:"content"`
+		} `json:"messages"`
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices": [{"message": {"role": "assistant", "content": "{\"action\": \"wait\"}"}}]}`)
+	}))
+	defer ts.Close()
+
+	client := NewClientWithBackend(NewOpenAICompatibleBackend(ts.URL+"/v1", "key", "vision-model", 0))
+	if _, err := client.MakeDecision(context.Background(), "system", "user", []byte("png-bytes")); err != nil {
+		t.Fatalf("MakeDecision failed: %v", err)
+	}
+
+	if len(body.Messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(body.Messages))
+	}
+	var parts []struct {
+		Type     string `json:"type"`
+		ImageURL struct {
+			URL string `json:"url"`
+		} `json:"image_url"`
+	}
+	if err := json.Unmarshal(body.Messages[1].Content, &parts); err != nil {
+		t.Fatalf("user message should be multi-part: %v (%s)", err, body.Messages[1].Content)
+	}
+	if len(parts) != 2 || parts[1].Type != "image_url" || !strings.HasPrefix(parts[1].ImageURL.URL, "data:image/png;base64,") {
+		t.Fatalf("screenshot not attached as an image part: %+v", parts)
+	}
+	if string(body.Messages[0].Content) != `"system"` {
+		t.Fatalf("text-only messages should stay plain strings, got %s", body.Messages[0].Content)
+	}
+}
+
+func TestOpenAIBackendReturnsFunctionArguments(t *testing.T) {
+	var body struct {
+		Tools []struct {
+			Function struct {
+				Name string `json:"name"`
+			} `json:"function"`
+		} `json:"tools"`
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices": [{"message": {"role": "assistant", "tool_calls": [{"id": "1", "type": "function",
+			"function": {"name": "decide", "arguments": "{\"action\": \"press\", \"text\": \"Enter\", \"reasoning\": \"submit\"}"}}]}}]}`)
+	}))
+	defer ts.Close()
+
+	client := NewClientWithBackend(NewOpenAICompatibleBackend(ts.URL+"/v1", "key", "model", 0))
+	decision, err := client.MakeDecision(context.Background(), "system", "user")
+	if err != nil {
+		t.Fatalf("MakeDecision failed: %v", err)
+	}
+	if decision.Action != "press" || decision.Text != "Enter" {
+		t.Fatalf("unexpected decision: %+v", decision)
+	}
+	if len(body.Tools) != 1 || body.Tools[0].Function.Name != "decide" {
+		t.Fatalf("expected the decide function to be offered, got %+v", body.Tools)
+	}
+}
+
+func TestOpenAIBackendStreamsDecision(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, part := range []string{`{\"action\": \"press\", `, `\"text\": \"Enter\", \"reasoning\": \"submit\"}`} {
+			fmt.Fprintf(w, "data: {\"choices\": [{\"index\": 0, \"delta\": {\"tool_calls\": [{\"index\": 0, \"type\": \"function\", \"function\": {\"name\": \"decide\", \"arguments\": \"%s\"}}]}}]}\n\n", part)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer ts.Close()
+
+	var streamed strings.Builder
+	client := NewClientWithBackend(NewOpenAICompatibleBackend(ts.URL+"/v1", "key", "model", 0))
+	client.SetStream(&streamed)
+	decision, err := client.MakeDecision(context.Background(), "system", "user")
+	if err != nil {
+		t.Fatalf("MakeDecision failed: %v", err)
+	}
+	if decision.Action != "press" || decision.Text != "Enter" {
+		t.Fatalf("unexpected decision: %+v", decision)
+	}
+	if got := streamed.String(); got != "{\"action\": \"press\", \"text\": \"Enter\", \"reasoning\": \"submit\"}\n" {
+		t.Fatalf("unexpected streamed output %q", got)
+	}
+}
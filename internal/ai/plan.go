@@ -0,0 +1,61 @@
+package ai
+
+import (
+	"encoding/json"
+)
+
+// StepCondition is a predicate evaluated against the live page, before a plan
+// step runs or, as Plan.DoneWhen, once the plan is through. All non-empty
+// checks must hold; Negate inverts the result.
+type StepCondition struct {
+	URLMatches      string `json:"url_matches,omitempty"` // regular expression
+	TextPresent     string `json:"text_present,omitempty"`
+	SelectorPresent string `json:"selector_present,omitempty"`
+	Negate          bool   `json:"negate,omitempty"`
+}
+
+// Plan is a task broken into steps, with the checks that tell the task is
+// done when the planner could name them.
+type Plan struct {
+	Steps    []PlanStep     `json:"steps"`
+	DoneWhen *StepCondition `json:"done_when,omitempty"`
+}
+
+// PlanStep is a single step of a task plan.
+type PlanStep struct {
+	Description string         `json:"step"`
+	If          *StepCondition `json:"if,omitempty"`
+	Manual      bool           `json:"manual,omitempty"`   // performed by the user, e.g. entering a 2FA code
+	Optional    bool           `json:"optional,omitempty"` // best-effort; failure does not fail the task
+	// Then and Else are the steps to take when If holds and when it does not.
+	// Without Then, the step itself is taken when If holds.
+	Then []PlanStep `json:"then,omitempty"`
+	Else []PlanStep `json:"else,omitempty"`
+}
+
+// Branches reports whether the step chooses between steps rather than being
+// one itself.
+func (s PlanStep) Branches() bool {
+	return len(s.Then) > 0
+}
+
+func (s PlanStep) String() string {
+	return s.Description
+}
+
+// UnmarshalJSON accepts both the object form and a bare string step.
+func (s *PlanStep) UnmarshalJSON(data []byte) error {
+	var description string
+	if err := json.Unmarshal(data, &description); err == nil {
+		*s = PlanStep{Description: description}
+		return nil
+	}
+
+	type planStep PlanStep
+	var step planStep
+	if err := json.Unmarshal(data, &step); err != nil {
+		return err
+	}
+	*s = PlanStep(step)
+	return nil
+}
@@ -0,0 +1,26 @@
+package ai
+
+import "testing"
+
+func TestParsePlan(t *testing.T) {
+	plan, err := parsePlan("```json\n" + `{"steps": [{"step": "Search for milk"}, "Add it to the cart"], "done_when": {"url_matches": "/cart", "text_present": "Milk"}}` + "\n```")
+	if err != nil {
+		t.Fatalf("parsePlan failed: %v", err)
+	}
+	if len(plan.Steps) != 2 || plan.Steps[1].Description != "Add it to the cart" {
+		t.Fatalf("unexpected steps: %+v", plan.Steps)
+	}
+	if plan.DoneWhen == nil || plan.DoneWhen.URLMatches != "/cart" || plan.DoneWhen.TextPresent != "Milk" {
+		t.Fatalf("unexpected done_when: %+v", plan.DoneWhen)
+	}
+
+	plan, err = parsePlan(`[{"step": "Search for milk"}]`)
+	if err != nil || len(plan.Steps) != 1 || plan.DoneWhen != nil {
+		t.Fatalf("a bare array should still parse: %+v, %v", plan, err)
+	}
+
+	plan, err = parsePlan("1. Open the shop\n2. Search for milk")
+	if err != nil || len(plan.Steps) != 2 || plan.Steps[0].Description != "Open the shop" {
+		t.Fatalf("a plain list should still parse: %+v, %v", plan, err)
+	}
+}
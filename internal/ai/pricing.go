@@ -0,0 +1,54 @@
+package ai
+
+import "strings"
+
+// Price is what a model charges per million tokens, in US dollars.
+type Price struct {
+	Prompt     float64
+	Completion float64
+}
+
+// Prices holds the list prices of hosted models by model name prefix; the
+// longest matching prefix wins, so dated snapshots such as
+// "gpt-4o-2024-08-06" are priced like their family. Models not listed, such
+// as local ones, are treated as free.
+var Prices = map[string]Price{
+	"gpt-3.5-turbo":     {Prompt: 0.50, Completion: 1.50},
+	"gpt-4":             {Prompt: 30, Completion: 60},
+	"gpt-4-turbo":       {Prompt: 10, Completion: 30},
+	"gpt-4o":            {Prompt: 2.50, Completion: 10},
+	"gpt-4o-mini":       {Prompt: 0.15, Completion: 0.60},
+	"gpt-4.1":           {Prompt: 2, Completion: 8},
+	"gpt-4.1-mini":      {Prompt: 0.40, Completion: 1.60},
+	"gpt-4.1-nano":      {Prompt: 0.10, Completion: 0.40},
+	"o3-mini":           {Prompt: 1.10, Completion: 4.40},
+	"o4-mini":           {Prompt: 1.10, Completion: 4.40},
+	"claude-3-haiku":    {Prompt: 0.25, Completion: 1.25},
+	"claude-3-5-haiku":  {Prompt: 0.80, Completion: 4},
+	"claude-3-5-sonnet": {Prompt: 3, Completion: 15},
+	"claude-3-7-sonnet": {Prompt: 3, Completion: 15},
+	"claude-haiku-4-5":  {Prompt: 1, Completion: 5},
+	"claude-sonnet-4":   {Prompt: 3, Completion: 15},
+	"claude-opus-4":     {Prompt: 15, Completion: 75},
+	"claude-opus-4-5":   {Prompt: 5, Completion: 25},
+}
+
+// LookupPrice returns the price of model, if it is known.
+func LookupPrice(model string) (Price, bool) {
+	model = strings.ToLower(model)
+	best := ""
+	for prefix := range Prices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return Prices[best], true
+}
+
+// Cost returns the price of the given token counts.
+func (p Price) Cost(promptTokens, completionTokens int) float64 {
+	return (float64(promptTokens)*p.Prompt + float64(completionTokens)*p.Completion) / 1e6
+}
@@ -0,0 +1,37 @@
+package ai
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestLookupPrice(t *testing.T) {
+	tests := []struct {
+		model  string
+		prompt float64
+		ok     bool
+	}{
+		{"gpt-4o-mini-2024-07-18", 0.15, true},
+		{"gpt-4o", 2.50, true},
+		{"gpt-4-turbo-preview", 10, true},
+		{"claude-sonnet-4-5", 3, true},
+		{"llama3.1", 0, false},
+	}
+	for _, tt := range tests {
+		price, ok := LookupPrice(tt.model)
+		if ok != tt.ok || price.Prompt != tt.prompt {
+			t.Errorf("LookupPrice(%q) = %+v, %v; want prompt price %v, %v", tt.model, price, ok, tt.prompt, tt.ok)
+		}
+	}
+}
+
+func TestRecordUsageAddsCost(t *testing.T) {
+	var usage Usage
+	ctx := WithUsage(context.Background(), &usage)
+	recordUsage(ctx, "gpt-4o", 1000000, 100000)
+	recordUsage(ctx, "llama3.1", 500, 50)
+	if usage.PromptTokens != 1000500 || usage.CompletionTokens != 100050 || math.Abs(usage.CostUSD-3.5) > 1e-9 {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+}
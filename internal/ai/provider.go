@@ -0,0 +1,134 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrEmptyResponse is returned by a backend that got no completion back.
+var ErrEmptyResponse = errors.New("empty response from model")
+
+// Provider is everything the agent needs from a language model. Client
+// implements it on top of any ChatBackend.
+type Provider interface {
+	Chat(ctx context.Context, req ChatRequest) (string, error)
+	PlanTask(ctx context.Context, task string, pageContext string) (Plan, error)
+	MakeDecision(ctx context.Context, systemPrompt, userInput string, screenshots ...[]byte) (DecisionResponse, error)
+	ParseUserRequest(ctx context.Context, userInput string) (UserRequestParsed, error)
+}
+
+// ChatBackend is a single chat-completion API (OpenAI, Anthropic, a local
+// model, ...). Prompting and response parsing live in Client, so a backend
+// only has to send messages and return the reply text.
+type ChatBackend interface {
+	Chat(ctx context.Context, req ChatRequest) (string, error)
+}
+
+// ChatRequest is a backend-neutral chat completion request.
+type ChatRequest struct {
+	Messages    []Message
+	Temperature float32
+	MaxTokens   int // 0 leaves the limit to the backend
+	// Function, if set, asks the backend to answer by calling this function
+	// (tool/function calling) and to return the call's JSON arguments.
+	// Backends without function calling return plain text instead.
+	Function *FunctionSpec
+	// Stream, if set, has the backend stream the reply and pass each piece of
+	// text (or of the function's JSON arguments) to it as it is generated.
+	Stream func(delta string)
+}
+
+// FunctionSpec describes a function the model must call.
+type FunctionSpec struct {
+	Name        string
+	Description string
+	Parameters  map[string]any // JSON schema of the arguments
+}
+
+// Provider names accepted by NewProvider.
+const (
+	ProviderOpenAI = "openai"
+	ProviderOllama = "ollama"
+	// ProviderOpenAICompatible is any server implementing the OpenAI chat API.
+	ProviderOpenAICompatible = "openai-compatible"
+	// ProviderAnthropic is Anthropic's Messages API (Claude models).
+	ProviderAnthropic = "anthropic"
+)
+
+// defaultOllamaURL and defaultOllamaModel target a stock local Ollama install.
+const (
+	defaultOllamaURL   = "http://localhost:11434/v1"
+	defaultOllamaModel = "llama3.1"
+)
+
+// ProviderConfig selects and configures a provider.
+type ProviderConfig struct {
+	Name    string // "" means OpenAI
+	APIKey  string
+	BaseURL string        // for local/compatible providers, or an Anthropic proxy
+	Model   string        // provider default if empty
+	Timeout time.Duration // per attempt; 0 means no timeout
+
+	// Cache, if set, answers identical requests with earlier replies.
+	Cache *ResponseCache
+}
+
+// NewProvider returns the provider selected by cfg.Name.
+func NewProvider(cfg ProviderConfig) (Provider, error) {
+	backend, err := newBackend(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Cache != nil {
+		backend = cfg.Cache.Wrap(backend, strings.ToLower(cfg.Name)+"|"+cfg.BaseURL+"|"+backendModel(backend))
+	}
+	return NewClientWithBackend(backend), nil
+}
+
+// backendModel returns the model a backend talks to, which may come from the
+// environment rather than the config.
+func backendModel(backend ChatBackend) string {
+	switch b := backend.(type) {
+	case *OpenAIBackend:
+		return b.model
+	case *AnthropicBackend:
+		return b.model
+	}
+	return ""
+}
+
+// newBackend returns the chat backend selected by cfg.Name.
+func newBackend(cfg ProviderConfig) (ChatBackend, error) {
+	name := strings.ToLower(strings.TrimSpace(cfg.Name))
+	switch name {
+	case "", ProviderOpenAI:
+		backend := NewOpenAIBackend(cfg.APIKey)
+		if cfg.Model != "" {
+			backend.model = cfg.Model
+		}
+		return backend, nil
+	case ProviderOllama, ProviderOpenAICompatible:
+		baseURL := cfg.BaseURL
+		if baseURL == "" {
+			if name != ProviderOllama {
+				return nil, fmt.Errorf("provider %q needs a base URL", cfg.Name)
+			}
+			baseURL = defaultOllamaURL
+		}
+		model := cfg.Model
+		if model == "" {
+			if name != ProviderOllama {
+				return nil, fmt.Errorf("provider %q needs a model name", cfg.Name)
+			}
+			model = defaultOllamaModel
+		}
+		return NewOpenAICompatibleBackend(baseURL, cfg.APIKey, model, cfg.Timeout), nil
+	case ProviderAnthropic:
+		return NewAnthropicBackend(cfg.BaseURL, cfg.APIKey, cfg.Model, cfg.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown AI provider %q", cfg.Name)
+	}
+}
@@ -0,0 +1,95 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeBackend replies with canned responses and records the requests it saw.
+type fakeBackend struct {
+	replies  []string
+	requests []ChatRequest
+}
+
+func (f *fakeBackend) Chat(ctx context.Context, req ChatRequest) (string, error) {
+	f.requests = append(f.requests, req)
+	if len(f.replies) == 0 {
+		return "", ErrEmptyResponse
+	}
+	reply := f.replies[0]
+	f.replies = f.replies[1:]
+	return reply, nil
+}
+
+func TestClientParsesBackendReplies(t *testing.T) {
+	backend := &fakeBackend{replies: []string{
+		"```json\n{\"action\": \"click\", \"selector\": \"#go\", \"reasoning\": \"Go\"}\n```",
+		"1. Open the site\n2. Search for Kremlin",
+	}}
+	var provider Provider = NewClientWithBackend(backend)
+	ctx := context.Background()
+
+	decision, err := provider.MakeDecision(ctx, "system", "user")
+	if err != nil {
+		t.Fatalf("MakeDecision failed: %v", err)
+	}
+	if decision.Action != "click" || decision.Selector != "#go" || decision.SchemaVersion != DecisionSchemaVersion {
+		t.Fatalf("unexpected decision: %+v", decision)
+	}
+	if got := backend.requests[0].Messages; len(got) != 2 || got[0].Role != RoleSystem || got[1].Content != "user" {
+		t.Fatalf("unexpected messages sent to backend: %+v", got)
+	}
+
+	plan, err := provider.PlanTask(ctx, "find the Kremlin", "page")
+	if err != nil {
+		t.Fatalf("PlanTask failed: %v", err)
+	}
+	if len(plan.Steps) != 2 || plan.Steps[1].Description != "Search for Kremlin" {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	if _, err := NewProvider(ProviderConfig{Name: "nonexistent"}); err == nil {
+		t.Fatalf("expected an error for an unknown provider")
+	}
+	if _, err := NewProvider(ProviderConfig{APIKey: "key"}); err != nil {
+		t.Fatalf("default provider should be OpenAI: %v", err)
+	}
+}
+
+func TestOllamaProviderUsesLocalEndpoint(t *testing.T) {
+	var gotPath, gotModel string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		var body struct {
+			Model string `json:"model"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		gotModel = body.Model
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices": [{"message": {"role": "assistant", "content": "{\"action\": \"complete\", \"reasoning\": \"done\"}"}}]}`)
+	}))
+	defer ts.Close()
+
+	provider, err := NewProvider(ProviderConfig{Name: ProviderOllama, BaseURL: ts.URL + "/v1", Model: "qwen2.5", Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	decision, err := provider.MakeDecision(context.Background(), "system", "user")
+	if err != nil {
+		t.Fatalf("MakeDecision against local endpoint failed: %v", err)
+	}
+	if decision.Action != "complete" || gotPath != "/v1/chat/completions" || gotModel != "qwen2.5" {
+		t.Fatalf("unexpected result: decision=%+v path=%s model=%s", decision, gotPath, gotModel)
+	}
+
+	if _, err := NewProvider(ProviderConfig{Name: ProviderOpenAICompatible}); err == nil {
+		t.Fatalf("openai-compatible provider without a base URL should fail")
+	}
+}
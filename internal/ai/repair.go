@@ -0,0 +1,70 @@
+package ai
+
+import "strings"
+
+// repairJSON fixes the usual ways a model mangles a JSON object: prose or a
+// code fence around it, a second object after it, and trailing commas. It
+// returns false when there is no object to repair.
+func repairJSON(content string) (string, bool) {
+	object, ok := firstJSONObject(content)
+	if !ok {
+		return "", false
+	}
+	return stripTrailingCommas(object), true
+}
+
+// firstJSONObject returns the first balanced {...} in s, ignoring braces
+// inside strings.
+func firstJSONObject(s string) (string, bool) {
+	start := strings.IndexByte(s, '{')
+	if start == -1 {
+		return "", false
+	}
+	depth := 0
+	inString, escaped := false, false
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+			if depth == 0 {
+				return s[start : i+1], true
+			}
+		}
+	}
+	return "", false
+}
+
+// stripTrailingCommas drops commas directly before a closing brace or
+// bracket, outside strings.
+func stripTrailingCommas(s string) string {
+	var b strings.Builder
+	inString, escaped := false, false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case !inString && c == ',':
+			rest := strings.TrimLeft(s[i+1:], " \t\r\n")
+			if rest != "" && (rest[0] == '}' || rest[0] == ']') {
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
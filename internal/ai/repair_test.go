@@ -0,0 +1,58 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRepairJSON(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+		ok             bool
+	}{
+		{"trailing commas", `{"action": "wait", "items": [1, 2,], }`, `{"action": "wait", "items": [1, 2] }`, true},
+		{"prose around", `Sure! {"action": "wait"} Hope this helps {"x": 1}`, `{"action": "wait"}`, true},
+		{"braces in strings", `{"text": "a } and , }", "n": {"m": 1,},}`, `{"text": "a } and , }", "n": {"m": 1}}`, true},
+		{"escaped quote", `{"text": "say \"}\"",}`, `{"text": "say \"}\""}`, true},
+		{"unterminated", `{"action": "wait"`, "", false},
+		{"no object", `click the button`, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := repairJSON(tt.in)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("%s: repairJSON(%q) = %q, %v; want %q, %v", tt.name, tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseDecisionRepairsJSON(t *testing.T) {
+	decision, err := parseDecision("Here is my decision:\n{\"action\": \"click\", \"selector\": \"#go\", \"reasoning\": \"Go\",}\nLet me know.")
+	if err != nil {
+		t.Fatalf("expected repaired decision, got %v", err)
+	}
+	if decision.Action != string(ActionClick) || decision.Selector != "#go" {
+		t.Fatalf("unexpected decision: %+v", decision)
+	}
+}
+
+func TestMakeDecisionReasksWithParseError(t *testing.T) {
+	backend := &fakeBackend{replies: []string{
+		`I think we should click "Go"`,
+		`{"action": "click", "selector": "#go", "reasoning": "Go"}`,
+	}}
+	decision, err := NewClientWithBackend(backend).MakeDecision(context.Background(), "system", "user")
+	if err != nil || decision.Selector != "#go" {
+		t.Fatalf("expected decision after re-ask, got %+v, %v", decision, err)
+	}
+	retry := backend.requests[1].Messages
+	if last := retry[len(retry)-1].Content; !strings.Contains(last, "invalid character") || !strings.Contains(last, "single JSON decision object") {
+		t.Fatalf("re-ask should include the parse error: %q", last)
+	}
+
+	backend = &fakeBackend{replies: []string{"nope", "still nope"}}
+	decision, err = NewClientWithBackend(backend).MakeDecision(context.Background(), "system", "user")
+	if err == nil || decision.Action != string(ActionError) {
+		t.Fatalf("expected an error decision, got %+v, %v", decision, err)
+	}
+}
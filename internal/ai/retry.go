@@ -0,0 +1,168 @@
+package ai
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy bounds the retries of transient API failures: rate limits
+// (429), server errors and overloads (5xx), timeouts and dropped connections.
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts, the first one included
+	BaseDelay   time.Duration // wait before the first retry, doubled for each next one
+	MaxDelay    time.Duration // longest single wait; a longer Retry-After gives up instead
+}
+
+// DefaultRetryPolicy is used by every backend created by this package.
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: time.Minute}
+
+// retryableStatuses are the HTTP statuses worth retrying. 529 is Anthropic's
+// "overloaded".
+var retryableStatuses = map[int]bool{
+	http.StatusTooManyRequests:     true,
+	http.StatusInternalServerError: true,
+	http.StatusBadGateway:          true,
+	http.StatusServiceUnavailable:  true,
+	http.StatusGatewayTimeout:      true,
+	529:                            true,
+}
+
+// newHTTPClient returns the HTTP client of an API backend: each attempt gets
+// timeout (0 means none) and transient failures are retried under policy.
+func newHTTPClient(timeout time.Duration, policy RetryPolicy) *http.Client {
+	return &http.Client{Transport: &retryTransport{
+		base:    http.DefaultTransport,
+		policy:  policy,
+		timeout: timeout,
+		sleep:   sleepContext,
+	}}
+}
+
+// retryTransport retries requests with jittered exponential backoff, honoring
+// the server's Retry-After. Retrying at this level covers every call of a
+// backend, whatever client library it uses.
+type retryTransport struct {
+	base    http.RoundTripper
+	policy  RetryPolicy
+	timeout time.Duration // per attempt
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		attemptReq, cancel, err := t.prepare(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := t.base.RoundTrip(attemptReq)
+
+		wait, retry := t.backoff(ctx, resp, err, attempt)
+		if !retry || attempt >= t.policy.MaxAttempts || (req.Body != nil && req.GetBody == nil) {
+			if resp != nil {
+				resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			} else {
+				cancel()
+			}
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		cancel()
+		if err := t.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// prepare returns the request of an attempt, with a fresh body for retries
+// and the per-attempt timeout.
+func (t *retryTransport) prepare(req *http.Request, attempt int) (*http.Request, context.CancelFunc, error) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if t.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+	}
+	attemptReq := req.WithContext(ctx)
+	if attempt > 1 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, nil, err
+		}
+		attemptReq.Body = body
+	}
+	return attemptReq, cancel, nil
+}
+
+// backoff reports whether an attempt should be retried and how long to wait
+// first: the server's Retry-After if it sent one, otherwise BaseDelay doubled
+// per attempt with jitter.
+func (t *retryTransport) backoff(ctx context.Context, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if ctx.Err() != nil {
+		return 0, false
+	}
+	if err == nil && !retryableStatuses[resp.StatusCode] {
+		return 0, false
+	}
+	if resp != nil {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			return wait, wait <= t.policy.MaxDelay
+		}
+	}
+	wait := t.policy.BaseDelay << (attempt - 1)
+	if wait <= 0 || wait > t.policy.MaxDelay {
+		wait = t.policy.MaxDelay
+	}
+	// Full jitter over the upper half spreads out clients that failed together.
+	wait = wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+	return wait, true
+}
+
+// parseRetryAfter reads a Retry-After header in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// cancelOnClose releases an attempt's timeout once its response is read.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
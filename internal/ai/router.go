@@ -0,0 +1,122 @@
+package ai
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// defaultRouterConfidence is the confidence below which Router asks the
+// strong model to reconsider a fast model's decision.
+const defaultRouterConfidence = 0.6
+
+// Router is a Provider that sends routine per-step decisions to a small, fast
+// model and escalates to a larger one where it matters: planning, parsing the
+// user's request, decisions the fast model fails to make or is unsure of, and
+// the decisions following a failed action (see Escalate).
+type Router struct {
+	Fast   Provider
+	Strong Provider
+	// MinConfidence is the reported confidence below which a fast decision
+	// is escalated. Decisions without a confidence are trusted.
+	MinConfidence float64
+
+	mu        sync.Mutex
+	escalated int // decisions still to be sent to Strong
+}
+
+// NewRouter returns a Router over the two providers.
+func NewRouter(fast, strong Provider) *Router {
+	return &Router{Fast: fast, Strong: strong, MinConfidence: defaultRouterConfidence}
+}
+
+// Escalate sends the next n decisions to the strong model, e.g. after an
+// action failed and the fast model's choices are suspect.
+func (r *Router) Escalate(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if n > r.escalated {
+		r.escalated = n
+	}
+}
+
+// takeEscalation reports whether the next decision goes to the strong model.
+func (r *Router) takeEscalation() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.escalated == 0 {
+		return false
+	}
+	r.escalated--
+	return true
+}
+
+// Chat sends raw requests, such as summaries, to the fast model.
+func (r *Router) Chat(ctx context.Context, req ChatRequest) (string, error) {
+	return r.Fast.Chat(ctx, req)
+}
+
+// PlanTask plans with the strong model.
+func (r *Router) PlanTask(ctx context.Context, task string, pageContext string) (Plan, error) {
+	return r.Strong.PlanTask(ctx, task, pageContext)
+}
+
+// ParseUserRequest parses with the strong model.
+func (r *Router) ParseUserRequest(ctx context.Context, userInput string) (UserRequestParsed, error) {
+	return r.Strong.ParseUserRequest(ctx, userInput)
+}
+
+// MakeDecision asks the fast model unless an escalation is pending, and asks
+// the strong model again when the fast one errs, gives up with an "error"
+// action, or reports a confidence below MinConfidence.
+func (r *Router) MakeDecision(ctx context.Context, systemPrompt, userInput string, screenshots ...[]byte) (DecisionResponse, error) {
+	if r.takeEscalation() {
+		return r.Strong.MakeDecision(ctx, systemPrompt, userInput, screenshots...)
+	}
+	decision, err := r.Fast.MakeDecision(ctx, systemPrompt, userInput, screenshots...)
+	if err == nil && !r.unsure(decision) {
+		return decision, nil
+	}
+	if ctx.Err() != nil {
+		return decision, err
+	}
+	return r.Strong.MakeDecision(ctx, systemPrompt, userInput, screenshots...)
+}
+
+// unsure reports whether a fast decision should be reconsidered.
+func (r *Router) unsure(decision DecisionResponse) bool {
+	if NormalizeAction(decision.Action) == ActionError {
+		return true
+	}
+	return decision.Confidence > 0 && decision.Confidence < r.MinConfidence
+}
+
+// SetStream implements Streamer for whichever of the models can stream.
+func (r *Router) SetStream(w io.Writer) {
+	for _, provider := range []Provider{r.Fast, r.Strong} {
+		if streamer, ok := provider.(Streamer); ok {
+			streamer.SetStream(w)
+		}
+	}
+}
+
+// CondenseForAnalysis summarizes long text with the fast model when it can.
+func (r *Router) CondenseForAnalysis(ctx context.Context, content string, task string) (string, error) {
+	if condenser, ok := r.Fast.(interface {
+		CondenseForAnalysis(ctx context.Context, content string, task string) (string, error)
+	}); ok {
+		return condenser.CondenseForAnalysis(ctx, content, task)
+	}
+	return content, nil
+}
+
+// VerifyStep checks a step with the fast model when it can, and trusts the
+// step otherwise.
+func (r *Router) VerifyStep(ctx context.Context, step, action, pageDiff string) (Verification, error) {
+	if verifier, ok := r.Fast.(interface {
+		VerifyStep(ctx context.Context, step, action, pageDiff string) (Verification, error)
+	}); ok {
+		return verifier.VerifyStep(ctx, step, action, pageDiff)
+	}
+	return Verification{Achieved: true}, nil
+}
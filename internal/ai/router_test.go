@@ -0,0 +1,49 @@
+package ai
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRouterSendsRoutineDecisionsToFastModel(t *testing.T) {
+	fast := &fakeBackend{replies: []string{`{"action": "click", "selector": "#go", "reasoning": "go", "confidence": 0.9}`}}
+	strong := &fakeBackend{replies: []string{"1. Open the site"}}
+	router := NewRouter(NewClientWithBackend(fast), NewClientWithBackend(strong))
+	ctx := context.Background()
+
+	if _, err := router.PlanTask(ctx, "task", "page"); err != nil {
+		t.Fatalf("PlanTask failed: %v", err)
+	}
+	decision, err := router.MakeDecision(ctx, "system", "user")
+	if err != nil {
+		t.Fatalf("MakeDecision failed: %v", err)
+	}
+	if decision.Selector != "#go" || len(fast.requests) != 1 || len(strong.requests) != 1 {
+		t.Fatalf("unexpected routing: decision=%+v fast=%d strong=%d", decision, len(fast.requests), len(strong.requests))
+	}
+}
+
+func TestRouterEscalates(t *testing.T) {
+	fast := &fakeBackend{replies: []string{
+		`{"action": "click", "selector": "#maybe", "reasoning": "guess", "confidence": 0.3}`,
+		`{"action": "error", "reasoning": "stuck"}`,
+	}}
+	strong := &fakeBackend{replies: []string{
+		`{"action": "click", "selector": "#sure", "reasoning": "sure"}`,
+		`{"action": "wait", "reasoning": "loading"}`,
+		`{"action": "scroll", "reasoning": "after a failure"}`,
+	}}
+	router := NewRouter(NewClientWithBackend(fast), NewClientWithBackend(strong))
+	ctx := context.Background()
+
+	if decision, _ := router.MakeDecision(ctx, "system", "unsure"); decision.Selector != "#sure" {
+		t.Fatalf("low-confidence decision should be escalated, got %+v", decision)
+	}
+	if decision, _ := router.MakeDecision(ctx, "system", "stuck"); decision.Action != "wait" {
+		t.Fatalf("error decision should be escalated, got %+v", decision)
+	}
+	router.Escalate(1)
+	if decision, _ := router.MakeDecision(ctx, "system", "failed"); decision.Action != "scroll" || len(fast.requests) != 2 {
+		t.Fatalf("escalated decision should skip the fast model, got %+v (fast calls %d)", decision, len(fast.requests))
+	}
+}
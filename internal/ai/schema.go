@@ -0,0 +1,275 @@
+package ai
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// DecisionSchemaVersion is the version of the DecisionResponse shape described
+// to the model. Bump it whenever fields or actions change meaning.
+const DecisionSchemaVersion = 1
+
+// ActionType is a single action the agent knows how to execute.
+type ActionType string
+
+const (
+	ActionNavigate   ActionType = "navigate"
+	ActionSearch     ActionType = "search"
+	ActionFetch      ActionType = "fetch"
+	ActionBack       ActionType = "back"
+	ActionForward    ActionType = "forward"
+	ActionReload     ActionType = "reload"
+	ActionClick      ActionType = "click"
+	ActionFill       ActionType = "fill"
+	ActionFocus      ActionType = "focus"
+	ActionHover      ActionType = "hover"
+	ActionTypeText   ActionType = "type"
+	ActionPress      ActionType = "press"
+	ActionSelect     ActionType = "select_option"
+	ActionCheck      ActionType = "check"
+	ActionUncheck    ActionType = "uncheck"
+	ActionUpload     ActionType = "upload"
+	ActionScroll     ActionType = "scroll"
+	ActionSwitchTab  ActionType = "switch_tab"
+	ActionScrape     ActionType = "scrape"
+	ActionCrawl      ActionType = "crawl"
+	ActionExtract    ActionType = "extract"
+	ActionArticle    ActionType = "read_article"
+	ActionScreenshot ActionType = "screenshot"
+	ActionSkill      ActionType = "skill"
+	ActionSave       ActionType = "save"
+	ActionAssert     ActionType = "assert"
+	ActionEvaluate   ActionType = "evaluate"
+	ActionWait       ActionType = "wait"
+	ActionWaitFor    ActionType = "wait_for"
+	ActionPause      ActionType = "pause"
+	ActionClarify    ActionType = "clarify"
+	ActionComplete   ActionType = "complete"
+	ActionError      ActionType = "error"
+)
+
+type actionSpec struct {
+	Type        ActionType
+	Description string
+	Aliases     []string
+}
+
+// actionSpecs is the single source of truth for the actions exposed to the model.
+var actionSpecs = []actionSpec{
+	{ActionNavigate, "go to a URL (set url)", nil},
+	{ActionSearch, "search the web and get the top results with their URLs, instead of operating a search page yourself (set text to the query), then navigate to the best result", []string{"web_search", "search_web"}},
+	{ActionFetch, "read a static page such as docs, an article or a listing over plain HTTP without moving the browser; much faster than navigate when you only need to read it (set url). Pages that need JavaScript are opened in the browser instead", []string{"http_get", "read_url"}},
+	{ActionBack, "return to the previous page of this tab, e.g. the search results after opening the wrong link", []string{"go_back"}},
+	{ActionForward, "go forward again after back", []string{"go_forward"}},
+	{ActionReload, "reload the current page", []string{"refresh"}},
+	{ActionClick, "click a button or link (set selector)", nil},
+	{ActionFill, "replace the value of a form field (set selector and text)", []string{"input"}},
+	{ActionFocus, "focus an element before typing (set selector)", nil},
+	{ActionHover, "move the mouse over an element to reveal a hover menu or tooltip (set selector)", []string{"mouse_move", "mouseover"}},
+	{ActionTypeText, "type text character by character (set selector and text)", nil},
+	{ActionPress, "press a keyboard key (set text to the key name, e.g. \"Enter\")", []string{"keypress", "key"}},
+	{ActionSelect, "choose an option of a <select> dropdown (set selector and text to the option label or value)", []string{"select"}},
+	{ActionCheck, "tick a checkbox or pick a radio button (set selector)", nil},
+	{ActionUncheck, "clear a checkbox (set selector)", nil},
+	{ActionUpload, "attach local files to a file input (set selector and text to the file path; separate several paths with commas). The user always confirms uploads", []string{"upload_file", "set_input_files"}},
+	{ActionScroll, "scroll the page: set selector to scroll to an element, or text to \"down\", \"up\", \"bottom\", \"top\" or a pixel offset; text \"more\" keeps scrolling an infinite feed until min_count items match selector or nothing new loads", nil},
+	{ActionSwitchTab, "switch to another open tab (set text to the tab index or part of its title/URL)", []string{"switch"}},
+	{ActionScrape, "collect the text of every element matching selector (set min_count to the fewest results that count as success)", nil},
+	{ActionCrawl, "collect the text of every element matching selector on this page and the result pages after it, following the next-page link (set max_pages, e.g. 5 for \"the first 5 pages\"; optionally set text to the selector of the next-page control)", []string{"scrape_pages", "paginate"}},
+	{ActionExtract, "read the page's tables, lists and schema.org JSON-LD metadata as structured data (optionally set text to \"tables\", \"lists\" or \"json_ld\", and selector to limit tables and lists to one element)", []string{"extract_data", "structured_data"}},
+	{ActionArticle, "read the main text of an article or blog post without menus and footers, e.g. to summarize the page or answer questions about it", []string{"readability", "read_page"}},
+	{ActionEvaluate, "run JavaScript in the page and get its JSON result, to read computed values or trigger behavior no element exposes (set text to the expression). Use it only when no other action works; the user always confirms it and it may be disabled", []string{"eval", "run_js"}},
+	{ActionScreenshot, "save a screenshot as evidence of the result (optionally set selector to capture one element)", nil},
+	{ActionAssert, "check the page for a verification task and record pass or fail: set text to text that must be on the page, selector to an element that must exist and/or url to a regular expression the URL must match; set negate to check that they are absent, and optionally timeout to keep checking for that many seconds. A failed check fails the task, so never act to make a check pass", []string{"check_page", "expect"}},
+	{ActionSave, "save a value for later steps: set save_as to a name and text to the value you read, or selector to save that element's text (a link's URL, a field's value); later actions refer to it as $name", []string{"set_variable", "remember"}},
+	{ActionSkill, "run one of the skills listed under Skills, a custom action of this deployment (set skill to its name and text to its input)", []string{"run_skill"}},
+	{ActionWait, "wait for page load or manual intervention such as a CAPTCHA; for pages that keep loading after \"load\", set text to \"networkidle\" or \"domcontentloaded\" (and optionally timeout in seconds)", nil},
+	{ActionWaitFor, "wait until an element appears, e.g. search results loading (set selector; optionally timeout in seconds)", []string{"wait_for_selector", "wait_for_element"}},
+	{ActionPause, "stop until the user finishes a manual step such as 2FA (explain what to do in reasoning)", nil},
+	{ActionClarify, "ask the user a question when the task leaves out something you need and guessing would likely do the wrong thing, e.g. which restaurant and what time for \"book a table\" (set text to the question); the answer is added to the task. Do not ask about what you can find on the page", []string{"ask_user", "ask"}},
+	{ActionComplete, "the task is finished (put the answer or requested information in text)", nil},
+	{ActionError, "no progress is possible", nil},
+}
+
+// Actions returns every action type known to the decision schema.
+func Actions() []ActionType {
+	actions := make([]ActionType, 0, len(actionSpecs))
+	for _, spec := range actionSpecs {
+		actions = append(actions, spec.Type)
+	}
+	return actions
+}
+
+// NormalizeAction maps a raw action string (including aliases) onto its ActionType.
+// Unknown actions are returned lower-cased as-is.
+func NormalizeAction(action string) ActionType {
+	action = strings.ToLower(strings.TrimSpace(action))
+	for _, spec := range actionSpecs {
+		if string(spec.Type) == action {
+			return spec.Type
+		}
+		for _, alias := range spec.Aliases {
+			if alias == action {
+				return spec.Type
+			}
+		}
+	}
+	return ActionType(action)
+}
+
+// ActionsPrompt lists the valid actions for inclusion in a system prompt.
+func ActionsPrompt() string {
+	var b strings.Builder
+	b.WriteString("Valid actions:\n")
+	for _, spec := range actionSpecs {
+		fmt.Fprintf(&b, "- %s: %s\n", spec.Type, spec.Description)
+	}
+	return b.String()
+}
+
+// DecisionFieldsPrompt describes the JSON fields of DecisionResponse. It is
+// generated from the struct tags so the prompt and the parser never disagree.
+func DecisionFieldsPrompt() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Return a JSON object (schema_version %d) with:\n", DecisionSchemaVersion)
+	t := reflect.TypeOf(DecisionResponse{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		desc := field.Tag.Get("desc")
+		if name == "" || name == "-" || desc == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "- %s: %s\n", name, desc)
+	}
+	return b.String()
+}
+
+// DecisionPrompt combines the action list and field description.
+func DecisionPrompt() string {
+	return ActionsPrompt() + "\n" + DecisionFieldsPrompt()
+}
+
+// variableName is the form of a save_as name.
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// decisionRequiredFields are the fields every decision must set.
+var decisionRequiredFields = []string{"action", "reasoning"}
+
+// DecisionJSONSchema returns a JSON schema for DecisionResponse, generated
+// from the same struct tags as DecisionFieldsPrompt, with the action enum.
+func DecisionJSONSchema() map[string]any {
+	properties := map[string]any{}
+	t := reflect.TypeOf(DecisionResponse{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		desc := field.Tag.Get("desc")
+		if name == "" || name == "-" || desc == "" {
+			continue
+		}
+		prop := map[string]any{"description": desc}
+		switch field.Type.Kind() {
+		case reflect.Bool:
+			prop["type"] = "boolean"
+		case reflect.Int:
+			prop["type"] = "integer"
+		case reflect.Float64:
+			prop["type"] = "number"
+		default:
+			prop["type"] = "string"
+		}
+		properties[name] = prop
+	}
+
+	actions := make([]string, 0, len(actionSpecs))
+	for _, spec := range actionSpecs {
+		actions = append(actions, string(spec.Type))
+	}
+	properties["action"].(map[string]any)["enum"] = actions
+
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+		"required":   decisionRequiredFields,
+	}
+}
+
+// ValidateDecision checks that a decision names a known action and carries
+// the fields that action needs, so malformed output is caught before execution.
+func ValidateDecision(d DecisionResponse) error {
+	action := NormalizeAction(d.Action)
+	known := false
+	for _, spec := range actionSpecs {
+		if spec.Type == action {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return fmt.Errorf("unknown action %q", d.Action)
+	}
+
+	switch action {
+	case ActionNavigate, ActionFetch:
+		if d.URL == "" {
+			return fmt.Errorf("%s requires url", action)
+		}
+	case ActionClick, ActionFocus, ActionHover, ActionScrape, ActionCrawl, ActionCheck, ActionUncheck, ActionWaitFor:
+		if d.Selector == "" && d.Element <= 0 {
+			return fmt.Errorf("%s requires selector", action)
+		}
+	case ActionFill, ActionTypeText, ActionSelect, ActionUpload:
+		if (d.Selector == "" && d.Element <= 0) || d.Text == "" {
+			return fmt.Errorf("%s requires selector and text", action)
+		}
+	case ActionPress:
+		if d.Text == "" {
+			return fmt.Errorf("%s requires text (the key name)", action)
+		}
+	case ActionSearch:
+		if d.Text == "" {
+			return fmt.Errorf("%s requires text (the query)", action)
+		}
+	case ActionEvaluate:
+		if d.Text == "" {
+			return fmt.Errorf("%s requires text (the script)", action)
+		}
+	case ActionClarify:
+		if d.Text == "" {
+			return fmt.Errorf("%s requires text (the question)", action)
+		}
+	case ActionSkill:
+		if d.Skill == "" {
+			return fmt.Errorf("%s requires skill (the skill name)", action)
+		}
+	case ActionAssert:
+		if d.Text == "" && d.Selector == "" && d.Element <= 0 && d.URL == "" {
+			return fmt.Errorf("%s requires text, selector or url", action)
+		}
+		if d.URL != "" {
+			if _, err := regexp.Compile(d.URL); err != nil {
+				return fmt.Errorf("%s url must be a regular expression: %w", action, err)
+			}
+		}
+	case ActionSave:
+		if d.SaveAs == "" || (d.Text == "" && d.Selector == "" && d.Element <= 0) {
+			return fmt.Errorf("%s requires save_as and text or selector", action)
+		}
+	}
+	if d.SaveAs != "" && !variableName.MatchString(d.SaveAs) {
+		return fmt.Errorf("save_as %q must be a name of letters, digits and underscores", d.SaveAs)
+	}
+	if d.MaxPages < 0 {
+		return fmt.Errorf("max_pages %d must not be negative", d.MaxPages)
+	}
+	if d.Timeout < 0 {
+		return fmt.Errorf("timeout %d must not be negative", d.Timeout)
+	}
+	if d.Confidence < 0 || d.Confidence > 1 {
+		return fmt.Errorf("confidence %.2f is outside 0.0-1.0", d.Confidence)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package ai
+
+import (
+	"context"
+	"testing"
+)
+
+func TestValidateDecision(t *testing.T) {
+	tests := []struct {
+		name     string
+		decision DecisionResponse
+		valid    bool
+	}{
+		{"click with selector", DecisionResponse{Action: "click", Selector: "#go"}, true},
+		{"alias", DecisionResponse{Action: "input", Selector: "#q", Text: "kremlin"}, true},
+		{"complete without fields", DecisionResponse{Action: "complete"}, true},
+		{"unknown action", DecisionResponse{Action: "teleport"}, false},
+		{"click without selector", DecisionResponse{Action: "click"}, false},
+		{"click by element number", DecisionResponse{Action: "click", Element: 17}, true},
+		{"fill by element number", DecisionResponse{Action: "fill", Element: 3, Text: "kremlin"}, true},
+		{"navigate without url", DecisionResponse{Action: "navigate"}, false},
+		{"fill without text", DecisionResponse{Action: "fill", Selector: "#q"}, false},
+		{"select with option", DecisionResponse{Action: "select_option", Selector: "#city", Text: "Moscow"}, true},
+		{"select without option", DecisionResponse{Action: "select", Selector: "#city"}, false},
+		{"check without selector", DecisionResponse{Action: "check"}, false},
+		{"hover alias", DecisionResponse{Action: "mouseover", Selector: "#menu"}, true},
+		{"hover without selector", DecisionResponse{Action: "hover"}, false},
+		{"wait_for with selector", DecisionResponse{Action: "wait_for", Selector: "#results", Timeout: 5}, true},
+		{"wait_for without selector", DecisionResponse{Action: "wait_for_selector"}, false},
+		{"negative timeout", DecisionResponse{Action: "wait_for", Selector: "#results", Timeout: -1}, false},
+		{"evaluate with script", DecisionResponse{Action: "eval", Text: "document.title"}, true},
+		{"evaluate without script", DecisionResponse{Action: "evaluate"}, false},
+		{"extract without fields", DecisionResponse{Action: "extract"}, true},
+		{"read_article alias", DecisionResponse{Action: "readability"}, true},
+		{"crawl with selector", DecisionResponse{Action: "crawl", Selector: ".price", MaxPages: 5}, true},
+		{"crawl without selector", DecisionResponse{Action: "paginate", MaxPages: 5}, false},
+		{"negative max_pages", DecisionResponse{Action: "crawl", Selector: ".price", MaxPages: -1}, false},
+		{"search with query", DecisionResponse{Action: "web_search", Text: "kremlin opening hours"}, true},
+		{"search without query", DecisionResponse{Action: "search"}, false},
+		{"fetch with url", DecisionResponse{Action: "http_get", URL: "https://example.com"}, true},
+		{"fetch without url", DecisionResponse{Action: "fetch"}, false},
+		{"scrape without selector", DecisionResponse{Action: "scrape"}, false},
+		{"confidence out of range", DecisionResponse{Action: "wait", Confidence: 1.5}, false},
+	}
+	for _, tt := range tests {
+		if err := ValidateDecision(tt.decision); (err == nil) != tt.valid {
+			t.Errorf("%s: ValidateDecision() = %v, want valid=%v", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestDecisionJSONSchemaListsActions(t *testing.T) {
+	schema := DecisionJSONSchema()
+	props := schema["properties"].(map[string]any)
+	enum := props["action"].(map[string]any)["enum"].([]string)
+	if len(enum) != len(Actions()) {
+		t.Fatalf("action enum has %d entries, want %d", len(enum), len(Actions()))
+	}
+	if props["confidence"].(map[string]any)["type"] != "number" {
+		t.Fatalf("confidence should be a number: %+v", props["confidence"])
+	}
+}
+
+func TestMakeDecisionRetriesInvalidDecision(t *testing.T) {
+	backend := &fakeBackend{replies: []string{
+		`{"action": "click", "reasoning": "no selector"}`,
+		`{"action": "click", "selector": "#search", "reasoning": "fixed"}`,
+	}}
+	decision, err := NewClientWithBackend(backend).MakeDecision(context.Background(), "system", "user")
+	if err != nil {
+		t.Fatalf("expected corrected decision, got %v", err)
+	}
+	if decision.Selector != "#search" || len(backend.requests) != 2 {
+		t.Fatalf("unexpected result: %+v after %d requests", decision, len(backend.requests))
+	}
+	if backend.requests[0].Function == nil || backend.requests[0].Function.Name != "decide" {
+		t.Fatalf("decision should be requested via function calling")
+	}
+	retry := backend.requests[1].Messages
+	if last := retry[len(retry)-1]; last.Role != RoleUser || last.Content == "user" {
+		t.Fatalf("retry should tell the model why the decision was rejected: %+v", last)
+	}
+
+	backend = &fakeBackend{replies: []string{`{"action": "fly"}`, `{"action": "fly"}`}}
+	if _, err := NewClientWithBackend(backend).MakeDecision(context.Background(), "system", "user"); err == nil {
+		t.Fatalf("expected persistently invalid decisions to fail")
+	}
+}
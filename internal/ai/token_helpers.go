@@ -3,17 +3,13 @@ package ai
 import (
 	"regexp"
 	"strings"
+
+	"github.com/VolodyaPopov923/AIBot/internal/tokens"
 )
 
-// approxTokens returns an approximate token count for a piece of text.
-// This is a heuristic (average 4 characters per token) and is used only for budgeting.
+// approxTokens returns the token count of a piece of text, used for budgeting.
 func approxTokens(s string) int {
-	if s == "" {
-		return 0
-	}
-	// collapse spaces to get better estimate
-	normalized := strings.Join(strings.Fields(s), " ")
-	return (len(normalized) + 3) / 4
+	return tokens.Count(s)
 }
 
 var sentenceSplitRE = regexp.MustCompile(`(?m)([^.!?\n]+[.!?\n]?)`)
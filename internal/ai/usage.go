@@ -0,0 +1,49 @@
+package ai
+
+import "context"
+
+// Usage is the token usage the API reported for the requests made with a
+// context from WithUsage.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	// CostUSD is the price of the tokens by Prices; zero for unknown models.
+	CostUSD float64
+	// CachedCalls counts the requests answered by a ResponseCache, which
+	// use no tokens.
+	CachedCalls int
+}
+
+type usageKey struct{}
+
+// WithUsage returns a context whose requests add the token usage reported by
+// the API to u. Backends that report no usage leave u unchanged, so a zero
+// Usage after a call means the counts have to be estimated.
+func WithUsage(ctx context.Context, u *Usage) context.Context {
+	return context.WithValue(ctx, usageKey{}, u)
+}
+
+// Reported reports whether any usage was recorded.
+func (u Usage) Reported() bool {
+	return u.PromptTokens > 0 || u.CompletionTokens > 0 || u.CachedCalls > 0
+}
+
+// recordUsage adds the usage model reported to the Usage of ctx, if any.
+func recordUsage(ctx context.Context, model string, prompt, completion int) {
+	u, ok := ctx.Value(usageKey{}).(*Usage)
+	if !ok || u == nil {
+		return
+	}
+	u.PromptTokens += prompt
+	u.CompletionTokens += completion
+	if price, ok := LookupPrice(model); ok {
+		u.CostUSD += price.Cost(prompt, completion)
+	}
+}
+
+// recordCacheHit counts a request answered from the cache in the Usage of ctx.
+func recordCacheHit(ctx context.Context) {
+	if u, ok := ctx.Value(usageKey{}).(*Usage); ok && u != nil {
+		u.CachedCalls++
+	}
+}
@@ -0,0 +1,64 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/VolodyaPopov923/AIBot/internal/prompts"
+)
+
+// Verification is the model's verdict on whether an action achieved its
+// plan step.
+type Verification struct {
+	Achieved bool   `json:"achieved"`
+	Reason   string `json:"reason"`
+}
+
+// verifyFunction is the function the model calls to return a Verification.
+var verifyFunction = &FunctionSpec{
+	Name:        "verify",
+	Description: "Report whether the step was achieved.",
+	Parameters: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"achieved": map[string]any{"type": "boolean", "description": "whether the action achieved the step"},
+			"reason":   map[string]any{"type": "string", "description": "why not, in one sentence, so the agent can try differently"},
+		},
+		"required": []string{"achieved", "reason"},
+	},
+}
+
+// VerifyStep asks the model whether action achieved step, judging by
+// pageDiff, the changes of the page the action caused.
+func (c *Client) VerifyStep(ctx context.Context, step, action, pageDiff string) (Verification, error) {
+	raw, err := c.backend.Chat(ctx, ChatRequest{
+		Temperature: 0.0,
+		Messages: []Message{
+			{Role: RoleSystem, Content: prompts.Render(prompts.Verify, nil)},
+			{Role: RoleUser, Content: fmt.Sprintf("Step: %s\nAction taken: %s\n\nPage changes:\n%s", step, action, pageDiff)},
+		},
+		MaxTokens: 200,
+		Function:  verifyFunction,
+	})
+	if err != nil {
+		return Verification{}, fmt.Errorf("failed to verify step: %w", err)
+	}
+	return parseVerification(raw)
+}
+
+func parseVerification(raw string) (Verification, error) {
+	content := strings.TrimSpace(raw)
+	var verdict Verification
+	if err := json.Unmarshal([]byte(content), &verdict); err != nil {
+		repaired, ok := repairJSON(content)
+		if !ok {
+			return Verification{}, fmt.Errorf("failed to parse verification: %w", err)
+		}
+		if err := json.Unmarshal([]byte(repaired), &verdict); err != nil {
+			return Verification{}, fmt.Errorf("failed to parse verification: %w", err)
+		}
+	}
+	return verdict, nil
+}
@@ -0,0 +1,43 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestVerifyStep(t *testing.T) {
+	backend := &fakeBackend{replies: []string{`{"achieved": false, "reason": "the search box is still empty",}`}}
+	verdict, err := NewClientWithBackend(backend).VerifyStep(context.Background(), "Search for milk", `{"action":"click"}`, "No visible change")
+	if err != nil {
+		t.Fatalf("VerifyStep failed: %v", err)
+	}
+	if verdict.Achieved || verdict.Reason != "the search box is still empty" {
+		t.Fatalf("unexpected verdict: %+v", verdict)
+	}
+	req := backend.requests[0]
+	if req.Function == nil || req.Function.Name != "verify" {
+		t.Fatalf("verdict should be requested via function calling")
+	}
+	if user := req.Messages[1].Content; !strings.Contains(user, "Search for milk") || !strings.Contains(user, "No visible change") {
+		t.Fatalf("step and page changes missing from request: %q", user)
+	}
+
+	backend = &fakeBackend{replies: []string{"yes it worked"}}
+	if _, err := NewClientWithBackend(backend).VerifyStep(context.Background(), "step", "action", "diff"); err == nil {
+		t.Fatalf("expected an error for a reply without JSON")
+	}
+}
+
+func TestRouterVerifiesWithFastModel(t *testing.T) {
+	fast := &fakeBackend{replies: []string{`{"achieved": true, "reason": ""}`}}
+	strong := &fakeBackend{}
+	router := NewRouter(NewClientWithBackend(fast), NewClientWithBackend(strong))
+	verdict, err := router.VerifyStep(context.Background(), "step", "action", "diff")
+	if err != nil || !verdict.Achieved {
+		t.Fatalf("unexpected verdict %+v, %v", verdict, err)
+	}
+	if len(fast.requests) != 1 || len(strong.requests) != 0 {
+		t.Fatalf("verification should go to the fast model")
+	}
+}
@@ -0,0 +1,226 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AXNode is a node of the accessibility tree: what a screen reader exposes
+// of the page. Layout-only wrappers are left out, so the tree is much smaller
+// than the DOM.
+type AXNode struct {
+	Role        string   `json:"role"`
+	Name        string   `json:"name,omitempty"`
+	Value       string   `json:"value,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Checked     string   `json:"checked,omitempty"` // "true", "false" or "mixed"
+	Disabled    bool     `json:"disabled,omitempty"`
+	Expanded    *bool    `json:"expanded,omitempty"`
+	Selected    bool     `json:"selected,omitempty"`
+	Level       int      `json:"level,omitempty"`
+	Children    []AXNode `json:"children,omitempty"`
+}
+
+// GetAccessibilityTree returns the accessibility tree of the active page,
+// rooted at the document. It needs Chromium: the tree is read over the
+// DevTools protocol.
+func (m *Manager) GetAccessibilityTree(ctx context.Context) (*AXNode, error) {
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	session, err := page.Context().NewCDPSession(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DevTools session: %w", err)
+	}
+	defer func() { _ = session.Detach() }()
+
+	result, err := session.Send("Accessibility.getFullAXTree", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read accessibility tree: %w", err)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read accessibility tree: %w", err)
+	}
+	var tree struct {
+		Nodes []cdpAXNode `json:"nodes"`
+	}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("failed to parse accessibility tree: %w", err)
+	}
+	root := buildAXTree(tree.Nodes)
+	if root == nil {
+		return nil, fmt.Errorf("accessibility tree is empty")
+	}
+	return root, nil
+}
+
+// cdpAXNode is a node as returned by Accessibility.getFullAXTree.
+type cdpAXNode struct {
+	NodeID      string          `json:"nodeId"`
+	ParentID    string          `json:"parentId"`
+	Ignored     bool            `json:"ignored"`
+	Role        *cdpAXValue     `json:"role"`
+	Name        *cdpAXValue     `json:"name"`
+	Value       *cdpAXValue     `json:"value"`
+	Description *cdpAXValue     `json:"description"`
+	Properties  []cdpAXProperty `json:"properties"`
+	ChildIDs    []string        `json:"childIds"`
+}
+
+type cdpAXValue struct {
+	Value interface{} `json:"value"`
+}
+
+type cdpAXProperty struct {
+	Name  string     `json:"name"`
+	Value cdpAXValue `json:"value"`
+}
+
+func (v *cdpAXValue) String() string {
+	if v == nil || v.Value == nil {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprint(v.Value))
+}
+
+// uninterestingRoles are wrappers that only matter for layout; their
+// children are attached to the nearest kept ancestor.
+var uninterestingRoles = map[string]bool{
+	"generic": true, "none": true, "presentation": true, "InlineTextBox": true, "LineBreak": true, "group": true,
+}
+
+// buildAXTree turns the flat DevTools node list into a tree, dropping ignored
+// nodes, nameless wrappers and text that only repeats its parent's name.
+func buildAXTree(nodes []cdpAXNode) *AXNode {
+	if len(nodes) == 0 {
+		return nil
+	}
+	byID := make(map[string]*cdpAXNode, len(nodes))
+	var root *cdpAXNode
+	for i := range nodes {
+		byID[nodes[i].NodeID] = &nodes[i]
+		if root == nil && nodes[i].ParentID == "" {
+			root = &nodes[i]
+		}
+	}
+	if root == nil {
+		root = &nodes[0]
+	}
+
+	var convert func(n *cdpAXNode, parentName string) []AXNode
+	convert = func(n *cdpAXNode, parentName string) []AXNode {
+		role, name := n.Role.String(), n.Name.String()
+		children := func(parentName string) []AXNode {
+			var out []AXNode
+			for _, id := range n.ChildIDs {
+				if child, ok := byID[id]; ok {
+					out = append(out, convert(child, parentName)...)
+				}
+			}
+			return out
+		}
+		if n.Ignored || (uninterestingRoles[role] && name == "") {
+			return children(parentName)
+		}
+		if role == "StaticText" {
+			if name == "" || strings.Contains(parentName, name) {
+				return nil
+			}
+			return []AXNode{{Role: "text", Name: name}}
+		}
+
+		node := AXNode{Role: role, Name: name, Value: n.Value.String(), Description: n.Description.String()}
+		for _, prop := range n.Properties {
+			value := prop.Value.String()
+			switch prop.Name {
+			case "checked":
+				node.Checked = value
+			case "disabled":
+				node.Disabled = value == "true"
+			case "expanded":
+				expanded := value == "true"
+				node.Expanded = &expanded
+			case "selected":
+				node.Selected = value == "true"
+			case "level":
+				node.Level, _ = strconv.Atoi(value)
+			}
+		}
+		node.Children = children(name)
+		return []AXNode{node}
+	}
+
+	converted := convert(root, "")
+	if len(converted) == 1 {
+		return &converted[0]
+	}
+	return &AXNode{Role: "RootWebArea", Children: converted}
+}
+
+// Format renders the tree below n as an indented outline such as
+//
+//   - heading "Store" [level=1]
+//   - link "Pricing"
+//   - checkbox "Remember me" [checked]
+//
+// Roles and names map directly onto role= locators. A positive maxRunes
+// truncates the output.
+func (n *AXNode) Format(maxRunes int) string {
+	var b strings.Builder
+	var write func(node AXNode, depth int)
+	write = func(node AXNode, depth int) {
+		b.WriteString(strings.Repeat("  ", depth))
+		b.WriteString("- ")
+		if node.Role == "text" {
+			b.WriteString("text: " + node.Name + "\n")
+			return
+		}
+		b.WriteString(node.Role)
+		if node.Name != "" {
+			fmt.Fprintf(&b, " %q", node.Name)
+		}
+		var states []string
+		switch node.Checked {
+		case "true":
+			states = append(states, "checked")
+		case "mixed":
+			states = append(states, "checked=mixed")
+		}
+		if node.Disabled {
+			states = append(states, "disabled")
+		}
+		if node.Expanded != nil {
+			states = append(states, fmt.Sprintf("expanded=%t", *node.Expanded))
+		}
+		if node.Selected {
+			states = append(states, "selected")
+		}
+		if node.Level > 0 {
+			states = append(states, fmt.Sprintf("level=%d", node.Level))
+		}
+		for _, state := range states {
+			b.WriteString(" [" + state + "]")
+		}
+		if node.Value != "" {
+			b.WriteString(": " + node.Value)
+		}
+		b.WriteString("\n")
+		for _, child := range node.Children {
+			write(child, depth+1)
+		}
+	}
+	for _, child := range n.Children {
+		write(child, 0)
+	}
+
+	out := strings.TrimRight(b.String(), "\n")
+	if capped := capText(out, maxRunes); capped != out {
+		return capped + "\n[truncated]"
+	}
+	return out
+}
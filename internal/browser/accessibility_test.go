@@ -0,0 +1,72 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBuildAXTree(t *testing.T) {
+	raw := `[
+		{"nodeId": "1", "role": {"value": "RootWebArea"}, "name": {"value": "Store"}, "childIds": ["2"]},
+		{"nodeId": "2", "parentId": "1", "role": {"value": "generic"}, "name": {"value": ""}, "childIds": ["3", "5", "7", "8", "9"]},
+		{"nodeId": "3", "parentId": "2", "role": {"value": "heading"}, "name": {"value": "Plans"},
+			"properties": [{"name": "level", "value": {"value": 2}}], "childIds": ["4"]},
+		{"nodeId": "4", "parentId": "3", "role": {"value": "StaticText"}, "name": {"value": "Plans"}},
+		{"nodeId": "5", "parentId": "2", "role": {"value": "checkbox"}, "name": {"value": "Remember me"},
+			"properties": [{"name": "checked", "value": {"value": "true"}}, {"name": "focusable", "value": {"value": true}}], "childIds": []},
+		{"nodeId": "7", "parentId": "2", "ignored": true, "role": {"value": "none"}, "childIds": ["10"]},
+		{"nodeId": "8", "parentId": "2", "role": {"value": "button"}, "name": {"value": "Pay"},
+			"properties": [{"name": "disabled", "value": {"value": true}}]},
+		{"nodeId": "9", "parentId": "2", "role": {"value": "StaticText"}, "name": {"value": "Prices include VAT"}},
+		{"nodeId": "10", "parentId": "7", "role": {"value": "textbox"}, "name": {"value": "Email"}, "value": {"value": "ivan@example.com"}}
+	]`
+	var nodes []cdpAXNode
+	if err := json.Unmarshal([]byte(raw), &nodes); err != nil {
+		t.Fatal(err)
+	}
+
+	got := buildAXTree(nodes).Format(0)
+	want := strings.Join([]string{
+		`- heading "Plans" [level=2]`,
+		`- checkbox "Remember me" [checked]`,
+		`- textbox "Email": ivan@example.com`,
+		`- button "Pay" [disabled]`,
+		`- text: Prices include VAT`,
+	}, "\n")
+	if got != want {
+		t.Fatalf("unexpected tree:\n%s\nwant:\n%s", got, want)
+	}
+
+	if short := buildAXTree(nodes).Format(20); !strings.HasSuffix(short, "[truncated]") {
+		t.Fatalf("expected truncated output, got:\n%s", short)
+	}
+}
+
+func TestGetAccessibilityTree(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	url := serveFixture(t, `<html><head><title>Login</title></head><body>
+		<h1>Sign in</h1>
+		<div><div><label>Email <input type="email"></label></div></div>
+		<button>Continue</button>
+	</body></html>`)
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("Navigate failed: %v", err)
+	}
+
+	tree, err := mgr.GetAccessibilityTree(ctx)
+	if err != nil {
+		t.Fatalf("GetAccessibilityTree failed: %v", err)
+	}
+	out := tree.Format(0)
+	for _, want := range []string{`heading "Sign in" [level=1]`, `textbox "Email"`, `button "Continue"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("tree missing %s:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "generic") {
+		t.Errorf("layout wrappers should be dropped:\n%s", out)
+	}
+}
@@ -0,0 +1,111 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// CaptchaWidget is a CAPTCHA widget found on the page. Kind is
+// "recaptcha_v2", "hcaptcha" or "turnstile".
+type CaptchaWidget struct {
+	Kind    string `json:"kind"`
+	SiteKey string `json:"siteKey"`
+}
+
+// findCaptchaScript looks for a widget's site key on its container element
+// and, for widgets rendered from script, in the src of the widget's iframe.
+const findCaptchaScript = `() => {
+	const attr = (selector) => {
+		const el = document.querySelector(selector);
+		return el ? el.getAttribute('data-sitekey') || '' : '';
+	};
+	const fromIframe = (pattern, param) => {
+		for (const frame of document.querySelectorAll('iframe[src]')) {
+			if (!pattern.test(frame.src)) continue;
+			try {
+				const url = new URL(frame.src);
+				const key = url.searchParams.get(param) || new URLSearchParams(url.hash.slice(1)).get(param);
+				if (key) return key;
+			} catch (e) {}
+		}
+		return '';
+	};
+	const widgets = [
+		['recaptcha_v2', () => attr('.g-recaptcha[data-sitekey]') || fromIframe(/\/recaptcha\/(api2|enterprise)\/anchor/, 'k')],
+		['hcaptcha', () => attr('.h-captcha[data-sitekey]') || fromIframe(/hcaptcha\.com/, 'sitekey')],
+		['turnstile', () => attr('.cf-turnstile[data-sitekey]')],
+	];
+	for (const [kind, siteKey] of widgets) {
+		const key = siteKey();
+		if (key) return {kind, siteKey: key};
+	}
+	return null;
+}`
+
+// submitCaptchaScript writes a solved token into the hidden response fields
+// the widget's form posts, then calls the callback the site registered with
+// data-callback, which is how most challenge pages continue.
+const submitCaptchaScript = `({kind, token}) => {
+	const fields = {
+		recaptcha_v2: ['g-recaptcha-response'],
+		hcaptcha: ['h-captcha-response', 'g-recaptcha-response'],
+		turnstile: ['cf-turnstile-response'],
+	}[kind] || [];
+	let filled = 0;
+	for (const name of fields) {
+		for (const el of document.querySelectorAll('[name="' + name + '"], #' + name)) {
+			el.value = token;
+			filled++;
+		}
+	}
+	const widget = document.querySelector('.g-recaptcha, .h-captcha, .cf-turnstile');
+	const callback = widget ? widget.getAttribute('data-callback') : '';
+	if (callback && typeof window[callback] === 'function') {
+		window[callback](token);
+		return true;
+	}
+	return filled > 0;
+}`
+
+// FindCaptcha returns the CAPTCHA widget of the active page's main frame, or
+// nil if the page has none that a solving service can handle.
+func (m *Manager) FindCaptcha(ctx context.Context) (*CaptchaWidget, error) {
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := page.Evaluate(findCaptchaScript)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look for CAPTCHA: %w", err)
+	}
+	if result == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CAPTCHA widget: %w", err)
+	}
+	var widget CaptchaWidget
+	if err := json.Unmarshal(data, &widget); err != nil {
+		return nil, fmt.Errorf("failed to read CAPTCHA widget: %w", err)
+	}
+	return &widget, nil
+}
+
+// SubmitCaptchaToken hands a solved token to the widget's page as if the user
+// had solved it.
+func (m *Manager) SubmitCaptchaToken(ctx context.Context, widget CaptchaWidget, token string) error {
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return err
+	}
+	result, err := page.Evaluate(submitCaptchaScript, map[string]interface{}{"kind": widget.Kind, "token": token})
+	if err != nil {
+		return fmt.Errorf("failed to submit CAPTCHA token: %w", err)
+	}
+	if accepted, _ := result.(bool); !accepted {
+		return fmt.Errorf("page has no %s response field to submit the token to", widget.Kind)
+	}
+	return nil
+}
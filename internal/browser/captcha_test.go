@@ -0,0 +1,55 @@
+package browser
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFindAndSubmitCaptcha(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	url := serveFixture(t, `<html><body>
+		<form>
+			<div class="g-recaptcha" data-sitekey="site-key-1" data-callback="onSolved"></div>
+			<textarea name="g-recaptcha-response" style="display:none"></textarea>
+		</form>
+		<script>window.onSolved = (token) => { document.title = 'solved ' + token; };</script>
+	</body></html>`)
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+
+	widget, err := mgr.FindCaptcha(ctx)
+	if err != nil {
+		t.Fatalf("FindCaptcha failed: %v", err)
+	}
+	if widget == nil || widget.Kind != "recaptcha_v2" || widget.SiteKey != "site-key-1" {
+		t.Fatalf("unexpected widget %+v", widget)
+	}
+	if err := mgr.SubmitCaptchaToken(ctx, *widget, "token-1"); err != nil {
+		t.Fatalf("SubmitCaptchaToken failed: %v", err)
+	}
+	result, err := mgr.Evaluate(ctx, `[document.title, document.querySelector('[name="g-recaptcha-response"]').value]`)
+	if err != nil {
+		t.Fatalf("evaluate failed: %v", err)
+	}
+	got := result.([]interface{})
+	if got[0] != "solved token-1" || got[1] != "token-1" {
+		t.Errorf("token not submitted: %v", got)
+	}
+}
+
+func TestFindCaptchaNone(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	if err := mgr.Navigate(ctx, serveFixture(t, `<html><body>No challenge here</body></html>`)); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+	widget, err := mgr.FindCaptcha(ctx)
+	if err != nil {
+		t.Fatalf("FindCaptcha failed: %v", err)
+	}
+	if widget != nil {
+		t.Errorf("expected no widget, got %+v", widget)
+	}
+}
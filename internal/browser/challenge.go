@@ -0,0 +1,71 @@
+package browser
+
+import (
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// detectChallengeScript names the bot check the page shows, if any.
+// Only widgets a person has to interact with count: invisible reCAPTCHA and
+// the reCAPTCHA v3 badge run on ordinary pages and are ignored.
+const detectChallengeScript = `() => {
+	const visible = (el) => {
+		const rect = el.getBoundingClientRect();
+		return rect.width > 0 && rect.height > 0 && getComputedStyle(el).visibility !== 'hidden';
+	};
+	const shown = (selector) => Array.from(document.querySelectorAll(selector)).some(visible);
+	const markers = [
+		['cloudflare', () => typeof window._cf_chl_opt !== 'undefined' || shown('#challenge-form, #challenge-running, #cf-challenge-running')],
+		['turnstile', () => shown('.cf-turnstile, iframe[src*="challenges.cloudflare.com"]')],
+		['recaptcha', () => shown('iframe[src*="/recaptcha/api2/anchor"]:not([src*="size=invisible"]), iframe[src*="/recaptcha/enterprise/anchor"]:not([src*="size=invisible"]), iframe[src*="/recaptcha/api2/bframe"]')],
+		['hcaptcha', () => shown('iframe[src*="hcaptcha.com"][src*="checkbox"], iframe[src*="hcaptcha.com"][src*="challenge"]')],
+		['yandex', () => location.pathname.includes('/showcaptcha') || shown('.CheckboxCaptcha, .AdvancedCaptcha, iframe[src*="smartcaptcha.yandexcloud.net"]')],
+		['datadome', () => shown('iframe[src*="captcha-delivery.com"]')],
+		['perimeterx', () => shown('#px-captcha')],
+	];
+	for (const [name, found] of markers) {
+		if (found()) return name;
+	}
+	return '';
+}`
+
+// detectChallenge returns the name of the bot check shown on the page, e.g.
+// "recaptcha" or "cloudflare", or "" if there is none.
+func detectChallenge(page playwright.Page) string {
+	result, err := page.Evaluate(detectChallengeScript)
+	if err != nil {
+		return ""
+	}
+	name, _ := result.(string)
+	return name
+}
+
+// documentResponse returns the most recent recorded response for the
+// document at url, ignoring the fragment.
+func (m *Manager) documentResponse(url string) (NetworkEntry, bool) {
+	url, _, _ = strings.Cut(url, "#")
+	m.networkMu.Lock()
+	defer m.networkMu.Unlock()
+	for i := len(m.networkEntries) - 1; i >= 0; i-- {
+		if entry := m.networkEntries[i]; entry.ResourceType == "document" && entry.URL == url && entry.Status != 0 {
+			return entry, true
+		}
+	}
+	return NetworkEntry{}, false
+}
+
+// pageStatus returns the HTTP status the page at url was served with (0 if
+// unknown) and the bot check it shows, if any.
+func (m *Manager) pageStatus(page playwright.Page, url string) (int, string) {
+	status := 0
+	challenge := detectChallenge(page)
+	if resp, ok := m.documentResponse(url); ok {
+		status = resp.Status
+		// Cloudflare marks its challenge responses explicitly.
+		if challenge == "" && strings.EqualFold(resp.ResponseHeaders["cf-mitigated"], "challenge") {
+			challenge = "cloudflare"
+		}
+	}
+	return status, challenge
+}
@@ -0,0 +1,61 @@
+package browser
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDocumentResponse(t *testing.T) {
+	m := &Manager{}
+	m.recordNetwork(NetworkEntry{URL: "https://example.com/", ResourceType: "document", Status: 403})
+	m.recordNetwork(NetworkEntry{URL: "https://example.com/app.js", ResourceType: "script", Status: 200})
+	m.recordNetwork(NetworkEntry{URL: "https://example.com/", ResourceType: "document", Status: 200})
+
+	resp, ok := m.documentResponse("https://example.com/#top")
+	if !ok || resp.Status != 200 {
+		t.Fatalf("documentResponse = %+v, %v; want the latest response", resp, ok)
+	}
+	if _, ok := m.documentResponse("https://example.com/other"); ok {
+		t.Error("expected no response for a page that was not loaded")
+	}
+}
+
+func TestPageContentChallenge(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		switch r.URL.Path {
+		case "/challenge":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`<html><body><div class="cf-turnstile" style="width:300px;height:65px"></div></body></html>`))
+		default:
+			_, _ = w.Write([]byte(`<html><head><title>Verify your robot vacuum</title></head><body>Docs</body></html>`))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	if err := mgr.Navigate(ctx, ts.URL+"/verify-robot"); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+	pc, err := mgr.GetPageContent(ctx)
+	if err != nil {
+		t.Fatalf("GetPageContent failed: %v", err)
+	}
+	if pc.Challenge != "" {
+		t.Errorf("ordinary page reported as %q challenge", pc.Challenge)
+	}
+
+	if err := mgr.Navigate(ctx, ts.URL+"/challenge"); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+	pc, err = mgr.GetPageContent(ctx)
+	if err != nil {
+		t.Fatalf("GetPageContent failed: %v", err)
+	}
+	if pc.Challenge != "turnstile" {
+		t.Errorf("Challenge = %q, want turnstile", pc.Challenge)
+	}
+}
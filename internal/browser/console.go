@@ -0,0 +1,76 @@
+package browser
+
+import (
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// maxConsoleEntries bounds the console buffer kept per manager.
+const maxConsoleEntries = 100
+
+// maxPageConsoleErrors is how many errors GetPageContent reports, and
+// maxConsoleErrorText caps the first line of each of them (in runes).
+const (
+	maxPageConsoleErrors = 5
+	maxConsoleErrorText  = 300
+)
+
+// ConsoleEntry is a console error or an uncaught page error.
+type ConsoleEntry struct {
+	Type string // "error" for console.error, "pageerror" for uncaught exceptions
+	Text string
+	URL  string
+	Time time.Time
+}
+
+// attachConsoleListeners records console errors and uncaught exceptions from the page.
+func (m *Manager) attachConsoleListeners(page playwright.Page) {
+	page.OnConsole(func(msg playwright.ConsoleMessage) {
+		if msg.Type() != "error" {
+			return
+		}
+		m.recordConsole(ConsoleEntry{Type: "error", Text: msg.Text(), URL: safePageURL(page)})
+	})
+	page.OnPageError(func(err *playwright.Error) {
+		m.recordConsole(ConsoleEntry{Type: "pageerror", Text: err.Message, URL: safePageURL(page)})
+	})
+}
+
+func (m *Manager) recordConsole(entry ConsoleEntry) {
+	entry.Time = time.Now()
+	m.consoleMu.Lock()
+	defer m.consoleMu.Unlock()
+	m.consoleEntries = append(m.consoleEntries, entry)
+	if len(m.consoleEntries) > maxConsoleEntries {
+		m.consoleEntries = m.consoleEntries[len(m.consoleEntries)-maxConsoleEntries:]
+	}
+}
+
+// RecentConsoleErrors returns up to limit of the most recent console and page errors, oldest first.
+func (m *Manager) RecentConsoleErrors(limit int) []ConsoleEntry {
+	m.consoleMu.Lock()
+	defer m.consoleMu.Unlock()
+	entries := m.consoleEntries
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return append([]ConsoleEntry(nil), entries...)
+}
+
+// pageConsoleErrors returns up to limit of the most recent errors logged by
+// the page at url, oldest first.
+func (m *Manager) pageConsoleErrors(url string, limit int) []ConsoleEntry {
+	m.consoleMu.Lock()
+	defer m.consoleMu.Unlock()
+	var entries []ConsoleEntry
+	for i := len(m.consoleEntries) - 1; i >= 0 && len(entries) < limit; i-- {
+		if entry := m.consoleEntries[i]; entry.URL == url {
+			first, _, _ := strings.Cut(entry.Text, "\n")
+			entry.Text = capText(first, maxConsoleErrorText)
+			entries = append([]ConsoleEntry{entry}, entries...)
+		}
+	}
+	return entries
+}
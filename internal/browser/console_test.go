@@ -0,0 +1,32 @@
+package browser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPageConsoleErrors(t *testing.T) {
+	m := &Manager{}
+	m.recordConsole(ConsoleEntry{Type: "error", Text: "old page error", URL: "https://shop.example/"})
+	for i := 0; i < maxPageConsoleErrors+2; i++ {
+		m.recordConsole(ConsoleEntry{Type: "error", Text: "cart error", URL: "https://shop.example/cart"})
+	}
+	m.recordConsole(ConsoleEntry{Type: "pageerror", Text: "TypeError: total is NaN\n    at checkout.js:12\n    at app.js:40", URL: "https://shop.example/cart"})
+
+	entries := m.pageConsoleErrors("https://shop.example/cart", maxPageConsoleErrors)
+	if len(entries) != maxPageConsoleErrors {
+		t.Fatalf("expected %d entries, got %d", maxPageConsoleErrors, len(entries))
+	}
+	for _, entry := range entries {
+		if entry.URL != "https://shop.example/cart" {
+			t.Fatalf("got an error of another page: %+v", entry)
+		}
+	}
+	last := entries[len(entries)-1]
+	if last.Type != "pageerror" || last.Text != "TypeError: total is NaN" {
+		t.Fatalf("expected the newest error last, without its stack, got %+v", last)
+	}
+	if got := m.pageConsoleErrors("https://shop.example/", 5); len(got) != 1 || !strings.Contains(got[0].Text, "old page") {
+		t.Fatalf("unexpected errors for the home page: %+v", got)
+	}
+}
@@ -0,0 +1,192 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Cookie is a browser cookie. Its JSON form matches Playwright's storage
+// state; files exported by browser extensions (with "expirationDate" and
+// lower-case sameSite values) are accepted too.
+type Cookie struct {
+	Name     string  `json:"name"`
+	Value    string  `json:"value"`
+	Domain   string  `json:"domain"`
+	Path     string  `json:"path,omitempty"`
+	Expires  float64 `json:"expires,omitempty"` // Unix seconds; 0 or -1 for a session cookie
+	HttpOnly bool    `json:"httpOnly,omitempty"`
+	Secure   bool    `json:"secure,omitempty"`
+	SameSite string  `json:"sameSite,omitempty"` // Strict, Lax or None
+}
+
+// UnmarshalJSON also accepts the extension export format.
+func (c *Cookie) UnmarshalJSON(data []byte) error {
+	type cookie Cookie
+	var raw struct {
+		cookie
+		ExpirationDate float64 `json:"expirationDate"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*c = Cookie(raw.cookie)
+	if c.Expires == 0 && raw.ExpirationDate > 0 {
+		c.Expires = raw.ExpirationDate
+	}
+	c.SameSite = normalizeSameSite(c.SameSite)
+	return nil
+}
+
+func normalizeSameSite(value string) string {
+	switch strings.ToLower(value) {
+	case "strict":
+		return "Strict"
+	case "lax":
+		return "Lax"
+	case "none", "no_restriction":
+		return "None"
+	default:
+		return ""
+	}
+}
+
+// LoadCookiesFile reads a JSON array of cookies.
+func LoadCookiesFile(path string) ([]Cookie, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cookies file: %w", err)
+	}
+	var cookies []Cookie
+	if err := json.Unmarshal(data, &cookies); err != nil {
+		return nil, fmt.Errorf("failed to parse cookies file: %w", err)
+	}
+	return cookies, nil
+}
+
+// GetCookies returns the cookies of the browser context, limited to the given
+// URLs if any are passed.
+func (m *Manager) GetCookies(ctx context.Context, urls ...string) ([]Cookie, error) {
+	if err := m.ensureBrowser(ctx); err != nil {
+		return nil, fmt.Errorf("browser not available: %w", err)
+	}
+
+	raw, err := m.context.Cookies(urls...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cookies: %w", err)
+	}
+	cookies := make([]Cookie, 0, len(raw))
+	for _, c := range raw {
+		cookie := Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Expires:  c.Expires,
+			HttpOnly: c.HttpOnly,
+			Secure:   c.Secure,
+		}
+		if c.SameSite != nil {
+			cookie.SameSite = string(*c.SameSite)
+		}
+		cookies = append(cookies, cookie)
+	}
+	return cookies, nil
+}
+
+// HTTPCookies returns the browser's cookies for url in net/http form, so a
+// plain HTTP request is sent with the same session. Errors yield no cookies.
+func (m *Manager) HTTPCookies(ctx context.Context, url string) []*http.Cookie {
+	cookies, err := m.GetCookies(ctx, url)
+	if err != nil {
+		log.Printf("Warning: %v\n", err)
+		return nil
+	}
+	out := make([]*http.Cookie, 0, len(cookies))
+	for _, c := range cookies {
+		out = append(out, &http.Cookie{Name: c.Name, Value: c.Value})
+	}
+	return out
+}
+
+// SetCookies adds cookies to the browser context, e.g. a session exported
+// from the user's own browser so login flows can be skipped.
+func (m *Manager) SetCookies(ctx context.Context, cookies []Cookie) error {
+	if err := m.ensureBrowser(ctx); err != nil {
+		return fmt.Errorf("browser not available: %w", err)
+	}
+	if len(cookies) == 0 {
+		return nil
+	}
+
+	optional := make([]playwright.OptionalCookie, 0, len(cookies))
+	for _, c := range cookies {
+		if c.Name == "" || c.Domain == "" {
+			return fmt.Errorf("cookie %q needs a name and a domain", c.Name)
+		}
+		path := c.Path
+		if path == "" {
+			path = "/"
+		}
+		cookie := playwright.OptionalCookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   playwright.String(c.Domain),
+			Path:     playwright.String(path),
+			HttpOnly: playwright.Bool(c.HttpOnly),
+			Secure:   playwright.Bool(c.Secure),
+		}
+		if c.Expires > 0 {
+			cookie.Expires = playwright.Float(c.Expires)
+		}
+		if sameSite := normalizeSameSite(c.SameSite); sameSite != "" {
+			attr := playwright.SameSiteAttribute(sameSite)
+			cookie.SameSite = &attr
+		}
+		optional = append(optional, cookie)
+	}
+
+	if err := m.context.AddCookies(optional); err != nil {
+		return fmt.Errorf("failed to set cookies: %w", err)
+	}
+	return nil
+}
+
+// ClearCookies removes the cookies of domain and its subdomains, or every
+// cookie if domain is empty.
+func (m *Manager) ClearCookies(ctx context.Context, domain string) error {
+	if err := m.ensureBrowser(ctx); err != nil {
+		return fmt.Errorf("browser not available: %w", err)
+	}
+
+	domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	var keep []Cookie
+	if domain != "" {
+		all, err := m.GetCookies(ctx)
+		if err != nil {
+			return err
+		}
+		for _, c := range all {
+			if !cookieMatchesDomain(c, domain) {
+				keep = append(keep, c)
+			}
+		}
+	}
+
+	if err := m.context.ClearCookies(); err != nil {
+		return fmt.Errorf("failed to clear cookies: %w", err)
+	}
+	return m.SetCookies(ctx, keep)
+}
+
+// cookieMatchesDomain reports whether a cookie belongs to domain or one of its subdomains.
+func cookieMatchesDomain(c Cookie, domain string) bool {
+	host := strings.TrimPrefix(strings.ToLower(c.Domain), ".")
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
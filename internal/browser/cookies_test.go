@@ -0,0 +1,74 @@
+package browser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadCookiesFileExtensionFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	data := `[
+		{"name": "sid", "value": "abc", "domain": ".example.com", "path": "/", "expirationDate": 1900000000.5, "sameSite": "no_restriction", "secure": true},
+		{"name": "lang", "value": "ru", "domain": "example.com", "expires": 1800000000, "sameSite": "lax"}
+	]`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cookies, err := LoadCookiesFile(path)
+	if err != nil {
+		t.Fatalf("LoadCookiesFile failed: %v", err)
+	}
+	if len(cookies) != 2 {
+		t.Fatalf("expected 2 cookies, got %d", len(cookies))
+	}
+	if cookies[0].Expires != 1900000000.5 || cookies[0].SameSite != "None" || !cookies[0].Secure {
+		t.Fatalf("extension cookie not converted: %+v", cookies[0])
+	}
+	if cookies[1].Expires != 1800000000 || cookies[1].SameSite != "Lax" {
+		t.Fatalf("playwright cookie not parsed: %+v", cookies[1])
+	}
+}
+
+func TestCookieMatchesDomain(t *testing.T) {
+	tests := []struct {
+		domain string
+		want   bool
+	}{
+		{".example.com", true},
+		{"example.com", true},
+		{"mail.example.com", true},
+		{"notexample.com", false},
+		{"example.org", false},
+	}
+	for _, tt := range tests {
+		if got := cookieMatchesDomain(Cookie{Domain: tt.domain}, "example.com"); got != tt.want {
+			t.Errorf("cookieMatchesDomain(%s) = %v, want %v", tt.domain, got, tt.want)
+		}
+	}
+}
+
+func TestSetAndClearCookies(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+
+	err := mgr.SetCookies(ctx, []Cookie{
+		{Name: "sid", Value: "1", Domain: "example.com"},
+		{Name: "sid", Value: "2", Domain: "example.org"},
+	})
+	if err != nil {
+		t.Fatalf("SetCookies failed: %v", err)
+	}
+	if err := mgr.ClearCookies(ctx, "example.com"); err != nil {
+		t.Fatalf("ClearCookies failed: %v", err)
+	}
+	cookies, err := mgr.GetCookies(ctx)
+	if err != nil {
+		t.Fatalf("GetCookies failed: %v", err)
+	}
+	if len(cookies) != 1 || cookies[0].Domain != "example.org" {
+		t.Fatalf("expected only the example.org cookie to remain, got %+v", cookies)
+	}
+}
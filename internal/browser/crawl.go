@@ -0,0 +1,135 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// CrawlOptions configures Crawl.
+type CrawlOptions struct {
+	ItemSelector string // elements whose text is collected on every page
+	NextSelector string // "next page" control; the built-in patterns if empty
+	MaxPages     int    // pages to visit, the current one included; defaultMaxPages if not positive
+	MaxItems     int    // stop once this many distinct items are collected; 0 means no limit
+}
+
+// CrawlPage is one page visited by Crawl and the new items found on it.
+type CrawlPage struct {
+	URL   string   `json:"url"`
+	Items []string `json:"items,omitempty"`
+}
+
+// CrawlResult is what Crawl collected.
+type CrawlResult struct {
+	Pages      []CrawlPage `json:"pages"`
+	Items      []string    `json:"items"`                // distinct items of all pages, in order
+	Duplicates int         `json:"duplicates,omitempty"` // items skipped because an earlier page had them
+	StopReason string      `json:"stop_reason"`
+}
+
+// Crawl collects ItemSelector text from the current page and the pages after
+// it, following the "next page" control. Next links are followed by URL, so a
+// link back to a page already crawled ends the crawl instead of looping; for
+// buttons that paginate in place, a page without new items ends it.
+func (m *Manager) Crawl(ctx context.Context, opts CrawlOptions) (CrawlResult, error) {
+	if strings.TrimSpace(opts.ItemSelector) == "" {
+		return CrawlResult{}, fmt.Errorf("crawl requires an item selector")
+	}
+	maxPages := opts.MaxPages
+	if maxPages <= 0 {
+		maxPages = defaultMaxPages
+	}
+
+	var result CrawlResult
+	visited := make(map[string]bool)
+	seen := make(map[string]bool)
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		url := crawlURL(m.CurrentURL())
+		visited[url] = true
+
+		items, err := m.ScrapeList(ctx, opts.ItemSelector)
+		if err != nil {
+			return result, fmt.Errorf("page %d: %w", len(result.Pages)+1, err)
+		}
+		crawled := CrawlPage{URL: url}
+		for _, item := range items {
+			if seen[item] {
+				result.Duplicates++
+				continue
+			}
+			seen[item] = true
+			crawled.Items = append(crawled.Items, item)
+		}
+		result.Pages = append(result.Pages, crawled)
+		result.Items = append(result.Items, crawled.Items...)
+
+		switch {
+		case len(result.Pages) > 1 && len(crawled.Items) == 0:
+			result.StopReason = "page had no new items"
+			return result, nil
+		case opts.MaxItems > 0 && len(result.Items) >= opts.MaxItems:
+			result.Items = result.Items[:opts.MaxItems]
+			result.StopReason = fmt.Sprintf("collected %d items", opts.MaxItems)
+			return result, nil
+		case len(result.Pages) >= maxPages:
+			result.StopReason = fmt.Sprintf("reached the %d page limit", maxPages)
+			return result, nil
+		}
+
+		next, err := m.nextPageURL(ctx, opts.NextSelector)
+		if err != nil {
+			return result, fmt.Errorf("page %d: %w", len(result.Pages), err)
+		}
+		if next != "" {
+			if visited[crawlURL(next)] {
+				result.StopReason = "next page was already crawled"
+				return result, nil
+			}
+			if err := m.Navigate(ctx, next); err != nil {
+				return result, fmt.Errorf("page %d: %w", len(result.Pages)+1, err)
+			}
+			if err := m.WaitForReady(ctx); err != nil {
+				log.Printf("Warning: readiness wait after next page failed: %v\n", err)
+			}
+			continue
+		}
+		more, err := m.NextPage(ctx, opts.NextSelector)
+		if err != nil {
+			return result, fmt.Errorf("page %d: %w", len(result.Pages), err)
+		}
+		if !more {
+			result.StopReason = "no next page"
+			return result, nil
+		}
+	}
+}
+
+// nextPageURL returns the absolute URL the "next page" control links to, or
+// "" if there is no control or it is not a plain link (e.g. a button or a
+// javascript: link), in which case it has to be clicked.
+func (m *Manager) nextPageURL(ctx context.Context, nextSelector string) (string, error) {
+	element, _, err := m.findNextControl(ctx, nextSelector)
+	if err != nil || element == nil {
+		return "", err
+	}
+	result, err := element.Evaluate(`(el) => (el.tagName === 'A' && el.getAttribute('href') && !el.getAttribute('href').startsWith('#')) ? el.href : ''`)
+	if err != nil {
+		return "", fmt.Errorf("failed to read next page link: %w", err)
+	}
+	href, _ := result.(string)
+	if strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		return "", nil
+	}
+	return href, nil
+}
+
+// crawlURL is the URL used to recognize a page Crawl has already visited.
+func crawlURL(url string) string {
+	url, _, _ = strings.Cut(url, "#")
+	return url
+}
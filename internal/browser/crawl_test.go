@@ -0,0 +1,81 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestCrawlFollowsNextLinks(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	if err := mgr.Navigate(ctx, servePaginatedFixture(t)); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+
+	result, err := mgr.Crawl(ctx, CrawlOptions{ItemSelector: ".item", MaxPages: 10})
+	if err != nil {
+		t.Fatalf("Crawl failed: %v", err)
+	}
+	if len(result.Pages) != 3 || len(result.Items) != 6 || result.StopReason != "no next page" {
+		t.Errorf("unexpected crawl result: %+v", result)
+	}
+}
+
+func TestCrawlPageLimit(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	if err := mgr.Navigate(ctx, servePaginatedFixture(t)); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+
+	result, err := mgr.Crawl(ctx, CrawlOptions{ItemSelector: ".item", MaxPages: 2})
+	if err != nil {
+		t.Fatalf("Crawl failed: %v", err)
+	}
+	if len(result.Pages) != 2 || len(result.Items) != 4 {
+		t.Errorf("expected 2 pages and 4 items, got %+v", result)
+	}
+}
+
+func TestCrawlStopsOnLoop(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	// Page 2 links "next" back to page 1, and both repeat a sponsored item.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pageNum, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if pageNum < 1 {
+			pageNum = 1
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, `<html><body>
+			<div class="item">Sponsored</div>
+			<div class="item">Result %d</div>
+			<a rel="next" href="/?page=%d#results">Next</a>
+		</body></html>`, pageNum, pageNum%2+1)
+	}))
+	t.Cleanup(ts.Close)
+	if err := mgr.Navigate(ctx, ts.URL+"/?page=1"); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+
+	result, err := mgr.Crawl(ctx, CrawlOptions{ItemSelector: ".item", MaxPages: 10})
+	if err != nil {
+		t.Fatalf("Crawl failed: %v", err)
+	}
+	if len(result.Pages) != 2 || result.StopReason != "next page was already crawled" {
+		t.Errorf("expected the crawl to stop at the loop, got %+v", result)
+	}
+	if len(result.Items) != 3 || result.Duplicates != 1 {
+		t.Errorf("expected 3 distinct items and 1 duplicate, got %+v", result)
+	}
+}
+
+func TestCrawlRequiresItemSelector(t *testing.T) {
+	if _, err := (&Manager{}).Crawl(context.Background(), CrawlOptions{}); err == nil {
+		t.Fatal("expected an error without an item selector")
+	}
+}
@@ -0,0 +1,122 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// defaultDownloadDir is where downloads are saved unless BROWSER_DOWNLOAD_DIR is set.
+const defaultDownloadDir = "downloads"
+
+// downloadPollInterval is how often WaitForDownloads checks for finished files.
+const downloadPollInterval = 100 * time.Millisecond
+
+// Download is a file the site sent to the browser.
+type Download struct {
+	URL   string
+	Path  string // where the file was saved; empty if saving failed
+	Error string
+	Time  time.Time
+}
+
+// downloadDir returns the directory downloads are saved to.
+func downloadDir() string {
+	if dir := strings.TrimSpace(os.Getenv("BROWSER_DOWNLOAD_DIR")); dir != "" {
+		return dir
+	}
+	return defaultDownloadDir
+}
+
+// attachDownloadListener saves every download of the page. SaveAs blocks
+// until the file is complete, so it runs off Playwright's dispatch goroutine.
+func (m *Manager) attachDownloadListener(page playwright.Page) {
+	page.OnDownload(func(d playwright.Download) {
+		m.downloadMu.Lock()
+		m.downloadsPending++
+		m.downloadMu.Unlock()
+		go m.saveDownload(d)
+	})
+}
+
+func (m *Manager) saveDownload(d playwright.Download) {
+	entry := Download{URL: d.URL()}
+	dir := downloadDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		entry.Error = fmt.Sprintf("failed to create download dir: %v", err)
+	} else {
+		path := uniqueFilePath(dir, d.SuggestedFilename())
+		if err := d.SaveAs(path); err != nil {
+			entry.Error = fmt.Sprintf("failed to save download: %v", err)
+		} else {
+			entry.Path = path
+		}
+	}
+	if entry.Error != "" {
+		log.Printf("Warning: download of %s failed: %s\n", entry.URL, entry.Error)
+	} else {
+		log.Printf("📥 Downloaded %s to %s\n", entry.URL, entry.Path)
+	}
+
+	entry.Time = time.Now()
+	m.downloadMu.Lock()
+	defer m.downloadMu.Unlock()
+	m.downloadsPending--
+	m.downloads = append(m.downloads, entry)
+}
+
+// uniqueFilePath returns dir/name, numbering the name if that file exists so
+// repeated downloads of report.csv do not overwrite each other.
+func uniqueFilePath(dir, name string) string {
+	name = filepath.Base(name)
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		name = "download"
+	}
+	path := filepath.Join(dir, name)
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path
+		}
+		path = filepath.Join(dir, stem+"-"+strconv.Itoa(i)+ext)
+	}
+}
+
+// TakeDownloads returns the downloads finished since the last call.
+func (m *Manager) TakeDownloads() []Download {
+	m.downloadMu.Lock()
+	defer m.downloadMu.Unlock()
+	done := m.downloads
+	m.downloads = nil
+	return done
+}
+
+// WaitForDownloads blocks until downloads in progress are saved or timeout
+// passes. It returns immediately when nothing is downloading.
+func (m *Manager) WaitForDownloads(ctx context.Context, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		m.downloadMu.Lock()
+		pending := m.downloadsPending
+		m.downloadMu.Unlock()
+		if pending == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%d download(s) still in progress after %s", pending, timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(downloadPollInterval):
+		}
+	}
+}
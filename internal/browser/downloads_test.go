@@ -0,0 +1,69 @@
+package browser
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUniqueFilePath(t *testing.T) {
+	dir := t.TempDir()
+	if got := uniqueFilePath(dir, "report.csv"); got != filepath.Join(dir, "report.csv") {
+		t.Fatalf("unexpected path %s", got)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "report.csv"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := uniqueFilePath(dir, "report.csv"); got != filepath.Join(dir, "report-1.csv") {
+		t.Fatalf("existing file should not be overwritten, got %s", got)
+	}
+	if got := uniqueFilePath(dir, "../../etc/passwd"); got != filepath.Join(dir, "passwd") {
+		t.Fatalf("suggested name should not escape the download dir, got %s", got)
+	}
+}
+
+func TestDownloadIsSaved(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("BROWSER_DOWNLOAD_DIR", dir)
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/report.csv" {
+			w.Header().Set("Content-Type", "text/csv")
+			w.Header().Set("Content-Disposition", `attachment; filename="report.csv"`)
+			_, _ = w.Write([]byte("city,population\nMoscow,13000000\n"))
+			return
+		}
+		_, _ = w.Write([]byte(`<html><body><a id="csv" href="/report.csv">Download CSV</a></body></html>`))
+	}))
+	t.Cleanup(ts.Close)
+
+	if err := mgr.Navigate(ctx, ts.URL); err != nil {
+		t.Fatalf("Navigate failed: %v", err)
+	}
+	if err := mgr.Click(ctx, "#csv"); err != nil {
+		t.Fatalf("Click failed: %v", err)
+	}
+
+	var downloads []Download
+	deadline := time.Now().Add(10 * time.Second)
+	for len(downloads) == 0 && time.Now().Before(deadline) {
+		if err := mgr.WaitForDownloads(ctx, 5*time.Second); err != nil {
+			t.Fatalf("WaitForDownloads failed: %v", err)
+		}
+		downloads = mgr.TakeDownloads()
+		time.Sleep(100 * time.Millisecond)
+	}
+	if len(downloads) != 1 || downloads[0].Path != filepath.Join(dir, "report.csv") {
+		t.Fatalf("expected report.csv to be saved, got %+v", downloads)
+	}
+	data, err := os.ReadFile(downloads[0].Path)
+	if err != nil || len(data) == 0 {
+		t.Fatalf("downloaded file is empty: %v", err)
+	}
+}
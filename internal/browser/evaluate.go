@@ -0,0 +1,20 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+)
+
+// Evaluate runs a JavaScript expression or function in the active page and
+// returns its JSON-serializable result. Promises are awaited.
+func (m *Manager) Evaluate(ctx context.Context, script string) (interface{}, error) {
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := page.Evaluate(script)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate script: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,271 @@
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// maxSelectOptions bounds how many options of a <select> are listed in the
+// page description.
+const maxSelectOptions = 15
+
+// defaultViewportMargin is how many screens above or below the viewport
+// elements are still extracted.
+const defaultViewportMargin = 2
+
+// maxLocatorNameLength skips role locators for elements whose accessible name
+// is too long to be a stable identifier (e.g. a whole article teaser).
+const maxLocatorNameLength = 80
+
+// extractElementsScript collects every interactive element of a frame in a
+// single round-trip: its kind, label, href, visibility and a selector.
+//
+// The walk covers the document and every open shadow root. Selectors prefer,
+// in order: a unique id, a name attribute, a role, placeholder or label
+// locator that matches only this element (see locators.go), and finally an
+// nth-of-type path. Elements inside shadow roots get a path chained through
+// their hosts with " >> ", so Playwright resolves each part within the
+// previous match.
+const extractElementsScript = `({maxOptions, maxNameLength, viewportMargin, maxMarkdown}) => {
+	const norm = (s) => (s || '').replace(/\s+/g, ' ').trim();
+	const quote = (s) => JSON.stringify(s);
+	const cssValue = (s) => '"' + s.replace(/\\/g, '\\\\').replace(/"/g, '\\"') + '"';
+
+	const isVisible = (el, rect) => {
+		if (rect.width === 0 || rect.height === 0) return false;
+		const style = getComputedStyle(el);
+		return style.visibility !== 'hidden' && style.display !== 'none';
+	};
+
+	const implicitRole = (el, tag, type) => {
+		const explicit = el.getAttribute('role');
+		if (explicit) return explicit;
+		if (tag === 'button' || (tag === 'input' && ['button', 'submit', 'reset'].includes(type))) return 'button';
+		if (tag === 'a' && el.hasAttribute('href')) return 'link';
+		if (tag === 'input' && (type === 'checkbox' || type === 'radio')) return type;
+		if (tag === 'select') return 'combobox';
+		if (tag === 'input' && type === 'search') return 'searchbox';
+		if (tag === 'textarea' || (tag === 'input' && ['', 'text', 'email', 'tel', 'url', 'password'].includes(type))) return 'textbox';
+		return '';
+	};
+
+	const labelText = (el) => norm(el.labels && el.labels[0] ? el.labels[0].innerText : '');
+
+	const accessibleName = (el, tag, type) => {
+		if (el.hasAttribute('aria-labelledby')) return '';
+		const text = ['input', 'textarea', 'select'].includes(tag) ? '' : el.textContent;
+		const value = ['button', 'submit', 'reset'].includes(type) ? el.value : '';
+		return norm(el.getAttribute('aria-label') || labelText(el) || text || el.getAttribute('title') || value);
+	};
+
+	const cssPath = (el) => {
+		const path = [];
+		let current = el;
+		while (current && current.tagName !== 'BODY') {
+			let index = 1;
+			for (let sib = current.previousElementSibling; sib; sib = sib.previousElementSibling) {
+				if (sib.tagName === current.tagName) index++;
+			}
+			path.unshift(current.tagName.toLowerCase() + ':nth-of-type(' + index + ')');
+			current = current.parentElement;
+		}
+		return path.join(' > ');
+	};
+
+	// Walk the document and open shadow roots in document order.
+	const nodes = [];
+	const visit = (root, prefix, inShadow) => {
+		for (const el of root.querySelectorAll('*')) {
+			const tag = el.tagName.toLowerCase();
+			const type = (el.getAttribute('type') || '').toLowerCase();
+			const role = implicitRole(el, tag, type);
+			const rect = el.getBoundingClientRect();
+			const visible = isVisible(el, rect) && el.getAttribute('aria-hidden') !== 'true';
+			nodes.push({el, tag, type, role, rect, visible, prefix, inShadow,
+				name: role && visible ? accessibleName(el, tag, type) : ''});
+			if (el.shadowRoot) {
+				visit(el.shadowRoot, prefix + cssPath(el) + ' >> ', true);
+			}
+		}
+	};
+	visit(document, '', false);
+
+	// Uniqueness checks mirror how Playwright resolves each locator: role
+	// names and labels match case-insensitive substrings of visible elements.
+	const countBy = (key) => {
+		const counts = new Map();
+		for (const n of nodes) {
+			const value = key(n);
+			if (value) counts.set(value, (counts.get(value) || 0) + 1);
+		}
+		return counts;
+	};
+	const ids = countBy((n) => !n.inShadow && n.el.getAttribute('id'));
+	const placeholders = countBy((n) => n.el.getAttribute('placeholder'));
+	const named = nodes.filter((n) => n.name);
+	const labelled = nodes.map((n) => (labelText(n.el) + ' ' + norm(n.el.getAttribute('aria-label'))).toLowerCase()).filter((t) => t.trim());
+	const roleUnique = (role, name) => {
+		const needle = name.toLowerCase();
+		return named.filter((n) => n.role === role && n.name.toLowerCase().includes(needle)).length === 1;
+	};
+	const labelUnique = (label) => {
+		const needle = label.toLowerCase();
+		return labelled.filter((t) => t.includes(needle)).length === 1;
+	};
+
+	const selectorFor = (n) => {
+		const el = n.el;
+		const id = el.getAttribute('id');
+		if (n.inShadow) {
+			return n.prefix + (id ? '[id=' + cssValue(id) + ']' : cssPath(el));
+		}
+		if (id && ids.get(id) === 1) return '[id=' + cssValue(id) + ']';
+		const name = el.getAttribute('name');
+		if (name) return n.tag + '[name=' + cssValue(name) + ']';
+		if (n.name && n.name.length <= maxNameLength && roleUnique(n.role, n.name)) {
+			return 'role=' + n.role + '[name=' + quote(n.name) + ']';
+		}
+		const placeholder = el.getAttribute('placeholder');
+		if (placeholder && placeholders.get(placeholder) === 1) return 'placeholder=' + quote(placeholder);
+		const label = labelText(el);
+		if (label && label.length <= maxNameLength && labelUnique(label)) return 'label=' + quote(label);
+		return cssPath(el);
+	};
+
+	// Hidden and disabled controls cannot be acted on. File inputs are the
+	// exception: sites hide them behind a styled button, and uploads set
+	// their files directly. Elements more than viewportMargin screens away
+	// are left out; nearer off-screen ones are flagged so the model scrolls.
+	const width = window.innerWidth;
+	const height = window.innerHeight;
+	const usable = (n) => {
+		if (!n.visible && !(n.tag === 'input' && n.type === 'file')) return false;
+		return !n.el.disabled && n.el.getAttribute('aria-disabled') !== 'true';
+	};
+	const offscreen = (r) => r.bottom <= 0 || r.top >= height || r.right <= 0 || r.left >= width;
+	const tooFar = (r) => viewportMargin >= 0 && (r.bottom < -viewportMargin * height || r.top > (1 + viewportMargin) * height);
+
+	const kinds = ['button', 'link', 'input', 'textarea', 'select', 'editable'];
+	const buckets = Object.fromEntries(kinds.map((k) => [k, []]));
+	for (const n of nodes) {
+		const el = n.el;
+		let kind = '';
+		let type = '';
+		let text = '';
+		if (n.tag === 'button' || el.getAttribute('role') === 'button') {
+			kind = type = 'button';
+			text = norm(el.textContent);
+			if (!text) continue;
+		} else if (n.tag === 'a' && el.hasAttribute('href')) {
+			kind = type = 'link';
+			text = norm(el.textContent);
+			if (!text) continue;
+		} else if (n.tag === 'input') {
+			if (n.type === 'hidden') continue;
+			kind = 'input';
+			if (n.type === 'checkbox' || n.type === 'radio') {
+				type = n.type;
+				const label = labelText(el) || el.getAttribute('aria-label') || el.getAttribute('name') || el.value || el.id || '';
+				text = norm(label) + (el.checked ? ' (checked)' : ' (unchecked)');
+			} else {
+				type = 'input';
+				text = el.getAttribute('placeholder') || el.getAttribute('type') || '';
+			}
+		} else if (n.tag === 'textarea') {
+			kind = type = 'textarea';
+			text = el.getAttribute('placeholder') || 'textarea';
+		} else if (n.tag === 'select') {
+			kind = type = 'select';
+			const label = norm(labelText(el) || el.getAttribute('aria-label') || el.getAttribute('name') || el.id || '');
+			const options = Array.from(el.options).slice(0, maxOptions).map((o) => (o.selected ? '*' : '') + norm(o.label || o.value));
+			text = options.length ? label + ', options: ' + options.join(' | ') : label;
+		} else if (el.hasAttribute('contenteditable') || el.getAttribute('role') === 'textbox') {
+			kind = type = 'editable';
+			text = el.getAttribute('aria-label') || el.getAttribute('placeholder') || 'text field';
+		} else {
+			continue;
+		}
+		if (!usable(n) || (n.visible && tooFar(n.rect))) continue;
+		const bucket = buckets[kind];
+		bucket.push({node: el, type, text, href: el.getAttribute('href') || '', selector: selectorFor(n), index: bucket.length, offscreen: n.visible && offscreen(n.rect),
+			box: {x: n.rect.left + window.scrollX, y: n.rect.top + window.scrollY, width: n.rect.width, height: n.rect.height}});
+	}
+	const elements = kinds.flatMap((k) => buckets[k]);
+
+	let markdown = '';
+	if (maxMarkdown > 0) {
+		const numbered = new Map(elements.map((e, i) => [e.node, {num: i + 1, type: e.type, text: e.text, href: e.href}]));
+		markdown = (` + markdownScript + `)(document.body, numbered, maxMarkdown);
+		for (const e of elements) e.inMarkdown = !!numbered.get(e.node).used;
+	}
+	return {elements: elements.map(({node, ...rest}) => rest), markdown};
+}`
+
+// extractedFrame is the result of extractElementsScript.
+type extractedFrame struct {
+	Elements []extractedElement `json:"elements"`
+	Markdown string             `json:"markdown"`
+}
+
+// extractedElement is one element of the extractElementsScript result.
+type extractedElement struct {
+	Type       string `json:"type"`
+	Text       string `json:"text"`
+	Href       string `json:"href"`
+	Selector   string `json:"selector"`
+	Index      int    `json:"index"`
+	Offscreen  bool   `json:"offscreen"`
+	InMarkdown bool   `json:"inMarkdown"`
+	Box        Box    `json:"box"`
+}
+
+// extractFrameElements finds the interactive elements of a single frame in one
+// Evaluate call instead of a round-trip per element and attribute. Only
+// elements the user could act on without more than a short scroll are kept.
+//
+// With withMarkdown the frame is also rendered as Markdown that refers to the
+// elements by their 1-based position in the returned slice.
+func (m *Manager) extractFrameElements(frame playwright.Frame, withMarkdown bool) ([]ElementInfo, string, error) {
+	maxMarkdown := 0
+	if withMarkdown {
+		maxMarkdown = m.maxMarkdown
+	}
+	result, err := frame.Evaluate(extractElementsScript, map[string]interface{}{
+		"maxOptions":     maxSelectOptions,
+		"maxNameLength":  maxLocatorNameLength,
+		"viewportMargin": m.viewportMargin,
+		"maxMarkdown":    maxMarkdown,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to extract elements: %w", err)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read extracted elements: %w", err)
+	}
+	var found extractedFrame
+	if err := json.Unmarshal(data, &found); err != nil {
+		return nil, "", fmt.Errorf("failed to read extracted elements: %w", err)
+	}
+
+	elements := make([]ElementInfo, 0, len(found.Elements))
+	for _, el := range found.Elements {
+		elements = append(elements, ElementInfo{
+			Type:       el.Type,
+			Text:       capText(el.Text, m.maxElementText),
+			Href:       el.Href,
+			Selector:   el.Selector,
+			Index:      el.Index,
+			Offscreen:  el.Offscreen,
+			InMarkdown: el.InMarkdown,
+			Box:        el.Box,
+		})
+	}
+	markdown := found.Markdown
+	if runes := []rune(markdown); len(runes) > maxMarkdown && maxMarkdown > 0 {
+		markdown = string(runes[:maxMarkdown]) + "\n\n[truncated]"
+	}
+	return elements, markdown, nil
+}
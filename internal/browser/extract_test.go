@@ -0,0 +1,84 @@
+package browser
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestCapText(t *testing.T) {
+	if got := capText("Кремль, Москва", 6); got != "Кремль" {
+		t.Errorf("capText should cut on rune boundaries, got %q", got)
+	}
+	if got := capText("short", 10); got != "short" {
+		t.Errorf("capText should keep short text, got %q", got)
+	}
+	if got := capText("unlimited", 0); got != "unlimited" {
+		t.Errorf("zero limit should disable the cap, got %q", got)
+	}
+}
+
+func TestExtractElementsCapsText(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+
+	url := serveFixture(t, `<html><body><a href="/long">`+strings.Repeat("длинный текст ", 50)+`</a></body></html>`)
+	mgr.SetMaxElementTextLength(40)
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+
+	content, err := mgr.GetPageContent(ctx)
+	if err != nil {
+		t.Fatalf("GetPageContent failed: %v", err)
+	}
+	if len(content.Elements) == 0 {
+		t.Fatalf("expected the link to be extracted")
+	}
+	for _, elem := range content.Elements {
+		if n := utf8.RuneCountInString(elem.Text); n > 40 {
+			t.Errorf("element text not capped at extraction: %d runes", n)
+		}
+	}
+}
+
+func TestExtractElementsFiltersUnusable(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	url := serveFixture(t, `<html><body>
+		<button>Visible</button>
+		<button disabled>Disabled</button>
+		<button aria-disabled="true">Aria disabled</button>
+		<ul style="display:none"><li><a href="/hidden">Hidden item</a></li></ul>
+		<input type="file" id="upload" style="display:none">
+		<div style="height:1200px"></div><button>Below fold</button>
+		<div style="height:20000px"></div><button>Far away</button>
+	</body></html>`)
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("Navigate failed: %v", err)
+	}
+
+	content, err := mgr.GetPageContent(ctx)
+	if err != nil {
+		t.Fatalf("GetPageContent failed: %v", err)
+	}
+	found := map[string]ElementInfo{}
+	for _, el := range content.Elements {
+		found[el.Text] = el
+	}
+	for _, text := range []string{"Disabled", "Aria disabled", "Hidden item", "Far away"} {
+		if _, ok := found[text]; ok {
+			t.Errorf("%q should not be extracted", text)
+		}
+	}
+	if el, ok := found["Visible"]; !ok || el.Offscreen {
+		t.Errorf("expected the visible button on screen, got %+v", el)
+	}
+	if el, ok := found["Below fold"]; !ok || !el.Offscreen {
+		t.Errorf("expected the button below the fold flagged off-screen, got %+v", el)
+	}
+	if _, ok := found["file"]; !ok {
+		t.Errorf("hidden file inputs must stay extractable for uploads")
+	}
+}
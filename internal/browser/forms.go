@@ -0,0 +1,107 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// SelectOption chooses an option of a <select> element by its value or its
+// visible label.
+func (m *Manager) SelectOption(ctx context.Context, selector, option string) error {
+	frame, selector, err := m.resolveFrame(ctx, selector)
+	if err != nil {
+		return err
+	}
+
+	selected, err := frame.SelectOption(selector, playwright.SelectOptionValues{
+		ValuesOrLabels: &[]string{option},
+	})
+	if err != nil {
+		if isPageClosedErr(err) {
+			log.Printf("Warning: page closed during select (possibly CAPTCHA): %v\n", err)
+			return nil
+		}
+		return fmt.Errorf("failed to select option %q: %w", option, err)
+	}
+	if len(selected) == 0 {
+		return fmt.Errorf("no option %q in %s", option, selector)
+	}
+	return nil
+}
+
+// Check ticks a checkbox or selects a radio button.
+func (m *Manager) Check(ctx context.Context, selector string) error {
+	return m.setChecked(ctx, selector, true)
+}
+
+// Uncheck clears a checkbox.
+func (m *Manager) Uncheck(ctx context.Context, selector string) error {
+	return m.setChecked(ctx, selector, false)
+}
+
+func (m *Manager) setChecked(ctx context.Context, selector string, checked bool) error {
+	frame, selector, err := m.resolveFrame(ctx, selector)
+	if err != nil {
+		return err
+	}
+
+	action := "check"
+	if checked {
+		err = frame.Check(selector)
+	} else {
+		action = "uncheck"
+		err = frame.Uncheck(selector)
+	}
+	if err != nil {
+		if isPageClosedErr(err) {
+			log.Printf("Warning: page closed during %s (possibly CAPTCHA): %v\n", action, err)
+			return nil
+		}
+		return fmt.Errorf("failed to %s element: %w", action, err)
+	}
+	return nil
+}
+
+// SetInputFiles attaches local files to a file input, replacing any files
+// selected before.
+func (m *Manager) SetInputFiles(ctx context.Context, selector string, paths []string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("no files to upload")
+	}
+	files := make([]playwright.InputFile, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read upload file: %w", err)
+		}
+		files = append(files, playwright.InputFile{
+			Name:     filepath.Base(path),
+			MimeType: mime.TypeByExtension(filepath.Ext(path)),
+			Buffer:   data,
+		})
+	}
+
+	frame, selector, err := m.resolveFrame(ctx, selector)
+	if err != nil {
+		return err
+	}
+	if err := frame.SetInputFiles(selector, files); err != nil {
+		if isPageClosedErr(err) {
+			log.Printf("Warning: page closed during upload (possibly CAPTCHA): %v\n", err)
+			return nil
+		}
+		return fmt.Errorf("failed to set input files: %w", err)
+	}
+	return nil
+}
+
+func isPageClosedErr(err error) bool {
+	return strings.Contains(err.Error(), "Page closed") || strings.Contains(err.Error(), "page closed")
+}
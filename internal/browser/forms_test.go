@@ -0,0 +1,90 @@
+package browser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFormControls(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	url := serveFixture(t, `<html><body>
+		<label for="city">City</label>
+		<select id="city"><option value="">Choose</option><option value="msk">Moscow</option><option value="spb">Saint Petersburg</option></select>
+		<label><input type="checkbox" id="terms"> I agree</label>
+		<label><input type="radio" name="size" id="large" value="large"> Large</label>
+	</body></html>`)
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("Navigate failed: %v", err)
+	}
+
+	content, err := mgr.GetPageContent(ctx)
+	if err != nil {
+		t.Fatalf("GetPageContent failed: %v", err)
+	}
+	types := map[string]string{}
+	for _, el := range content.Elements {
+		types[el.Type] = el.Text
+	}
+	if !strings.Contains(types["select"], "Saint Petersburg") {
+		t.Errorf("select options not described: %q", types["select"])
+	}
+	if !strings.Contains(types["checkbox"], "I agree (unchecked)") {
+		t.Errorf("checkbox not described: %q", types["checkbox"])
+	}
+
+	if err := mgr.SelectOption(ctx, "#city", "Saint Petersburg"); err != nil {
+		t.Fatalf("SelectOption by label failed: %v", err)
+	}
+	if err := mgr.SelectOption(ctx, "#city", "msk"); err != nil {
+		t.Fatalf("SelectOption by value failed: %v", err)
+	}
+	if err := mgr.SelectOption(ctx, "#city", "Kazan"); err == nil {
+		t.Fatal("expected an error for a missing option")
+	}
+
+	if err := mgr.Check(ctx, "#terms"); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if err := mgr.Check(ctx, "#large"); err != nil {
+		t.Fatalf("Check radio failed: %v", err)
+	}
+	if checked, _ := mgr.page.IsChecked("#terms"); !checked {
+		t.Fatal("checkbox should be checked")
+	}
+	if err := mgr.Uncheck(ctx, "#terms"); err != nil {
+		t.Fatalf("Uncheck failed: %v", err)
+	}
+	if checked, _ := mgr.page.IsChecked("#terms"); checked {
+		t.Fatal("checkbox should be unchecked")
+	}
+}
+
+func TestSetInputFiles(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	url := serveFixture(t, `<html><body><input type="file" id="cv" multiple></body></html>`)
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("Navigate failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	resume := filepath.Join(dir, "resume.txt")
+	if err := os.WriteFile(resume, []byte("Ivan Petrov"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.SetInputFiles(ctx, "#cv", []string{resume}); err != nil {
+		t.Fatalf("SetInputFiles failed: %v", err)
+	}
+	name, err := mgr.page.Evaluate(`() => document.getElementById("cv").files[0].name`)
+	if err != nil || name != "resume.txt" {
+		t.Fatalf("expected resume.txt to be attached, got %v (%v)", name, err)
+	}
+
+	if err := mgr.SetInputFiles(ctx, "#cv", []string{filepath.Join(dir, "missing.pdf")}); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+}
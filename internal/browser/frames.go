@@ -0,0 +1,78 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Selectors of elements inside an iframe are prefixed with the frame they
+// belong to, e.g. `frame=payment >> input[name="card"]` or `frame=2 >> button`.
+const (
+	frameSelectorPrefix    = "frame="
+	frameSelectorSeparator = " >> "
+)
+
+// frameSelector prefixes selector with a frame identifier.
+func frameSelector(frameID, selector string) string {
+	return frameSelectorPrefix + frameID + frameSelectorSeparator + selector
+}
+
+// SplitFrameSelector separates the frame identifier from a prefixed selector.
+func SplitFrameSelector(selector string) (frameID, inner string, ok bool) {
+	rest, found := strings.CutPrefix(strings.TrimSpace(selector), frameSelectorPrefix)
+	if !found {
+		return "", selector, false
+	}
+	frameID, inner, found = strings.Cut(rest, frameSelectorSeparator)
+	if !found || strings.TrimSpace(frameID) == "" || strings.TrimSpace(inner) == "" {
+		return "", selector, false
+	}
+	return strings.TrimSpace(frameID), strings.TrimSpace(inner), true
+}
+
+// frameIdentifier names frames[i] by its name attribute when that is unique,
+// since names survive ads loading before it; otherwise by its index.
+func frameIdentifier(frames []playwright.Frame, i int) string {
+	name := frames[i].Name()
+	if name == "" || strings.Contains(name, frameSelectorSeparator) {
+		return strconv.Itoa(i)
+	}
+	for j, other := range frames {
+		if j != i && other.Name() == name {
+			return strconv.Itoa(i)
+		}
+	}
+	return name
+}
+
+// resolveFrame finds the frame a selector targets on the active page and
+// returns the selector to use inside it. Unprefixed selectors target the main frame.
+func (m *Manager) resolveFrame(ctx context.Context, selector string) (playwright.Frame, string, error) {
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	frameID, inner, ok := SplitFrameSelector(selector)
+	if !ok {
+		return page.MainFrame(), TranslateLocator(selector), nil
+	}
+	inner = TranslateLocator(inner)
+
+	frames := page.Frames()
+	if idx, err := strconv.Atoi(frameID); err == nil {
+		if idx < 0 || idx >= len(frames) {
+			return nil, "", fmt.Errorf("frame %d not found (page has %d frames)", idx, len(frames))
+		}
+		return frames[idx], inner, nil
+	}
+	for _, frame := range frames {
+		if frame.Name() == frameID {
+			return frame, inner, nil
+		}
+	}
+	return nil, "", fmt.Errorf("frame %q not found", frameID)
+}
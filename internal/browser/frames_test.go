@@ -0,0 +1,85 @@
+package browser
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSplitFrameSelector(t *testing.T) {
+	tests := []struct {
+		selector string
+		frameID  string
+		inner    string
+		ok       bool
+	}{
+		{`frame=payment >> input[name="card"]`, "payment", `input[name="card"]`, true},
+		{"frame=2 >> button", "2", "button", true},
+		{"button.login", "", "button.login", false},
+		{"frame= >> button", "", "frame= >> button", false},
+		{"frame=2", "", "frame=2", false},
+	}
+	for _, tt := range tests {
+		frameID, inner, ok := SplitFrameSelector(tt.selector)
+		if frameID != tt.frameID || inner != tt.inner || ok != tt.ok {
+			t.Errorf("SplitFrameSelector(%q) = %q, %q, %v", tt.selector, frameID, inner, ok)
+		}
+	}
+	if got := frameSelector("login", "#user"); got != "frame=login >> #user" {
+		t.Errorf("frameSelector = %q", got)
+	}
+}
+
+func TestIframeElementsAreExtractedAndActionable(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if r.URL.Path == "/login" {
+			_, _ = w.Write([]byte(`<html><body>
+				<input id="user" placeholder="Login">
+				<button id="signin" onclick="document.title = 'signed in as ' + document.getElementById('user').value">Sign in</button>
+			</body></html>`))
+			return
+		}
+		_, _ = w.Write([]byte(`<html><body><h1>Shop</h1><iframe name="login" src="/login"></iframe></body></html>`))
+	}))
+	t.Cleanup(ts.Close)
+
+	if err := mgr.Navigate(ctx, ts.URL); err != nil {
+		t.Fatalf("Navigate failed: %v", err)
+	}
+	content, err := mgr.GetPageContent(ctx)
+	if err != nil {
+		t.Fatalf("GetPageContent failed: %v", err)
+	}
+	var input, button string
+	for _, el := range content.Elements {
+		switch {
+		case el.Type == "input" && el.Text == "Login":
+			input = el.Selector
+		case el.Type == "button" && strings.Contains(el.Text, "Sign in"):
+			button = el.Selector
+		}
+	}
+	if !strings.HasPrefix(input, "frame=login >> ") || !strings.HasPrefix(button, "frame=login >> ") {
+		t.Fatalf("iframe elements not extracted with a frame prefix: input=%q button=%q", input, button)
+	}
+
+	if err := mgr.Fill(ctx, input, "ivan"); err != nil {
+		t.Fatalf("Fill in iframe failed: %v", err)
+	}
+	if err := mgr.Click(ctx, button); err != nil {
+		t.Fatalf("Click in iframe failed: %v", err)
+	}
+	frame, _, err := mgr.resolveFrame(ctx, button)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if title, _ := frame.Title(); title != "signed in as ivan" {
+		t.Fatalf("click did not reach the iframe, title %q", title)
+	}
+}
@@ -0,0 +1,53 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// GoBack returns to the previous page in the active tab's history.
+func (m *Manager) GoBack(ctx context.Context) error {
+	return m.traverseHistory(ctx, "back", func(page playwright.Page) (playwright.Response, error) {
+		return page.GoBack()
+	})
+}
+
+// GoForward moves to the next page in the active tab's history, undoing GoBack.
+func (m *Manager) GoForward(ctx context.Context) error {
+	return m.traverseHistory(ctx, "forward", func(page playwright.Page) (playwright.Response, error) {
+		return page.GoForward()
+	})
+}
+
+// Reload reloads the active page.
+func (m *Manager) Reload(ctx context.Context) error {
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := page.Reload(); err != nil {
+		return fmt.Errorf("failed to reload page: %w", err)
+	}
+	return nil
+}
+
+// traverseHistory runs a history navigation. Playwright returns no response
+// both when there is no history entry and for same-document navigations, so an
+// unchanged URL is what tells the two apart.
+func (m *Manager) traverseHistory(ctx context.Context, direction string, navigate func(playwright.Page) (playwright.Response, error)) error {
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return err
+	}
+	before := page.URL()
+	response, err := navigate(page)
+	if err != nil {
+		return fmt.Errorf("failed to go %s: %w", direction, err)
+	}
+	if response == nil && page.URL() == before {
+		return fmt.Errorf("no page to go %s to in this tab's history", direction)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package browser
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestHistoryNavigation(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	url := serveFixture(t, `<html><body><a href="/second">Second</a></body></html>`)
+
+	if err := mgr.GoBack(ctx); err == nil {
+		t.Fatal("expected an error going back from the first page")
+	}
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+	if err := mgr.Navigate(ctx, url+"/second"); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+
+	if err := mgr.GoBack(ctx); err != nil {
+		t.Fatalf("back failed: %v", err)
+	}
+	if strings.HasSuffix(mgr.page.URL(), "/second") {
+		t.Fatalf("expected to be back on the first page, URL is %s", mgr.page.URL())
+	}
+	if err := mgr.GoForward(ctx); err != nil {
+		t.Fatalf("forward failed: %v", err)
+	}
+	if !strings.HasSuffix(mgr.page.URL(), "/second") {
+		t.Fatalf("expected to be on the second page, URL is %s", mgr.page.URL())
+	}
+	if err := mgr.GoForward(ctx); err == nil {
+		t.Fatal("expected an error going forward from the last page")
+	}
+	if err := mgr.Reload(ctx); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+}
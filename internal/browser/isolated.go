@@ -0,0 +1,154 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// savedContext is the persistent profile context and its tabs, set aside while
+// an isolated context is active.
+type savedContext struct {
+	context      playwright.BrowserContext
+	pages        map[string]playwright.Page
+	pageOrder    []string
+	activePageID string
+}
+
+// Isolated reports whether an isolated context is active.
+func (m *Manager) Isolated() bool {
+	return m.saved != nil
+}
+
+// StartIsolatedContext switches to a fresh incognito context: cookies and
+// storage start empty and are thrown away by EndIsolatedContext, so a task can
+// run without the logins of the persistent profile and without leaving
+// anything behind in it. The profile's tabs stay open and come back afterwards.
+func (m *Manager) StartIsolatedContext(ctx context.Context) error {
+	if m.saved != nil {
+		return fmt.Errorf("an isolated context is already active")
+	}
+	browserCtx, err := m.newIsolatedContext(ctx)
+	if err != nil {
+		return err
+	}
+	m.saved = &savedContext{
+		context:      m.context,
+		pages:        m.pages,
+		pageOrder:    m.pageOrder,
+		activePageID: m.activePageID,
+	}
+	m.context = browserCtx
+	m.rebuildPageTracking(browserCtx)
+	return nil
+}
+
+// EndIsolatedContext closes the isolated context and returns to the
+// persistent profile. It does nothing if no isolated context is active.
+func (m *Manager) EndIsolatedContext(ctx context.Context) error {
+	saved := m.saved
+	if saved == nil {
+		return nil
+	}
+	m.saved = nil
+	if m.context != nil {
+		if err := m.context.Close(); err != nil {
+			log.Printf("Warning: failed to close isolated context: %v\n", err)
+		}
+	}
+
+	m.context = saved.context
+	m.pages = saved.pages
+	m.pageOrder = saved.pageOrder
+	m.setPage(nil)
+	m.activePageID = ""
+	m.pageListeners = make(map[string]struct{})
+	for id := range m.pages {
+		m.pageListeners[id] = struct{}{}
+	}
+	if saved.activePageID != "" {
+		m.setActivePage(saved.activePageID, true)
+	}
+	// Tabs of the profile may have been closed or crashed meanwhile.
+	if _, err := m.activePage(ctx); err != nil {
+		return fmt.Errorf("failed to return to the persistent profile: %w", err)
+	}
+	return nil
+}
+
+// newIsolatedContext opens a context with the manager's launch options on the
+// incognito browser, launching that browser on first use. A persistent
+// context cannot host other contexts, so this is a separate browser process,
+// unless the manager is attached to a remote browser.
+func (m *Manager) newIsolatedContext(ctx context.Context) (playwright.BrowserContext, error) {
+	if err := m.ensurePlaywright(ctx); err != nil {
+		return nil, err
+	}
+	device, err := lookupDevice(m.playwright.Devices, m.launch.Device)
+	if err != nil {
+		return nil, err
+	}
+	// Reuse the persistent context options so both contexts look the same to sites.
+	var persistent playwright.BrowserTypeLaunchPersistentContextOptions
+	m.launch.apply(&persistent, device)
+
+	host := m.incognito
+	if m.browser != nil {
+		// A remote browser can host the isolated context itself.
+		host = m.browser
+	} else if host == nil || !host.IsConnected() {
+		headless, _ := strconv.ParseBool(os.Getenv("BROWSER_HEADLESS"))
+		opts := playwright.BrowserTypeLaunchOptions{
+			Headless:          playwright.Bool(headless),
+			Args:              append(defaultLaunchArgs(), persistent.Args...),
+			IgnoreDefaultArgs: persistent.IgnoreDefaultArgs,
+			Proxy:             persistent.Proxy,
+		}
+		var browser playwright.Browser
+		switch strings.ToLower(strings.TrimSpace(os.Getenv("PLAYWRIGHT_BROWSER"))) {
+		case "firefox":
+			browser, err = m.playwright.Firefox.Launch(opts)
+		case "webkit":
+			browser, err = m.playwright.WebKit.Launch(opts)
+		default:
+			browser, err = m.playwright.Chromium.Launch(opts)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to launch browser for isolated context: %w", err)
+		}
+		m.incognito = browser
+		host = browser
+	}
+
+	browserCtx, err := host.NewContext(playwright.BrowserNewContextOptions{
+		Proxy:             persistent.Proxy,
+		Viewport:          persistent.Viewport,
+		UserAgent:         persistent.UserAgent,
+		DeviceScaleFactor: persistent.DeviceScaleFactor,
+		IsMobile:          persistent.IsMobile,
+		HasTouch:          persistent.HasTouch,
+		Locale:            persistent.Locale,
+		TimezoneId:        persistent.TimezoneId,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create isolated context: %w", err)
+	}
+	// The first page is created before the listeners are attached so that it
+	// is not registered with the tabs of the context being replaced.
+	if _, err := browserCtx.NewPage(); err != nil {
+		_ = browserCtx.Close()
+		return nil, fmt.Errorf("failed to create page in isolated context: %w", err)
+	}
+	if m.launch.Stealth {
+		if err := applyStealth(browserCtx, m.launch); err != nil {
+			log.Printf("Warning: %v\n", err)
+		}
+	}
+	m.attachContextListeners(browserCtx)
+	return browserCtx, nil
+}
@@ -0,0 +1,44 @@
+package browser
+
+import (
+	"context"
+	"testing"
+)
+
+func TestIsolatedContextKeepsProfileCookies(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	url := serveFixture(t, `<html><body>Fixture</body></html>`)
+
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+	profilePage := mgr.page
+	if err := mgr.SetCookies(ctx, []Cookie{{Name: "session", Value: "profile", Domain: "127.0.0.1", Path: "/"}}); err != nil {
+		t.Fatalf("SetCookies failed: %v", err)
+	}
+
+	if err := mgr.StartIsolatedContext(ctx); err != nil {
+		t.Fatalf("StartIsolatedContext failed: %v", err)
+	}
+	if !mgr.Isolated() {
+		t.Fatal("expected an isolated context to be active")
+	}
+	if cookies, err := mgr.GetCookies(ctx); err != nil || len(cookies) != 0 {
+		t.Fatalf("expected no profile cookies in the isolated context, got %v (%v)", cookies, err)
+	}
+	if err := mgr.SetCookies(ctx, []Cookie{{Name: "tracking", Value: "isolated", Domain: "127.0.0.1", Path: "/"}}); err != nil {
+		t.Fatalf("SetCookies failed: %v", err)
+	}
+
+	if err := mgr.EndIsolatedContext(ctx); err != nil {
+		t.Fatalf("EndIsolatedContext failed: %v", err)
+	}
+	if mgr.page != profilePage {
+		t.Fatal("expected the profile tab to be active again")
+	}
+	cookies, err := mgr.GetCookies(ctx)
+	if err != nil || len(cookies) != 1 || cookies[0].Name != "session" {
+		t.Fatalf("expected only the profile cookie after isolation, got %v (%v)", cookies, err)
+	}
+}
@@ -0,0 +1,168 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// LaunchOptions are applied every time the browser context is (re)launched.
+type LaunchOptions struct {
+	Proxy *ProxyConfig
+
+	// CDPEndpoint attaches to a running Chromium over the DevTools protocol
+	// instead of launching one (see ConnectOverCDP). The options below only
+	// apply to locally launched browsers.
+	CDPEndpoint string
+
+	// Device is a Playwright device preset such as "iPhone 14" or "Pixel 7".
+	// The fields below override individual settings of the preset.
+	Device            string
+	Viewport          *Viewport
+	DeviceScaleFactor float64
+	UserAgent         string
+	Mobile            bool // mobile meta viewport and touch events
+
+	// Stealth hides common automation tells (navigator.webdriver, the
+	// headless user agent, an empty plugin list) from sites that challenge
+	// automated browsers with CAPTCHAs. See stealth.go.
+	Stealth bool
+	// Locale (e.g. "de-DE") and TimezoneID (e.g. "Europe/Berlin") should
+	// match the proxy's location; a mismatch is another automation tell.
+	Locale     string
+	TimezoneID string
+}
+
+// Viewport is the page size in CSS pixels.
+type Viewport struct {
+	Width  int
+	Height int
+}
+
+// ParseViewport parses a "WIDTHxHEIGHT" size such as "390x844".
+func ParseViewport(value string) (*Viewport, error) {
+	w, h, ok := strings.Cut(strings.ToLower(strings.TrimSpace(value)), "x")
+	width, errW := strconv.Atoi(strings.TrimSpace(w))
+	height, errH := strconv.Atoi(strings.TrimSpace(h))
+	if !ok || errW != nil || errH != nil || width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid viewport %q, expected WIDTHxHEIGHT", value)
+	}
+	return &Viewport{Width: width, Height: height}, nil
+}
+
+// lookupDevice finds a device preset by name, ignoring case.
+func lookupDevice(devices map[string]*playwright.DeviceDescriptor, name string) (*playwright.DeviceDescriptor, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, nil
+	}
+	if device, ok := devices[name]; ok {
+		return device, nil
+	}
+	for known, device := range devices {
+		if strings.EqualFold(known, name) {
+			return device, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown device %q", name)
+}
+
+// ProxyConfig routes all browser traffic through a proxy server.
+type ProxyConfig struct {
+	Server   string   // e.g. http://proxy.corp:3128 or socks5://127.0.0.1:1080
+	Username string   // optional
+	Password string   // optional
+	Bypass   []string // hosts that skip the proxy, e.g. localhost, .internal.example
+}
+
+// ParseProxyBypass splits a comma-separated bypass list.
+func ParseProxyBypass(list string) []string {
+	var hosts []string
+	for _, host := range strings.Split(list, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+func (p *ProxyConfig) playwrightProxy() *playwright.Proxy {
+	if p == nil || strings.TrimSpace(p.Server) == "" {
+		return nil
+	}
+	proxy := &playwright.Proxy{Server: strings.TrimSpace(p.Server)}
+	if p.Username != "" {
+		proxy.Username = playwright.String(p.Username)
+		proxy.Password = playwright.String(p.Password)
+	}
+	if len(p.Bypass) > 0 {
+		proxy.Bypass = playwright.String(strings.Join(p.Bypass, ","))
+	}
+	return proxy
+}
+
+// apply sets the launch options on opts. device is the resolved preset for
+// o.Device, or nil.
+func (o LaunchOptions) apply(opts *playwright.BrowserTypeLaunchPersistentContextOptions, device *playwright.DeviceDescriptor) {
+	if proxy := o.Proxy.playwrightProxy(); proxy != nil {
+		opts.Proxy = proxy
+	}
+
+	if device != nil {
+		if device.Viewport != nil {
+			opts.Viewport = &playwright.Size{Width: device.Viewport.Width, Height: device.Viewport.Height}
+		}
+		if device.UserAgent != "" {
+			opts.UserAgent = playwright.String(device.UserAgent)
+		}
+		if device.DeviceScaleFactor > 0 {
+			opts.DeviceScaleFactor = playwright.Float(device.DeviceScaleFactor)
+		}
+		opts.IsMobile = playwright.Bool(device.IsMobile)
+		opts.HasTouch = playwright.Bool(device.HasTouch)
+	}
+
+	if o.Viewport != nil {
+		opts.Viewport = &playwright.Size{Width: o.Viewport.Width, Height: o.Viewport.Height}
+	}
+	if o.DeviceScaleFactor > 0 {
+		opts.DeviceScaleFactor = playwright.Float(o.DeviceScaleFactor)
+	}
+	if o.UserAgent != "" {
+		opts.UserAgent = playwright.String(o.UserAgent)
+	}
+	if o.Mobile {
+		opts.IsMobile = playwright.Bool(true)
+		opts.HasTouch = playwright.Bool(true)
+	}
+	if o.Locale != "" {
+		opts.Locale = playwright.String(o.Locale)
+	}
+	if o.TimezoneID != "" {
+		opts.TimezoneId = playwright.String(o.TimezoneID)
+	}
+	if o.Stealth {
+		opts.Args = append(opts.Args, stealthArgs...)
+		opts.IgnoreDefaultArgs = []string{"--enable-automation"}
+	}
+}
+
+// SetProxy switches to another proxy (nil for a direct connection), e.g. to
+// rotate proxies between scraping tasks. A proxy is fixed when the context is
+// launched, so the running context is relaunched on the same profile.
+func (m *Manager) SetProxy(ctx context.Context, proxy *ProxyConfig) error {
+	if proxy != nil && strings.TrimSpace(proxy.Server) == "" {
+		return fmt.Errorf("proxy server is required")
+	}
+	m.launch.Proxy = proxy
+	if m.context == nil {
+		return nil
+	}
+	if err := m.RecoverBrowser(ctx); err != nil {
+		return fmt.Errorf("failed to relaunch browser with new proxy: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package browser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+func TestParseProxyBypass(t *testing.T) {
+	got := ParseProxyBypass(" localhost, .internal.example ,,")
+	want := []string{"localhost", ".internal.example"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseProxyBypass = %v, want %v", got, want)
+	}
+}
+
+func TestLaunchOptionsApplyProxy(t *testing.T) {
+	var opts playwright.BrowserTypeLaunchPersistentContextOptions
+	LaunchOptions{}.apply(&opts, nil)
+	if opts.Proxy != nil {
+		t.Fatalf("expected no proxy without config, got %+v", opts.Proxy)
+	}
+
+	LaunchOptions{Proxy: &ProxyConfig{
+		Server:   "http://proxy.corp:3128",
+		Username: "bot",
+		Password: "secret",
+		Bypass:   []string{"localhost", ".internal.example"},
+	}}.apply(&opts, nil)
+	if opts.Proxy == nil || opts.Proxy.Server != "http://proxy.corp:3128" {
+		t.Fatalf("proxy server not applied: %+v", opts.Proxy)
+	}
+	if *opts.Proxy.Username != "bot" || *opts.Proxy.Password != "secret" {
+		t.Fatalf("proxy credentials not applied: %+v", opts.Proxy)
+	}
+	if *opts.Proxy.Bypass != "localhost,.internal.example" {
+		t.Fatalf("unexpected bypass list %q", *opts.Proxy.Bypass)
+	}
+}
+
+func TestParseViewport(t *testing.T) {
+	vp, err := ParseViewport(" 390X844 ")
+	if err != nil || vp.Width != 390 || vp.Height != 844 {
+		t.Fatalf("ParseViewport = %+v, %v", vp, err)
+	}
+	for _, bad := range []string{"", "390", "0x844", "wide x tall"} {
+		if _, err := ParseViewport(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestLaunchOptionsApplyDevice(t *testing.T) {
+	devices := map[string]*playwright.DeviceDescriptor{
+		"iPhone 14": {
+			UserAgent:         "Mozilla/5.0 (iPhone)",
+			Viewport:          &playwright.Size{Width: 390, Height: 664},
+			DeviceScaleFactor: 3,
+			IsMobile:          true,
+			HasTouch:          true,
+		},
+	}
+	if _, err := lookupDevice(devices, "Nokia 3310"); err == nil {
+		t.Fatal("expected error for unknown device")
+	}
+	device, err := lookupDevice(devices, "iphone 14")
+	if err != nil {
+		t.Fatalf("lookupDevice failed: %v", err)
+	}
+
+	var opts playwright.BrowserTypeLaunchPersistentContextOptions
+	LaunchOptions{Device: "iPhone 14", Viewport: &Viewport{Width: 400, Height: 800}}.apply(&opts, device)
+	if opts.Viewport.Width != 400 || opts.Viewport.Height != 800 {
+		t.Fatalf("explicit viewport should override the preset, got %+v", opts.Viewport)
+	}
+	if *opts.UserAgent != "Mozilla/5.0 (iPhone)" || *opts.DeviceScaleFactor != 3 || !*opts.IsMobile || !*opts.HasTouch {
+		t.Fatalf("device preset not applied: %+v", opts)
+	}
+}
+
+func TestLaunchOptionsApplyStealth(t *testing.T) {
+	opts := playwright.BrowserTypeLaunchPersistentContextOptions{Args: defaultLaunchArgs()}
+	LaunchOptions{Stealth: true, Locale: "de-DE", TimezoneID: "Europe/Berlin"}.apply(&opts, nil)
+	if !reflect.DeepEqual(opts.Args[len(opts.Args)-len(stealthArgs):], stealthArgs) {
+		t.Fatalf("stealth args not applied: %v", opts.Args)
+	}
+	if !reflect.DeepEqual(opts.IgnoreDefaultArgs, []string{"--enable-automation"}) {
+		t.Fatalf("automation switch not dropped: %v", opts.IgnoreDefaultArgs)
+	}
+	if *opts.Locale != "de-DE" || *opts.TimezoneId != "Europe/Berlin" {
+		t.Fatalf("locale/timezone not applied: %v %v", *opts.Locale, *opts.TimezoneId)
+	}
+}
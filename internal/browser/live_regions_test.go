@@ -0,0 +1,38 @@
+package browser
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGetLiveRegionsAfterClick(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+
+	url := serveFixture(t, `<html><body>
+		<button id="search" onclick="document.getElementById('status').textContent = '3 results found'">Search</button>
+		<div id="status" role="status"></div>
+	</body></html>`)
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+
+	before, err := mgr.GetLiveRegions(ctx)
+	if err != nil {
+		t.Fatalf("GetLiveRegions failed: %v", err)
+	}
+	if len(before) != 0 {
+		t.Fatalf("expected no announcements before click, got %v", before)
+	}
+
+	if err := mgr.Click(ctx, "#search"); err != nil {
+		t.Fatalf("click failed: %v", err)
+	}
+	after, err := mgr.GetLiveRegions(ctx)
+	if err != nil {
+		t.Fatalf("GetLiveRegions failed: %v", err)
+	}
+	if len(after) != 1 || after[0] != "3 results found" {
+		t.Fatalf("expected announcement to be captured, got %v", after)
+	}
+}
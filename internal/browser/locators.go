@@ -0,0 +1,54 @@
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Besides CSS, selectors may be Playwright-style locators that survive DOM
+// changes:
+//
+//	role=button[name="Sign in"]   like getByRole("button", {name: "Sign in"})
+//	text="Sign in"                like getByText("Sign in")
+//	placeholder="Search"          like getByPlaceholder("Search")
+//	label="Email"                 like getByLabel("Email")
+//
+// role= and text= are native Playwright selector engines; placeholder= and
+// label= are translated by TranslateLocator.
+const (
+	placeholderLocatorPrefix = "placeholder="
+	labelLocatorPrefix       = "label="
+)
+
+// TranslateLocator rewrites the locator forms Playwright has no public engine
+// for into selectors it understands. Other selectors are returned unchanged.
+func TranslateLocator(selector string) string {
+	trimmed := strings.TrimSpace(selector)
+	if value, ok := strings.CutPrefix(trimmed, placeholderLocatorPrefix); ok {
+		return fmt.Sprintf(`[placeholder=%s]`, quoteLocatorValue(unquoteLocatorValue(value)))
+	}
+	if value, ok := strings.CutPrefix(trimmed, labelLocatorPrefix); ok {
+		return fmt.Sprintf(`internal:label=%si`, quoteLocatorValue(unquoteLocatorValue(value)))
+	}
+	return selector
+}
+
+// roleLocator builds a role= locator matching the accessible name.
+func roleLocator(role, name string) string {
+	return fmt.Sprintf(`role=%s[name=%s]`, role, quoteLocatorValue(name))
+}
+
+func quoteLocatorValue(value string) string {
+	quoted, _ := json.Marshal(value)
+	return string(quoted)
+}
+
+func unquoteLocatorValue(value string) string {
+	value = strings.TrimSpace(value)
+	var unquoted string
+	if strings.HasPrefix(value, `"`) && json.Unmarshal([]byte(value), &unquoted) == nil {
+		return unquoted
+	}
+	return value
+}
@@ -0,0 +1,76 @@
+package browser
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTranslateLocator(t *testing.T) {
+	tests := map[string]string{
+		`placeholder="Search"`:        `[placeholder="Search"]`,
+		`placeholder=Search maps`:     `[placeholder="Search maps"]`,
+		`label="Email"`:               `internal:label="Email"i`,
+		`role=button[name="Sign in"]`: `role=button[name="Sign in"]`,
+		`text="Sign in"`:              `text="Sign in"`,
+		`div:nth-of-type(2) > button`: `div:nth-of-type(2) > button`,
+		`placeholder="Say \"hi\""`:    `[placeholder="Say \"hi\""]`,
+	}
+	for in, want := range tests {
+		if got := TranslateLocator(in); got != want {
+			t.Errorf("TranslateLocator(%s) = %s, want %s", in, got, want)
+		}
+	}
+	if got := roleLocator("button", `Say "hi"`); got != `role=button[name="Say \"hi\""]` {
+		t.Errorf("roleLocator escaped incorrectly: %s", got)
+	}
+}
+
+func TestSemanticLocatorsSurviveDOMChanges(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	url := serveFixture(t, `<html><body><div id="app">
+		<div><button onclick="document.title = 'saved'">Save draft</button></div>
+		<div><input placeholder="Search articles"></div>
+		<label for="mail">Email</label><div><input id="mail" placeholder="you@example.com"></div>
+		<div><button>Save</button><button>Save</button></div>
+	</div></body></html>`)
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("Navigate failed: %v", err)
+	}
+
+	content, err := mgr.GetPageContent(ctx)
+	if err != nil {
+		t.Fatalf("GetPageContent failed: %v", err)
+	}
+	selectors := map[string]string{}
+	for _, el := range content.Elements {
+		selectors[el.Text] = el.Selector
+	}
+	if got := selectors["Save draft"]; got != `role=button[name="Save draft"]` {
+		t.Fatalf("expected a role locator for the button, got %q", got)
+	}
+	if got := selectors["Search articles"]; got != `role=textbox[name="Search articles"]` && got != `placeholder="Search articles"` {
+		t.Fatalf("expected a semantic locator for the search field, got %q", got)
+	}
+	if got := selectors["Save"]; strings.HasPrefix(got, "role=") {
+		t.Fatalf("ambiguous buttons must not get a role locator, got %q", got)
+	}
+
+	// Insert content before the button: an nth-of-type path would now be stale.
+	if _, err := mgr.page.Evaluate(`() => document.getElementById("app").prepend(document.createElement("div"))`); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.Click(ctx, selectors["Save draft"]); err != nil {
+		t.Fatalf("Click with role locator failed: %v", err)
+	}
+	if title, _ := mgr.page.Title(); title != "saved" {
+		t.Fatalf("role locator hit the wrong element, title %q", title)
+	}
+	if err := mgr.Fill(ctx, `placeholder="Search articles"`, "go"); err != nil {
+		t.Fatalf("Fill with placeholder locator failed: %v", err)
+	}
+	if err := mgr.Fill(ctx, `label="Email"`, "ivan@example.com"); err != nil {
+		t.Fatalf("Fill with label locator failed: %v", err)
+	}
+}
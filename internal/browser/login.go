@@ -0,0 +1,173 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// loginAttr marks the sign-in fields FindLoginForm found, so they can be
+// filled by selector.
+const loginAttr = "data-aibot-login"
+
+// Selectors of the fields FindLoginForm marks.
+const (
+	usernameFieldSelector = "[" + loginAttr + "=username]"
+	passwordFieldSelector = "[" + loginAttr + "=password]"
+)
+
+// LoginForm tells which sign-in fields are visible on the active page. Sites
+// that ask for the username and the password on separate pages show one at
+// a time.
+type LoginForm struct {
+	Username bool `json:"username"`
+	Password bool `json:"password"`
+}
+
+// findLoginFormScript marks the visible password field and the username
+// field that goes with it: the one its autocomplete, name or id calls a
+// username, login, email or phone, else the last text field before the
+// password in the same form.
+const findLoginFormScript = `(attr) => {
+	const visible = el => {
+		const rect = el.getBoundingClientRect();
+		const style = getComputedStyle(el);
+		return rect.width > 0 && rect.height > 0 && style.visibility !== 'hidden' && style.display !== 'none';
+	};
+	document.querySelectorAll('[' + attr + ']').forEach(el => el.removeAttribute(attr));
+	const password = [...document.querySelectorAll('input[type=password]')].find(visible);
+	const scope = (password && password.form) || document;
+	const texts = [...scope.querySelectorAll('input')].filter(el =>
+		visible(el) && !el.disabled && ['', 'text', 'email', 'tel'].includes((el.getAttribute('type') || '').toLowerCase()));
+	let username = texts.find(el => /username|email/.test(el.autocomplete || '')) ||
+		texts.find(el => /user|login|email|phone|account/i.test((el.name || '') + ' ' + (el.id || '')));
+	if (!username && password) {
+		username = texts.filter(el => el.compareDocumentPosition(password) & Node.DOCUMENT_POSITION_FOLLOWING).pop();
+	}
+	if (username) username.setAttribute(attr, 'username');
+	if (password) password.setAttribute(attr, 'password');
+	return {username: !!username, password: !!password};
+}`
+
+// FindLoginForm looks for sign-in fields on the active page.
+func (m *Manager) FindLoginForm(ctx context.Context) (LoginForm, error) {
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return LoginForm{}, err
+	}
+	found, err := page.Evaluate(findLoginFormScript, loginAttr)
+	if err != nil {
+		return LoginForm{}, fmt.Errorf("failed to look for a login form: %w", err)
+	}
+	fields, _ := found.(map[string]interface{})
+	username, _ := fields["username"].(bool)
+	password, _ := fields["password"].(bool)
+	return LoginForm{Username: username, Password: password}, nil
+}
+
+// FillLogin types the credentials into the sign-in fields on the active
+// page and submits them with Enter. It returns the fields it found; with
+// neither, nothing is typed.
+func (m *Manager) FillLogin(ctx context.Context, username, password string) (LoginForm, error) {
+	form, err := m.FindLoginForm(ctx)
+	if err != nil || (!form.Username && !form.Password) {
+		return form, err
+	}
+	if form.Username && username != "" {
+		if err := m.Fill(ctx, usernameFieldSelector, username); err != nil {
+			return form, err
+		}
+	}
+	if form.Password {
+		if err := m.Fill(ctx, passwordFieldSelector, password); err != nil {
+			return form, err
+		}
+	}
+	// Fill leaves the focus in the last field, where Enter submits its form.
+	if err := m.PressKey(ctx, "Enter"); err != nil {
+		return form, err
+	}
+	return form, nil
+}
+
+// authCookieName matches the names sites commonly give session cookies.
+var authCookieName = regexp.MustCompile(`(?i)sess|auth|token|sid|jwt|login|user|remember`)
+
+// LoginWatch tells whether a login happened since it was started. A page
+// without a sign-in form proves nothing by itself, as a start page may just
+// not show one, so a login is a change: the sign-in form was seen and is
+// gone, or the site set a session cookie it did not have before.
+type LoginWatch struct {
+	mgr     *Manager
+	check   string
+	cookies map[string]string // domain and name of each cookie to its value
+	sawForm bool
+}
+
+// WatchLogin starts watching the active page for a login. With check, the
+// login is instead told by that selector, one found only when logged in.
+func (m *Manager) WatchLogin(ctx context.Context, check string) (*LoginWatch, error) {
+	w := &LoginWatch{mgr: m, check: check, cookies: make(map[string]string)}
+	if check != "" {
+		return w, nil
+	}
+	cookies, err := m.GetCookies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cookies {
+		w.cookies[c.Domain+" "+c.Name] = c.Value
+	}
+	if _, err := w.formShown(ctx); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// LoggedIn reports whether the watched login has happened.
+func (w *LoginWatch) LoggedIn(ctx context.Context) (bool, error) {
+	if w.check != "" {
+		return w.mgr.ElementExists(ctx, w.check)
+	}
+	shown, err := w.formShown(ctx)
+	if err != nil || shown {
+		return false, err
+	}
+	if w.sawForm {
+		return true, nil
+	}
+	return w.newSessionCookie(ctx)
+}
+
+// formShown tells whether the page asks to sign in: it has a password field,
+// or a username field on a sign-in URL.
+func (w *LoginWatch) formShown(ctx context.Context) (bool, error) {
+	form, err := w.mgr.FindLoginForm(ctx)
+	if err != nil {
+		return false, err
+	}
+	path := strings.ToLower(w.mgr.CurrentURL())
+	signIn := strings.Contains(path, "login") || strings.Contains(path, "signin") || strings.Contains(path, "sign-in")
+	shown := form.Password || (form.Username && signIn)
+	w.sawForm = w.sawForm || shown
+	return shown, nil
+}
+
+// newSessionCookie tells whether a cookie that looks like a session, by its
+// name or by being HttpOnly, was set or changed since the watch started.
+func (w *LoginWatch) newSessionCookie(ctx context.Context) (bool, error) {
+	cookies, err := w.mgr.GetCookies(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range cookies {
+		if c.Value == "" || !(c.HttpOnly || authCookieName.MatchString(c.Name)) {
+			continue
+		}
+		if old, ok := w.cookies[c.Domain+" "+c.Name]; !ok || old != c.Value {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,92 @@
+package browser
+
+import (
+	"context"
+	"testing"
+)
+
+const loginFixture = `<html><body>
+<form id="search"><input name="q" placeholder="Search"></form>
+<form action="/done" onsubmit="event.preventDefault(); document.title = this.elements[1].value + ':' + this.elements[2].value">
+	<input type="hidden" name="token" value="x">
+	<input id="who" type="email">
+	<input type="password">
+</form>
+</body></html>`
+
+func TestFillLogin(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	if err := mgr.Navigate(ctx, serveFixture(t, loginFixture)); err != nil {
+		t.Fatalf("Navigate failed: %v", err)
+	}
+
+	form, err := mgr.FillLogin(ctx, "me@example.com", "hunter2")
+	if err != nil {
+		t.Fatalf("FillLogin failed: %v", err)
+	}
+	if !form.Username || !form.Password {
+		t.Fatalf("login fields not found: %+v", form)
+	}
+	title, err := mgr.page.Title()
+	if err != nil || title != "me@example.com:hunter2" {
+		t.Errorf("form submitted with %q, %v", title, err)
+	}
+
+	if err := mgr.Navigate(ctx, serveFixture(t, `<html><body><input name="q"></body></html>`)); err != nil {
+		t.Fatalf("Navigate failed: %v", err)
+	}
+	if form, err := mgr.FindLoginForm(ctx); err != nil || form.Username || form.Password {
+		t.Errorf("a page without a login form gave %+v, %v", form, err)
+	}
+}
+
+func TestWatchLoginNeedsAChange(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	if err := mgr.Navigate(ctx, serveFixture(t, `<html><body><h1>Welcome</h1><a href="/signin">Sign in</a></body></html>`)); err != nil {
+		t.Fatalf("Navigate failed: %v", err)
+	}
+
+	watch, err := mgr.WatchLogin(ctx, "")
+	if err != nil {
+		t.Fatalf("WatchLogin failed: %v", err)
+	}
+	if ok, err := watch.LoggedIn(ctx); err != nil || ok {
+		t.Fatalf("a start page without a login form counted as logged in: %v, %v", ok, err)
+	}
+	if _, err := mgr.Evaluate(ctx, `document.cookie = "theme=dark"`); err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if ok, err := watch.LoggedIn(ctx); err != nil || ok {
+		t.Errorf("a cookie unrelated to a session counted as a login: %v, %v", ok, err)
+	}
+	if _, err := mgr.Evaluate(ctx, `document.cookie = "session_id=abc123"`); err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if ok, err := watch.LoggedIn(ctx); err != nil || !ok {
+		t.Errorf("a new session cookie should count as a login: %v, %v", ok, err)
+	}
+}
+
+func TestWatchLoginSeesTheFormGo(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	if err := mgr.Navigate(ctx, serveFixture(t, loginFixture)); err != nil {
+		t.Fatalf("Navigate failed: %v", err)
+	}
+
+	watch, err := mgr.WatchLogin(ctx, "")
+	if err != nil {
+		t.Fatalf("WatchLogin failed: %v", err)
+	}
+	if ok, err := watch.LoggedIn(ctx); err != nil || ok {
+		t.Fatalf("a page with a login form counted as logged in: %v, %v", ok, err)
+	}
+	if _, err := mgr.Evaluate(ctx, `document.querySelector('input[type=password]').form.remove()`); err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if ok, err := watch.LoggedIn(ctx); err != nil || !ok {
+		t.Errorf("the login form going away should count as a login: %v, %v", ok, err)
+	}
+}
@@ -6,44 +6,116 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/playwright-community/playwright-go"
 )
 
 // Manager handles browser automation with persistent sessions
 type Manager struct {
-	browser    playwright.Browser
+	browser playwright.Browser
+	// page is the active page. Only the goroutine driving the browser sets
+	// it, through setPage; pageMu lets CaptureViewport read it from others.
 	page       playwright.Page
+	pageMu     sync.RWMutex
 	context    playwright.BrowserContext
 	playwright *playwright.Playwright
 
+	profile     string
+	userDataDir string
+	ephemeral   bool // userDataDir is a temporary directory removed on Close
+	launch      LaunchOptions
+
+	incognito playwright.Browser // hosts isolated contexts; launched on first use
+	saved     *savedContext      // the persistent context while an isolated one is active
+
 	pageListeners    map[string]struct{}
 	contextListeners map[string]struct{}
 	pages            map[string]playwright.Page
 	pageOrder        []string
 	activePageID     string
+
+	waitStrategies  map[string]WaitStrategy
+	readinessChecks map[string]ReadinessCheck
+	lastSettledURL  string
+	navTimeout      time.Duration
+	maxElementText  int
+	viewportMargin  int
+	maxMarkdown     int
+
+	popupRules         []PopupRule
+	defaultPopupPolicy PopupPolicy
+
+	consoleMu      sync.Mutex
+	consoleEntries []ConsoleEntry
+
+	networkMu      sync.Mutex
+	networkEntries []NetworkEntry
+
+	downloadMu       sync.Mutex
+	downloads        []Download
+	downloadsPending int
 }
 
-// NewManager initializes a new browser manager
+// NewManager initializes a new browser manager using the profile named by BROWSER_PROFILE
 func NewManager(ctx context.Context) (*Manager, error) {
+	return NewManagerWithProfile(ctx, os.Getenv("BROWSER_PROFILE"))
+}
+
+// NewManagerWithProfile initializes a browser manager on a named profile ("" for the default)
+func NewManagerWithProfile(ctx context.Context, profile string) (*Manager, error) {
+	return NewManagerWithOptions(ctx, profile, LaunchOptions{})
+}
+
+// NewManagerWithOptions initializes a browser manager on a named profile with
+// extra launch options such as a proxy.
+func NewManagerWithOptions(ctx context.Context, profile string, launch LaunchOptions) (*Manager, error) {
+	// Persistent session: use a user-data-dir so manual logins persist across restarts.
+	// BROWSER_EPHEMERAL uses a throwaway dir instead, e.g. for CI runs that
+	// start from BROWSER_STORAGE_STATE.
+	ephemeral := ephemeralFromEnv()
+	remote := strings.TrimSpace(launch.CDPEndpoint) != ""
+	var userDataDir string
+	var err error
+	if remote {
+		// The remote browser keeps its own profile.
+		ephemeral = false
+	} else if ephemeral {
+		userDataDir, err = os.MkdirTemp("", "aibot-profile-*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temporary user data dir: %w", err)
+		}
+	} else {
+		userDataDir, err = ProfileDir(profile)
+		if err != nil {
+			return nil, err
+		}
+		if err := acquireProfileLock(userDataDir); err != nil {
+			return nil, err
+		}
+	}
+	release := func() { releaseUserDataDir(userDataDir, ephemeral) }
+
 	pw, err := playwright.Run()
 	if err != nil {
+		release()
 		return nil, fmt.Errorf("failed to run playwright: %w", err)
 	}
 
-	// Persistent session: use a user-data-dir so manual logins persist across restarts
-	userDataDir := os.Getenv("BROWSER_USER_DATA_DIR")
-	if userDataDir == "" {
-		userDataDir = ".pw_user_data"
-	}
-	if err := os.MkdirAll(userDataDir, 0o755); err != nil {
-		log.Printf("Warning: failed to ensure user data dir: %v\n", err)
+	var remoteBrowser playwright.Browser
+	var browserCtx playwright.BrowserContext
+	if remote {
+		launch.CDPEndpoint = strings.TrimSpace(launch.CDPEndpoint)
+		remoteBrowser, browserCtx, err = connectOverCDP(pw, launch.CDPEndpoint)
+	} else {
+		browserCtx, err = launchPersistentWithFallback(pw, userDataDir, defaultLaunchArgs(), launch)
 	}
-
-	browserCtx, err := launchPersistentWithFallback(pw, userDataDir, defaultLaunchArgs())
 	if err != nil {
+		release()
 		return nil, err
 	}
 
@@ -55,15 +127,30 @@ func NewManager(ctx context.Context) (*Manager, error) {
 	}
 
 	manager := &Manager{
-		browser:          nil,
+		browser:          remoteBrowser,
 		context:          browserCtx,
 		playwright:       pw,
+		profile:          profile,
+		userDataDir:      userDataDir,
+		ephemeral:        ephemeral,
+		launch:           launch,
 		pageListeners:    make(map[string]struct{}),
 		contextListeners: make(map[string]struct{}),
 		pages:            make(map[string]playwright.Page),
-	}
+		waitStrategies:   loadWaitStrategiesFromEnv(),
+		readinessChecks:  loadReadinessChecksFromEnv(),
+		maxElementText:   envInt("BROWSER_MAX_ELEMENT_TEXT", defaultMaxElementText),
+		navTimeout:       envDuration("BROWSER_NAVIGATION_TIMEOUT"),
+		viewportMargin:   envInt("BROWSER_VIEWPORT_MARGIN", defaultViewportMargin),
+		maxMarkdown:      envInt("BROWSER_MAX_MARKDOWN", defaultMaxMarkdown),
+	}
+	manager.loadPopupRulesFromEnv()
 	manager.attachContextListeners(browserCtx)
 	manager.rebuildPageTracking(browserCtx)
+	if err := manager.loadStorageStateFromEnv(ctx); err != nil {
+		_ = manager.Close(ctx)
+		return nil, err
+	}
 	return manager, nil
 }
 
@@ -85,15 +172,19 @@ func (m *Manager) RecoverBrowser(ctx context.Context) error {
 	// Close old resources
 	m.cleanupCurrentContext()
 
-	// Reinitialize
-	pw := m.playwright
-	userDataDir := os.Getenv("BROWSER_USER_DATA_DIR")
-	if userDataDir == "" {
-		userDataDir = ".pw_user_data"
+	// An isolated context is replaced by a fresh one; the profile stays put.
+	if m.saved != nil {
+		browserCtx, err := m.newIsolatedContext(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to recover browser: %w", err)
+		}
+		m.context = browserCtx
+		m.rebuildPageTracking(browserCtx)
+		return nil
 	}
 
-	// Try to create new context
-	browserCtx, err := launchPersistentWithFallback(pw, userDataDir, defaultLaunchArgs())
+	// Reinitialize: try to create new context
+	browserCtx, err := m.launchContext()
 	if err != nil {
 		return fmt.Errorf("failed to recover browser: %w", err)
 	}
@@ -110,6 +201,34 @@ func (m *Manager) RecoverBrowser(ctx context.Context) error {
 	return nil
 }
 
+// activePage returns the current page. If the active page has gone away (e.g.
+// closed by the site mid-task), another open tab is activated or a new one is
+// opened before falling back to full browser recovery.
+func (m *Manager) activePage(ctx context.Context) (playwright.Page, error) {
+	if m.page != nil && m.page.IsClosed() {
+		// The close event may not have been dispatched yet.
+		m.handlePageClosed(m.page)
+	}
+	if m.page == nil && len(m.pageOrder) > 0 {
+		m.setActivePage(m.pageOrder[len(m.pageOrder)-1], true)
+	}
+	if m.page == nil && m.context != nil {
+		if page, err := m.context.NewPage(); err == nil {
+			m.registerPage(page, true)
+			m.setActivePage(pageIdentifier(page), false)
+		}
+	}
+	if err := m.ensureBrowser(ctx); err != nil {
+		return nil, fmt.Errorf("browser not available: %w", err)
+	}
+
+	page := m.page
+	if page == nil {
+		return nil, fmt.Errorf("browser not available: no active page")
+	}
+	return page, nil
+}
+
 // ensurePlaywright makes sure the playwright runtime is running; if not, it starts a new one.
 func (m *Manager) ensurePlaywright(ctx context.Context) error {
 	if m.playwright != nil {
@@ -146,12 +265,8 @@ func (m *Manager) ensureBrowser(ctx context.Context) error {
 		return err
 	}
 
-	// Launch a persistent context similar to NewManager
-	userDataDir := os.Getenv("BROWSER_USER_DATA_DIR")
-	if userDataDir == "" {
-		userDataDir = ".pw_user_data"
-	}
-	browserCtx, err := launchPersistentWithFallback(m.playwright, userDataDir, defaultLaunchArgs())
+	// Launch a persistent context (or reconnect) similar to NewManager
+	browserCtx, err := m.launchContext()
 	if err != nil {
 		return fmt.Errorf("failed to restart browser context: %w", err)
 	}
@@ -171,12 +286,13 @@ func (m *Manager) ensureBrowser(ctx context.Context) error {
 // Navigate goes to a specific URL
 // If the page closes (e.g., due to CAPTCHA), it gracefully handles the error
 func (m *Manager) Navigate(ctx context.Context, url string) error {
-	if err := m.ensureBrowser(ctx); err != nil {
-		return fmt.Errorf("browser not available: %w", err)
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return err
 	}
 
 	url = normalizeURL(url)
-	if _, err := m.page.Goto(url); err != nil {
+	if _, err := page.Goto(url); err != nil {
 		// Check if error is due to page closure (common with CAPTCHA challenges)
 		errMsg := err.Error()
 		if strings.Contains(errMsg, "Page closed") || strings.Contains(errMsg, "page closed") {
@@ -192,183 +308,212 @@ func (m *Manager) Navigate(ctx context.Context, url string) error {
 
 // GetPageContent extracts structured information from the current page
 func (m *Manager) GetPageContent(ctx context.Context) (PageContent, error) {
-	if err := m.ensureBrowser(ctx); err != nil {
-		return PageContent{}, fmt.Errorf("browser not available: %w", err)
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return PageContent{}, err
 	}
 
 	// Get title
-	title, err := m.page.Title()
+	title, err := page.Title()
 	if err != nil {
 		title = "Unknown"
 	}
 
 	// Get URL
-	url := m.page.URL()
+	url := page.URL()
 
 	// Extract all interactive elements
-	elements, err := m.extractElements(ctx)
+	elements, markdown, err := m.extractElements(ctx, page)
 	if err != nil {
 		log.Printf("Warning: failed to extract elements: %v\n", err)
 		elements = []ElementInfo{}
 	}
 
 	// Get main text content
-	mainText, err := m.page.TextContent("body")
+	mainText, err := page.TextContent("body")
 	if err != nil {
 		mainText = ""
 	}
 
+	liveRegions, _ := readLiveRegions(page)
+	status, challenge := m.pageStatus(page, url)
+
 	return PageContent{
-		Title:    title,
-		URL:      url,
-		Elements: elements,
-		MainText: mainText,
+		Title:         title,
+		URL:           url,
+		Status:        status,
+		Challenge:     challenge,
+		Elements:      elements,
+		MainText:      mainText,
+		Markdown:      markdown,
+		Headings:      m.extractHeadings(page),
+		LiveRegions:   liveRegions,
+		ConsoleErrors: m.pageConsoleErrors(url, maxPageConsoleErrors),
 	}, nil
 }
 
-// extractElements finds all interactive elements on the page
-func (m *Manager) extractElements(ctx context.Context) ([]ElementInfo, error) {
-	elements := []ElementInfo{}
-
-	// Find all buttons
-	buttons, _ := m.page.QuerySelectorAll("button")
-	for i, btn := range buttons {
-		text, _ := btn.TextContent()
-		selector, _ := m.getSelector(ctx, btn)
-		if text != "" {
-			elements = append(elements, ElementInfo{
-				Type:     "button",
-				Text:     text,
-				Selector: selector,
-				Index:    i,
-			})
-		}
-	}
-
-	// Find all clickable links
-	links, _ := m.page.QuerySelectorAll("a[href]")
-	for i, link := range links {
-		text, _ := link.TextContent()
-		href, _ := link.GetAttribute("href")
-		selector, _ := m.getSelector(ctx, link)
-		if text != "" {
-			elements = append(elements, ElementInfo{
-				Type:     "link",
-				Text:     text,
-				Href:     href,
-				Selector: selector,
-				Index:    i,
-			})
-		}
-	}
-
-	// Find form inputs
-	inputs, _ := m.page.QuerySelectorAll("input")
-	for i, input := range inputs {
-		placeholder, _ := input.GetAttribute("placeholder")
-		inputType, _ := input.GetAttribute("type")
-		selector, _ := m.getSelector(ctx, input)
-		label := placeholder
-		if label == "" {
-			label = inputType
-		}
-		elements = append(elements, ElementInfo{
-			Type:     "input",
-			Text:     label,
-			Selector: selector,
-			Index:    i,
-		})
+// liveRegionSelector matches ARIA live regions that announce status changes
+const liveRegionSelector = `[aria-live]:not([aria-live="off"]), [role="status"], [role="alert"], [role="log"]`
+
+// GetLiveRegions returns the non-empty text of ARIA live regions on the current page.
+// These announcements ("3 results found") are a reliable signal of what an action did.
+func (m *Manager) GetLiveRegions(ctx context.Context) ([]string, error) {
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return nil, err
 	}
+	return readLiveRegions(page)
+}
 
-	// Textareas behave like inputs for most sites
-	textareas, _ := m.page.QuerySelectorAll("textarea")
-	for i, ta := range textareas {
-		placeholder, _ := ta.GetAttribute("placeholder")
-		selector, _ := m.getSelector(ctx, ta)
-		label := placeholder
-		if label == "" {
-			label = "textarea"
-		}
-		elements = append(elements, ElementInfo{
-			Type:     "textarea",
-			Text:     label,
-			Selector: selector,
-			Index:    i,
-		})
+func readLiveRegions(page playwright.Page) ([]string, error) {
+	result, err := page.Evaluate(`(selector) => Array.from(document.querySelectorAll(selector))
+		.map((el) => (el.innerText || el.textContent || '').trim())
+		.filter((t) => t.length > 0)`, liveRegionSelector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read live regions: %w", err)
 	}
+	return toStringSlice(result), nil
+}
 
-	// Some complex UIs (e.g., Yandex Maps) use contenteditable divs instead of inputs
-	contentEditable, _ := m.page.QuerySelectorAll("[contenteditable], [role=\"textbox\"]")
-	for i, elem := range contentEditable {
-		selector, _ := m.getSelector(ctx, elem)
-		label, _ := elem.GetAttribute("aria-label")
-		if label == "" {
-			label, _ = elem.GetAttribute("placeholder")
-		}
-		if label == "" {
-			label = "text field"
+// extractHeadings returns the visible text of h1-h3 headings in document order
+func (m *Manager) extractHeadings(page playwright.Page) []string {
+	result, err := page.Evaluate(`() => Array.from(document.querySelectorAll('h1, h2, h3'))
+		.map((h) => (h.innerText || '').trim())
+		.filter((t) => t.length > 0)`)
+	if err != nil {
+		return nil
+	}
+	return toStringSlice(result)
+}
+
+// toStringSlice converts an Evaluate result holding a JS string array
+func toStringSlice(result interface{}) []string {
+	items, ok := result.([]interface{})
+	if !ok {
+		return nil
+	}
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		if text, ok := item.(string); ok {
+			values = append(values, text)
 		}
-		elements = append(elements, ElementInfo{
-			Type:     "editable",
-			Text:     label,
-			Selector: selector,
-			Index:    i,
-		})
 	}
+	return values
+}
 
-	return elements, nil
+// defaultMaxElementText is the default rune cap for ElementInfo.Text. It is
+// generous enough for matching; prompts may truncate further.
+const defaultMaxElementText = 500
+
+// SetMaxElementTextLength caps the text stored per extracted element (in runes). Zero disables the cap.
+func (m *Manager) SetMaxElementTextLength(n int) {
+	m.maxElementText = n
 }
 
-// getSelector generates a CSS selector for an element
-func (m *Manager) getSelector(ctx context.Context, element playwright.ElementHandle) (string, error) {
-	if element == nil {
-		return "", fmt.Errorf("nil element handle")
-	}
+// SetViewportMargin sets how many screens above or below the viewport elements
+// are still extracted. A negative margin extracts elements anywhere on the page.
+func (m *Manager) SetViewportMargin(screens int) {
+	m.viewportMargin = screens
+}
 
-	if id, err := element.GetAttribute("id"); err == nil && id != "" {
-		return fmt.Sprintf(`[id="%s"]`, cssEscapeAttrValue(id)), nil
-	}
+// SetMaxMarkdownLength caps PageContent.Markdown (in runes). Zero disables the
+// Markdown rendering.
+func (m *Manager) SetMaxMarkdownLength(n int) {
+	m.maxMarkdown = n
+}
 
-	if name, err := element.GetAttribute("name"); err == nil && name != "" {
-		tagName := getTagName(element)
-		if tagName == "" {
-			tagName = "*"
-		}
-		return fmt.Sprintf(`%s[name="%s"]`, tagName, cssEscapeAttrValue(name)), nil
+// capText trims text to at most maxRunes runes. A non-positive limit disables the cap.
+func capText(text string, maxRunes int) string {
+	if maxRunes <= 0 || len(text) <= maxRunes {
+		return text
+	}
+	runes := []rune(text)
+	if len(runes) <= maxRunes {
+		return text
 	}
+	return string(runes[:maxRunes])
+}
 
-	selector, err := m.page.Evaluate(`(element) => {
-		let path = [];
-		let current = element;
-		while (current && current.tagName !== 'BODY') {
-			let index = 0;
-			let sibling = current.previousElementSibling;
-			while (sibling) {
-				if (sibling.tagName === current.tagName) index++;
-				sibling = sibling.previousElementSibling;
+// extractElements finds all interactive elements on the page, including those
+// inside iframes (login widgets, payment forms), whose selectors are prefixed
+// with their frame. It also returns the main frame rendered as Markdown.
+func (m *Manager) extractElements(ctx context.Context, page playwright.Page) ([]ElementInfo, string, error) {
+	frames := page.Frames()
+	var elements []ElementInfo
+	var markdown string
+	for i, frame := range frames {
+		if frame.IsDetached() {
+			continue
+		}
+		frameElements, frameMarkdown, err := m.extractFrameElements(frame, i == 0)
+		if err != nil {
+			if i == 0 {
+				return nil, "", err
 			}
-			path.unshift(current.tagName.toLowerCase() + ':nth-of-type(' + (index + 1) + ')');
-			current = current.parentElement;
+			// A child frame that is still loading or navigating away
+			// should not hide the rest of the page.
+			log.Printf("Warning: skipping elements of frame %d: %v\n", i, err)
+			continue
 		}
-		return path.join(' > ');
-	}`, element)
-
-	if err == nil {
-		if selectorStr, ok := selector.(string); ok {
-			return selectorStr, nil
+		if i > 0 {
+			frameID := frameIdentifier(frames, i)
+			for j := range frameElements {
+				frameElements[j].Selector = frameSelector(frameID, frameElements[j].Selector)
+			}
+		}
+		if i == 0 {
+			markdown = frameMarkdown
 		}
+		elements = append(elements, frameElements...)
+	}
+	if elements == nil {
+		elements = []ElementInfo{}
+	}
+	return elements, markdown, nil
+}
+
+// ElementExists reports whether at least one element matches the selector
+func (m *Manager) ElementExists(ctx context.Context, selector string) (bool, error) {
+	frame, selector, err := m.resolveFrame(ctx, selector)
+	if err != nil {
+		return false, err
+	}
+
+	element, err := frame.QuerySelector(selector)
+	if err != nil {
+		return false, fmt.Errorf("failed to query selector: %w", err)
+	}
+	return element != nil, nil
+}
+
+// ElementValue returns what an element holds: the absolute URL of a link,
+// the value of a form field, or else its visible text.
+func (m *Manager) ElementValue(ctx context.Context, selector string) (string, error) {
+	frame, selector, err := m.resolveFrame(ctx, selector)
+	if err != nil {
+		return "", err
 	}
 
-	return "", fmt.Errorf("failed to get selector")
+	value, err := frame.EvalOnSelector(selector, `el => {
+		if (el.href) return String(el.href);
+		if ('value' in el && el.tagName !== 'BUTTON') return String(el.value);
+		return (el.innerText || el.textContent || '').trim();
+	}`, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to read element: %w", err)
+	}
+	text, _ := value.(string)
+	return text, nil
 }
 
 // Click clicks on an element by selector
 func (m *Manager) Click(ctx context.Context, selector string) error {
-	if err := m.ensureBrowser(ctx); err != nil {
-		return fmt.Errorf("browser not available: %w", err)
+	frame, selector, err := m.resolveFrame(ctx, selector)
+	if err != nil {
+		return err
 	}
 
-	if err := m.page.Click(selector); err != nil {
+	if err := frame.Click(selector); err != nil {
 		// If page closed while clicking, attempt non-fatal behavior
 		if strings.Contains(err.Error(), "Page closed") || strings.Contains(err.Error(), "page closed") {
 			log.Printf("Warning: page closed during click (possibly CAPTCHA): %v\n", err)
@@ -381,11 +526,12 @@ func (m *Manager) Click(ctx context.Context, selector string) error {
 
 // Fill fills a form field
 func (m *Manager) Fill(ctx context.Context, selector, text string) error {
-	if err := m.ensureBrowser(ctx); err != nil {
-		return fmt.Errorf("browser not available: %w", err)
+	frame, selector, err := m.resolveFrame(ctx, selector)
+	if err != nil {
+		return err
 	}
 
-	if err := m.page.Fill(selector, text); err != nil {
+	if err := frame.Fill(selector, text); err != nil {
 		if strings.Contains(err.Error(), "Page closed") || strings.Contains(err.Error(), "page closed") {
 			log.Printf("Warning: page closed during fill (possibly CAPTCHA): %v\n", err)
 			return nil
@@ -397,11 +543,12 @@ func (m *Manager) Fill(ctx context.Context, selector, text string) error {
 
 // Focus brings focus to an element
 func (m *Manager) Focus(ctx context.Context, selector string) error {
-	if err := m.ensureBrowser(ctx); err != nil {
-		return fmt.Errorf("browser not available: %w", err)
+	frame, selector, err := m.resolveFrame(ctx, selector)
+	if err != nil {
+		return err
 	}
 
-	if err := m.page.Focus(selector); err != nil {
+	if err := frame.Focus(selector); err != nil {
 		if strings.Contains(err.Error(), "Page closed") || strings.Contains(err.Error(), "page closed") {
 			log.Printf("Warning: page closed during focus (possibly CAPTCHA): %v\n", err)
 			return nil
@@ -411,13 +558,31 @@ func (m *Manager) Focus(ctx context.Context, selector string) error {
 	return nil
 }
 
+// Hover moves the mouse over an element, e.g. to open a hover-triggered menu or tooltip
+func (m *Manager) Hover(ctx context.Context, selector string) error {
+	frame, selector, err := m.resolveFrame(ctx, selector)
+	if err != nil {
+		return err
+	}
+
+	if err := frame.Hover(selector); err != nil {
+		if isPageClosedErr(err) {
+			log.Printf("Warning: page closed during hover (possibly CAPTCHA): %v\n", err)
+			return nil
+		}
+		return fmt.Errorf("failed to hover over element: %w", err)
+	}
+	return nil
+}
+
 // TypeText types into an element (character-by-character)
 func (m *Manager) TypeText(ctx context.Context, selector, text string) error {
-	if err := m.ensureBrowser(ctx); err != nil {
-		return fmt.Errorf("browser not available: %w", err)
+	frame, selector, err := m.resolveFrame(ctx, selector)
+	if err != nil {
+		return err
 	}
 
-	if err := m.page.Type(selector, text); err != nil {
+	if err := frame.Type(selector, text); err != nil {
 		if strings.Contains(err.Error(), "Page closed") || strings.Contains(err.Error(), "page closed") {
 			log.Printf("Warning: page closed during type (possibly CAPTCHA): %v\n", err)
 			return nil
@@ -429,11 +594,12 @@ func (m *Manager) TypeText(ctx context.Context, selector, text string) error {
 
 // PressKey sends a keyboard key press (e.g., Enter)
 func (m *Manager) PressKey(ctx context.Context, key string) error {
-	if err := m.ensureBrowser(ctx); err != nil {
-		return fmt.Errorf("browser not available: %w", err)
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return err
 	}
 
-	if err := m.page.Keyboard().Press(key); err != nil {
+	if err := page.Keyboard().Press(key); err != nil {
 		if strings.Contains(err.Error(), "Page closed") || strings.Contains(err.Error(), "page closed") {
 			log.Printf("Warning: page closed during key press (possibly CAPTCHA): %v\n", err)
 			return nil
@@ -444,9 +610,17 @@ func (m *Manager) PressKey(ctx context.Context, key string) error {
 }
 
 // Wait waits for navigation or element
+// The wait strategy is chosen per domain (see SetWaitStrategy) so SPA route changes are not missed.
 // If the page closes during waiting (e.g., due to CAPTCHA), it gracefully handles it
 func (m *Manager) WaitForNavigation(ctx context.Context) error {
-	if err := m.page.WaitForLoadState(); err != nil {
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = m.waitForState(page, m.waitStrategyFor(page.URL()), m.navTimeout)
+	m.lastSettledURL = page.URL()
+	if err != nil {
 		// Check if error is due to page closure (common with CAPTCHA challenges)
 		errMsg := err.Error()
 		if strings.Contains(errMsg, "Page closed") || strings.Contains(errMsg, "page closed") {
@@ -462,13 +636,27 @@ func (m *Manager) WaitForNavigation(ctx context.Context) error {
 
 // Close closes the browser
 func (m *Manager) Close(ctx context.Context) error {
-	if m.page != nil {
-		_ = m.page.Close()
+	if m.browser != nil {
+		// Only disconnect: the tabs of a remote browser are not ours to
+		// close. Contexts created over the connection go away with it.
+		_ = m.browser.Close()
+	} else {
+		if m.page != nil {
+			_ = m.page.Close()
+		}
+		if m.context != nil {
+			_ = m.context.Close()
+		}
+		if m.saved != nil {
+			_ = m.saved.context.Close()
+		}
 	}
-	if m.context != nil {
-		_ = m.context.Close()
+	m.saved = nil
+	if m.incognito != nil {
+		_ = m.incognito.Close()
 	}
 	// persistent context is closed above; no explicit browser.Close needed
+	releaseUserDataDir(m.userDataDir, m.ephemeral)
 	if m.playwright != nil {
 		return m.playwright.Stop()
 	}
@@ -488,6 +676,9 @@ func (m *Manager) SaveStorageState(path string) error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal storage state: %w", err)
 	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create storage state dir: %w", err)
+	}
 	if err := os.WriteFile(path, stateBytes, 0o600); err != nil {
 		return fmt.Errorf("failed to write storage state file: %w", err)
 	}
@@ -501,19 +692,26 @@ func defaultLaunchArgs() []string {
 	}
 }
 
-func launchPersistentWithFallback(pw *playwright.Playwright, userDataDir string, args []string) (playwright.BrowserContext, error) {
+func launchPersistentWithFallback(pw *playwright.Playwright, userDataDir string, args []string, launchOpts LaunchOptions) (playwright.BrowserContext, error) {
 	if pw == nil {
 		return nil, fmt.Errorf("playwright not initialized")
 	}
 
+	device, err := lookupDevice(pw.Devices, launchOpts.Device)
+	if err != nil {
+		return nil, err
+	}
+
 	requestedBrowser := strings.ToLower(strings.TrimSpace(os.Getenv("PLAYWRIGHT_BROWSER")))
 	attempts := []string{}
 
 	launch := func(browserType string) (playwright.BrowserContext, error) {
+		headless, _ := strconv.ParseBool(os.Getenv("BROWSER_HEADLESS"))
 		opts := playwright.BrowserTypeLaunchPersistentContextOptions{
-			Headless: playwright.Bool(false),
+			Headless: playwright.Bool(headless),
 			Args:     args,
 		}
+		launchOpts.apply(&opts, device)
 		switch browserType {
 		case "firefox":
 			return pw.Firefox.LaunchPersistentContext(userDataDir, opts)
@@ -541,6 +739,11 @@ func launchPersistentWithFallback(pw *playwright.Playwright, userDataDir string,
 			if requestedBrowser != "" && requestedBrowser != browserName {
 				log.Printf("Requested browser %s unavailable, using %s fallback\n", requestedBrowser, browserName)
 			}
+			if launchOpts.Stealth {
+				if err := applyStealth(ctx, launchOpts); err != nil {
+					log.Printf("Warning: %v\n", err)
+				}
+			}
 			return ctx, nil
 		}
 		log.Printf("%s launch failed: %v\n", strings.Title(browserName), err)
@@ -572,10 +775,18 @@ func (m *Manager) ListOpenPages() []TabInfo {
 	return pages
 }
 
+// CurrentURL returns the URL of the active page, or "" if there is none.
+func (m *Manager) CurrentURL() string {
+	if m.page == nil || m.page.IsClosed() {
+		return ""
+	}
+	return m.page.URL()
+}
+
 // SwitchToPage selects a browser tab either by index or substring match on title/URL.
 func (m *Manager) SwitchToPage(ctx context.Context, target string) error {
-	if err := m.ensureBrowser(ctx); err != nil {
-		return fmt.Errorf("browser not available: %w", err)
+	if _, err := m.activePage(ctx); err != nil {
+		return err
 	}
 	if len(m.pageOrder) == 0 {
 		return fmt.Errorf("no open pages to switch")
@@ -628,8 +839,9 @@ func (m *Manager) attachContextListeners(browserCtx playwright.BrowserContext) {
 
 	browserCtx.OnPage(func(p playwright.Page) {
 		log.Printf("Browser emitted a new page event (URL: %s)\n", safePageURL(p))
-		m.registerPage(p, true)
+		m.handleNewPage(p)
 	})
+	m.attachNetworkListeners(browserCtx)
 }
 
 func (m *Manager) attachPageListeners(page playwright.Page) {
@@ -653,6 +865,9 @@ func (m *Manager) attachPageListeners(page playwright.Page) {
 	page.OnCrash(func(p playwright.Page) {
 		log.Printf("❌ Page crash event: title=%q url=%s\n", safePageTitle(p), safePageURL(p))
 	})
+
+	m.attachConsoleListeners(page)
+	m.attachDownloadListener(page)
 }
 
 func safePageTitle(page playwright.Page) string {
@@ -677,22 +892,22 @@ func safePageURL(page playwright.Page) string {
 	return url
 }
 
-func getTagName(element playwright.ElementHandle) string {
-	if element == nil {
-		return ""
-	}
-	tag, err := element.Evaluate(`(el) => el.tagName.toLowerCase()`)
-	if err == nil {
-		if tagStr, ok := tag.(string); ok {
-			return tagStr
-		}
+// envInt reads an integer environment variable, falling back to def when unset or invalid
+func envInt(name string, def int) int {
+	value, err := strconv.Atoi(strings.TrimSpace(os.Getenv(name)))
+	if err != nil {
+		return def
 	}
-	return ""
+	return value
 }
 
-func cssEscapeAttrValue(value string) string {
-	value = strings.ReplaceAll(value, `\`, `\\`)
-	value = strings.ReplaceAll(value, `"`, `\"`)
+// envDuration reads a duration environment variable such as "45s", returning
+// zero when unset or invalid
+func envDuration(name string) time.Duration {
+	value, err := time.ParseDuration(strings.TrimSpace(os.Getenv(name)))
+	if err != nil {
+		return 0
+	}
 	return value
 }
 
@@ -713,15 +928,35 @@ type PageContent struct {
 	URL      string
 	Elements []ElementInfo
 	MainText string
+	Markdown string // main frame as Markdown, elements referenced as [#N ...]
+	// AccessibilityTree is the formatted GetAccessibilityTree outline. It is
+	// not filled by GetPageContent; callers add it when they want it.
+	AccessibilityTree string
+	Headings          []string
+	LiveRegions       []string       // ARIA live region / status announcements
+	ConsoleErrors     []ConsoleEntry // recent JS errors of this page, oldest first
+	Status            int            // HTTP status the document was served with, 0 if unknown
+	Challenge         string         // bot check shown instead of content, e.g. "recaptcha" or "cloudflare"
 }
 
 // ElementInfo represents a single interactive element
 type ElementInfo struct {
-	Type     string // button, link, input, etc.
-	Text     string
-	Href     string
-	Selector string
-	Index    int
+	Type       string // button, link, input, etc.
+	Text       string
+	Href       string
+	Selector   string
+	Index      int
+	Offscreen  bool // outside the viewport, needs a scroll before it can be seen
+	InMarkdown bool // described inline in PageContent.Markdown
+	Box        Box  // position within its frame's document at extraction time
+}
+
+// Box is an element's bounding box in CSS pixels.
+type Box struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
 }
 
 // TabInfo describes an open browser tab.
@@ -738,7 +973,7 @@ func (m *Manager) rebuildPageTracking(browserCtx playwright.BrowserContext) {
 	}
 	m.pages = make(map[string]playwright.Page)
 	m.pageOrder = nil
-	m.page = nil
+	m.setPage(nil)
 	m.activePageID = ""
 	if m.pageListeners == nil {
 		m.pageListeners = make(map[string]struct{})
@@ -793,7 +1028,7 @@ func (m *Manager) handlePageClosed(page playwright.Page) {
 
 	if m.activePageID == id {
 		m.activePageID = ""
-		m.page = nil
+		m.setPage(nil)
 		if len(m.pageOrder) > 0 {
 			m.setActivePage(m.pageOrder[len(m.pageOrder)-1], true)
 		}
@@ -801,25 +1036,37 @@ func (m *Manager) handlePageClosed(page playwright.Page) {
 }
 
 func (m *Manager) cleanupCurrentContext() {
-	if m.page != nil {
-		_ = m.page.Close()
-	}
-	if m.context != nil {
-		_ = m.context.Close()
+	if m.browser != nil && m.saved == nil {
+		_ = m.browser.Close()
+		m.browser = nil
+	} else {
+		if m.page != nil {
+			_ = m.page.Close()
+		}
+		if m.context != nil {
+			_ = m.context.Close()
+		}
 	}
-	m.page = nil
+	m.setPage(nil)
 	m.activePageID = ""
 	m.pageOrder = nil
 	m.pages = make(map[string]playwright.Page)
 	m.pageListeners = make(map[string]struct{})
 }
 
+// setPage makes page the active one.
+func (m *Manager) setPage(page playwright.Page) {
+	m.pageMu.Lock()
+	m.page = page
+	m.pageMu.Unlock()
+}
+
 func (m *Manager) setActivePage(pageID string, bringToFront bool) {
 	page, ok := m.pages[pageID]
 	if !ok {
 		return
 	}
-	m.page = page
+	m.setPage(page)
 	m.activePageID = pageID
 	if bringToFront && page != nil {
 		if err := page.BringToFront(); err != nil {
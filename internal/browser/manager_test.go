@@ -2,10 +2,13 @@ package browser
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
 func TestNewManager(t *testing.T) {
+	t.Setenv("BROWSER_USER_DATA_DIR", t.TempDir())
 	ctx := context.Background()
 	mgr, err := NewManager(ctx)
 	if err != nil {
@@ -19,6 +22,7 @@ func TestNewManager(t *testing.T) {
 }
 
 func TestNavigate(t *testing.T) {
+	t.Setenv("BROWSER_USER_DATA_DIR", t.TempDir())
 	ctx := context.Background()
 	mgr, err := NewManager(ctx)
 	if err != nil {
@@ -36,3 +40,74 @@ func TestNavigate(t *testing.T) {
 		t.Error("URL not updated after navigation")
 	}
 }
+
+// newFixtureManager starts a headless manager with a throwaway profile, skipping
+// the test when Playwright is not installed.
+func newFixtureManager(t *testing.T) *Manager {
+	t.Helper()
+	t.Setenv("BROWSER_HEADLESS", "true")
+	t.Setenv("BROWSER_USER_DATA_DIR", t.TempDir())
+
+	ctx := context.Background()
+	mgr, err := NewManager(ctx)
+	if err != nil {
+		t.Skipf("Playwright unavailable: %v", err)
+	}
+	t.Cleanup(func() { _ = mgr.Close(ctx) })
+	return mgr
+}
+
+// serveFixture serves the given HTML for every request.
+func serveFixture(t *testing.T, html string) string {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(html))
+	}))
+	t.Cleanup(ts.Close)
+	return ts.URL
+}
+
+func TestActivePageClosedMidTask(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	url := serveFixture(t, `<html><head><title>Fixture</title></head><body><button>Go</button></body></html>`)
+
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("Navigate failed: %v", err)
+	}
+	if err := mgr.page.Close(); err != nil {
+		t.Fatalf("failed to close page: %v", err)
+	}
+
+	if _, err := mgr.GetPageContent(ctx); err != nil {
+		t.Fatalf("GetPageContent after close failed: %v", err)
+	}
+	if err := mgr.WaitForNavigation(ctx); err != nil {
+		t.Fatalf("WaitForNavigation after close failed: %v", err)
+	}
+}
+
+func TestHoverRevealsMenu(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	url := serveFixture(t, `<html><head><style>
+		#submenu { display: none; }
+		#menu:hover #submenu { display: block; }
+	</style></head><body>
+		<div id="menu">Products<ul id="submenu"><li><a href="/pricing">Pricing</a></li></ul></div>
+	</body></html>`)
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("Navigate failed: %v", err)
+	}
+
+	if visible, _ := mgr.page.IsVisible("#submenu a"); visible {
+		t.Fatal("submenu should be hidden before hovering")
+	}
+	if err := mgr.Hover(ctx, "#menu"); err != nil {
+		t.Fatalf("Hover failed: %v", err)
+	}
+	if visible, _ := mgr.page.IsVisible("#submenu a"); !visible {
+		t.Fatal("submenu should be visible after hovering")
+	}
+}
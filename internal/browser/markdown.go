@@ -0,0 +1,121 @@
+package browser
+
+// defaultMaxMarkdown is the default rune cap for PageContent.Markdown.
+const defaultMaxMarkdown = 12000
+
+// markdownScript renders a document as Markdown for the model: headings,
+// paragraphs, lists, tables and links keep the page structure at a fraction
+// of the tokens of raw text plus a flat element list.
+//
+// It is a JS function expression embedded in extractElementsScript and called
+// as (root, numbered, limit). numbered maps extracted elements to their number
+// in the element list; those are written inline as "[#3 Pricing](/pricing)"
+// or "[#4 input: Email]" and marked used, so the list can omit their text.
+// Invisible subtrees are skipped and output stops once limit characters have
+// been written.
+const markdownScript = `(root, numbered, limit) => {
+	const skip = new Set(['script', 'style', 'noscript', 'template', 'svg', 'canvas', 'iframe', 'head']);
+	const blocks = new Set(['p', 'div', 'section', 'article', 'main', 'header', 'footer', 'nav', 'aside', 'form', 'fieldset', 'figure', 'blockquote', 'pre', 'dl', 'dt', 'dd', 'details', 'summary']);
+	const flat = (s) => (s || '').replace(/\s+/g, ' ').trim();
+	// List indentation survives the whitespace cleanup below as a placeholder.
+	const indent = '\u0001';
+	let out = '';
+	let size = 0;
+	const emit = (s) => {
+		if (size < limit) {
+			out += s;
+			size += s.length;
+		}
+	};
+	const hidden = (el) => {
+		const style = getComputedStyle(el);
+		return style.display === 'none' || style.visibility === 'hidden';
+	};
+	const children = (el) => (el.shadowRoot ? [...el.shadowRoot.childNodes, ...el.childNodes] : [...el.childNodes]);
+
+	const walk = (node, depth) => {
+		if (size >= limit) return;
+		if (node.nodeType === Node.TEXT_NODE) {
+			const text = node.textContent.replace(/\s+/g, ' ');
+			if (text.trim()) emit(text);
+			return;
+		}
+		if (node.nodeType !== Node.ELEMENT_NODE) return;
+		const el = node;
+		const tag = el.tagName.toLowerCase();
+		if (skip.has(tag) || hidden(el)) return;
+
+		const entry = numbered.get(el);
+		if (entry) {
+			entry.used = true;
+			if (entry.type === 'link') {
+				emit(' [#' + entry.num + ' ' + flat(el.textContent) + '](' + entry.href + ') ');
+			} else {
+				emit(' [#' + entry.num + ' ' + entry.type + ': ' + entry.text + '] ');
+			}
+			return;
+		}
+
+		const heading = /^h([1-6])$/.exec(tag);
+		if (heading) {
+			const text = flat(el.innerText);
+			if (text) emit('\n\n' + '#'.repeat(Number(heading[1])) + ' ' + text + '\n\n');
+			return;
+		}
+		switch (tag) {
+		case 'br':
+			emit('\n');
+			return;
+		case 'hr':
+			emit('\n\n---\n\n');
+			return;
+		case 'a': {
+			const text = flat(el.textContent);
+			const href = el.getAttribute('href');
+			if (text) emit(href ? ' [' + text + '](' + href + ') ' : text);
+			return;
+		}
+		case 'ul':
+		case 'ol': {
+			let n = 0;
+			if (depth === 0) emit('\n');
+			for (const item of el.children) {
+				if (item.tagName.toLowerCase() !== 'li' || hidden(item)) continue;
+				n++;
+				emit('\n' + indent.repeat(depth) + (tag === 'ol' ? n + '. ' : '- '));
+				for (const child of children(item)) walk(child, depth + 1);
+			}
+			if (depth === 0) emit('\n\n');
+			return;
+		}
+		case 'table': {
+			emit('\n\n');
+			let header = true;
+			for (const row of el.querySelectorAll('tr')) {
+				const cells = [...row.cells].map((c) => flat(c.innerText).replace(/\|/g, '\\|'));
+				if (!cells.length) continue;
+				emit('| ' + cells.join(' | ') + ' |\n');
+				if (header) {
+					emit('|' + ' --- |'.repeat(cells.length) + '\n');
+					header = false;
+				}
+			}
+			emit('\n');
+			return;
+		}
+		}
+
+		const block = blocks.has(tag) || tag === 'li' || tag === 'table';
+		if (block) emit('\n\n');
+		for (const child of children(el)) walk(child, depth);
+		if (block) emit('\n\n');
+	};
+	walk(root, 0);
+
+	return out
+		.split('\n')
+		.map((line) => line.replace(/[ \t]+/g, ' ').trim().replace(/\u0001/g, '  '))
+		.join('\n')
+		.replace(/\n{3,}/g, '\n\n')
+		.trim();
+}`
@@ -0,0 +1,56 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPageMarkdown(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	url := serveFixture(t, `<html><body>
+		<h1>Store</h1>
+		<p>Best   prices in town.</p>
+		<ul><li><a href="/pricing">Pricing</a></li><li>Support</li></ul>
+		<table><tr><th>Plan</th><th>Price</th></tr><tr><td>Pro</td><td>$10</td></tr></table>
+		<input placeholder="Email">
+		<div style="display:none">Secret</div>
+	</body></html>`)
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("Navigate failed: %v", err)
+	}
+
+	content, err := mgr.GetPageContent(ctx)
+	if err != nil {
+		t.Fatalf("GetPageContent failed: %v", err)
+	}
+	md := content.Markdown
+	for _, want := range []string{"# Store", "Best prices in town.", "- Support", "| Plan | Price |", "| Pro | $10 |"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "Secret") {
+		t.Errorf("Markdown should skip hidden content:\n%s", md)
+	}
+	for i, el := range content.Elements {
+		if !el.InMarkdown {
+			t.Errorf("element %+v not referenced in Markdown", el)
+			continue
+		}
+		if !strings.Contains(md, fmt.Sprintf("[#%d ", i+1)) {
+			t.Errorf("element %d not numbered in Markdown:\n%s", i+1, md)
+		}
+	}
+
+	mgr.SetMaxMarkdownLength(0)
+	content, err = mgr.GetPageContent(ctx)
+	if err != nil {
+		t.Fatalf("GetPageContent failed: %v", err)
+	}
+	if content.Markdown != "" {
+		t.Errorf("zero limit should disable Markdown, got %q", content.Markdown)
+	}
+}
@@ -0,0 +1,69 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+)
+
+// elementMarksID is the id of the overlay added by ShowElementMarks.
+const elementMarksID = "__aibot_element_marks"
+
+// showMarksScript draws a numbered badge and outline over every box. The
+// overlay lives in its own shadow root so page styles cannot reach it, and
+// ignores pointer events so it never intercepts a click.
+const showMarksScript = `({id, marks}) => {
+	document.getElementById(id)?.remove();
+	const host = document.createElement('div');
+	host.id = id;
+	host.style.cssText = 'position:absolute;top:0;left:0;width:0;height:0;z-index:2147483647;pointer-events:none';
+	const root = host.attachShadow({mode: 'closed'});
+	const colors = ['#e6194b', '#3cb44b', '#4363d8', '#f58231', '#911eb4', '#008080'];
+	for (const {num, x, y, width, height} of marks) {
+		const color = colors[num % colors.length];
+		const outline = document.createElement('div');
+		outline.style.cssText = 'position:absolute;box-sizing:border-box;border:2px solid ' + color +
+			';left:' + x + 'px;top:' + y + 'px;width:' + width + 'px;height:' + height + 'px';
+		const badge = document.createElement('div');
+		badge.textContent = String(num);
+		badge.style.cssText = 'position:absolute;padding:0 3px;font:bold 12px/16px sans-serif;color:#fff;background:' + color +
+			';left:' + x + 'px;top:' + Math.max(0, y - 16) + 'px';
+		root.append(outline, badge);
+	}
+	document.documentElement.appendChild(host);
+}`
+
+// ShowElementMarks overlays each element of the main frame with a badge
+// showing its 1-based position in elements (set-of-marks), so a model looking
+// at a screenshot can refer to "element 17" instead of writing a selector.
+// Elements inside iframes and elements without a size are not marked.
+func (m *Manager) ShowElementMarks(ctx context.Context, elements []ElementInfo) error {
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return err
+	}
+	marks := make([]map[string]interface{}, 0, len(elements))
+	for i, el := range elements {
+		if _, _, inFrame := SplitFrameSelector(el.Selector); inFrame || el.Box.Width == 0 || el.Box.Height == 0 {
+			continue
+		}
+		marks = append(marks, map[string]interface{}{
+			"num": i + 1, "x": el.Box.X, "y": el.Box.Y, "width": el.Box.Width, "height": el.Box.Height,
+		})
+	}
+	if _, err := page.Evaluate(showMarksScript, map[string]interface{}{"id": elementMarksID, "marks": marks}); err != nil {
+		return fmt.Errorf("failed to draw element marks: %w", err)
+	}
+	return nil
+}
+
+// HideElementMarks removes the overlay added by ShowElementMarks.
+func (m *Manager) HideElementMarks(ctx context.Context) error {
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := page.Evaluate(`(id) => document.getElementById(id)?.remove()`, elementMarksID); err != nil {
+		return fmt.Errorf("failed to remove element marks: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package browser
+
+import (
+	"context"
+	"testing"
+)
+
+func TestElementMarksDoNotBlockClicks(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	url := serveFixture(t, `<html><body><button onclick="document.title = 'clicked'">Go</button></body></html>`)
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("Navigate failed: %v", err)
+	}
+	content, err := mgr.GetPageContent(ctx)
+	if err != nil {
+		t.Fatalf("GetPageContent failed: %v", err)
+	}
+	if len(content.Elements) != 1 || content.Elements[0].Box.Width == 0 {
+		t.Fatalf("expected one element with a bounding box, got %+v", content.Elements)
+	}
+
+	if err := mgr.ShowElementMarks(ctx, content.Elements); err != nil {
+		t.Fatalf("ShowElementMarks failed: %v", err)
+	}
+	if exists, _ := mgr.ElementExists(ctx, "#"+elementMarksID); !exists {
+		t.Fatal("expected the marks overlay on the page")
+	}
+	if err := mgr.Click(ctx, content.Elements[0].Selector); err != nil {
+		t.Fatalf("Click through the overlay failed: %v", err)
+	}
+	if title, _ := mgr.page.Title(); title != "clicked" {
+		t.Fatalf("overlay intercepted the click, title %q", title)
+	}
+
+	if err := mgr.HideElementMarks(ctx); err != nil {
+		t.Fatalf("HideElementMarks failed: %v", err)
+	}
+	if exists, _ := mgr.ElementExists(ctx, "#"+elementMarksID); exists {
+		t.Fatal("expected the overlay to be removed")
+	}
+}
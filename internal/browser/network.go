@@ -0,0 +1,192 @@
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// maxNetworkEntries bounds the network log kept per manager.
+const maxNetworkEntries = 500
+
+// redactedHeaders carry credentials and are never written to the log.
+var redactedHeaders = map[string]bool{
+	"authorization": true, "proxy-authorization": true, "cookie": true, "set-cookie": true,
+}
+
+// NetworkEntry is a request the page made and, unless it failed, its response.
+type NetworkEntry struct {
+	Method          string            `json:"method"`
+	URL             string            `json:"url"`
+	ResourceType    string            `json:"resource_type"` // document, xhr, fetch, script, image, ...
+	Status          int               `json:"status,omitempty"`
+	StatusText      string            `json:"status_text,omitempty"`
+	MIMEType        string            `json:"mime_type,omitempty"`
+	Failure         string            `json:"failure,omitempty"`
+	RequestHeaders  map[string]string `json:"request_headers,omitempty"`
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+	Started         time.Time         `json:"started"`
+	Duration        time.Duration     `json:"duration"`
+}
+
+// attachNetworkListeners records every finished or failed request of the
+// context's pages. Credentials in headers are redacted.
+func (m *Manager) attachNetworkListeners(browserCtx playwright.BrowserContext) {
+	browserCtx.OnRequestFinished(func(req playwright.Request) {
+		// Reading the response is a round-trip, which must not block
+		// Playwright's dispatch goroutine.
+		go func() {
+			entry := newNetworkEntry(req)
+			if resp, err := req.Response(); err == nil && resp != nil {
+				entry.Status = resp.Status()
+				entry.StatusText = resp.StatusText()
+				entry.ResponseHeaders = redactHeaders(resp.Headers())
+				entry.MIMEType, _, _ = strings.Cut(entry.ResponseHeaders["content-type"], ";")
+			}
+			m.recordNetwork(entry)
+		}()
+	})
+	browserCtx.OnRequestFailed(func(req playwright.Request) {
+		entry := newNetworkEntry(req)
+		if err := req.Failure(); err != nil {
+			entry.Failure = err.Error()
+		}
+		m.recordNetwork(entry)
+	})
+}
+
+func newNetworkEntry(req playwright.Request) NetworkEntry {
+	entry := NetworkEntry{
+		Method:         req.Method(),
+		URL:            req.URL(),
+		ResourceType:   req.ResourceType(),
+		RequestHeaders: redactHeaders(req.Headers()),
+		Started:        time.Now(),
+	}
+	if timing := req.Timing(); timing != nil && timing.StartTime > 0 {
+		entry.Started = time.UnixMilli(int64(timing.StartTime))
+		if timing.ResponseEnd > 0 {
+			entry.Duration = time.Duration(timing.ResponseEnd * float64(time.Millisecond))
+		}
+	}
+	return entry
+}
+
+func redactHeaders(headers map[string]string) map[string]string {
+	out := make(map[string]string, len(headers))
+	for name, value := range headers {
+		if redactedHeaders[strings.ToLower(name)] {
+			value = "[redacted]"
+		}
+		out[name] = value
+	}
+	return out
+}
+
+func (m *Manager) recordNetwork(entry NetworkEntry) {
+	m.networkMu.Lock()
+	defer m.networkMu.Unlock()
+	m.networkEntries = append(m.networkEntries, entry)
+	if len(m.networkEntries) > maxNetworkEntries {
+		m.networkEntries = m.networkEntries[len(m.networkEntries)-maxNetworkEntries:]
+	}
+}
+
+// NetworkLog returns the recorded requests, oldest first. With resource
+// types given (e.g. "xhr", "fetch") only those requests are returned.
+func (m *Manager) NetworkLog(resourceTypes ...string) []NetworkEntry {
+	m.networkMu.Lock()
+	defer m.networkMu.Unlock()
+	if len(resourceTypes) == 0 {
+		return append([]NetworkEntry(nil), m.networkEntries...)
+	}
+	var entries []NetworkEntry
+	for _, entry := range m.networkEntries {
+		for _, t := range resourceTypes {
+			if entry.ResourceType == t {
+				entries = append(entries, entry)
+				break
+			}
+		}
+	}
+	return entries
+}
+
+// ClearNetworkLog forgets the recorded requests, e.g. when a new task starts.
+func (m *Manager) ClearNetworkLog() {
+	m.networkMu.Lock()
+	defer m.networkMu.Unlock()
+	m.networkEntries = nil
+}
+
+// WriteHAR saves network entries as a HAR 1.2 file that browser devtools and
+// HAR viewers can open. Bodies are not recorded, so only metadata is included.
+func WriteHAR(path string, entries []NetworkEntry) error {
+	type nameValue struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	pairs := func(headers map[string]string) []nameValue {
+		out := make([]nameValue, 0, len(headers))
+		for name, value := range headers {
+			out = append(out, nameValue{name, value})
+		}
+		return out
+	}
+
+	harEntries := make([]map[string]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		query := []nameValue{}
+		if parsed, err := url.Parse(entry.URL); err == nil {
+			for name, values := range parsed.Query() {
+				for _, value := range values {
+					query = append(query, nameValue{name, value})
+				}
+			}
+		}
+		millis := float64(entry.Duration) / float64(time.Millisecond)
+		response := map[string]interface{}{
+			"status": entry.Status, "statusText": entry.StatusText, "httpVersion": "HTTP/1.1",
+			"headers": pairs(entry.ResponseHeaders), "cookies": []nameValue{},
+			"content":     map[string]interface{}{"size": -1, "mimeType": entry.MIMEType},
+			"redirectURL": entry.ResponseHeaders["location"], "headersSize": -1, "bodySize": -1,
+		}
+		if entry.Failure != "" {
+			response["_error"] = entry.Failure
+		}
+		harEntries = append(harEntries, map[string]interface{}{
+			"startedDateTime": entry.Started.Format(time.RFC3339Nano),
+			"time":            millis,
+			"_resourceType":   entry.ResourceType,
+			"request": map[string]interface{}{
+				"method": entry.Method, "url": entry.URL, "httpVersion": "HTTP/1.1",
+				"headers": pairs(entry.RequestHeaders), "queryString": query, "cookies": []nameValue{},
+				"headersSize": -1, "bodySize": -1,
+			},
+			"response": response,
+			"cache":    map[string]interface{}{},
+			"timings":  map[string]float64{"send": 0, "wait": millis, "receive": 0},
+		})
+	}
+
+	har := map[string]interface{}{
+		"log": map[string]interface{}{
+			"version": "1.2",
+			"creator": map[string]string{"name": "AIBot", "version": "1"},
+			"entries": harEntries,
+		},
+	}
+	data, err := json.MarshalIndent(har, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode HAR: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write HAR file: %w", err)
+	}
+	return nil
+}
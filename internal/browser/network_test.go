@@ -0,0 +1,97 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNetworkLogFilterAndHAR(t *testing.T) {
+	m := &Manager{}
+	m.recordNetwork(NetworkEntry{Method: "GET", URL: "https://shop.example/", ResourceType: "document", Status: 200})
+	m.recordNetwork(NetworkEntry{Method: "POST", URL: "https://shop.example/api/items?page=2", ResourceType: "xhr", Status: 200,
+		MIMEType: "application/json", RequestHeaders: redactHeaders(map[string]string{"Cookie": "session=secret", "accept": "application/json"})})
+	m.recordNetwork(NetworkEntry{Method: "GET", URL: "https://shop.example/logo.png", ResourceType: "image", Failure: "net::ERR_ABORTED"})
+
+	if got := m.NetworkLog("xhr", "fetch"); len(got) != 1 || got[0].Method != "POST" {
+		t.Fatalf("expected only the XHR entry, got %+v", got)
+	}
+	if got := m.NetworkLog("xhr")[0].RequestHeaders["Cookie"]; got != "[redacted]" {
+		t.Fatalf("expected the cookie header to be redacted, got %q", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "task.har")
+	if err := WriteHAR(path, m.NetworkLog()); err != nil {
+		t.Fatalf("WriteHAR failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var har struct {
+		Log struct {
+			Version string `json:"version"`
+			Entries []struct {
+				Request struct {
+					Method      string `json:"method"`
+					QueryString []struct {
+						Name  string `json:"name"`
+						Value string `json:"value"`
+					} `json:"queryString"`
+				} `json:"request"`
+				Response struct {
+					Status int `json:"status"`
+				} `json:"response"`
+			} `json:"entries"`
+		} `json:"log"`
+	}
+	if err := json.Unmarshal(data, &har); err != nil {
+		t.Fatalf("HAR is not valid JSON: %v", err)
+	}
+	if har.Log.Version != "1.2" || len(har.Log.Entries) != 3 {
+		t.Fatalf("unexpected HAR log: %+v", har.Log)
+	}
+	if q := har.Log.Entries[1].Request.QueryString; len(q) != 1 || q[0].Name != "page" || q[0].Value != "2" {
+		t.Fatalf("expected the query string to be split out, got %+v", q)
+	}
+
+	m.ClearNetworkLog()
+	if len(m.NetworkLog()) != 0 {
+		t.Fatal("expected the log to be empty after ClearNetworkLog")
+	}
+}
+
+func TestNetworkLogRecordsXHR(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/items" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[{"name": "Tea"}]`))
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><body><script>fetch('/api/items')</script></body></html>`))
+	}))
+	defer ts.Close()
+
+	if err := mgr.Navigate(ctx, ts.URL); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if entries := mgr.NetworkLog("fetch"); len(entries) == 1 {
+			if entries[0].Status != 200 || entries[0].MIMEType != "application/json" {
+				t.Fatalf("unexpected fetch entry: %+v", entries[0])
+			}
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("fetch request was not recorded: %+v", mgr.NetworkLog())
+}
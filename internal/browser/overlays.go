@@ -0,0 +1,42 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+)
+
+// consentSelectors match the accept buttons of common cookie/consent banners.
+var consentSelectors = []string{
+	`#onetrust-accept-btn-handler`,
+	`button#L2AGLb`,
+	`[aria-label="Accept all"]`,
+	`button:has-text("Accept all")`,
+	`button:has-text("Accept")`,
+	`button:has-text("I agree")`,
+	`button:has-text("Принять")`,
+	`button:has-text("Согласен")`,
+}
+
+// DismissConsent clicks the first visible consent banner button it finds.
+// It reports whether anything was dismissed.
+func (m *Manager) DismissConsent(ctx context.Context) (bool, error) {
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, selector := range consentSelectors {
+		element, err := page.QuerySelector(selector)
+		if err != nil || element == nil {
+			continue
+		}
+		if visible, _ := element.IsVisible(); !visible {
+			continue
+		}
+		if err := element.Click(); err != nil {
+			return false, fmt.Errorf("failed to dismiss consent banner: %w", err)
+		}
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,129 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// defaultNextSelectors match common "next page" controls when no selector is given.
+var defaultNextSelectors = []string{
+	`a[rel="next"]`,
+	`[aria-label="Next page"]`,
+	`[aria-label="Next"]`,
+	`a:has-text("Next")`,
+	`button:has-text("Next")`,
+	`a:has-text("Далее")`,
+	`button:has-text("Далее")`,
+	`a:has-text("Следующая")`,
+}
+
+// defaultMaxPages bounds ScrapePaginated when maxPages is not positive.
+const defaultMaxPages = 20
+
+// NextPage clicks the "next page" control and waits for the new page to be
+// ready. nextSelector overrides the built-in patterns. It returns false when
+// there is no usable next control, i.e. the current page is the last one.
+func (m *Manager) NextPage(ctx context.Context, nextSelector string) (bool, error) {
+	element, selector, err := m.findNextControl(ctx, nextSelector)
+	if err != nil || element == nil {
+		return false, err
+	}
+	if err := element.Click(); err != nil {
+		return false, fmt.Errorf("failed to click next page control %s: %w", selector, err)
+	}
+	if err := m.WaitForNavigation(ctx); err != nil {
+		log.Printf("Warning: navigation wait after next page failed: %v\n", err)
+	}
+	if err := m.WaitForReady(ctx); err != nil {
+		log.Printf("Warning: readiness wait after next page failed: %v\n", err)
+	}
+	return true, nil
+}
+
+// findNextControl returns the first visible, enabled "next page" control and
+// the selector that matched it, or nil if there is none.
+func (m *Manager) findNextControl(ctx context.Context, nextSelector string) (playwright.ElementHandle, string, error) {
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	candidates := defaultNextSelectors
+	if strings.TrimSpace(nextSelector) != "" {
+		candidates = []string{nextSelector}
+	}
+
+	for _, selector := range candidates {
+		element, err := page.QuerySelector(selector)
+		if err != nil || element == nil {
+			continue
+		}
+		if visible, _ := element.IsVisible(); !visible {
+			continue
+		}
+		if enabled, _ := element.IsEnabled(); !enabled {
+			continue
+		}
+		if disabled, _ := element.GetAttribute("aria-disabled"); disabled == "true" {
+			continue
+		}
+		return element, selector, nil
+	}
+	return nil, "", nil
+}
+
+// ScrapeList returns the visible text of every element matching itemSelector.
+func (m *Manager) ScrapeList(ctx context.Context, itemSelector string) ([]string, error) {
+	frame, itemSelector, err := m.resolveFrame(ctx, itemSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	elements, err := frame.QuerySelectorAll(itemSelector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query items: %w", err)
+	}
+	items := make([]string, 0, len(elements))
+	for _, element := range elements {
+		text, err := element.InnerText()
+		if err != nil {
+			continue
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			items = append(items, capText(text, m.maxElementText))
+		}
+	}
+	return items, nil
+}
+
+// ScrapePaginated collects ScrapeList results from the current page and every
+// following page, stopping at the last page or after maxPages pages.
+func (m *Manager) ScrapePaginated(ctx context.Context, itemSelector, nextSelector string, maxPages int) ([]string, error) {
+	if maxPages <= 0 {
+		maxPages = defaultMaxPages
+	}
+
+	var all []string
+	for pageNum := 1; ; pageNum++ {
+		items, err := m.ScrapeList(ctx, itemSelector)
+		if err != nil {
+			return all, fmt.Errorf("page %d: %w", pageNum, err)
+		}
+		all = append(all, items...)
+
+		if pageNum >= maxPages {
+			return all, nil
+		}
+		more, err := m.NextPage(ctx, nextSelector)
+		if err != nil {
+			return all, fmt.Errorf("page %d: %w", pageNum, err)
+		}
+		if !more {
+			return all, nil
+		}
+	}
+}
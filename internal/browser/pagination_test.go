@@ -0,0 +1,73 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// servePaginatedFixture serves a three-page result list at /?page=N.
+func servePaginatedFixture(t *testing.T) string {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pageNum, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if pageNum < 1 {
+			pageNum = 1
+		}
+		next := ""
+		if pageNum < 3 {
+			next = fmt.Sprintf(`<a class="pager" href="/?page=%d">Next</a>`, pageNum+1)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, `<html><body><ul>
+			<li class="item">Result %[1]d-a</li>
+			<li class="item">Result %[1]d-b</li>
+		</ul>%[2]s</body></html>`, pageNum, next)
+	}))
+	t.Cleanup(ts.Close)
+	return ts.URL
+}
+
+func TestScrapePaginatedStopsAtLastPage(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+
+	if err := mgr.Navigate(ctx, servePaginatedFixture(t)); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+	items, err := mgr.ScrapePaginated(ctx, ".item", "", 10)
+	if err != nil {
+		t.Fatalf("ScrapePaginated failed: %v", err)
+	}
+	if len(items) != 6 || items[0] != "Result 1-a" || items[5] != "Result 3-b" {
+		t.Fatalf("unexpected items: %v", items)
+	}
+
+	more, err := mgr.NextPage(ctx, "")
+	if err != nil {
+		t.Fatalf("NextPage on last page failed: %v", err)
+	}
+	if more {
+		t.Fatalf("expected no next page after the last page")
+	}
+}
+
+func TestNextPageCustomSelector(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+
+	if err := mgr.Navigate(ctx, servePaginatedFixture(t)); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+	more, err := mgr.NextPage(ctx, "a.pager")
+	if err != nil || !more {
+		t.Fatalf("expected to follow custom next selector, got more=%v err=%v", more, err)
+	}
+	items, err := mgr.ScrapeList(ctx, ".item")
+	if err != nil || len(items) != 2 || items[0] != "Result 2-a" {
+		t.Fatalf("expected page 2 items, got %v (err=%v)", items, err)
+	}
+}
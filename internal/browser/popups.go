@@ -0,0 +1,96 @@
+package browser
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// PopupPolicy decides what happens when a site opens a new window or tab.
+type PopupPolicy string
+
+const (
+	// PopupSwitch registers the new page and makes it active (e.g. login popups).
+	PopupSwitch PopupPolicy = "switch"
+	// PopupBackground registers the new page but keeps the current page active.
+	PopupBackground PopupPolicy = "background"
+	// PopupClose closes the new page immediately (e.g. ad popups).
+	PopupClose PopupPolicy = "close"
+)
+
+// popupLoadTimeout bounds how long we wait for a popup to reveal its URL.
+const popupLoadTimeout = 5 * time.Second
+
+// PopupRule applies a policy to new pages whose URL contains Pattern.
+type PopupRule struct {
+	Pattern string
+	Policy  PopupPolicy
+}
+
+// SetPopupPolicy adds a rule for popups whose URL contains pattern. An empty
+// pattern (or "*") sets the default policy. Earlier rules take precedence.
+func (m *Manager) SetPopupPolicy(pattern string, policy PopupPolicy) {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "" || pattern == "*" {
+		m.defaultPopupPolicy = policy
+		return
+	}
+	m.popupRules = append(m.popupRules, PopupRule{Pattern: pattern, Policy: policy})
+}
+
+// popupPolicyFor returns the policy for a new page at the given URL.
+func (m *Manager) popupPolicyFor(url string) PopupPolicy {
+	lower := strings.ToLower(url)
+	for _, rule := range m.popupRules {
+		if strings.Contains(lower, rule.Pattern) {
+			return rule.Policy
+		}
+	}
+	if m.defaultPopupPolicy != "" {
+		return m.defaultPopupPolicy
+	}
+	return PopupSwitch
+}
+
+// handleNewPage registers a page opened by the site and applies the popup
+// policy once its URL is known. Event handlers run on Playwright's dispatch
+// goroutine, so the wait happens in the background.
+func (m *Manager) handleNewPage(page playwright.Page) {
+	m.registerPage(page, false)
+
+	go func() {
+		if url := page.URL(); url == "" || url == "about:blank" {
+			_ = page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
+				State:   playwright.LoadStateDomcontentloaded,
+				Timeout: playwright.Float(float64(popupLoadTimeout.Milliseconds())),
+			})
+		}
+		url := safePageURL(page)
+
+		switch policy := m.popupPolicyFor(url); policy {
+		case PopupClose:
+			log.Printf("Closing popup per policy: %s\n", url)
+			if err := page.Close(); err != nil {
+				log.Printf("Warning: failed to close popup: %v\n", err)
+			}
+		case PopupBackground:
+			log.Printf("Keeping popup in background: %s\n", url)
+		default:
+			m.setActivePage(pageIdentifier(page), true)
+		}
+	}()
+}
+
+// loadPopupRulesFromEnv applies BROWSER_POPUP_POLICY ("ads.example=close,accounts.google=switch,*=background").
+func (m *Manager) loadPopupRulesFromEnv() {
+	for _, entry := range strings.Split(os.Getenv("BROWSER_POPUP_POLICY"), ",") {
+		pattern, policy, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		m.SetPopupPolicy(pattern, PopupPolicy(strings.ToLower(strings.TrimSpace(policy))))
+	}
+}
@@ -0,0 +1,85 @@
+package browser
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPopupPolicyFor(t *testing.T) {
+	m := &Manager{}
+	m.SetPopupPolicy("ads.", PopupClose)
+	m.SetPopupPolicy("accounts.google", PopupSwitch)
+	m.SetPopupPolicy("*", PopupBackground)
+
+	tests := []struct {
+		url      string
+		expected PopupPolicy
+	}{
+		{"https://ads.example.com/banner", PopupClose},
+		{"https://accounts.google.com/signin", PopupSwitch},
+		{"https://example.com/help", PopupBackground},
+	}
+	for _, tt := range tests {
+		if got := m.popupPolicyFor(tt.url); got != tt.expected {
+			t.Errorf("popupPolicyFor(%s) = %s, want %s", tt.url, got, tt.expected)
+		}
+	}
+
+	if got := (&Manager{}).popupPolicyFor("https://example.com"); got != PopupSwitch {
+		t.Errorf("default policy should switch to the new page, got %s", got)
+	}
+}
+
+func TestPopupPolicyApplied(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ad", "/login":
+			w.Write([]byte(`<html><body>` + r.URL.Path + `</body></html>`))
+		default:
+			w.Write([]byte(`<html><body>
+				<button id="ad" onclick="window.open('/ad')">Ad</button>
+				<button id="login" onclick="window.open('/login')">Login</button>
+			</body></html>`))
+		}
+	}))
+	defer ts.Close()
+
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	mgr.SetPopupPolicy("/ad", PopupClose)
+	mgr.SetPopupPolicy("/login", PopupSwitch)
+
+	if err := mgr.Navigate(ctx, ts.URL); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+
+	if err := mgr.Click(ctx, "#ad"); err != nil {
+		t.Fatalf("click failed: %v", err)
+	}
+	waitFor(t, func() bool { return len(mgr.context.Pages()) == 1 })
+	if strings.HasSuffix(mgr.page.URL(), "/ad") {
+		t.Fatalf("ad popup should have been closed, active page is %s", mgr.page.URL())
+	}
+
+	if err := mgr.Click(ctx, "#login"); err != nil {
+		t.Fatalf("click failed: %v", err)
+	}
+	waitFor(t, func() bool { return strings.HasSuffix(mgr.page.URL(), "/login") })
+}
+
+// waitFor polls cond until it holds or the deadline passes.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatalf("condition not met before timeout")
+}
@@ -0,0 +1,215 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// lockFileName is created inside a user-data-dir while a manager is using it.
+const lockFileName = ".aibot.lock"
+
+// profileMarkerName marks a directory as an AIBot profile so that clearing the
+// default profile (the base dir) does not delete named profiles nested inside it.
+const profileMarkerName = ".aibot-profile"
+
+// ErrProfileLocked is returned when another process is already using a profile.
+var ErrProfileLocked = errors.New("browser profile is in use by another process")
+
+// baseUserDataDir returns the root directory for persistent browser profiles.
+func baseUserDataDir() string {
+	dir := os.Getenv("BROWSER_USER_DATA_DIR")
+	if dir == "" {
+		dir = ".pw_user_data"
+	}
+	return dir
+}
+
+// ProfileDir maps a profile name onto its user-data-dir. The empty profile is
+// the base directory itself, so existing sessions keep working.
+func ProfileDir(profile string) (string, error) {
+	profile = strings.TrimSpace(profile)
+	if profile == "" {
+		return baseUserDataDir(), nil
+	}
+	if profile == "." || profile == ".." || strings.ContainsAny(profile, `/\`) {
+		return "", fmt.Errorf("invalid profile name %q", profile)
+	}
+	return filepath.Join(baseUserDataDir(), profile), nil
+}
+
+// acquireProfileLock marks dir as in use by this process. A lock left behind by
+// a process that no longer exists is taken over.
+func acquireProfileLock(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create user data dir: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, profileMarkerName), nil, 0o600); err != nil {
+		log.Printf("Warning: failed to write profile marker: %v\n", err)
+	}
+	path := filepath.Join(dir, lockFileName)
+
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_, _ = f.WriteString(strconv.Itoa(os.Getpid()))
+			return f.Close()
+		}
+		if !os.IsExist(err) {
+			return fmt.Errorf("failed to create profile lock: %w", err)
+		}
+
+		data, readErr := os.ReadFile(path)
+		pid, convErr := strconv.Atoi(strings.TrimSpace(string(data)))
+		if readErr == nil && convErr == nil && processAlive(pid) {
+			return fmt.Errorf("%w: %s (pid %d)", ErrProfileLocked, dir, pid)
+		}
+		log.Printf("Removing stale profile lock in %s\n", dir)
+		_ = os.Remove(path)
+	}
+	return fmt.Errorf("%w: %s", ErrProfileLocked, dir)
+}
+
+// releaseProfileLock removes the lock if it belongs to this process.
+func releaseProfileLock(dir string) {
+	if dir == "" {
+		return
+	}
+	path := filepath.Join(dir, lockFileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil && pid == os.Getpid() {
+		_ = os.Remove(path)
+	}
+}
+
+// releaseUserDataDir gives up a user-data-dir once the browser is done with
+// it: the lock of a profile is released, a temporary dir is deleted.
+func releaseUserDataDir(dir string, ephemeral bool) {
+	if !ephemeral {
+		releaseProfileLock(dir)
+		return
+	}
+	if dir != "" {
+		if err := os.RemoveAll(dir); err != nil {
+			log.Printf("Warning: failed to remove temporary profile %s: %v\n", dir, err)
+		}
+	}
+}
+
+// Profile returns the name of the active profile ("" for the default profile).
+func (m *Manager) Profile() string {
+	return m.profile
+}
+
+// SwitchProfile relaunches the browser context with a different profile's
+// user-data-dir. The new profile is launched before the current one is
+// closed, so a profile that fails to launch leaves the current one running.
+func (m *Manager) SwitchProfile(ctx context.Context, profile string) error {
+	if m.Remote() {
+		return fmt.Errorf("profiles are not available on a remote browser")
+	}
+	dir, err := ProfileDir(profile)
+	if err != nil {
+		return err
+	}
+	if dir == m.userDataDir {
+		return nil
+	}
+	if err := acquireProfileLock(dir); err != nil {
+		return err
+	}
+	if err := m.ensurePlaywright(ctx); err != nil {
+		releaseProfileLock(dir)
+		return err
+	}
+
+	browserCtx, err := launchPersistentWithFallback(m.playwright, dir, defaultLaunchArgs(), m.launch)
+	if err != nil {
+		releaseProfileLock(dir)
+		return fmt.Errorf("failed to launch profile %q: %w", profile, err)
+	}
+	if len(browserCtx.Pages()) == 0 {
+		if _, err := browserCtx.NewPage(); err != nil {
+			_ = browserCtx.Close()
+			releaseProfileLock(dir)
+			return fmt.Errorf("failed to create page for profile %q: %w", profile, err)
+		}
+	}
+
+	m.cleanupCurrentContext()
+	releaseUserDataDir(m.userDataDir, m.ephemeral)
+	m.profile = profile
+	m.userDataDir = dir
+	m.ephemeral = false
+	m.context = browserCtx
+	m.attachContextListeners(browserCtx)
+	m.rebuildPageTracking(browserCtx)
+	log.Printf("Switched to browser profile %q (%s)\n", profile, dir)
+	return nil
+}
+
+// ClearProfileData deletes the stored session (cookies, storage, cache) of a
+// profile that is not currently in use. Named profiles nested in the default
+// profile's directory are kept.
+func ClearProfileData(profile string) error {
+	dir, err := ProfileDir(profile)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+	if err := acquireProfileLock(dir); err != nil {
+		return err
+	}
+	defer releaseProfileLock(dir)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read profile dir: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if name == lockFileName || name == profileMarkerName {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if entry.IsDir() {
+			if _, err := os.Stat(filepath.Join(path, profileMarkerName)); err == nil {
+				continue
+			}
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// ClearSession logs out of everything in the running browser by clearing
+// cookies, permissions, and web storage of every open tab.
+func (m *Manager) ClearSession(ctx context.Context) error {
+	if _, err := m.activePage(ctx); err != nil {
+		return err
+	}
+	if err := m.context.ClearCookies(); err != nil {
+		return fmt.Errorf("failed to clear cookies: %w", err)
+	}
+	if err := m.context.ClearPermissions(); err != nil {
+		return fmt.Errorf("failed to clear permissions: %w", err)
+	}
+	for _, page := range m.context.Pages() {
+		if _, err := page.Evaluate(`() => { try { localStorage.clear(); sessionStorage.clear(); } catch (e) {} }`); err != nil {
+			log.Printf("Warning: failed to clear storage for %s: %v\n", safePageURL(page), err)
+		}
+	}
+	return nil
+}
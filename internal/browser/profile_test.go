@@ -0,0 +1,165 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+func TestProfileDir(t *testing.T) {
+	t.Setenv("BROWSER_USER_DATA_DIR", "/tmp/pw")
+
+	work, err := ProfileDir("work")
+	if err != nil {
+		t.Fatalf("ProfileDir failed: %v", err)
+	}
+	personal, _ := ProfileDir("personal")
+	if work == personal {
+		t.Fatalf("different profiles must use different dirs, both got %s", work)
+	}
+	if base, _ := ProfileDir(""); base != "/tmp/pw" {
+		t.Fatalf("default profile should use the base dir, got %s", base)
+	}
+	if _, err := ProfileDir("../escape"); err == nil {
+		t.Fatalf("expected path-like profile names to be rejected")
+	}
+}
+
+func TestProfileLockPreventsConcurrentUse(t *testing.T) {
+	dir := t.TempDir()
+	if err := acquireProfileLock(dir); err != nil {
+		t.Fatalf("first lock failed: %v", err)
+	}
+	if err := acquireProfileLock(dir); !errors.Is(err, ErrProfileLocked) {
+		t.Fatalf("expected ErrProfileLocked for a second user, got %v", err)
+	}
+	releaseProfileLock(dir)
+	if err := acquireProfileLock(dir); err != nil {
+		t.Fatalf("lock should be free after release: %v", err)
+	}
+	releaseProfileLock(dir)
+}
+
+func TestProfilesIsolateCookies(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+
+	url := serveFixture(t, `<html><body>profile</body></html>`)
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+	if err := mgr.context.AddCookies([]playwright.OptionalCookie{{Name: "session", Value: "work", URL: playwright.String(url)}}); err != nil {
+		t.Fatalf("failed to set cookie: %v", err)
+	}
+
+	if err := mgr.SwitchProfile(ctx, "personal"); err != nil {
+		t.Fatalf("switch profile failed: %v", err)
+	}
+	cookies, err := mgr.context.Cookies(url)
+	if err != nil {
+		t.Fatalf("failed to read cookies: %v", err)
+	}
+	if len(cookies) != 0 {
+		t.Fatalf("expected a clean cookie jar in the new profile, got %v", cookies)
+	}
+}
+
+func TestFailedSwitchKeepsCurrentProfile(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	url := serveFixture(t, `<html><body>still here</body></html>`)
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+
+	// An unknown device fails the launch after the new profile is locked.
+	mgr.launch.Device = "No Such Phone"
+	if err := mgr.SwitchProfile(ctx, "broken"); err == nil {
+		t.Fatal("switching with a launch that fails should fail")
+	}
+	mgr.launch.Device = ""
+
+	if err := acquireProfileLock(mgr.userDataDir); !errors.Is(err, ErrProfileLocked) {
+		t.Errorf("the current profile should stay locked, got %v", err)
+	}
+	brokenDir, _ := ProfileDir("broken")
+	if err := acquireProfileLock(brokenDir); err != nil {
+		t.Errorf("the profile that failed to launch should be unlocked: %v", err)
+	}
+	releaseProfileLock(brokenDir)
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("the current profile should keep working: %v", err)
+	}
+}
+
+func TestClearProfileData(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("BROWSER_USER_DATA_DIR", base)
+
+	// A stored session in the default profile and a named profile nested inside it.
+	if err := os.MkdirAll(filepath.Join(base, "Default"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(base, "Default", "Cookies"), []byte("session"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	workDir, _ := ProfileDir("work")
+	if err := acquireProfileLock(workDir); err != nil {
+		t.Fatal(err)
+	}
+	releaseProfileLock(workDir)
+
+	if err := ClearProfileData(""); err != nil {
+		t.Fatalf("ClearProfileData failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(base, "Default")); !os.IsNotExist(err) {
+		t.Fatalf("expected session data to be removed")
+	}
+	if _, err := os.Stat(workDir); err != nil {
+		t.Fatalf("named profile should survive clearing the default profile: %v", err)
+	}
+
+	if err := acquireProfileLock(workDir); err != nil {
+		t.Fatal(err)
+	}
+	defer releaseProfileLock(workDir)
+	if err := ClearProfileData("work"); !errors.Is(err, ErrProfileLocked) {
+		t.Fatalf("clearing a profile in use should fail with ErrProfileLocked, got %v", err)
+	}
+}
+
+func TestClearSessionRelaunchesClean(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+
+	url := serveFixture(t, `<html><body>session</body></html>`)
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+	if err := mgr.context.AddCookies([]playwright.OptionalCookie{{Name: "session", Value: "1", URL: playwright.String(url)}}); err != nil {
+		t.Fatalf("failed to set cookie: %v", err)
+	}
+	if err := mgr.Close(ctx); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	if err := ClearProfileData(""); err != nil {
+		t.Fatalf("ClearProfileData failed: %v", err)
+	}
+
+	relaunched, err := NewManager(ctx)
+	if err != nil {
+		t.Fatalf("relaunch failed: %v", err)
+	}
+	defer relaunched.Close(ctx)
+	cookies, err := relaunched.context.Cookies(url)
+	if err != nil {
+		t.Fatalf("failed to read cookies: %v", err)
+	}
+	if len(cookies) != 0 {
+		t.Fatalf("expected a clean profile after clearing, got %v", cookies)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package browser
+
+// processAlive reports whether a process with the given pid is running. Windows
+// has no signal-0 probe, so any recorded pid is treated as live; remove the lock
+// file by hand after a crash.
+func processAlive(pid int) bool {
+	return pid > 0
+}
@@ -0,0 +1,133 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// maxArticleText bounds the article text returned by ExtractArticle (in runes).
+const maxArticleText = 100000
+
+// Article is the main content of a page without its navigation, sidebars,
+// footers and other boilerplate.
+type Article struct {
+	Title     string `json:"title"`
+	Byline    string `json:"byline,omitempty"`
+	Published string `json:"published,omitempty"`
+	// Text is the article as plain text, one block per paragraph separated by
+	// blank lines. Headings are prefixed with "#" and list items with "-".
+	Text string `json:"text"`
+}
+
+// extractArticleScript finds the element holding the main content the way
+// readability tools do: every paragraph credits its parent and, less so, its
+// grandparents by its length, link-heavy and boilerplate-named containers are
+// penalized, and the best scoring element wins.
+const extractArticleScript = `() => {
+	const norm = (s) => (s || '').replace(/\s+/g, ' ').trim();
+	const meta = (...names) => {
+		for (const name of names) {
+			const el = document.querySelector('meta[property="' + name + '"], meta[name="' + name + '"]');
+			if (el && el.content) return norm(el.content);
+		}
+		return '';
+	};
+	const noise = 'script, style, noscript, template, svg, canvas, iframe, nav, header, footer, aside, form, button, dialog, ' +
+		'[role="navigation"], [role="banner"], [role="contentinfo"], [role="complementary"], [role="dialog"], [aria-hidden="true"], [hidden]';
+	const noisyName = /comment|share|social|related|promo|sidebar|cookie|banner|advert|newsletter|subscribe|breadcrumb|menu/i;
+	const nameOf = (el) => (typeof el.className === 'string' ? el.className : '') + ' ' + el.id;
+	const linkDensity = (el) => {
+		const total = norm(el.textContent).length || 1;
+		let links = 0;
+		for (const a of el.querySelectorAll('a')) links += norm(a.textContent).length;
+		return links / total;
+	};
+
+	const scores = new Map();
+	for (const p of document.body.querySelectorAll('p, pre, blockquote')) {
+		if (p.closest(noise)) continue;
+		const text = norm(p.textContent);
+		if (text.length < 25) continue;
+		const score = 1 + text.split(',').length + Math.min(3, Math.floor(text.length / 100));
+		let parent = p.parentElement;
+		for (let depth = 0; parent && depth < 3; depth++, parent = parent.parentElement) {
+			scores.set(parent, (scores.get(parent) || 0) + score / (depth + 1));
+		}
+	}
+	let best = null;
+	let bestScore = 0;
+	for (const [el, score] of scores) {
+		let adjusted = score * (1 - linkDensity(el));
+		if (noisyName.test(nameOf(el))) adjusted *= 0.3;
+		if (el.matches('article, main, [role="main"], [itemprop="articleBody"]')) adjusted *= 1.5;
+		if (adjusted > bestScore) {
+			best = el;
+			bestScore = adjusted;
+		}
+	}
+	if (!best) best = document.querySelector('article, main, [role="main"]') || document.body;
+
+	const blockTags = 'p, div, section, article, main, h1, h2, h3, h4, h5, h6, ul, ol, li, table, pre, blockquote, figure, dl';
+	const blocks = [];
+	const walk = (el) => {
+		for (const child of el.children) {
+			if (child.matches(noise)) continue;
+			if (noisyName.test(nameOf(child)) && linkDensity(child) > 0.3) continue;
+			const tag = child.tagName;
+			if (/^H[1-6]$/.test(tag)) {
+				const text = norm(child.textContent);
+				if (text) blocks.push('#'.repeat(Number(tag[1])) + ' ' + text);
+			} else if (tag === 'PRE') {
+				const text = child.textContent.trim();
+				if (text) blocks.push(text);
+			} else if (tag === 'TABLE') {
+				const text = (child.innerText || '').trim();
+				if (text) blocks.push(text);
+			} else if (child.querySelector(blockTags)) {
+				walk(child);
+			} else {
+				const text = norm(child.textContent);
+				if (!text) continue;
+				if (tag === 'LI') blocks.push('- ' + text);
+				else if (tag === 'BLOCKQUOTE') blocks.push('> ' + text);
+				else blocks.push(text);
+			}
+		}
+	};
+	walk(best);
+
+	const heading = best.querySelector('h1') || document.querySelector('h1');
+	const author = document.querySelector('[rel="author"], [itemprop="author"], .byline, .author');
+	const time = document.querySelector('time[datetime]');
+	return {
+		title: meta('og:title', 'twitter:title') || norm(heading && heading.textContent) || document.title,
+		byline: meta('author', 'article:author') || norm(author && author.textContent),
+		published: meta('article:published_time', 'datePublished') || (time ? time.getAttribute('datetime') : ''),
+		text: blocks.join('\n\n'),
+	};
+}`
+
+// ExtractArticle returns the main content of the active page, e.g. to
+// summarize a news article or blog post without spending tokens on its menus
+// and footers.
+func (m *Manager) ExtractArticle(ctx context.Context) (Article, error) {
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return Article{}, err
+	}
+	result, err := page.Evaluate(extractArticleScript)
+	if err != nil {
+		return Article{}, fmt.Errorf("failed to extract article: %w", err)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return Article{}, fmt.Errorf("failed to read article: %w", err)
+	}
+	var article Article
+	if err := json.Unmarshal(data, &article); err != nil {
+		return Article{}, fmt.Errorf("failed to read article: %w", err)
+	}
+	article.Text = capText(article.Text, maxArticleText)
+	return article, nil
+}
@@ -0,0 +1,48 @@
+package browser
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestExtractArticle(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	url := serveFixture(t, `<html><head>
+		<title>Kettles | Example News</title>
+		<meta name="author" content="Jane Doe">
+		<meta property="article:published_time" content="2024-03-01">
+	</head><body>
+		<header><nav><a href="/">Home</a> <a href="/news">News</a></nav></header>
+		<div class="sidebar"><p>Subscribe to our newsletter, get the latest stories, every single day of the week.</p></div>
+		<article>
+			<h1>Why kettles whistle</h1>
+			<p>A kettle whistles because steam, forced through a small opening, sets up vibrations in the air.</p>
+			<p>The pitch depends on the size of the opening, the speed of the steam, and the shape of the chamber.</p>
+			<ul><li>Small openings whistle higher</li><li>Fast steam whistles louder</li></ul>
+		</article>
+		<footer><p>Copyright Example News, all rights reserved, since the beginning of time.</p></footer>
+	</body></html>`)
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+
+	article, err := mgr.ExtractArticle(ctx)
+	if err != nil {
+		t.Fatalf("ExtractArticle failed: %v", err)
+	}
+	if article.Title != "Why kettles whistle" || article.Byline != "Jane Doe" || article.Published != "2024-03-01" {
+		t.Errorf("unexpected metadata: %+v", article)
+	}
+	for _, want := range []string{"# Why kettles whistle", "steam, forced through", "- Small openings whistle higher"} {
+		if !strings.Contains(article.Text, want) {
+			t.Errorf("article text missing %q:\n%s", want, article.Text)
+		}
+	}
+	for _, noise := range []string{"newsletter", "Copyright", "Home"} {
+		if strings.Contains(article.Text, noise) {
+			t.Errorf("article text contains boilerplate %q:\n%s", noise, article.Text)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// ConnectOverCDP attaches to an already-running Chromium (a local Chrome
+// started with --remote-debugging-port, browserless.io, a remote grid)
+// instead of a locally launched browser. endpoint is the DevTools URL, e.g.
+// http://localhost:9222 or wss://chrome.browserless.io?token=....
+//
+// The local browser is closed and its profile released. The browser's
+// default context is used as-is, so device and viewport options are not
+// applied. Recovery reconnects to the same endpoint.
+func (m *Manager) ConnectOverCDP(ctx context.Context, endpoint string) error {
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		return fmt.Errorf("CDP endpoint is required")
+	}
+	if m.saved != nil {
+		return fmt.Errorf("cannot switch browsers while an isolated context is active")
+	}
+	if err := m.ensurePlaywright(ctx); err != nil {
+		return err
+	}
+	browser, browserCtx, err := connectOverCDP(m.playwright, endpoint)
+	if err != nil {
+		return err
+	}
+
+	m.cleanupCurrentContext()
+	releaseUserDataDir(m.userDataDir, m.ephemeral)
+	m.userDataDir = ""
+	m.ephemeral = false
+	m.launch.CDPEndpoint = endpoint
+	m.browser = browser
+	m.context = browserCtx
+	m.attachContextListeners(browserCtx)
+	m.rebuildPageTracking(browserCtx)
+	log.Printf("Connected to remote browser %s\n", browser.Version())
+	return nil
+}
+
+// Remote reports whether the manager is attached to a browser over CDP.
+func (m *Manager) Remote() bool {
+	return m.launch.CDPEndpoint != ""
+}
+
+// connectOverCDP connects to endpoint and returns the browser and its default
+// context, making sure it has a page.
+func connectOverCDP(pw *playwright.Playwright, endpoint string) (playwright.Browser, playwright.BrowserContext, error) {
+	if pw == nil {
+		return nil, nil, fmt.Errorf("playwright not initialized")
+	}
+	browser, err := pw.Chromium.ConnectOverCDP(endpoint)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to browser over CDP: %w", err)
+	}
+	var browserCtx playwright.BrowserContext
+	if contexts := browser.Contexts(); len(contexts) > 0 {
+		browserCtx = contexts[0]
+	} else if browserCtx, err = browser.NewContext(); err != nil {
+		_ = browser.Close()
+		return nil, nil, fmt.Errorf("failed to create context on remote browser: %w", err)
+	}
+	if len(browserCtx.Pages()) == 0 {
+		if _, err := browserCtx.NewPage(); err != nil {
+			_ = browser.Close()
+			return nil, nil, fmt.Errorf("failed to create page on remote browser: %w", err)
+		}
+	}
+	return browser, browserCtx, nil
+}
+
+// launchContext (re)starts the browser context: a connection to the CDP
+// endpoint if one is configured, otherwise a persistent context on the
+// profile's user-data-dir.
+func (m *Manager) launchContext() (playwright.BrowserContext, error) {
+	if m.launch.CDPEndpoint == "" {
+		return launchPersistentWithFallback(m.playwright, m.userDataDir, defaultLaunchArgs(), m.launch)
+	}
+	browser, browserCtx, err := connectOverCDP(m.playwright, m.launch.CDPEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	m.browser = browser
+	return browserCtx, nil
+}
@@ -0,0 +1,23 @@
+package browser
+
+import (
+	"context"
+	"testing"
+)
+
+func TestConnectOverCDPRequiresEndpoint(t *testing.T) {
+	m := &Manager{}
+	if err := m.ConnectOverCDP(context.Background(), "  "); err == nil {
+		t.Fatal("expected an error for an empty endpoint")
+	}
+	if m.Remote() {
+		t.Fatal("a failed connection must not mark the manager as remote")
+	}
+}
+
+func TestSwitchProfileOnRemoteBrowser(t *testing.T) {
+	m := &Manager{launch: LaunchOptions{CDPEndpoint: "http://localhost:9222"}}
+	if err := m.SwitchProfile(context.Background(), "work"); err == nil {
+		t.Fatal("expected profiles to be unavailable on a remote browser")
+	}
+}
@@ -0,0 +1,84 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// ScreenshotOptions controls what Screenshot captures.
+type ScreenshotOptions struct {
+	FullPage bool   // capture the whole scrollable page instead of the viewport
+	Selector string // capture only this element
+	Path     string // also write the PNG to this file
+}
+
+// CaptureViewport takes a PNG of the viewport of the active page for someone
+// watching a task from another goroutine, such as the web dashboard. Unlike
+// Screenshot it never switches tabs, opens a page or recovers the browser,
+// which would pull the browser from under the task; without a usable page it
+// fails instead.
+func (m *Manager) CaptureViewport() ([]byte, error) {
+	m.pageMu.RLock()
+	page := m.page
+	m.pageMu.RUnlock()
+	if page == nil || page.IsClosed() {
+		return nil, errors.New("no page to capture")
+	}
+	data, err := page.Screenshot(playwright.PageScreenshotOptions{Type: playwright.ScreenshotTypePng})
+	if err != nil {
+		return nil, fmt.Errorf("failed to take screenshot: %w", err)
+	}
+	return data, nil
+}
+
+// Screenshot captures the active page, or a single element, as PNG.
+func (m *Manager) Screenshot(ctx context.Context, opts ScreenshotOptions) ([]byte, error) {
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Path != "" {
+		if err := os.MkdirAll(filepath.Dir(opts.Path), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create screenshot dir: %w", err)
+		}
+	}
+
+	var data []byte
+	if opts.Selector != "" {
+		element, err := page.QuerySelector(opts.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query selector: %w", err)
+		}
+		if element == nil {
+			return nil, fmt.Errorf("no element matches %s", opts.Selector)
+		}
+		elemOpts := playwright.ElementHandleScreenshotOptions{Type: playwright.ScreenshotTypePng}
+		if opts.Path != "" {
+			elemOpts.Path = playwright.String(opts.Path)
+		}
+		data, err = element.Screenshot(elemOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to take element screenshot: %w", err)
+		}
+		return data, nil
+	}
+
+	pageOpts := playwright.PageScreenshotOptions{
+		FullPage: playwright.Bool(opts.FullPage),
+		Type:     playwright.ScreenshotTypePng,
+	}
+	if opts.Path != "" {
+		pageOpts.Path = playwright.String(opts.Path)
+	}
+	data, err = page.Screenshot(pageOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take screenshot: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,66 @@
+package browser
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScreenshotReturnsPNG(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+
+	url := serveFixture(t, `<html><body><canvas id="map" width="400" height="300"></canvas></body></html>`)
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+	data, err := mgr.Screenshot(ctx, ScreenshotOptions{})
+	if err != nil {
+		t.Fatalf("Screenshot failed: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("\x89PNG")) {
+		t.Fatalf("expected PNG data, got %d bytes", len(data))
+	}
+}
+
+func TestScreenshotElementToFile(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+
+	url := serveFixture(t, `<html><body><div id="address" style="width:200px;height:50px">Red Square, Moscow</div></body></html>`)
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "shots", "address.png")
+	if _, err := mgr.Screenshot(ctx, ScreenshotOptions{Selector: "#address", Path: path}); err != nil {
+		t.Fatalf("element screenshot failed: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+		t.Fatalf("expected screenshot file at %s: %v", path, err)
+	}
+	if _, err := mgr.Screenshot(ctx, ScreenshotOptions{Selector: "#missing"}); err == nil {
+		t.Fatalf("expected an error for a missing element")
+	}
+}
+
+func TestCaptureViewportLeavesTheBrowserAlone(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	if err := mgr.Navigate(ctx, serveFixture(t, `<html><body><h1>Live</h1></body></html>`)); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+	data, err := mgr.CaptureViewport()
+	if err != nil || !bytes.HasPrefix(data, []byte("\x89PNG")) {
+		t.Fatalf("CaptureViewport gave %d bytes, %v", len(data), err)
+	}
+
+	if err := mgr.page.Close(); err != nil {
+		t.Fatalf("failed to close page: %v", err)
+	}
+	// Screenshot would open a new tab here; a capture must not.
+	if _, err := mgr.CaptureViewport(); err == nil {
+		t.Fatal("capturing a closed page should fail, not open another")
+	}
+}
@@ -0,0 +1,160 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// defaultMaxScrolls bounds ScrollUntilLoaded on feeds that never end.
+	defaultMaxScrolls = 10
+	// scrollGrowthTimeout is how long to wait for new content after a scroll.
+	scrollGrowthTimeout = 3 * time.Second
+	scrollPollInterval  = 250 * time.Millisecond
+)
+
+// ScrollToBottom scrolls the page to the end so lazily loaded content renders.
+func (m *Manager) ScrollToBottom(ctx context.Context) error {
+	return m.scrollEval(ctx, `() => window.scrollTo(0, document.body.scrollHeight)`)
+}
+
+// ScrollToTop scrolls back to the start of the page.
+func (m *Manager) ScrollToTop(ctx context.Context) error {
+	return m.scrollEval(ctx, `() => window.scrollTo(0, 0)`)
+}
+
+// ScrollBy scrolls the page vertically by pixels (negative scrolls up).
+func (m *Manager) ScrollBy(ctx context.Context, pixels int) error {
+	return m.scrollEval(ctx, `px => window.scrollBy(0, px)`, pixels)
+}
+
+// ScrollByViewport scrolls one screen down, or up when pages is negative.
+func (m *Manager) ScrollByViewport(ctx context.Context, pages int) error {
+	return m.scrollEval(ctx, `n => window.scrollBy(0, n * window.innerHeight * 0.9)`, pages)
+}
+
+// ScrollIntoView scrolls until the element matching selector is visible.
+func (m *Manager) ScrollIntoView(ctx context.Context, selector string) error {
+	frame, inner, err := m.resolveFrame(ctx, selector)
+	if err != nil {
+		return err
+	}
+	if err := frame.Locator(inner).First().ScrollIntoViewIfNeeded(); err != nil {
+		return fmt.Errorf("failed to scroll to %s: %w", selector, err)
+	}
+	return nil
+}
+
+func (m *Manager) scrollEval(ctx context.Context, script string, arg ...interface{}) error {
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := page.Evaluate(script, arg...); err != nil {
+		return fmt.Errorf("failed to scroll: %w", err)
+	}
+	return nil
+}
+
+// InfiniteScrollOptions controls ScrollUntilLoaded.
+type InfiniteScrollOptions struct {
+	// ItemSelector counts loaded items; when empty the page height is used
+	// to detect new content.
+	ItemSelector string
+	// MinItems stops scrolling once this many items are present.
+	MinItems int
+	// MaxScrolls bounds the number of scrolls (default 10).
+	MaxScrolls int
+}
+
+// ScrollUntilLoaded scrolls an infinite feed to the bottom repeatedly,
+// waiting for new content after each scroll, until enough items are loaded,
+// nothing new appears, or MaxScrolls is reached. It returns the final item
+// count (or 0 without an ItemSelector).
+func (m *Manager) ScrollUntilLoaded(ctx context.Context, opts InfiniteScrollOptions) (int, error) {
+	maxScrolls := opts.MaxScrolls
+	if maxScrolls <= 0 {
+		maxScrolls = defaultMaxScrolls
+	}
+
+	size, err := m.contentSize(ctx, opts.ItemSelector)
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < maxScrolls; i++ {
+		if opts.ItemSelector != "" && opts.MinItems > 0 && size >= opts.MinItems {
+			break
+		}
+		if err := m.ScrollToBottom(ctx); err != nil {
+			return scrolledItems(size, opts), err
+		}
+		grown, err := m.waitForGrowth(ctx, opts.ItemSelector, size)
+		if err != nil {
+			return scrolledItems(size, opts), err
+		}
+		if grown <= size {
+			break
+		}
+		size = grown
+	}
+	_ = m.WaitForReady(ctx)
+	return scrolledItems(size, opts), nil
+}
+
+func scrolledItems(size int, opts InfiniteScrollOptions) int {
+	if opts.ItemSelector == "" {
+		return 0
+	}
+	return size
+}
+
+// waitForGrowth polls until the content size exceeds previous or the growth
+// timeout passes, and returns the latest size.
+func (m *Manager) waitForGrowth(ctx context.Context, itemSelector string, previous int) (int, error) {
+	deadline := time.Now().Add(scrollGrowthTimeout)
+	size := previous
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return size, ctx.Err()
+		case <-time.After(scrollPollInterval):
+		}
+		current, err := m.contentSize(ctx, itemSelector)
+		if err != nil {
+			return size, err
+		}
+		if current > previous {
+			return current, nil
+		}
+		size = current
+	}
+	return size, nil
+}
+
+// contentSize measures how much of a feed is loaded: the number of items
+// matching itemSelector, or the document height.
+func (m *Manager) contentSize(ctx context.Context, itemSelector string) (int, error) {
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if itemSelector != "" {
+		count, err := page.Locator(itemSelector).Count()
+		if err != nil {
+			return 0, fmt.Errorf("failed to count %s: %w", itemSelector, err)
+		}
+		return count, nil
+	}
+	result, err := page.Evaluate(`() => document.body.scrollHeight`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure page height: %w", err)
+	}
+	switch h := result.(type) {
+	case int:
+		return h, nil
+	case float64:
+		return int(h), nil
+	}
+	return 0, nil
+}
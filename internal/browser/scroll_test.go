@@ -0,0 +1,70 @@
+package browser
+
+import (
+	"context"
+	"testing"
+)
+
+// infiniteFeedHTML appends ten items whenever the page is scrolled to the
+// bottom, up to 50 items.
+const infiniteFeedHTML = `<html><body><div id="feed"></div><script>
+	const feed = document.getElementById("feed");
+	function more() {
+		for (let i = 0; i < 10 && feed.children.length < 50; i++) {
+			const item = document.createElement("div");
+			item.className = "item";
+			item.style.height = "200px";
+			item.textContent = "Item " + (feed.children.length + 1);
+			feed.appendChild(item);
+		}
+	}
+	more();
+	window.addEventListener("scroll", () => {
+		if (window.innerHeight + window.scrollY >= document.body.scrollHeight - 10) {
+			setTimeout(more, 100);
+		}
+	});
+</script></body></html>`
+
+func TestScrollUntilLoaded(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	if err := mgr.Navigate(ctx, serveFixture(t, infiniteFeedHTML)); err != nil {
+		t.Fatalf("Navigate failed: %v", err)
+	}
+
+	count, err := mgr.ScrollUntilLoaded(ctx, InfiniteScrollOptions{ItemSelector: ".item", MinItems: 25})
+	if err != nil {
+		t.Fatalf("ScrollUntilLoaded failed: %v", err)
+	}
+	if count < 25 || count >= 50 {
+		t.Fatalf("expected to stop once 25 items loaded, got %d", count)
+	}
+
+	count, err = mgr.ScrollUntilLoaded(ctx, InfiniteScrollOptions{ItemSelector: ".item"})
+	if err != nil {
+		t.Fatalf("ScrollUntilLoaded failed: %v", err)
+	}
+	if count != 50 {
+		t.Fatalf("expected the whole feed to load, got %d", count)
+	}
+}
+
+func TestScrollIntoView(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	url := serveFixture(t, `<html><body><div style="height:5000px"></div><button id="late">Late</button></body></html>`)
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("Navigate failed: %v", err)
+	}
+	if err := mgr.ScrollIntoView(ctx, "#late"); err != nil {
+		t.Fatalf("ScrollIntoView failed: %v", err)
+	}
+	y, err := mgr.page.Evaluate(`() => window.scrollY`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if y == 0 {
+		t.Fatal("expected the page to scroll down to the button")
+	}
+}
@@ -0,0 +1,172 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// defaultSearchResults is how many results Search returns when limit is not positive.
+const defaultSearchResults = 10
+
+// SearchEngine describes how to query a search engine and read its result
+// page. Results are read from the HTML a person would see, so no API key is
+// needed; the selectors have to follow the engine's markup.
+type SearchEngine struct {
+	Name string
+	// URL is the result page address with %s where the escaped query goes.
+	URL             string
+	ResultSelector  string // one element per organic result
+	TitleSelector   string // within a result
+	LinkSelector    string // within a result; the title's enclosing link if empty
+	SnippetSelector string // within a result
+}
+
+// SearchEngines are the built-in engines, by name.
+var SearchEngines = map[string]SearchEngine{
+	"duckduckgo": {
+		Name:            "duckduckgo",
+		URL:             "https://html.duckduckgo.com/html/?q=%s",
+		ResultSelector:  ".result:not(.result--ad)",
+		TitleSelector:   ".result__a",
+		SnippetSelector: ".result__snippet",
+	},
+	"google": {
+		Name:            "google",
+		URL:             "https://www.google.com/search?q=%s",
+		ResultSelector:  "#search div.g",
+		TitleSelector:   "h3",
+		SnippetSelector: "[data-sncf], .VwiC3b",
+	},
+	"yandex": {
+		Name:            "yandex",
+		URL:             "https://yandex.ru/search/?text=%s",
+		ResultSelector:  "li.serp-item:not([data-fast-name=\"direct\"])",
+		TitleSelector:   "h2",
+		LinkSelector:    "a.OrganicTitle-Link, a[href^=\"http\"]",
+		SnippetSelector: ".OrganicTextContentSpan, .TextContainer",
+	},
+}
+
+// DefaultSearchEngine is used when no engine is configured.
+const DefaultSearchEngine = "duckduckgo"
+
+// LookupSearchEngine finds a built-in engine by name, ignoring case. An empty
+// name selects DefaultSearchEngine.
+func LookupSearchEngine(name string) (SearchEngine, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = DefaultSearchEngine
+	}
+	if engine, ok := SearchEngines[name]; ok {
+		return engine, nil
+	}
+	known := make([]string, 0, len(SearchEngines))
+	for n := range SearchEngines {
+		known = append(known, n)
+	}
+	sort.Strings(known)
+	return SearchEngine{}, fmt.Errorf("unknown search engine %q (known: %s)", name, strings.Join(known, ", "))
+}
+
+// SearchResult is one organic result of a search.
+type SearchResult struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Snippet string `json:"snippet,omitempty"`
+}
+
+// readSearchResultsScript reads the results of a result page with the
+// engine's selectors.
+const readSearchResultsScript = `({result, title, link, snippet}) => {
+	const norm = (s) => (s || '').replace(/\s+/g, ' ').trim();
+	return Array.from(document.querySelectorAll(result)).map((el) => {
+		const heading = el.querySelector(title);
+		const anchor = link ? el.querySelector(link) : heading && heading.closest('a');
+		const text = snippet ? el.querySelector(snippet) : null;
+		return {
+			title: norm(heading && heading.textContent),
+			url: anchor ? anchor.href : '',
+			snippet: norm(text && text.textContent),
+		};
+	}).filter((r) => r.title && r.url);
+}`
+
+// Search opens the engine's result page for query in the active tab and
+// returns up to limit results. Links through the engine's redirector are
+// replaced by their target, and repeated URLs are dropped.
+func (m *Manager) Search(ctx context.Context, engine SearchEngine, query string, limit int) ([]SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("search requires a query")
+	}
+	if limit <= 0 {
+		limit = defaultSearchResults
+	}
+	if err := m.Navigate(ctx, fmt.Sprintf(engine.URL, url.QueryEscape(query))); err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", engine.Name, err)
+	}
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := page.Evaluate(readSearchResultsScript, map[string]interface{}{
+		"result":  engine.ResultSelector,
+		"title":   engine.TitleSelector,
+		"link":    engine.LinkSelector,
+		"snippet": engine.SnippetSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s results: %w", engine.Name, err)
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s results: %w", engine.Name, err)
+	}
+	var found []SearchResult
+	if err := json.Unmarshal(data, &found); err != nil {
+		return nil, fmt.Errorf("failed to read %s results: %w", engine.Name, err)
+	}
+
+	results := make([]SearchResult, 0, limit)
+	seen := make(map[string]bool)
+	for _, result := range found {
+		result.URL = unwrapSearchRedirect(result.URL)
+		if seen[result.URL] {
+			continue
+		}
+		seen[result.URL] = true
+		result.Snippet = capText(result.Snippet, m.maxElementText)
+		results = append(results, result)
+		if len(results) == limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+// unwrapSearchRedirect returns the target of a search engine's click-tracking
+// link, such as DuckDuckGo's /l/?uddg=... or Google's /url?q=..., and any
+// other link unchanged.
+func unwrapSearchRedirect(link string) string {
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	query := parsed.Query()
+	if target := query.Get("uddg"); target != "" {
+		return target
+	}
+	if parsed.Path == "/url" {
+		if target := query.Get("q"); strings.HasPrefix(target, "http") {
+			return target
+		}
+		if target := query.Get("url"); strings.HasPrefix(target, "http") {
+			return target
+		}
+	}
+	return link
+}
@@ -0,0 +1,69 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLookupSearchEngine(t *testing.T) {
+	engine, err := LookupSearchEngine("")
+	if err != nil || engine.Name != DefaultSearchEngine {
+		t.Fatalf("default engine = %+v, %v", engine, err)
+	}
+	if engine, err := LookupSearchEngine("Yandex"); err != nil || engine.Name != "yandex" {
+		t.Errorf("LookupSearchEngine(Yandex) = %+v, %v", engine, err)
+	}
+	if _, err := LookupSearchEngine("altavista"); err == nil {
+		t.Error("expected an error for an unknown engine")
+	}
+}
+
+func TestUnwrapSearchRedirect(t *testing.T) {
+	tests := map[string]string{
+		"https://duckduckgo.com/l/?uddg=https%3A%2F%2Fexample.com%2Fa%3Fb%3D1&rut=abc": "https://example.com/a?b=1",
+		"https://www.google.com/url?q=https://example.com/&sa=U":                       "https://example.com/",
+		"https://example.com/url?q=kettles":                                            "https://example.com/url?q=kettles",
+		"https://example.com/page":                                                     "https://example.com/page",
+	}
+	for link, want := range tests {
+		if got := unwrapSearchRedirect(link); got != want {
+			t.Errorf("unwrapSearchRedirect(%q) = %q, want %q", link, got, want)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	var gotQuery string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query().Get("q")
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<html><body>
+			<div class="result result--ad"><a class="result__a" href="https://ads.example">Buy now</a></div>
+			<div class="result"><a class="result__a" href="/l/?uddg=https%3A%2F%2Fexample.com%2Fkremlin">Kremlin</a><a class="result__snippet">Opening hours and tickets</a></div>
+			<div class="result"><a class="result__a" href="https://example.com/kremlin">Kremlin again</a></div>
+			<div class="result"><a class="result__a" href="https://example.org/moscow">Moscow guide</a></div>
+		</body></html>`)
+	}))
+	t.Cleanup(ts.Close)
+
+	engine := SearchEngines["duckduckgo"]
+	engine.URL = ts.URL + "/html/?q=%s"
+	results, err := mgr.Search(ctx, engine, "kremlin hours", 5)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if gotQuery != "kremlin hours" {
+		t.Errorf("engine got query %q", gotQuery)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results (ad and duplicate dropped), got %+v", results)
+	}
+	if results[0].URL != "https://example.com/kremlin" || results[0].Snippet != "Opening hours and tickets" {
+		t.Errorf("unexpected first result: %+v", results[0])
+	}
+}
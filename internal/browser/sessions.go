@@ -0,0 +1,103 @@
+package browser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sessionExt is the extension of the storage state files of named sessions.
+const sessionExt = ".json"
+
+// DefaultSessionsDir is where named sessions are kept: aibot/sessions in
+// the user config dir.
+func DefaultSessionsDir() string {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, "aibot", "sessions")
+}
+
+// SessionFile resolves the argument of session save and load. A name such
+// as "work" is work.json in dir; anything that looks like a file, with a
+// path separator or a .json extension, is used as it is.
+func SessionFile(dir, arg string) (string, error) {
+	arg = strings.TrimSpace(arg)
+	if strings.ContainsAny(arg, `/\`) || strings.EqualFold(filepath.Ext(arg), sessionExt) {
+		return arg, nil
+	}
+	if !validSessionName(arg) {
+		return "", fmt.Errorf("invalid session name %q: use letters, digits, '-', '_' and '.'", arg)
+	}
+	return filepath.Join(dir, arg+sessionExt), nil
+}
+
+func validSessionName(name string) bool {
+	if name == "" || name == "." || name == ".." {
+		return false
+	}
+	for _, r := range name {
+		if !(r == '-' || r == '_' || r == '.' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// SavedSession describes a named session for listing.
+type SavedSession struct {
+	Name    string
+	Path    string
+	Saved   time.Time
+	Cookies int
+	Sites   []string // cookie domains, without leading dots
+}
+
+// ListSessions returns the named sessions in dir, sorted by name. A missing
+// dir has none.
+func ListSessions(dir string) ([]SavedSession, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sessions dir: %w", err)
+	}
+	var sessions []SavedSession
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), sessionExt)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		session := SavedSession{Name: name, Path: path}
+		if info, err := entry.Info(); err == nil {
+			session.Saved = info.ModTime()
+		}
+		if state, err := LoadStorageStateFile(path); err == nil {
+			session.Cookies = len(state.Cookies)
+			session.Sites = cookieSites(state.Cookies)
+		}
+		sessions = append(sessions, session)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Name < sessions[j].Name })
+	return sessions, nil
+}
+
+func cookieSites(cookies []Cookie) []string {
+	seen := make(map[string]bool)
+	var sites []string
+	for _, c := range cookies {
+		site := strings.TrimPrefix(c.Domain, ".")
+		if site != "" && !seen[site] {
+			seen[site] = true
+			sites = append(sites, site)
+		}
+	}
+	sort.Strings(sites)
+	return sites
+}
@@ -0,0 +1,59 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSessionFile(t *testing.T) {
+	dir := filepath.Join("home", "sessions")
+	for arg, want := range map[string]string{
+		"work":            filepath.Join(dir, "work.json"),
+		"shop.example_2":  filepath.Join(dir, "shop.example_2.json"),
+		"state.json":      "state.json",
+		"backup/work":     "backup/work",
+		"/tmp/state.JSON": "/tmp/state.JSON",
+		" personal ":      filepath.Join(dir, "personal.json"),
+	} {
+		got, err := SessionFile(dir, arg)
+		if err != nil || got != want {
+			t.Errorf("SessionFile(%q) = %q, %v; want %q", arg, got, err, want)
+		}
+	}
+	for _, arg := range []string{"", "..", "my work"} {
+		if _, err := SessionFile(dir, arg); err == nil {
+			t.Errorf("SessionFile(%q) should fail", arg)
+		}
+	}
+}
+
+func TestListSessions(t *testing.T) {
+	dir := t.TempDir()
+	if sessions, err := ListSessions(filepath.Join(dir, "missing")); err != nil || len(sessions) != 0 {
+		t.Fatalf("a missing dir should have no sessions: %v %v", sessions, err)
+	}
+	files := map[string]string{
+		"work.json":     `{"cookies": [{"name": "sid", "domain": ".github.com"}, {"name": "a", "domain": "mail.yandex.ru"}, {"name": "b", "domain": "github.com"}]}`,
+		"personal.json": `{"cookies": []}`,
+		"notes.txt":     "not a session",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sessions, err := ListSessions(dir)
+	if err != nil {
+		t.Fatalf("ListSessions failed: %v", err)
+	}
+	if len(sessions) != 2 || sessions[0].Name != "personal" || sessions[1].Name != "work" {
+		t.Fatalf("unexpected sessions: %+v", sessions)
+	}
+	work := sessions[1]
+	if work.Cookies != 3 || !reflect.DeepEqual(work.Sites, []string{"github.com", "mail.yandex.ru"}) || work.Saved.IsZero() {
+		t.Errorf("unexpected work session: %+v", work)
+	}
+}
@@ -0,0 +1,60 @@
+package browser
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const shadowFixtureHTML = `<html><body>
+	<shop-cart></shop-cart>
+	<script>
+		customElements.define("shop-cart", class extends HTMLElement {
+			constructor() {
+				super();
+				const root = this.attachShadow({mode: "open"});
+				root.innerHTML = '<div><input placeholder="Promo code"><button>Checkout</button></div>';
+				root.querySelector("button").addEventListener("click", () => { document.title = "checked out"; });
+			}
+		});
+	</script>
+</body></html>`
+
+func TestShadowDOMElementsAreExtracted(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	if err := mgr.Navigate(ctx, serveFixture(t, shadowFixtureHTML)); err != nil {
+		t.Fatalf("Navigate failed: %v", err)
+	}
+
+	content, err := mgr.GetPageContent(ctx)
+	if err != nil {
+		t.Fatalf("GetPageContent failed: %v", err)
+	}
+	var checkout []ElementInfo
+	var promo string
+	for _, el := range content.Elements {
+		if el.Type == "button" && el.Text == "Checkout" {
+			checkout = append(checkout, el)
+		}
+		if el.Type == "input" && el.Text == "Promo code" {
+			promo = el.Selector
+		}
+	}
+	if len(checkout) != 1 {
+		t.Fatalf("expected the shadow button exactly once, got %+v", checkout)
+	}
+	if !strings.Contains(checkout[0].Selector, " >> ") {
+		t.Fatalf("expected a selector chained through the shadow host, got %q", checkout[0].Selector)
+	}
+
+	if err := mgr.Fill(ctx, promo, "SPRING"); err != nil {
+		t.Fatalf("Fill in shadow root failed: %v", err)
+	}
+	if err := mgr.Click(ctx, checkout[0].Selector); err != nil {
+		t.Fatalf("Click in shadow root failed: %v", err)
+	}
+	if title, _ := mgr.page.Title(); title != "checked out" {
+		t.Fatalf("click did not reach the shadow button, title %q", title)
+	}
+}
@@ -0,0 +1,78 @@
+package browser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// stealthArgs hide the automation switches Chromium exposes to pages.
+var stealthArgs = []string{"--disable-blink-features=AutomationControlled"}
+
+// stealthScript runs before any page script and hides the most common
+// automation tells: navigator.webdriver, a headless user agent, an empty
+// plugin list and missing window.chrome.
+const stealthScript = `(() => {
+	const define = (proto, name, value) => Object.defineProperty(proto, name, {get: () => value, configurable: true});
+
+	define(Navigator.prototype, 'webdriver', false);
+
+	const ua = navigator.userAgent.replace('HeadlessChrome', 'Chrome');
+	define(Navigator.prototype, 'userAgent', ua);
+	define(Navigator.prototype, 'appVersion', ua.replace(/^Mozilla\//, ''));
+
+	if (!navigator.languages || navigator.languages.length === 0) {
+		define(Navigator.prototype, 'languages', [navigator.language || 'en-US', 'en']);
+	}
+
+	if (navigator.plugins.length === 0 && typeof PluginArray !== 'undefined') {
+		const plugins = ['PDF Viewer', 'Chrome PDF Viewer', 'Chromium PDF Viewer', 'Microsoft Edge PDF Viewer', 'WebKit built-in PDF']
+			.map((name) => ({name, filename: 'internal-pdf-viewer', description: 'Portable Document Format', length: 1}));
+		const list = Object.create(PluginArray.prototype);
+		plugins.forEach((plugin, i) => { list[i] = plugin; });
+		define(list, 'length', plugins.length);
+		list.item = (i) => plugins[i] || null;
+		list.namedItem = (name) => plugins.find((p) => p.name === name) || null;
+		define(Navigator.prototype, 'plugins', list);
+	}
+
+	if (navigator.userAgent.includes('Chrome') && !window.chrome) {
+		window.chrome = {runtime: {}, app: {isInstalled: false}};
+	}
+
+	// Headless reports "denied" for notifications while Notification.permission
+	// says "default"; real browsers agree.
+	if (navigator.permissions && window.Notification) {
+		const query = navigator.permissions.query.bind(navigator.permissions);
+		navigator.permissions.query = (params) => params && params.name === 'notifications'
+			? Promise.resolve({state: Notification.permission === 'default' ? 'prompt' : Notification.permission})
+			: query(params);
+	}
+})();`
+
+// applyStealth installs stealthScript in every page of the context and sends
+// the same non-headless user agent in HTTP requests.
+func applyStealth(browserCtx playwright.BrowserContext, launch LaunchOptions) error {
+	if err := browserCtx.AddInitScript(playwright.Script{Content: playwright.String(stealthScript)}); err != nil {
+		return fmt.Errorf("failed to install stealth script: %w", err)
+	}
+	if launch.UserAgent != "" || launch.Device != "" {
+		return nil
+	}
+	pages := browserCtx.Pages()
+	if len(pages) == 0 {
+		return nil
+	}
+	ua, err := pages[0].Evaluate("navigator.userAgent")
+	if err != nil {
+		return fmt.Errorf("failed to read user agent: %w", err)
+	}
+	if s, ok := ua.(string); ok && strings.Contains(s, "HeadlessChrome") {
+		header := map[string]string{"User-Agent": strings.Replace(s, "HeadlessChrome", "Chrome", 1)}
+		if err := browserCtx.SetExtraHTTPHeaders(header); err != nil {
+			return fmt.Errorf("failed to set user agent: %w", err)
+		}
+	}
+	return nil
+}
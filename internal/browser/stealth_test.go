@@ -0,0 +1,35 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestStealthHidesAutomation(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	if err := applyStealth(mgr.context, LaunchOptions{Stealth: true}); err != nil {
+		t.Fatalf("applyStealth failed: %v", err)
+	}
+	url := serveFixture(t, `<html><body>Fixture</body></html>`)
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+
+	result, err := mgr.Evaluate(ctx, `({webdriver: navigator.webdriver, plugins: navigator.plugins.length, ua: navigator.userAgent})`)
+	if err != nil {
+		t.Fatalf("evaluate failed: %v", err)
+	}
+	fingerprint := result.(map[string]interface{})
+	if fingerprint["webdriver"] != false {
+		t.Errorf("navigator.webdriver = %v, want false", fingerprint["webdriver"])
+	}
+	if n := fmt.Sprint(fingerprint["plugins"]); n == "0" {
+		t.Errorf("expected plugins to be reported, got %v", fingerprint["plugins"])
+	}
+	if ua, _ := fingerprint["ua"].(string); ua == "" || strings.Contains(ua, "HeadlessChrome") {
+		t.Errorf("unexpected user agent %q", ua)
+	}
+}
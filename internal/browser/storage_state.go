@@ -0,0 +1,135 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// StorageState is the file format written by SaveStorageState (and by
+// Playwright's own storageState): cookies plus localStorage per origin.
+type StorageState struct {
+	Cookies []Cookie        `json:"cookies"`
+	Origins []OriginStorage `json:"origins"`
+}
+
+// OriginStorage holds the localStorage entries of one origin.
+type OriginStorage struct {
+	Origin       string        `json:"origin"`
+	LocalStorage []StorageItem `json:"localStorage"`
+}
+
+// StorageItem is a single localStorage key/value pair.
+type StorageItem struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// LoadStorageStateFile reads a storage state file.
+func LoadStorageStateFile(path string) (StorageState, error) {
+	var state StorageState
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state, fmt.Errorf("failed to read storage state file: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("failed to parse storage state file: %w", err)
+	}
+	return state, nil
+}
+
+// LoadStorageState restores a session saved with SaveStorageState into the
+// running context, so a fresh or ephemeral browser starts logged in.
+func (m *Manager) LoadStorageState(ctx context.Context, path string) error {
+	state, err := LoadStorageStateFile(path)
+	if err != nil {
+		return err
+	}
+	if err := m.SetCookies(ctx, state.Cookies); err != nil {
+		return err
+	}
+	return m.seedLocalStorage(ctx, state.Origins)
+}
+
+// seedLocalStorage writes localStorage entries origin by origin. The active
+// page visits each origin with every request answered by an empty document,
+// so no site code runs, and then returns to where it was.
+func (m *Manager) seedLocalStorage(ctx context.Context, origins []OriginStorage) error {
+	var pending []OriginStorage
+	for _, o := range origins {
+		if o.Origin != "" && len(o.LocalStorage) > 0 {
+			pending = append(pending, o)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return err
+	}
+	returnURL := page.URL()
+
+	blank := func(route playwright.Route) {
+		_ = route.Fulfill(playwright.RouteFulfillOptions{
+			Status:      playwright.Int(200),
+			ContentType: playwright.String("text/html"),
+			Body:        "<html></html>",
+		})
+	}
+	if err := page.Route("**/*", blank); err != nil {
+		return fmt.Errorf("failed to intercept requests: %w", err)
+	}
+
+	var seedErr error
+	for _, o := range pending {
+		if _, err := page.Goto(o.Origin); err != nil {
+			seedErr = fmt.Errorf("failed to open %s: %w", o.Origin, err)
+			break
+		}
+		if _, err := page.Evaluate(`items => { for (const { name, value } of items) localStorage.setItem(name, value); }`, o.LocalStorage); err != nil {
+			seedErr = fmt.Errorf("failed to restore localStorage for %s: %w", o.Origin, err)
+			break
+		}
+	}
+
+	if err := page.Unroute("**/*", blank); err != nil {
+		log.Printf("Warning: failed to remove request interception: %v\n", err)
+	}
+	if returnURL != "" && returnURL != "about:blank" {
+		if _, err := page.Goto(returnURL); err != nil {
+			log.Printf("Warning: failed to return to %s: %v\n", returnURL, err)
+		}
+	} else if _, err := page.Goto("about:blank"); err != nil {
+		log.Printf("Warning: failed to reset page: %v\n", err)
+	}
+	return seedErr
+}
+
+// ephemeralFromEnv reports whether BROWSER_EPHEMERAL asks for a throwaway
+// profile that is deleted when the manager closes.
+func ephemeralFromEnv() bool {
+	ephemeral, _ := strconv.ParseBool(strings.TrimSpace(os.Getenv("BROWSER_EPHEMERAL")))
+	return ephemeral
+}
+
+// loadStorageStateFromEnv restores BROWSER_STORAGE_STATE, if set, into a
+// freshly started manager.
+func (m *Manager) loadStorageStateFromEnv(ctx context.Context) error {
+	path := strings.TrimSpace(os.Getenv("BROWSER_STORAGE_STATE"))
+	if path == "" {
+		return nil
+	}
+	if err := m.LoadStorageState(ctx, path); err != nil {
+		return fmt.Errorf("failed to load BROWSER_STORAGE_STATE: %w", err)
+	}
+	log.Printf("Loaded storage state from %s\n", path)
+	return nil
+}
@@ -0,0 +1,69 @@
+package browser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadStorageStateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	data := `{
+		"cookies": [{"name": "sid", "value": "abc", "domain": "example.com", "path": "/", "expires": -1, "httpOnly": true, "secure": false, "sameSite": "Lax"}],
+		"origins": [{"origin": "https://example.com", "localStorage": [{"name": "token", "value": "xyz"}]}]
+	}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := LoadStorageStateFile(path)
+	if err != nil {
+		t.Fatalf("LoadStorageStateFile failed: %v", err)
+	}
+	if len(state.Cookies) != 1 || state.Cookies[0].Name != "sid" || state.Cookies[0].SameSite != "Lax" {
+		t.Fatalf("cookies not parsed: %+v", state.Cookies)
+	}
+	if len(state.Origins) != 1 || state.Origins[0].Origin != "https://example.com" || state.Origins[0].LocalStorage[0].Value != "xyz" {
+		t.Fatalf("origins not parsed: %+v", state.Origins)
+	}
+}
+
+func TestStorageStateRoundTripIntoEphemeralBrowser(t *testing.T) {
+	url := serveFixture(t, `<html><body>app</body></html>`)
+	path := filepath.Join(t.TempDir(), "state.json")
+	ctx := context.Background()
+
+	source := newFixtureManager(t)
+	if err := source.Navigate(ctx, url); err != nil {
+		t.Fatalf("Navigate failed: %v", err)
+	}
+	if _, err := source.page.Evaluate(`() => localStorage.setItem("token", "xyz")`); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.SaveStorageState(path); err != nil {
+		t.Fatalf("SaveStorageState failed: %v", err)
+	}
+	_ = source.Close(ctx)
+
+	t.Setenv("BROWSER_EPHEMERAL", "true")
+	t.Setenv("BROWSER_STORAGE_STATE", path)
+	mgr := newFixtureManager(t)
+	tempDir := mgr.userDataDir
+
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("Navigate failed: %v", err)
+	}
+	token, err := mgr.page.Evaluate(`() => localStorage.getItem("token")`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "xyz" {
+		t.Fatalf("expected restored localStorage token, got %v", token)
+	}
+
+	_ = mgr.Close(ctx)
+	if _, err := os.Stat(tempDir); !os.IsNotExist(err) {
+		t.Fatalf("expected ephemeral profile %s to be removed", tempDir)
+	}
+}
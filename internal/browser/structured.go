@@ -0,0 +1,184 @@
+package browser
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Limits for ExtractStructured, so a huge listing page cannot flood the
+// model's context or the task result.
+const (
+	maxStructuredTables = 20
+	maxStructuredLists  = 30
+	maxStructuredRows   = 500
+)
+
+// Table is an HTML table. Cells spanning several columns are repeated in each
+// of them, so every row lines up with Headers.
+type Table struct {
+	Caption string     `json:"caption,omitempty"`
+	Headers []string   `json:"headers,omitempty"`
+	Rows    [][]string `json:"rows"`
+}
+
+// List is a <ul> or <ol> list. An item's text leaves out its nested lists,
+// which are returned as lists of their own.
+type List struct {
+	Ordered bool     `json:"ordered,omitempty"`
+	Items   []string `json:"items"`
+}
+
+// StructuredData is what ExtractStructured found on a page.
+type StructuredData struct {
+	URL    string                   `json:"url"`
+	Tables []Table                  `json:"tables,omitempty"`
+	Lists  []List                   `json:"lists,omitempty"`
+	JSONLD []map[string]interface{} `json:"json_ld,omitempty"` // schema.org objects; @graph entries are flattened
+}
+
+// StructuredKinds select what ExtractStructured collects.
+const (
+	StructuredTables = "tables"
+	StructuredLists  = "lists"
+	StructuredJSONLD = "json_ld"
+)
+
+// extractStructuredScript reads tables, lists and JSON-LD below root. Lists
+// inside navigation, headers and footers are menus, not data, and are skipped
+// unless the caller scoped the extraction to them.
+const extractStructuredScript = `(root, {kinds, scoped, maxTables, maxLists, maxRows}) => {
+	const norm = (s) => (s || '').replace(/\s+/g, ' ').trim();
+	const within = (selector) => (root.matches(selector) ? [root] : []).concat(Array.from(root.querySelectorAll(selector)));
+	const out = {tables: [], lists: [], jsonLd: []};
+
+	if (kinds.includes('tables')) {
+		for (const table of within('table').slice(0, maxTables)) {
+			const rows = Array.from(table.rows);
+			const cells = (row) => Array.from(row.cells).flatMap((cell) => Array(Math.max(1, cell.colSpan)).fill(norm(cell.innerText)));
+			let headers = [];
+			let body = rows;
+			const head = table.tHead && table.tHead.rows[0];
+			if (head) {
+				headers = cells(head);
+				body = rows.filter((row) => row.parentElement !== table.tHead);
+			} else if (rows.length && Array.from(rows[0].cells).every((cell) => cell.tagName === 'TH')) {
+				headers = cells(rows[0]);
+				body = rows.slice(1);
+			}
+			const data = body.map(cells).filter((row) => row.some((cell) => cell)).slice(0, maxRows);
+			if (data.length === 0) continue;
+			out.tables.push({caption: norm(table.caption && table.caption.innerText), headers, rows: data});
+		}
+	}
+
+	if (kinds.includes('lists')) {
+		for (const list of within('ul, ol')) {
+			if (out.lists.length >= maxLists) break;
+			if (!scoped && list.closest('nav, header, footer, [role="navigation"], [role="menu"]')) continue;
+			const items = Array.from(list.children).filter((el) => el.tagName === 'LI').map((li) => {
+				const copy = li.cloneNode(true);
+				copy.querySelectorAll('ul, ol').forEach((nested) => nested.remove());
+				return norm(copy.innerText || copy.textContent);
+			}).filter((text) => text).slice(0, maxRows);
+			if (items.length < 2) continue;
+			out.lists.push({ordered: list.tagName === 'OL', items});
+		}
+	}
+
+	if (kinds.includes('json_ld')) {
+		for (const script of document.querySelectorAll('script[type="application/ld+json"]')) {
+			let data;
+			try { data = JSON.parse(script.textContent); } catch (e) { continue; }
+			for (const item of [].concat(data)) {
+				if (!item || typeof item !== 'object') continue;
+				const graph = item['@graph'];
+				if (Array.isArray(graph)) out.jsonLd.push(...graph.filter((g) => g && typeof g === 'object'));
+				else out.jsonLd.push(item);
+			}
+		}
+	}
+	return out;
+}`
+
+// ExtractStructured reads the tables, lists and schema.org JSON-LD metadata of
+// the active page. Kinds picks any of StructuredTables, StructuredLists and
+// StructuredJSONLD; none means all three. A selector limits tables and lists
+// to that element; JSON-LD always covers the whole document.
+func (m *Manager) ExtractStructured(ctx context.Context, selector string, kinds ...string) (StructuredData, error) {
+	if len(kinds) == 0 {
+		kinds = []string{StructuredTables, StructuredLists, StructuredJSONLD}
+	}
+	scope := selector
+	if scope == "" {
+		scope = "html"
+	}
+	frame, inner, err := m.resolveFrame(ctx, scope)
+	if err != nil {
+		return StructuredData{}, err
+	}
+	result, err := frame.Locator(inner).First().Evaluate(extractStructuredScript, map[string]interface{}{
+		"kinds":     kinds,
+		"scoped":    selector != "",
+		"maxTables": maxStructuredTables,
+		"maxLists":  maxStructuredLists,
+		"maxRows":   maxStructuredRows,
+	})
+	if err != nil {
+		return StructuredData{}, fmt.Errorf("failed to extract structured data: %w", err)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return StructuredData{}, fmt.Errorf("failed to read structured data: %w", err)
+	}
+	var found struct {
+		Tables []Table                  `json:"tables"`
+		Lists  []List                   `json:"lists"`
+		JSONLD []map[string]interface{} `json:"jsonLd"`
+	}
+	if err := json.Unmarshal(data, &found); err != nil {
+		return StructuredData{}, fmt.Errorf("failed to read structured data: %w", err)
+	}
+	return StructuredData{URL: frame.URL(), Tables: found.Tables, Lists: found.Lists, JSONLD: found.JSONLD}, nil
+}
+
+// Empty reports whether nothing was found.
+func (d StructuredData) Empty() bool {
+	return len(d.Tables) == 0 && len(d.Lists) == 0 && len(d.JSONLD) == 0
+}
+
+// WriteCSV writes the tables and lists as CSV, one block per table or list
+// separated by an empty record. A table's header row comes first; a list is a
+// single "item" column. JSON-LD is nested and has no CSV form, so it is left out.
+func (d StructuredData) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	first := true
+	block := func(records [][]string) {
+		if !first {
+			_ = cw.Write([]string{})
+		}
+		first = false
+		_ = cw.WriteAll(records)
+	}
+	for _, table := range d.Tables {
+		var records [][]string
+		if len(table.Headers) > 0 {
+			records = append(records, table.Headers)
+		}
+		block(append(records, table.Rows...))
+	}
+	for _, list := range d.Lists {
+		records := [][]string{{"item"}}
+		for _, item := range list.Items {
+			records = append(records, []string{item})
+		}
+		block(records)
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package browser
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestExtractStructured(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+	url := serveFixture(t, `<html><head>
+		<script type="application/ld+json">{"@context": "https://schema.org", "@graph": [{"@type": "Product", "name": "Kettle"}, {"@type": "Offer", "price": "19.99"}]}</script>
+		<script type="application/ld+json">not json</script>
+	</head><body>
+		<nav><ul><li>Home</li><li>Shop</li></ul></nav>
+		<table id="prices">
+			<caption>Prices</caption>
+			<thead><tr><th>Item</th><th>Price</th></tr></thead>
+			<tbody><tr><td>Kettle</td><td>19.99</td></tr><tr><td colspan="2">Sold out</td></tr></tbody>
+		</table>
+		<ol><li>Boil water <ul><li>nested</li><li>also nested</li></ul></li><li>Pour</li></ol>
+	</body></html>`)
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+
+	data, err := mgr.ExtractStructured(ctx, "")
+	if err != nil {
+		t.Fatalf("ExtractStructured failed: %v", err)
+	}
+	if len(data.Tables) != 1 {
+		t.Fatalf("expected 1 table, got %+v", data.Tables)
+	}
+	table := data.Tables[0]
+	if table.Caption != "Prices" || len(table.Headers) != 2 || table.Headers[1] != "Price" {
+		t.Errorf("unexpected table header: %+v", table)
+	}
+	if len(table.Rows) != 2 || table.Rows[0][1] != "19.99" || table.Rows[1][1] != "Sold out" {
+		t.Errorf("unexpected table rows: %+v", table.Rows)
+	}
+	// The nav menu is skipped; the nested list is its own list.
+	if len(data.Lists) != 2 || !data.Lists[0].Ordered || data.Lists[0].Items[0] != "Boil water" {
+		t.Errorf("unexpected lists: %+v", data.Lists)
+	}
+	if len(data.JSONLD) != 2 || data.JSONLD[0]["name"] != "Kettle" {
+		t.Errorf("unexpected JSON-LD: %+v", data.JSONLD)
+	}
+
+	tablesOnly, err := mgr.ExtractStructured(ctx, "#prices", StructuredTables)
+	if err != nil {
+		t.Fatalf("ExtractStructured failed: %v", err)
+	}
+	if len(tablesOnly.Tables) != 1 || len(tablesOnly.Lists) != 0 || len(tablesOnly.JSONLD) != 0 {
+		t.Errorf("expected only the table, got %+v", tablesOnly)
+	}
+}
+
+func TestStructuredDataWriteCSV(t *testing.T) {
+	data := StructuredData{
+		Tables: []Table{{Headers: []string{"Item", "Price"}, Rows: [][]string{{"Kettle", "19.99"}, {"Mug, large", "5"}}}},
+		Lists:  []List{{Items: []string{"one", "two"}}},
+		JSONLD: []map[string]interface{}{{"@type": "Product"}},
+	}
+	var buf bytes.Buffer
+	if err := data.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	want := "Item,Price\nKettle,19.99\n\"Mug, large\",5\n\nitem\none\ntwo\n"
+	if buf.String() != want {
+		t.Errorf("WriteCSV =\n%q\nwant\n%q", buf.String(), want)
+	}
+}
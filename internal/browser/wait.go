@@ -0,0 +1,269 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// WaitStrategy controls how WaitForNavigation decides that a page has settled.
+type WaitStrategy string
+
+const (
+	// WaitLoad waits for the load event only (classic multi-page sites).
+	WaitLoad WaitStrategy = "load"
+	// WaitSPA additionally waits for client-side route changes made through the
+	// History API, which never fire a load event, then for the network to go idle.
+	WaitSPA WaitStrategy = "spa"
+	// WaitNetworkIdle waits until there are no network connections for 500ms.
+	WaitNetworkIdle WaitStrategy = "networkidle"
+	// WaitDOMContentLoaded waits for the HTML to be parsed, without images
+	// and other subresources.
+	WaitDOMContentLoaded WaitStrategy = "domcontentloaded"
+)
+
+// ParseWaitStrategy maps a strategy name such as "networkidle" onto a
+// WaitStrategy, ignoring case.
+func ParseWaitStrategy(name string) (WaitStrategy, bool) {
+	strategy := WaitStrategy(strings.ToLower(strings.TrimSpace(name)))
+	switch strategy {
+	case WaitLoad, WaitSPA, WaitNetworkIdle, WaitDOMContentLoaded:
+		return strategy, true
+	}
+	return "", false
+}
+
+// softNavTimeout bounds how long a SPA wait watches for a URL change.
+const softNavTimeout = 3 * time.Second
+
+// SetWaitStrategy configures the wait strategy for a domain and its subdomains.
+// An empty domain sets the default strategy.
+func (m *Manager) SetWaitStrategy(domain string, strategy WaitStrategy) {
+	if m.waitStrategies == nil {
+		m.waitStrategies = make(map[string]WaitStrategy)
+	}
+	m.waitStrategies[strings.ToLower(strings.TrimSpace(domain))] = strategy
+}
+
+// waitStrategyFor returns the most specific strategy configured for the URL's host.
+func (m *Manager) waitStrategyFor(rawURL string) WaitStrategy {
+	if strategy, ok := lookupDomain(m.waitStrategies, rawURL); ok {
+		return strategy
+	}
+	return WaitLoad
+}
+
+// lookupDomain finds the entry for the URL's host, walking up to parent domains
+// and finally to the "" default entry.
+func lookupDomain[T any](entries map[string]T, rawURL string) (T, bool) {
+	var zero T
+	if len(entries) == 0 {
+		return zero, false
+	}
+	host := ""
+	if parsed, err := url.Parse(rawURL); err == nil {
+		host = strings.ToLower(parsed.Hostname())
+	}
+	for host != "" {
+		if value, ok := entries[host]; ok {
+			return value, true
+		}
+		idx := strings.Index(host, ".")
+		if idx == -1 {
+			break
+		}
+		host = host[idx+1:]
+	}
+	value, ok := entries[""]
+	return value, ok
+}
+
+// loadWaitStrategiesFromEnv parses BROWSER_WAIT_STRATEGIES ("app.example.com=spa,example.org=networkidle").
+func loadWaitStrategiesFromEnv() map[string]WaitStrategy {
+	strategies := make(map[string]WaitStrategy)
+	for _, entry := range strings.Split(os.Getenv("BROWSER_WAIT_STRATEGIES"), ",") {
+		domain, strategy, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		strategies[strings.ToLower(strings.TrimSpace(domain))] = WaitStrategy(strings.ToLower(strings.TrimSpace(strategy)))
+	}
+	return strategies
+}
+
+// SetNavigationTimeout bounds every WaitForNavigation. Zero restores
+// Playwright's default of 30 seconds.
+func (m *Manager) SetNavigationTimeout(timeout time.Duration) {
+	m.navTimeout = timeout
+}
+
+// WaitForLoadState waits for the active page to reach the given state, for at
+// most timeout (zero uses the navigation timeout). Unlike WaitForNavigation it
+// ignores the per-domain strategy, e.g. to wait for network idle once on a
+// page that usually settles at "load".
+func (m *Manager) WaitForLoadState(ctx context.Context, state WaitStrategy, timeout time.Duration) error {
+	if _, ok := ParseWaitStrategy(string(state)); !ok {
+		return fmt.Errorf("unknown load state %q", state)
+	}
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return err
+	}
+	if timeout <= 0 {
+		timeout = m.navTimeout
+	}
+	if err := m.waitForState(page, state, timeout); err != nil {
+		return fmt.Errorf("page did not reach %s: %w", state, err)
+	}
+	m.lastSettledURL = page.URL()
+	return nil
+}
+
+// waitForState waits for page to settle according to strategy; unknown
+// strategies wait for load. A zero timeout leaves Playwright's default in place.
+func (m *Manager) waitForState(page playwright.Page, strategy WaitStrategy, timeout time.Duration) error {
+	opts := playwright.PageWaitForLoadStateOptions{}
+	if timeout > 0 {
+		opts.Timeout = playwright.Float(float64(timeout.Milliseconds()))
+	}
+	switch strategy {
+	case WaitSPA:
+		return m.waitForSoftNavigation(page, opts.Timeout)
+	case WaitNetworkIdle:
+		opts.State = playwright.LoadStateNetworkidle
+	case WaitDOMContentLoaded:
+		opts.State = playwright.LoadStateDomcontentloaded
+	default:
+		opts.State = playwright.LoadStateLoad
+	}
+	return page.WaitForLoadState(opts)
+}
+
+// waitForSoftNavigation waits for either a real navigation or a History API
+// route change away from the last settled URL, then for network idle.
+func (m *Manager) waitForSoftNavigation(page playwright.Page, loadTimeout *float64) error {
+	if err := page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{Timeout: loadTimeout}); err != nil {
+		return err
+	}
+
+	if page.URL() == m.lastSettledURL {
+		_, err := page.WaitForFunction(`(from) => window.location.href !== from`, m.lastSettledURL, playwright.PageWaitForFunctionOptions{
+			Polling: 100,
+			Timeout: playwright.Float(float64(softNavTimeout.Milliseconds())),
+		})
+		if err != nil && !errors.Is(err, playwright.TimeoutError) {
+			return err
+		}
+	}
+
+	// Soft navigations fetch their data after the URL changes; give them a chance to land.
+	err := page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
+		State:   playwright.LoadStateNetworkidle,
+		Timeout: playwright.Float(float64(softNavTimeout.Milliseconds())),
+	})
+	if err != nil && !errors.Is(err, playwright.TimeoutError) {
+		return err
+	}
+	return nil
+}
+
+// defaultReadyTimeout is used when a ReadinessCheck does not set its own timeout.
+const defaultReadyTimeout = 10 * time.Second
+
+// ReadinessCheck describes when a freshly loaded page is ready to be read.
+// Any combination of checks may be set; they are applied in order.
+type ReadinessCheck struct {
+	NetworkIdle bool
+	Selector    string // wait until an element matching the selector is visible
+	Script      string // wait until the JS expression is truthy
+	Timeout     time.Duration
+}
+
+// SetReadinessCheck configures the readiness wait for a domain and its subdomains.
+// An empty domain sets the default check.
+func (m *Manager) SetReadinessCheck(domain string, check ReadinessCheck) {
+	if m.readinessChecks == nil {
+		m.readinessChecks = make(map[string]ReadinessCheck)
+	}
+	m.readinessChecks[strings.ToLower(strings.TrimSpace(domain))] = check
+}
+
+// WaitForReady applies the readiness check configured for the current page's
+// domain. It is a no-op when no check is configured.
+func (m *Manager) WaitForReady(ctx context.Context) error {
+	page, err := m.activePage(ctx)
+	if err != nil {
+		return err
+	}
+	check, ok := lookupDomain(m.readinessChecks, page.URL())
+	if !ok {
+		return nil
+	}
+
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = defaultReadyTimeout
+	}
+	timeoutMs := playwright.Float(float64(timeout.Milliseconds()))
+
+	if check.NetworkIdle {
+		if err := page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{State: playwright.LoadStateNetworkidle, Timeout: timeoutMs}); err != nil {
+			return fmt.Errorf("page did not reach network idle: %w", err)
+		}
+	}
+	if check.Selector != "" {
+		if _, err := page.WaitForSelector(check.Selector, playwright.PageWaitForSelectorOptions{Timeout: timeoutMs}); err != nil {
+			return fmt.Errorf("ready selector %q did not appear: %w", check.Selector, err)
+		}
+	}
+	if check.Script != "" {
+		if _, err := page.WaitForFunction(check.Script, nil, playwright.PageWaitForFunctionOptions{Timeout: timeoutMs}); err != nil {
+			return fmt.Errorf("ready script did not become truthy: %w", err)
+		}
+	}
+	return nil
+}
+
+// defaultSelectorTimeout bounds WaitForSelector when no timeout is given.
+const defaultSelectorTimeout = 10 * time.Second
+
+// WaitForSelector waits until an element matching selector is visible, for at
+// most timeout (zero means defaultSelectorTimeout). Use it for content that
+// loads after the page does instead of sleeping a fixed time.
+func (m *Manager) WaitForSelector(ctx context.Context, selector string, timeout time.Duration) error {
+	frame, selector, err := m.resolveFrame(ctx, selector)
+	if err != nil {
+		return err
+	}
+	if timeout <= 0 {
+		timeout = defaultSelectorTimeout
+	}
+	_, err = frame.WaitForSelector(selector, playwright.FrameWaitForSelectorOptions{
+		State:   playwright.WaitForSelectorStateVisible,
+		Timeout: playwright.Float(float64(timeout.Milliseconds())),
+	})
+	if err != nil {
+		return fmt.Errorf("element %s did not appear within %s: %w", selector, timeout, err)
+	}
+	return nil
+}
+
+// loadReadinessChecksFromEnv parses BROWSER_READY_SELECTORS ("example.com=#results;shop.io=.product").
+// Entries are separated by ';' because selectors may contain commas.
+func loadReadinessChecksFromEnv() map[string]ReadinessCheck {
+	checks := make(map[string]ReadinessCheck)
+	for _, entry := range strings.Split(os.Getenv("BROWSER_READY_SELECTORS"), ";") {
+		domain, selector, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || strings.TrimSpace(selector) == "" {
+			continue
+		}
+		checks[strings.ToLower(strings.TrimSpace(domain))] = ReadinessCheck{Selector: strings.TrimSpace(selector)}
+	}
+	return checks
+}
@@ -0,0 +1,124 @@
+package browser
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWaitStrategyForDomain(t *testing.T) {
+	m := &Manager{}
+	m.SetWaitStrategy("example.com", WaitSPA)
+	m.SetWaitStrategy("static.example.com", WaitLoad)
+
+	tests := []struct {
+		url      string
+		expected WaitStrategy
+	}{
+		{"https://example.com/app", WaitSPA},
+		{"https://www.example.com/", WaitSPA},
+		{"https://static.example.com/", WaitLoad},
+		{"https://other.org/", WaitLoad},
+	}
+	for _, tt := range tests {
+		if got := m.waitStrategyFor(tt.url); got != tt.expected {
+			t.Errorf("waitStrategyFor(%s) = %s, want %s", tt.url, got, tt.expected)
+		}
+	}
+}
+
+func TestParseWaitStrategy(t *testing.T) {
+	for name, want := range map[string]WaitStrategy{
+		"networkidle":        WaitNetworkIdle,
+		" DOMContentLoaded ": WaitDOMContentLoaded,
+		"load":               WaitLoad,
+		"spa":                WaitSPA,
+	} {
+		if got, ok := ParseWaitStrategy(name); !ok || got != want {
+			t.Errorf("ParseWaitStrategy(%q) = %q, %v; want %q", name, got, ok, want)
+		}
+	}
+	if _, ok := ParseWaitStrategy("waiting for results"); ok {
+		t.Error("free text must not parse as a wait strategy")
+	}
+}
+
+func TestWaitForNavigationSoftNavigation(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+
+	url := serveFixture(t, `<html><body>
+		<button id="go" onclick="setTimeout(() => history.pushState({}, '', '/details'), 500)">Details</button>
+	</body></html>`)
+	mgr.SetWaitStrategy("127.0.0.1", WaitSPA)
+
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+	if err := mgr.WaitForNavigation(ctx); err != nil {
+		t.Fatalf("initial wait failed: %v", err)
+	}
+	if err := mgr.Click(ctx, "#go"); err != nil {
+		t.Fatalf("click failed: %v", err)
+	}
+	if err := mgr.WaitForNavigation(ctx); err != nil {
+		t.Fatalf("soft navigation wait failed: %v", err)
+	}
+	if !strings.HasSuffix(mgr.page.URL(), "/details") {
+		t.Fatalf("expected wait to return after pushState, URL is %s", mgr.page.URL())
+	}
+}
+
+func TestWaitForReadyDelayedContent(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+
+	url := serveFixture(t, `<html><body><div id="app">Loading...</div>
+		<script>
+			setTimeout(() => {
+				document.getElementById('app').innerHTML = '<button id="buy">Buy now</button>';
+			}, 1000);
+		</script>
+	</body></html>`)
+	mgr.SetReadinessCheck("127.0.0.1", ReadinessCheck{Selector: "#buy"})
+
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+	if err := mgr.WaitForReady(ctx); err != nil {
+		t.Fatalf("readiness wait failed: %v", err)
+	}
+	content, err := mgr.GetPageContent(ctx)
+	if err != nil {
+		t.Fatalf("GetPageContent failed: %v", err)
+	}
+	for _, elem := range content.Elements {
+		if elem.Type == "button" && strings.Contains(elem.Text, "Buy now") {
+			return
+		}
+	}
+	t.Fatalf("expected delayed button in first extraction, got %+v", content.Elements)
+}
+
+func TestWaitForSelector(t *testing.T) {
+	mgr := newFixtureManager(t)
+	ctx := context.Background()
+
+	url := serveFixture(t, `<html><body><div id="app"></div>
+		<script>
+			setTimeout(() => {
+				document.getElementById('app').innerHTML = '<p id="result">Done</p>';
+			}, 500);
+		</script>
+	</body></html>`)
+	if err := mgr.Navigate(ctx, url); err != nil {
+		t.Fatalf("navigate failed: %v", err)
+	}
+	if err := mgr.WaitForSelector(ctx, "#result", 3*time.Second); err != nil {
+		t.Fatalf("WaitForSelector failed: %v", err)
+	}
+	if err := mgr.WaitForSelector(ctx, "#never", 200*time.Millisecond); err == nil {
+		t.Fatal("expected a timeout for a missing element")
+	}
+}
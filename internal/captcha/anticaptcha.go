@@ -0,0 +1,103 @@
+package captcha
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// AntiCaptcha solves challenges with anti-captcha.com.
+type AntiCaptcha struct {
+	APIKey  string
+	BaseURL string // default https://api.anti-captcha.com
+	poller
+}
+
+// NewAntiCaptcha returns an anti-captcha solver for the account's API key.
+func NewAntiCaptcha(apiKey string) *AntiCaptcha {
+	return &AntiCaptcha{APIKey: apiKey, BaseURL: "https://api.anti-captcha.com", poller: newPoller()}
+}
+
+// antiCaptchaResponse covers the replies of createTask and getTaskResult.
+type antiCaptchaResponse struct {
+	ErrorID          int    `json:"errorId"`
+	ErrorDescription string `json:"errorDescription"`
+	TaskID           int64  `json:"taskId"`
+	Status           string `json:"status"` // "processing" or "ready"
+	Solution         struct {
+		GRecaptchaResponse string `json:"gRecaptchaResponse"`
+		Token              string `json:"token"`
+	} `json:"solution"`
+}
+
+// Solve creates a task for the challenge and waits for its result.
+func (s *AntiCaptcha) Solve(ctx context.Context, challenge Challenge) (string, error) {
+	var taskType string
+	switch challenge.Kind {
+	case RecaptchaV2:
+		taskType = "RecaptchaV2TaskProxyless"
+	case HCaptcha:
+		taskType = "HCaptchaTaskProxyless"
+	case Turnstile:
+		taskType = "TurnstileTaskProxyless"
+	default:
+		return "", fmt.Errorf("anti-captcha: unsupported CAPTCHA kind %q", challenge.Kind)
+	}
+
+	var created antiCaptchaResponse
+	err := s.call(ctx, "/createTask", map[string]interface{}{
+		"clientKey": s.APIKey,
+		"task": map[string]string{
+			"type":       taskType,
+			"websiteURL": challenge.PageURL,
+			"websiteKey": challenge.SiteKey,
+		},
+	}, &created)
+	if err != nil {
+		return "", err
+	}
+
+	return s.poll(ctx, func(ctx context.Context) (string, bool, error) {
+		var result antiCaptchaResponse
+		if err := s.call(ctx, "/getTaskResult", map[string]interface{}{"clientKey": s.APIKey, "taskId": created.TaskID}, &result); err != nil {
+			return "", false, err
+		}
+		if result.Status != "ready" {
+			return "", false, nil
+		}
+		if token := result.Solution.GRecaptchaResponse; token != "" {
+			return token, true, nil
+		}
+		return result.Solution.Token, true, nil
+	})
+}
+
+func (s *AntiCaptcha) call(ctx context.Context, path string, body interface{}, out *antiCaptchaResponse) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("anti-captcha: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(s.BaseURL, "/")+path, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("anti-captcha: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("anti-captcha request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("anti-captcha returned HTTP %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("anti-captcha returned invalid JSON: %w", err)
+	}
+	if out.ErrorID != 0 {
+		return fmt.Errorf("anti-captcha failed: %s", out.ErrorDescription)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// Package captcha solves CAPTCHA challenges through third-party solving
+// services, so unattended runs are not stuck waiting for a person.
+package captcha
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Kind is the type of CAPTCHA widget.
+type Kind string
+
+const (
+	RecaptchaV2 Kind = "recaptcha_v2"
+	HCaptcha    Kind = "hcaptcha"
+	Turnstile   Kind = "turnstile" // Cloudflare
+)
+
+// Challenge is a CAPTCHA widget found on a page.
+type Challenge struct {
+	Kind    Kind
+	SiteKey string
+	PageURL string
+}
+
+// Solver returns the response token for a challenge. The token is submitted
+// to the page in place of a person solving the widget.
+type Solver interface {
+	Solve(ctx context.Context, challenge Challenge) (string, error)
+}
+
+// Provider names accepted by NewSolver.
+const (
+	Provider2Captcha    = "2captcha"
+	ProviderAntiCaptcha = "anti-captcha"
+)
+
+// pollInterval is how often a solver asks whether its task is done, and
+// solveTimeout bounds the whole solve; services take 10-60s.
+const (
+	pollInterval = 5 * time.Second
+	solveTimeout = 3 * time.Minute
+)
+
+// NewSolver returns the solver for a provider name.
+func NewSolver(provider, apiKey string) (Solver, error) {
+	if strings.TrimSpace(apiKey) == "" {
+		return nil, fmt.Errorf("CAPTCHA solver %q needs an API key", provider)
+	}
+	switch strings.ToLower(strings.TrimSpace(provider)) {
+	case Provider2Captcha, "twocaptcha":
+		return NewTwoCaptcha(apiKey), nil
+	case ProviderAntiCaptcha, "anticaptcha":
+		return NewAntiCaptcha(apiKey), nil
+	default:
+		return nil, fmt.Errorf("unknown CAPTCHA provider %q (use %s or %s)", provider, Provider2Captcha, ProviderAntiCaptcha)
+	}
+}
+
+// poller runs the submit-then-poll flow both services share.
+type poller struct {
+	client   *http.Client
+	interval time.Duration
+}
+
+func newPoller() poller {
+	return poller{client: &http.Client{Timeout: 30 * time.Second}, interval: pollInterval}
+}
+
+// poll calls check every interval until it reports a token or an error.
+func (p poller) poll(ctx context.Context, check func(ctx context.Context) (token string, done bool, err error)) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, solveTimeout)
+	defer cancel()
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("CAPTCHA not solved in time: %w", ctx.Err())
+		case <-ticker.C:
+		}
+		token, done, err := check(ctx)
+		if err != nil {
+			return "", err
+		}
+		if done {
+			return token, nil
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewSolver(t *testing.T) {
+	if s, err := NewSolver("2captcha", "key"); err != nil {
+		t.Fatalf("2captcha: %v", err)
+	} else if _, ok := s.(*TwoCaptcha); !ok {
+		t.Errorf("2captcha: got %T", s)
+	}
+	if s, err := NewSolver("Anti-Captcha", "key"); err != nil {
+		t.Fatalf("anti-captcha: %v", err)
+	} else if _, ok := s.(*AntiCaptcha); !ok {
+		t.Errorf("anti-captcha: got %T", s)
+	}
+	if _, err := NewSolver("2captcha", ""); err == nil {
+		t.Error("expected an error without an API key")
+	}
+	if _, err := NewSolver("deathbycaptcha", "key"); err == nil {
+		t.Error("expected an error for an unknown provider")
+	}
+}
+
+func TestTwoCaptchaSolve(t *testing.T) {
+	polls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/in.php":
+			_ = r.ParseForm()
+			if r.Form.Get("method") != "hcaptcha" || r.Form.Get("sitekey") != "site-key" || r.Form.Get("pageurl") != "https://example.com/login" {
+				t.Errorf("unexpected task form: %v", r.Form)
+			}
+			_, _ = w.Write([]byte(`{"status":1,"request":"42"}`))
+		case "/res.php":
+			if r.URL.Query().Get("id") != "42" {
+				t.Errorf("polled task %q, want 42", r.URL.Query().Get("id"))
+			}
+			polls++
+			if polls < 2 {
+				_, _ = w.Write([]byte(`{"status":0,"request":"CAPCHA_NOT_READY"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"status":1,"request":"token-123"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	solver := NewTwoCaptcha("key")
+	solver.BaseURL = srv.URL
+	solver.interval = time.Millisecond
+	token, err := solver.Solve(context.Background(), Challenge{Kind: HCaptcha, SiteKey: "site-key", PageURL: "https://example.com/login"})
+	if err != nil {
+		t.Fatalf("Solve: %v", err)
+	}
+	if token != "token-123" || polls != 2 {
+		t.Errorf("got token %q after %d polls", token, polls)
+	}
+}
+
+func TestTwoCaptchaSolveError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":0,"request":"ERROR_ZERO_BALANCE"}`))
+	}))
+	defer srv.Close()
+
+	solver := NewTwoCaptcha("key")
+	solver.BaseURL = srv.URL
+	_, err := solver.Solve(context.Background(), Challenge{Kind: RecaptchaV2, SiteKey: "k", PageURL: "https://example.com"})
+	if err == nil || !strings.Contains(err.Error(), "ERROR_ZERO_BALANCE") {
+		t.Fatalf("expected the service error, got %v", err)
+	}
+}
+
+func TestAntiCaptchaSolve(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["clientKey"] != "key" {
+			t.Errorf("clientKey = %v", body["clientKey"])
+		}
+		switch r.URL.Path {
+		case "/createTask":
+			task, _ := body["task"].(map[string]interface{})
+			if task["type"] != "RecaptchaV2TaskProxyless" || task["websiteKey"] != "site-key" {
+				t.Errorf("unexpected task: %v", task)
+			}
+			_, _ = w.Write([]byte(`{"errorId":0,"taskId":7}`))
+		case "/getTaskResult":
+			if body["taskId"] != float64(7) {
+				t.Errorf("polled task %v, want 7", body["taskId"])
+			}
+			_, _ = w.Write([]byte(`{"errorId":0,"status":"ready","solution":{"gRecaptchaResponse":"token-abc"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	solver := NewAntiCaptcha("key")
+	solver.BaseURL = srv.URL
+	solver.interval = time.Millisecond
+	token, err := solver.Solve(context.Background(), Challenge{Kind: RecaptchaV2, SiteKey: "site-key", PageURL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("Solve: %v", err)
+	}
+	if token != "token-abc" {
+		t.Errorf("token = %q", token)
+	}
+}
+
+func TestAntiCaptchaSolveError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errorId":1,"errorDescription":"ERROR_KEY_DOES_NOT_EXIST"}`))
+	}))
+	defer srv.Close()
+
+	solver := NewAntiCaptcha("key")
+	solver.BaseURL = srv.URL
+	_, err := solver.Solve(context.Background(), Challenge{Kind: Turnstile, SiteKey: "k", PageURL: "https://example.com"})
+	if err == nil || !strings.Contains(err.Error(), "ERROR_KEY_DOES_NOT_EXIST") {
+		t.Fatalf("expected the service error, got %v", err)
+	}
+}
+
+func TestSolveUnsupportedKind(t *testing.T) {
+	for _, solver := range []Solver{NewTwoCaptcha("key"), NewAntiCaptcha("key")} {
+		if _, err := solver.Solve(context.Background(), Challenge{Kind: "funcaptcha"}); err == nil {
+			t.Errorf("%T: expected an error for an unsupported kind", solver)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// TwoCaptcha solves challenges with 2captcha.com.
+type TwoCaptcha struct {
+	APIKey  string
+	BaseURL string // default https://2captcha.com
+	poller
+}
+
+// NewTwoCaptcha returns a 2captcha solver for the account's API key.
+func NewTwoCaptcha(apiKey string) *TwoCaptcha {
+	return &TwoCaptcha{APIKey: apiKey, BaseURL: "https://2captcha.com", poller: newPoller()}
+}
+
+// twoCaptchaResponse is the JSON reply of in.php and res.php.
+type twoCaptchaResponse struct {
+	Status  int    `json:"status"`
+	Request string `json:"request"`
+}
+
+// Solve submits the challenge and waits for a worker to solve it.
+func (s *TwoCaptcha) Solve(ctx context.Context, challenge Challenge) (string, error) {
+	form := url.Values{"key": {s.APIKey}, "json": {"1"}, "pageurl": {challenge.PageURL}}
+	switch challenge.Kind {
+	case RecaptchaV2:
+		form.Set("method", "userrecaptcha")
+		form.Set("googlekey", challenge.SiteKey)
+	case HCaptcha:
+		form.Set("method", "hcaptcha")
+		form.Set("sitekey", challenge.SiteKey)
+	case Turnstile:
+		form.Set("method", "turnstile")
+		form.Set("sitekey", challenge.SiteKey)
+	default:
+		return "", fmt.Errorf("2captcha: unsupported CAPTCHA kind %q", challenge.Kind)
+	}
+
+	var submitted twoCaptchaResponse
+	if err := s.call(ctx, http.MethodPost, "/in.php", form, &submitted); err != nil {
+		return "", err
+	}
+	if submitted.Status != 1 {
+		return "", fmt.Errorf("2captcha rejected the task: %s", submitted.Request)
+	}
+
+	query := url.Values{"key": {s.APIKey}, "action": {"get"}, "id": {submitted.Request}, "json": {"1"}}
+	return s.poll(ctx, func(ctx context.Context) (string, bool, error) {
+		var result twoCaptchaResponse
+		if err := s.call(ctx, http.MethodGet, "/res.php", query, &result); err != nil {
+			return "", false, err
+		}
+		switch {
+		case result.Status == 1:
+			return result.Request, true, nil
+		case result.Request == "CAPCHA_NOT_READY":
+			return "", false, nil
+		default:
+			return "", false, fmt.Errorf("2captcha failed: %s", result.Request)
+		}
+	})
+}
+
+func (s *TwoCaptcha) call(ctx context.Context, method, path string, params url.Values, out interface{}) error {
+	endpoint := strings.TrimRight(s.BaseURL, "/") + path
+	var req *http.Request
+	var err error
+	if method == http.MethodPost {
+		req, err = http.NewRequestWithContext(ctx, method, endpoint, strings.NewReader(params.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	} else {
+		req, err = http.NewRequestWithContext(ctx, method, endpoint+"?"+params.Encode(), nil)
+	}
+	if err != nil {
+		return fmt.Errorf("2captcha: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("2captcha request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("2captcha returned HTTP %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("2captcha returned invalid JSON: %w", err)
+	}
+	return nil
+}
@@ -2,6 +2,8 @@ package context
 
 import (
 	"fmt"
+
+	"github.com/VolodyaPopov923/AIBot/internal/tokens"
 )
 
 // TokenCounter tracks token usage
@@ -112,13 +114,28 @@ func (cm *ContextManager) RemoveOldest(count int) {
 	cm.messages = cm.messages[count:]
 }
 
+// HistoryTokens estimates the tokens of the stored messages
+func (cm *ContextManager) HistoryTokens() int {
+	total := 0
+	for _, msg := range cm.messages {
+		total += EstimateTokens(msg.Content)
+	}
+	return total
+}
+
+// TrimToTokens removes the oldest messages until the history fits in limit tokens
+func (cm *ContextManager) TrimToTokens(limit int) {
+	for len(cm.messages) > 0 && cm.HistoryTokens() > limit {
+		cm.RemoveOldest(1)
+	}
+}
+
 // TokenCounter returns the token counter
 func (cm *ContextManager) TokenCounter() *TokenCounter {
 	return cm.tokenCounter
 }
 
-// EstimateTokens estimates tokens for a string (rough approximation)
+// EstimateTokens estimates tokens for a string (see tokens.Count)
 func EstimateTokens(text string) int {
-	// Rough estimate: ~4 characters per token
-	return (len(text) + 3) / 4
+	return tokens.Count(text)
 }
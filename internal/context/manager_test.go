@@ -45,9 +45,31 @@ func TestEstimateTokens(t *testing.T) {
 	text := "Hello, this is a test message"
 	tokens := EstimateTokens(text)
 
-	// Rough estimate: ~4 chars per token
-	expected := (len(text) + 3) / 4
-	if tokens != expected {
-		t.Errorf("Expected %d tokens, got %d", expected, tokens)
+	// An estimate, not the encoding: cl100k_base spends 7 tokens on it.
+	if tokens < 6 || tokens > 9 {
+		t.Errorf("Expected about 7 tokens, got %d", tokens)
+	}
+}
+
+func TestTrimToTokens(t *testing.T) {
+	cm := NewContextManager(8000, 20)
+	cm.AddMessage("user", "Page: Example Domain (https://example.com)")
+	cm.AddMessage("assistant", `{"action": "click", "selector": "#more"}`)
+	cm.AddMessage("system", "Click failed: element not found")
+
+	cm.TrimToTokens(cm.HistoryTokens())
+	if len(cm.GetMessages()) != 3 {
+		t.Fatalf("history within the limit should be kept, got %d messages", len(cm.GetMessages()))
+	}
+
+	cm.TrimToTokens(EstimateTokens("Click failed: element not found"))
+	messages := cm.GetMessages()
+	if len(messages) != 1 || messages[0].Role != "system" {
+		t.Fatalf("expected only the newest message to remain, got %+v", messages)
+	}
+
+	cm.TrimToTokens(-1)
+	if len(cm.GetMessages()) != 0 {
+		t.Fatalf("expected an empty history, got %+v", cm.GetMessages())
 	}
 }
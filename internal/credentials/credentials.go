@@ -0,0 +1,82 @@
+// Package credentials reads the logins the login command can fill in by
+// itself. They live in a JSON file readable only by its owner, keyed by
+// site:
+//
+//	{
+//	  "github.com": {"username": "me@example.com", "password_env": "GITHUB_PASSWORD"},
+//	  "mail.yandex.ru": {"username": "me", "password": "..."}
+//	}
+//
+// password_env names an environment variable holding the password, so the
+// file itself need not contain it.
+package credentials
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Credential is the login of one site.
+type Credential struct {
+	Username    string `json:"username"`
+	Password    string `json:"password,omitempty"`
+	PasswordEnv string `json:"password_env,omitempty"`
+}
+
+// DefaultPath is where the credentials are kept unless configured:
+// aibot/credentials.json in the user config dir.
+func DefaultPath() string {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, "aibot", "credentials.json")
+}
+
+// Lookup returns the credential stored for host or the closest of its
+// parent domains, so an entry for github.com also serves www.github.com.
+// A missing file holds no credentials.
+func Lookup(path, host string) (Credential, bool, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Credential{}, false, nil
+	}
+	if err != nil {
+		return Credential{}, false, fmt.Errorf("failed to read credentials: %w", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		return Credential{}, false, fmt.Errorf("%s is readable by other users; chmod 600 it", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Credential{}, false, fmt.Errorf("failed to read credentials: %w", err)
+	}
+	var stored map[string]Credential
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return Credential{}, false, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+	for domain := host; domain != ""; {
+		if cred, ok := stored[domain]; ok {
+			if cred.PasswordEnv != "" {
+				cred.Password = os.Getenv(cred.PasswordEnv)
+				if cred.Password == "" {
+					return Credential{}, false, fmt.Errorf("%s, the password of %s, is not set", cred.PasswordEnv, domain)
+				}
+			}
+			return cred, true, nil
+		}
+		_, parent, ok := strings.Cut(domain, ".")
+		if !ok || !strings.Contains(parent, ".") {
+			break
+		}
+		domain = parent
+	}
+	return Credential{}, false, nil
+}
@@ -0,0 +1,54 @@
+package credentials
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	if _, ok, err := Lookup(path, "github.com"); ok || err != nil {
+		t.Fatalf("a missing file should hold nothing: %v %v", ok, err)
+	}
+	data := `{
+		"github.com": {"username": "me@example.com", "password_env": "TEST_GITHUB_PASSWORD"},
+		"mail.yandex.ru": {"username": "me", "password": "hunter2"}
+	}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_GITHUB_PASSWORD", "s3cret")
+
+	for host, want := range map[string]Credential{
+		"github.com":      {Username: "me@example.com", Password: "s3cret", PasswordEnv: "TEST_GITHUB_PASSWORD"},
+		"www.github.com":  {Username: "me@example.com", Password: "s3cret", PasswordEnv: "TEST_GITHUB_PASSWORD"},
+		"gist.github.com": {Username: "me@example.com", Password: "s3cret", PasswordEnv: "TEST_GITHUB_PASSWORD"},
+		"Mail.Yandex.ru":  {Username: "me", Password: "hunter2"},
+	} {
+		got, ok, err := Lookup(path, host)
+		if err != nil || !ok || got != want {
+			t.Errorf("Lookup(%q) = %+v, %v, %v", host, got, ok, err)
+		}
+	}
+	for _, host := range []string{"yandex.ru", "example.com", "com"} {
+		if _, ok, err := Lookup(path, host); ok || err != nil {
+			t.Errorf("Lookup(%q) should find nothing: %v %v", host, ok, err)
+		}
+	}
+
+	t.Setenv("TEST_GITHUB_PASSWORD", "")
+	if _, _, err := Lookup(path, "github.com"); err == nil {
+		t.Error("an unset password_env should be an error")
+	}
+
+	if runtime.GOOS != "windows" {
+		if err := os.Chmod(path, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := Lookup(path, "mail.yandex.ru"); err == nil {
+			t.Error("a file others can read should be refused")
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Package daemon lets a long-running agent take tasks over a Unix socket, so
+// scripts do not pay for starting a browser on every call.
+//
+// The protocol is JSON lines: a client connects, sends one Request and reads
+// lines until the daemon closes the connection. For a task these are the
+// lines of "agent run -output json": its events, then a line of type
+// "result". Hanging up, or closing the writing half of the connection,
+// cancels the task.
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Commands a client can send.
+const (
+	CommandRun    = "run"
+	CommandStatus = "status"
+	CommandStop   = "stop"
+)
+
+// Request is the line a client sends after connecting.
+type Request struct {
+	Command string `json:"command"`
+	Task    string `json:"task,omitempty"`
+	URL     string `json:"url,omitempty"`
+}
+
+// Handler answers a request by writing lines to out. ctx is cancelled when
+// the client hangs up or the daemon stops.
+type Handler func(ctx context.Context, req Request, out *json.Encoder)
+
+// DefaultSocketPath is where the daemon listens unless told otherwise: in
+// $XDG_RUNTIME_DIR if set, else in the temp dir, per user.
+func DefaultSocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "aibot.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("aibot-%d.sock", os.Getuid()))
+}
+
+// Listen opens the socket at path, readable by the current user only. A
+// socket left behind by a daemon that died is replaced; one that still
+// answers is an error.
+func Listen(path string) (net.Listener, error) {
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("a daemon is already listening on %s", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+	// The socket is created private; a chmod afterwards would leave a
+	// window in which others could connect.
+	listener, err := listenPrivate(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	return listener, nil
+}
+
+// Serve answers connections on listener with handle until ctx is cancelled,
+// then closes the listener and waits for the open connections.
+func Serve(ctx context.Context, listener net.Listener, handle Handler) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			serveConn(ctx, conn, handle)
+		}()
+	}
+}
+
+func serveConn(ctx context.Context, conn net.Conn, handle Handler) {
+	defer conn.Close()
+	var req Request
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		log.Printf("Warning: invalid daemon request: %v\n", err)
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		// Clients send nothing after the request, so a read only returns
+		// once they hang up.
+		_, _ = io.Copy(io.Discard, conn)
+		cancel()
+	}()
+	handle(ctx, req, json.NewEncoder(conn))
+}
+
+// Dial connects to the daemon at path and sends req; read the answer from
+// the returned connection.
+func Dial(path string, req Request) (*net.UnixConn, error) {
+	conn, err := net.DialUnix("unix", nil, &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) || errors.Is(err, syscall.ECONNREFUSED) {
+			return nil, fmt.Errorf("no daemon is listening on %s; start one with \"agent daemon\"", path)
+		}
+		return nil, err
+	}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	return conn, nil
+}
@@ -0,0 +1,95 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func startDaemon(t *testing.T, handle Handler) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "d.sock")
+	listener, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- Serve(ctx, listener, handle) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+	})
+	return path
+}
+
+func TestDaemonAnswersRequests(t *testing.T) {
+	path := startDaemon(t, func(ctx context.Context, req Request, out *json.Encoder) {
+		_ = out.Encode(map[string]string{"type": "step_started", "message": req.Task})
+		_ = out.Encode(map[string]any{"type": "result", "exit_code": 0})
+	})
+	// Connecting takes write permission, which only the owner may have.
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm()&0o077 != 0 || info.Mode().Perm()&0o200 == 0 {
+		t.Fatalf("socket should be private: %v %v", info, err)
+	}
+	if _, err := Listen(path); err == nil {
+		t.Fatal("a second daemon should not take over a live socket")
+	}
+
+	conn, err := Dial(path, Request{Command: CommandRun, Task: "find the Kremlin"})
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+	var lines []map[string]any
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var line map[string]any
+		_ = json.Unmarshal(scanner.Bytes(), &line)
+		lines = append(lines, line)
+	}
+	if len(lines) != 2 || lines[0]["message"] != "find the Kremlin" || lines[1]["type"] != "result" {
+		t.Fatalf("unexpected answer: %v", lines)
+	}
+}
+
+func TestDaemonCancelsOnHangUp(t *testing.T) {
+	cancelled := make(chan struct{})
+	path := startDaemon(t, func(ctx context.Context, req Request, out *json.Encoder) {
+		<-ctx.Done()
+		close(cancelled)
+	})
+	conn, err := Dial(path, Request{Command: CommandRun, Task: "wait"})
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	_ = conn.CloseWrite()
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("closing the connection should cancel the request")
+	}
+	conn.Close()
+}
+
+func TestListenReplacesStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "d.sock")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	listener, err := Listen(path)
+	if err != nil {
+		t.Fatalf("a dead socket should be replaced: %v", err)
+	}
+	listener.Close()
+
+	if _, err := Dial(filepath.Join(t.TempDir(), "none.sock"), Request{Command: CommandStatus}); err == nil {
+		t.Fatal("dialing a missing daemon should fail")
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package daemon
+
+import (
+	"net"
+	"syscall"
+)
+
+// listenPrivate listens on a Unix socket that only the current user can
+// connect to from the moment it exists. The umask is process-wide, so files
+// other goroutines create meanwhile are private too, which is harmless.
+func listenPrivate(path string) (net.Listener, error) {
+	old := syscall.Umask(0o077)
+	defer syscall.Umask(old)
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package daemon
+
+import "net"
+
+// listenPrivate listens on a Unix socket. Windows has no umask; the socket
+// gets the permissions of the directory it is in.
+func listenPrivate(path string) (net.Listener, error) {
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,331 @@
+// Package dashboard draws a live view of a running task in the terminal in
+// place of the scrolling log: the plan with the status of each step, the
+// agent's reasoning, the open tabs and the tokens and cost so far. It is fed
+// by the agent's progress events.
+package dashboard
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/VolodyaPopov923/AIBot/internal/agent"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+// How many of the latest reasoning and log lines are shown.
+const (
+	reasoningLines = 6
+	logLines       = 4
+)
+
+type stepStatus int
+
+const (
+	stepPending stepStatus = iota
+	stepRunning
+	stepTrouble // an action of the step failed; the agent is recovering
+	stepDone
+	stepFailed
+)
+
+var stepMarks = map[stepStatus]string{
+	stepPending: "·",
+	stepRunning: "▶",
+	stepTrouble: "⚠",
+	stepDone:    "✓",
+	stepFailed:  "✗",
+}
+
+type step struct {
+	description string
+	status      stepStatus
+}
+
+// Dashboard renders task progress. Its methods are safe for concurrent use.
+type Dashboard struct {
+	out io.Writer
+	// Tabs, if set, lists the open tabs when a task starts or the page
+	// changes. It is called from HandleEvent, so from the task's goroutine.
+	Tabs func() []browser.TabInfo
+	// Passthrough, if set, receives what is written to the dashboard while
+	// no task is shown, so it can take log output for good rather than
+	// only while a task runs.
+	Passthrough io.Writer
+
+	mu        sync.Mutex
+	active    bool
+	suspended bool // the agent waits for the user; the prompt has the screen
+	task      string
+	status    string
+	url       string
+	started   time.Time
+	steps     []step
+	reasoning []string
+	logs      []string
+	partial   string // log output not ended by a newline yet
+	tabs      []browser.TabInfo
+	usage     agent.TokenUsage
+}
+
+// New creates a dashboard drawing on out, which should be a terminal.
+func New(out io.Writer) *Dashboard {
+	return &Dashboard{out: out}
+}
+
+// Start switches to the alternate screen and draws the dashboard for task;
+// Stop brings the previous screen back.
+func (d *Dashboard) Start(task string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.active, d.suspended = true, false
+	d.task, d.status, d.url, d.started = task, "starting", "", time.Now()
+	d.steps, d.reasoning, d.logs, d.partial, d.tabs = nil, nil, nil, "", nil
+	d.usage = agent.TokenUsage{}
+	fmt.Fprint(d.out, "\x1b[?1049h")
+	d.draw()
+}
+
+// Stop leaves the dashboard; what the terminal showed before comes back.
+func (d *Dashboard) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.active {
+		return
+	}
+	d.active = false
+	if !d.suspended {
+		fmt.Fprint(d.out, "\x1b[?1049l")
+	}
+}
+
+// suspend hands the screen back while the agent waits for an answer, so a
+// confirmation or question printed on the terminal is not painted over;
+// resume draws the dashboard again. The caller holds mu.
+func (d *Dashboard) suspend() {
+	if d.active && !d.suspended {
+		d.suspended = true
+		fmt.Fprint(d.out, "\x1b[?1049l")
+	}
+}
+
+func (d *Dashboard) resume() {
+	if d.active && d.suspended {
+		d.suspended = false
+		fmt.Fprint(d.out, "\x1b[?1049h")
+	}
+}
+
+// HandleEvent updates the dashboard with a progress event; it fits
+// agent.Agent.OnEvent.
+func (d *Dashboard) HandleEvent(event agent.Event) {
+	var tabs []browser.TabInfo
+	if d.Tabs != nil && (event.Type == agent.EventTaskStarted || event.Type == agent.EventPageChanged) {
+		tabs = d.Tabs()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if tabs != nil {
+		d.tabs = tabs
+	}
+	if event.Usage != nil {
+		d.usage = *event.Usage
+	}
+	if event.URL != "" {
+		d.url = event.URL
+	}
+	switch event.Type {
+	case agent.EventTaskStarted:
+		d.status = "running"
+	case agent.EventPlanning:
+		d.status = "planning"
+		if event.Message != "" {
+			d.addReasoning("🧭 " + event.Message)
+		}
+	case agent.EventPlanReady:
+		d.status = "running"
+		d.steps = d.steps[:0]
+		for _, description := range event.Steps {
+			d.steps = append(d.steps, step{description: description})
+		}
+	case agent.EventStepStarted:
+		for i := range d.steps {
+			if i < event.Step-1 && d.steps[i].status != stepFailed {
+				d.steps[i].status = stepDone
+			}
+		}
+		d.setStep(event.Step, stepRunning)
+	case agent.EventDecision:
+		d.addReasoning(fmt.Sprintf("🤔 %s: %s", event.Action, event.Message))
+	case agent.EventActionExecuted:
+		d.setCurrent(stepTrouble, stepRunning)
+	case agent.EventActionFailed:
+		d.setCurrent(stepRunning, stepTrouble)
+		d.addReasoning(fmt.Sprintf("❌ %s failed: %s", event.Action, event.Message))
+	case agent.EventStepVerified:
+		d.addReasoning(fmt.Sprintf("🔎 step %d: %s", event.Step, event.Message))
+	case agent.EventAssertion:
+		d.addReasoning("✅ " + event.Message)
+	case agent.EventCaptchaWait:
+		d.status = "waiting for a CAPTCHA to be solved"
+	case agent.EventPaused:
+		// The pause is announced before whoever paused the task prompts
+		// for what to do next.
+		d.status = "paused"
+		d.suspend()
+	case agent.EventResumed:
+		d.status = "running"
+		d.resume()
+	case agent.EventAwaitingInput:
+		d.suspend()
+	case agent.EventInputReceived:
+		d.resume()
+	case agent.EventTaskFinished:
+		final := stepDone
+		d.status = "finished"
+		if event.Message != "success" {
+			final = stepFailed
+			d.status = "failed: " + event.Message
+		}
+		for i := range d.steps {
+			if d.steps[i].status == stepRunning || d.steps[i].status == stepTrouble {
+				d.steps[i].status = final
+			}
+		}
+	}
+	d.draw()
+}
+
+// Write takes log output, e.g. with log.SetOutput, and shows its last lines.
+func (d *Dashboard) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.active && d.Passthrough != nil {
+		return d.Passthrough.Write(p)
+	}
+	lines := strings.Split(d.partial+string(p), "\n")
+	d.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		if line = strings.TrimSpace(line); line != "" {
+			d.logs = appendLast(d.logs, line, logLines)
+		}
+	}
+	d.draw()
+	return len(p), nil
+}
+
+func (d *Dashboard) setStep(n int, status stepStatus) {
+	if n >= 1 && n <= len(d.steps) {
+		d.steps[n-1].status = status
+	}
+}
+
+// setCurrent moves the step in status from to status to.
+func (d *Dashboard) setCurrent(from, to stepStatus) {
+	for i := range d.steps {
+		if d.steps[i].status == from {
+			d.steps[i].status = to
+			return
+		}
+	}
+}
+
+func (d *Dashboard) addReasoning(line string) {
+	d.reasoning = appendLast(d.reasoning, line, reasoningLines)
+}
+
+// appendLast appends line and keeps the last max lines.
+func appendLast(lines []string, line string, max int) []string {
+	lines = append(lines, line)
+	if len(lines) > max {
+		lines = lines[len(lines)-max:]
+	}
+	return lines
+}
+
+// draw repaints the whole screen; the caller holds mu. Line wrapping is off
+// while drawing, so long lines are cut at the edge of the terminal instead of
+// pushing the rest down. Nothing is drawn while suspended for a prompt.
+func (d *Dashboard) draw() {
+	if !d.active || d.suspended {
+		return
+	}
+	fmt.Fprint(d.out, "\x1b[H\x1b[2J\x1b[?7l"+d.render()+"\x1b[?7h")
+}
+
+// render lays the dashboard out as text.
+func (d *Dashboard) render() string {
+	var b strings.Builder
+	line := func(format string, args ...any) {
+		fmt.Fprintf(&b, format+"\r\n", args...)
+	}
+	rule := strings.Repeat("─", 60)
+
+	line("📋 %s", oneLine(d.task))
+	line("   %s · %s · %d tokens (%d prompt + %d completion) · %s",
+		d.status, time.Since(d.started).Round(time.Second), d.usage.TotalTokens,
+		d.usage.PromptTokens, d.usage.CompletionTokens, formatCost(d.usage.CostUSD))
+	if d.url != "" {
+		line("   🔗 %s", d.url)
+	}
+
+	line(rule)
+	if len(d.steps) == 0 {
+		line(" Plan: none, deciding step by step")
+	} else {
+		line(" Plan")
+		for i, s := range d.steps {
+			line("  %s %d. %s", stepMarks[s.status], i+1, oneLine(s.description))
+		}
+	}
+
+	line(rule)
+	line(" Reasoning")
+	for _, r := range d.reasoning {
+		line("  %s", oneLine(r))
+	}
+
+	if len(d.tabs) > 0 {
+		line(rule)
+		line(" Tabs")
+		for _, tab := range d.tabs {
+			marker := " "
+			if tab.Active {
+				marker = "*"
+			}
+			line("  %s %d. %s — %s", marker, tab.Index, oneLine(tab.Title), tab.URL)
+		}
+	}
+
+	if len(d.logs) > 0 {
+		line(rule)
+		line(" Log")
+		for _, l := range d.logs {
+			line("  %s", oneLine(l))
+		}
+	}
+	line(rule)
+	line(" Ctrl-C pauses the task")
+	return b.String()
+}
+
+// oneLine keeps text on a single row.
+func oneLine(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) > 300 {
+		s = string([]rune(s)[:300]) + "…"
+	}
+	return s
+}
+
+// formatCost prints a dollar cost like the rest of the CLI does.
+func formatCost(usd float64) string {
+	if usd == 0 {
+		return "no cost"
+	}
+	return fmt.Sprintf("$%.4f", usd)
+}
@@ -0,0 +1,129 @@
+package dashboard
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/VolodyaPopov923/AIBot/internal/agent"
+	"github.com/VolodyaPopov923/AIBot/internal/browser"
+)
+
+func TestDashboardFollowsTask(t *testing.T) {
+	var out bytes.Buffer
+	d := New(&out)
+	d.Tabs = func() []browser.TabInfo {
+		return []browser.TabInfo{{Index: 1, Title: "Яндекс Карты", URL: "https://yandex.ru/maps", Active: true}}
+	}
+	d.Start("найди кремль")
+
+	usage := agent.TokenUsage{PromptTokens: 1200, CompletionTokens: 80, TotalTokens: 1280, CostUSD: 0.0042}
+	for _, event := range []agent.Event{
+		{Type: agent.EventTaskStarted, URL: "https://yandex.ru/maps"},
+		{Type: agent.EventPlanReady, Steps: []string{"Type the query", "Press search", "Read the address"}},
+		{Type: agent.EventStepStarted, Step: 1},
+		{Type: agent.EventStepStarted, Step: 2},
+		{Type: agent.EventDecision, Action: "click", Message: "The search button starts the search"},
+		{Type: agent.EventActionFailed, Action: "click", Message: "element not found", Usage: &usage},
+		{Type: agent.EventPageChanged, URL: "https://yandex.ru/maps/?text=kremlin"},
+	} {
+		d.HandleEvent(event)
+	}
+	frame := d.render()
+	for _, want := range []string{
+		"📋 найди кремль",
+		"✓ 1. Type the query",
+		"⚠ 2. Press search",
+		"· 3. Read the address",
+		"🤔 click: The search button starts the search",
+		"❌ click failed: element not found",
+		"1280 tokens (1200 prompt + 80 completion) · $0.0042",
+		"* 1. Яндекс Карты — https://yandex.ru/maps",
+		"🔗 https://yandex.ru/maps/?text=kremlin",
+	} {
+		if !strings.Contains(frame, want) {
+			t.Errorf("dashboard lacks %q:\n%s", want, frame)
+		}
+	}
+
+	d.HandleEvent(agent.Event{Type: agent.EventTaskFinished, Message: "success"})
+	frame = d.render()
+	if !strings.Contains(frame, "✓ 2. Press search") || !strings.Contains(frame, "finished") {
+		t.Errorf("a finished task should complete its running step:\n%s", frame)
+	}
+
+	d.Stop()
+	if !strings.HasSuffix(out.String(), "\x1b[?1049l") {
+		t.Errorf("Stop should leave the alternate screen")
+	}
+}
+
+func TestDashboardShowsLog(t *testing.T) {
+	d := New(&bytes.Buffer{})
+	d.Start("task")
+	logger := log.New(d, "", 0)
+	for _, line := range []string{"one", "two", "three", "four", "five"} {
+		logger.Println(line)
+	}
+	_, _ = d.Write([]byte("partial"))
+
+	frame := d.render()
+	if strings.Contains(frame, "  one") || !strings.Contains(frame, "  five") {
+		t.Errorf("the log should keep the last %d lines:\n%s", logLines, frame)
+	}
+	if strings.Contains(frame, "partial") {
+		t.Errorf("an unfinished log line should wait for its newline:\n%s", frame)
+	}
+	if !strings.Contains(frame, "Plan: none") {
+		t.Errorf("a task without a plan should say so:\n%s", frame)
+	}
+}
+
+func TestDashboardStepsAsideForPrompts(t *testing.T) {
+	var out bytes.Buffer
+	d := New(&out)
+	d.Start("task")
+
+	d.HandleEvent(agent.Event{Type: agent.EventAwaitingInput, Message: "❓ Which account?"})
+	out.Reset()
+	out.WriteString("Your answer: ")
+	logger := log.New(d, "", 0)
+	logger.Println("a log line while the user types")
+	d.HandleEvent(agent.Event{Type: agent.EventDecision, Action: "click", Message: "meanwhile"})
+	if got := out.String(); got != "Your answer: " {
+		t.Fatalf("the dashboard repainted over a pending prompt: %q", got)
+	}
+
+	d.HandleEvent(agent.Event{Type: agent.EventInputReceived})
+	if !strings.Contains(out.String(), "\x1b[?1049h") || !strings.Contains(out.String(), "meanwhile") {
+		t.Errorf("the dashboard should come back with what happened meanwhile: %q", out.String())
+	}
+
+	d.HandleEvent(agent.Event{Type: agent.EventPaused})
+	out.Reset()
+	d.Stop()
+	if out.Len() != 0 {
+		t.Errorf("Stop after a pause should not leave the alternate screen twice: %q", out.String())
+	}
+}
+
+func TestDashboardPassesLogThroughWhenIdle(t *testing.T) {
+	var screen, stderr bytes.Buffer
+	d := New(&screen)
+	d.Passthrough = &stderr
+	logger := log.New(d, "", 0)
+
+	logger.Println("before the task")
+	d.Start("task")
+	logger.Println("during the task")
+	d.Stop()
+	logger.Println("after the task")
+
+	if got := stderr.String(); got != "before the task\nafter the task\n" {
+		t.Errorf("log output outside a task should pass through, got %q", got)
+	}
+	if !strings.Contains(screen.String(), "during the task") {
+		t.Errorf("log output during a task should be shown on the dashboard")
+	}
+}
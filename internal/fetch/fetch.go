@@ -0,0 +1,123 @@
+// Package fetch reads static pages over plain HTTP, for read-only steps that
+// do not need a browser. No JavaScript runs, so it is much faster than
+// driving Playwright, but pages rendered by scripts come back empty; Fetch
+// reports those with ErrNeedsBrowser.
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrNeedsBrowser is returned for pages that cannot be read without a
+// browser: script-rendered pages, bot protection and non-HTML content.
+var ErrNeedsBrowser = errors.New("page needs a browser")
+
+const (
+	defaultTimeout   = 15 * time.Second
+	defaultUserAgent = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36"
+	// maxBodyBytes bounds how much of a response is read.
+	maxBodyBytes = 5 << 20
+	// minStaticText is the least text a page with scripts must have to count
+	// as static; less usually means an app shell that renders in the browser.
+	minStaticText = 200
+)
+
+// Page is a fetched page.
+type Page struct {
+	URL    string // after redirects
+	Status int
+	Title  string
+	Text   string // visible text, one line per block
+	Links  []Link
+}
+
+// Link is a link of a fetched page.
+type Link struct {
+	Text string
+	URL  string // absolute
+}
+
+// Fetcher fetches pages over HTTP.
+type Fetcher struct {
+	Client    *http.Client
+	UserAgent string
+	// Cookies, if set, returns the cookies to send with a request, e.g. the
+	// browser's, so pages behind a login read the same as in the browser.
+	Cookies func(ctx context.Context, url string) []*http.Cookie
+}
+
+// New returns a Fetcher. A non-nil proxy routes requests through it, as the
+// browser's traffic is.
+func New(proxy *url.URL) *Fetcher {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	return &Fetcher{
+		Client:    &http.Client{Timeout: defaultTimeout, Transport: transport},
+		UserAgent: defaultUserAgent,
+	}
+}
+
+// Fetch downloads and parses the page at rawURL.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Page, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return nil, fmt.Errorf("%w: unsupported scheme %q", ErrNeedsBrowser, req.URL.Scheme)
+	}
+	req.Header.Set("User-Agent", f.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/plain;q=0.9,*/*;q=0.5")
+	if f.Cookies != nil {
+		for _, cookie := range f.Cookies(ctx, rawURL) {
+			req.AddCookie(cookie)
+		}
+	}
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		// Usually bot protection, which a browser may get through.
+		return nil, fmt.Errorf("%w: %s returned HTTP %d", ErrNeedsBrowser, rawURL, resp.StatusCode)
+	case resp.StatusCode >= 400:
+		return nil, fmt.Errorf("%s returned HTTP %d", rawURL, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", rawURL, err)
+	}
+	page := &Page{URL: resp.Request.URL.String(), Status: resp.StatusCode}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/html", "application/xhtml+xml", "":
+	case "text/plain":
+		page.Text = strings.TrimSpace(strings.ToValidUTF8(string(body), "�"))
+		return page, nil
+	default:
+		return nil, fmt.Errorf("%w: %s is %s, not HTML", ErrNeedsBrowser, rawURL, mediaType)
+	}
+
+	doc := parseHTML(string(body), resp.Request.URL)
+	page.Title, page.Text, page.Links = doc.title, doc.text, doc.links
+	if doc.scripts > 0 && len([]rune(page.Text)) < minStaticText {
+		return nil, fmt.Errorf("%w: %s has almost no text without JavaScript", ErrNeedsBrowser, rawURL)
+	}
+	return page, nil
+}
@@ -0,0 +1,117 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const articleHTML = `<!DOCTYPE html>
+<html lang="en"><head>
+<meta charset="utf-8">
+<title>Kettles &amp; Teapots</title>
+<style>body { color: red; }</style>
+<script>if (a < b && c > d) { document.write("<p>nope</p>"); }</script>
+</head><body>
+<!-- header <b>comment</b> -->
+<nav><a href="/">Home</a> <a href="#top">Top</a></nav>
+<h1>Why kettles whistle</h1>
+<p>A kettle whistles because steam, forced through a small opening,<br>sets up vibrations&nbsp;in the air.</p>
+<ul><li>Small openings whistle higher<li>Fast steam whistles louder</ul>
+<p>Read <a href="/physics?topic=steam">more about steam</a> or <a href="javascript:void(0)">nothing</a>.
+<img src="x.png" alt="kettle">
+<input type=checkbox checked>
+</body></html>`
+
+func TestParseHTML(t *testing.T) {
+	base, _ := url.Parse("https://example.com/articles/kettles")
+	doc := parseHTML(articleHTML, base)
+
+	if doc.title != "Kettles & Teapots" {
+		t.Errorf("title = %q", doc.title)
+	}
+	if doc.scripts != 1 {
+		t.Errorf("scripts = %d, want 1", doc.scripts)
+	}
+	for _, want := range []string{
+		"Why kettles whistle",
+		"A kettle whistles because steam, forced through a small opening,\nsets up vibrations in the air.",
+		"Small openings whistle higher\nFast steam whistles louder",
+		"Read more about steam or nothing.",
+	} {
+		if !strings.Contains(doc.text, want) {
+			t.Errorf("text missing %q:\n%s", want, doc.text)
+		}
+	}
+	for _, unwanted := range []string{"nope", "color: red", "comment"} {
+		if strings.Contains(doc.text, unwanted) {
+			t.Errorf("text contains %q:\n%s", unwanted, doc.text)
+		}
+	}
+	want := []Link{{Text: "Home", URL: "https://example.com/"}, {Text: "more about steam", URL: "https://example.com/physics?topic=steam"}}
+	if len(doc.links) != len(want) {
+		t.Fatalf("links = %+v, want %+v", doc.links, want)
+	}
+	for i := range want {
+		if doc.links[i] != want[i] {
+			t.Errorf("link %d = %+v, want %+v", i, doc.links[i], want[i])
+		}
+	}
+}
+
+func TestFetch(t *testing.T) {
+	var gotCookie string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/article":
+			if c, err := r.Cookie("session"); err == nil {
+				gotCookie = c.Value
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(articleHTML))
+		case "/old":
+			http.Redirect(w, r, "/article", http.StatusMovedPermanently)
+		case "/app":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<html><body><div id="root"></div><script src="/bundle.js"></script></body></html>`))
+		case "/blocked":
+			w.WriteHeader(http.StatusForbidden)
+		case "/report.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			_, _ = w.Write([]byte("%PDF-1.4"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	f := New(nil)
+	f.Cookies = func(ctx context.Context, url string) []*http.Cookie {
+		return []*http.Cookie{{Name: "session", Value: "abc"}}
+	}
+	ctx := context.Background()
+
+	page, err := f.Fetch(ctx, srv.URL+"/old")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if page.URL != srv.URL+"/article" || page.Status != http.StatusOK || page.Title != "Kettles & Teapots" {
+		t.Errorf("unexpected page: %+v", page)
+	}
+	if gotCookie != "abc" {
+		t.Errorf("cookie not sent, got %q", gotCookie)
+	}
+
+	for _, path := range []string{"/app", "/blocked", "/report.pdf"} {
+		if _, err := f.Fetch(ctx, srv.URL+path); !errors.Is(err, ErrNeedsBrowser) {
+			t.Errorf("%s: expected ErrNeedsBrowser, got %v", path, err)
+		}
+	}
+	if _, err := f.Fetch(ctx, srv.URL+"/missing"); err == nil || errors.Is(err, ErrNeedsBrowser) {
+		t.Errorf("/missing: expected a plain error, got %v", err)
+	}
+}
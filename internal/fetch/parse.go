@@ -0,0 +1,156 @@
+package fetch
+
+import (
+	"encoding/xml"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// maxLinks bounds the links kept per page.
+const maxLinks = 200
+
+// parsedHTML is what parseHTML read from a document.
+type parsedHTML struct {
+	title   string
+	text    string
+	links   []Link
+	scripts int // <script> elements, a hint that the page renders client-side
+}
+
+var (
+	commentPattern = regexp.MustCompile(`(?s)<!--.*?-->`)
+	scriptPattern  = regexp.MustCompile(`(?is)<script\b.*?</script\s*>`)
+	// Elements whose content is never visible text. Go's regexp has no
+	// backreferences, so each gets its own pattern.
+	hiddenPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?is)<style\b.*?</style\s*>`),
+		regexp.MustCompile(`(?is)<noscript\b.*?</noscript\s*>`),
+		regexp.MustCompile(`(?is)<template\b.*?</template\s*>`),
+		regexp.MustCompile(`(?is)<svg\b.*?</svg\s*>`),
+	}
+)
+
+// blockElements start a new line of text.
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true, "dd": true, "div": true,
+	"dl": true, "dt": true, "figcaption": true, "footer": true, "form": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "header": true, "hr": true, "li": true, "main": true, "nav": true, "ol": true,
+	"p": true, "pre": true, "section": true, "table": true, "td": true, "th": true, "tr": true, "ul": true,
+}
+
+// parseHTML extracts the title, visible text and links of a document. The
+// standard library has no HTML parser, so the document goes through
+// encoding/xml in its lenient HTML mode after the parts it cannot read
+// (scripts, styles, comments) are removed. Parsing stops at the first error
+// it cannot recover from, keeping what was read until then.
+func parseHTML(doc string, base *url.URL) parsedHTML {
+	var out parsedHTML
+	doc = strings.ToValidUTF8(doc, "�")
+	doc = commentPattern.ReplaceAllString(doc, "")
+	out.scripts = len(scriptPattern.FindAllStringIndex(doc, -1))
+	doc = scriptPattern.ReplaceAllString(doc, "")
+	for _, pattern := range hiddenPatterns {
+		doc = pattern.ReplaceAllString(doc, "")
+	}
+
+	decoder := xml.NewDecoder(strings.NewReader(doc))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	var text, title, linkText strings.Builder
+	inTitle, inHead := false, false
+	linkHref := ""
+	inLink := false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			switch name {
+			case "head":
+				inHead = true
+			case "body":
+				inHead = false
+			case "title":
+				inTitle = true
+			case "a":
+				inLink, linkHref = true, attr(t, "href")
+				linkText.Reset()
+			}
+			if blockElements[name] {
+				text.WriteString("\n")
+			}
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			switch name {
+			case "head":
+				inHead = false
+			case "title":
+				inTitle = false
+			case "a":
+				if inLink {
+					out.addLink(base, linkHref, linkText.String())
+				}
+				inLink = false
+			}
+			if blockElements[name] {
+				text.WriteString("\n")
+			}
+		case xml.CharData:
+			switch {
+			case inTitle:
+				title.Write(t)
+			case inHead:
+			default:
+				text.Write(t)
+				if inLink {
+					linkText.Write(t)
+				}
+			}
+		}
+	}
+
+	out.title = collapseSpaces(title.String())
+	var lines []string
+	for _, line := range strings.Split(text.String(), "\n") {
+		if line = collapseSpaces(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	out.text = strings.Join(lines, "\n")
+	return out
+}
+
+func (p *parsedHTML) addLink(base *url.URL, href, text string) {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") || len(p.links) >= maxLinks {
+		return
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return
+	}
+	resolved := base.ResolveReference(ref)
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return
+	}
+	p.links = append(p.links, Link{Text: collapseSpaces(text), URL: resolved.String()})
+}
+
+func attr(element xml.StartElement, name string) string {
+	for _, a := range element.Attr {
+		if strings.EqualFold(a.Name.Local, name) {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,481 @@
+// Package lineedit reads lines from a terminal with editing: arrow keys move
+// through the line and through the history, which is kept in a file across
+// sessions, and Tab completes the word before the cursor. Input that is not
+// a terminal is read line by line as is.
+package lineedit
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ErrInterrupt is returned by ReadLine when Ctrl-C abandons the line.
+var ErrInterrupt = errors.New("interrupted")
+
+// defaultMaxHistory bounds the history kept in memory and in the file.
+const defaultMaxHistory = 1000
+
+// pollInterval is how often ReadLineContext checks whether to give up.
+const pollInterval = 100 * time.Millisecond
+
+// Editor reads edited lines. It is not safe for concurrent use.
+type Editor struct {
+	in          *bufio.Reader
+	out         io.Writer
+	fd          int // terminal on stdin, or -1
+	historyFile string
+	history     []string
+
+	// Complete, if set, returns the candidates for the word before the
+	// cursor; before is the line up to the cursor. A single candidate
+	// replaces the word, several are listed.
+	Complete func(before string) []string
+	// MaxHistory bounds the history; zero means 1000 lines.
+	MaxHistory int
+
+	// state of the line being edited
+	buf       []rune
+	pos       int
+	width     int // columns of the terminal
+	cursorRow int // row of the cursor below the prompt's, for redraws
+}
+
+// New creates an editor reading from in, which must read stdin, and writing
+// to stdout. The history is loaded from historyFile, and each line added
+// with AddHistory is appended to it; an empty historyFile keeps history in
+// memory only. If the history cannot be read, the editor returned along
+// with the error works without it.
+func New(in *bufio.Reader, historyFile string) (*Editor, error) {
+	e := &Editor{in: in, out: os.Stdout, fd: int(os.Stdin.Fd()), historyFile: historyFile}
+	if !isTerminal(e.fd) {
+		e.fd = -1
+	}
+	if historyFile == "" {
+		return e, nil
+	}
+	data, err := os.ReadFile(historyFile)
+	if os.IsNotExist(err) {
+		return e, nil
+	}
+	if err != nil {
+		return e, fmt.Errorf("failed to read history: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			e.history = append(e.history, line)
+		}
+	}
+	if max := e.maxHistory(); len(e.history) > max {
+		e.history = e.history[len(e.history)-max:]
+		if err := e.rewriteHistory(); err != nil {
+			return e, err
+		}
+	}
+	return e, nil
+}
+
+// DefaultHistoryPath is the history file under the user's config dir.
+func DefaultHistoryPath() string {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, "aibot", "history")
+}
+
+// History returns the remembered lines, oldest first.
+func (e *Editor) History() []string {
+	return append([]string(nil), e.history...)
+}
+
+// AddHistory remembers a line for the up arrow and appends it to the history
+// file, which New trims back to MaxHistory lines. Blank lines and repeats of
+// the previous line are skipped.
+func (e *Editor) AddHistory(line string) error {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.ContainsAny(line, "\r\n") {
+		return nil
+	}
+	if n := len(e.history); n > 0 && e.history[n-1] == line {
+		return nil
+	}
+	e.history = append(e.history, line)
+	if max := e.maxHistory(); len(e.history) > max {
+		e.history = e.history[len(e.history)-max:]
+	}
+	if e.historyFile == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(e.historyFile), 0o700); err != nil {
+		return fmt.Errorf("failed to save history: %w", err)
+	}
+	f, err := os.OpenFile(e.historyFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to save history: %w", err)
+	}
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to save history: %w", err)
+	}
+	return f.Close()
+}
+
+func (e *Editor) maxHistory() int {
+	if e.MaxHistory > 0 {
+		return e.MaxHistory
+	}
+	return defaultMaxHistory
+}
+
+// rewriteHistory replaces the history file with the lines in memory.
+func (e *Editor) rewriteHistory() error {
+	data := strings.Join(e.history, "\n") + "\n"
+	if err := os.WriteFile(e.historyFile, []byte(data), 0o600); err != nil {
+		return fmt.Errorf("failed to save history: %w", err)
+	}
+	return nil
+}
+
+// ReadLine shows prompt and returns the line entered, without its newline.
+// It returns io.EOF for Ctrl-D on an empty line and ErrInterrupt for Ctrl-C.
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	if e.fd < 0 {
+		return e.readPlain(prompt)
+	}
+	restore, err := makeRaw(e.fd)
+	if err != nil {
+		return e.readPlain(prompt)
+	}
+	defer restore()
+	return e.edit(prompt, terminalWidth(e.fd))
+}
+
+// readPlain reads a line without editing, e.g. from a pipe.
+func (e *Editor) readPlain(prompt string) (string, error) {
+	fmt.Fprint(e.out, prompt)
+	line, err := e.in.ReadString('\n')
+	if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// ReadLineContext reads a line without editing from in, a reader over the
+// file f, and returns it without its newline, giving up when ctx is done. It
+// reads only once f has input, so a read given up on does not linger and take
+// the next line from whoever reads f after, such as an Editor. Where waiting
+// on a file is not supported the read blocks as usual.
+func ReadLineContext(ctx context.Context, in *bufio.Reader, f *os.File) (string, error) {
+	if in.Buffered() == 0 {
+		fd := int(f.Fd())
+		for {
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
+			ready, err := waitInput(fd, pollInterval)
+			if err != nil {
+				return "", err
+			}
+			if ready {
+				break
+			}
+		}
+	}
+	line, err := in.ReadString('\n')
+	if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// Keys the editor handles, as the terminal sends them.
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyBackspace = 8 // Ctrl-H
+	keyTab       = 9
+	keyEnter     = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyReturn    = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyDelete    = 127 // what most terminals send for backspace
+)
+
+// edit runs the editing loop on a terminal width columns wide.
+func (e *Editor) edit(prompt string, width int) (string, error) {
+	if width <= 0 {
+		width = 80
+	}
+	e.buf, e.pos, e.width, e.cursorRow = e.buf[:0], 0, width, 0
+	historyPos := len(e.history)
+	var editing []rune // the new line while browsing the history
+
+	showHistory := func(to int) {
+		if to < 0 || to > len(e.history) || to == historyPos {
+			return
+		}
+		if historyPos == len(e.history) {
+			editing = append(editing[:0], e.buf...)
+		}
+		historyPos = to
+		if to == len(e.history) {
+			e.buf = append(e.buf[:0], editing...)
+		} else {
+			e.buf = append(e.buf[:0], []rune(e.history[to])...)
+		}
+		e.pos = len(e.buf)
+	}
+
+	e.refresh(prompt)
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			if errors.Is(err, io.EOF) && len(e.buf) > 0 {
+				break
+			}
+			return "", err
+		}
+		switch r {
+		case keyEnter, keyReturn:
+			e.pos = len(e.buf)
+			e.refresh(prompt)
+			fmt.Fprint(e.out, "\r\n")
+			return string(e.buf), nil
+		case keyCtrlC:
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", ErrInterrupt
+		case keyCtrlD:
+			if len(e.buf) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			e.deleteAt(e.pos)
+		case keyBackspace, keyDelete:
+			if e.pos > 0 {
+				e.pos--
+				e.deleteAt(e.pos)
+			}
+		case keyTab:
+			e.complete(prompt)
+		case keyCtrlA:
+			e.pos = 0
+		case keyCtrlE:
+			e.pos = len(e.buf)
+		case keyCtrlB:
+			e.move(-1)
+		case keyCtrlF:
+			e.move(1)
+		case keyCtrlK:
+			e.buf = e.buf[:e.pos]
+		case keyCtrlU:
+			e.buf = append(e.buf[:0], e.buf[e.pos:]...)
+			e.pos = 0
+		case keyCtrlW:
+			start := e.wordStart()
+			e.buf = append(e.buf[:start], e.buf[e.pos:]...)
+			e.pos = start
+		case keyCtrlL:
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+			e.cursorRow = 0
+		case keyCtrlP:
+			showHistory(historyPos - 1)
+		case keyCtrlN:
+			showHistory(historyPos + 1)
+		case keyEscape:
+			switch e.readEscape() {
+			case "[A", "OA":
+				showHistory(historyPos - 1)
+			case "[B", "OB":
+				showHistory(historyPos + 1)
+			case "[C", "OC":
+				e.move(1)
+			case "[D", "OD":
+				e.move(-1)
+			case "[H", "OH", "[1~", "[7~":
+				e.pos = 0
+			case "[F", "OF", "[4~", "[8~":
+				e.pos = len(e.buf)
+			case "[3~":
+				e.deleteAt(e.pos)
+			case "[1;5C", "[1;3C", "f":
+				e.pos = e.wordEnd()
+			case "[1;5D", "[1;3D", "b":
+				e.pos = e.wordStart()
+			}
+		default:
+			if unicode.IsPrint(r) {
+				e.insert(r)
+			}
+		}
+		e.refresh(prompt)
+	}
+	fmt.Fprint(e.out, "\r\n")
+	return string(e.buf), nil
+}
+
+// readEscape reads the rest of an escape sequence: "[A" for the up arrow,
+// "[3~" for Delete, or a single letter for Alt with that letter.
+func (e *Editor) readEscape() string {
+	first, _, err := e.in.ReadRune()
+	if err != nil {
+		return ""
+	}
+	if first != '[' && first != 'O' {
+		return string(first)
+	}
+	seq := []rune{first}
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return string(seq)
+		}
+		seq = append(seq, r)
+		if r >= 0x40 && r <= 0x7e { // final byte of a CSI sequence
+			return string(seq)
+		}
+	}
+}
+
+func (e *Editor) insert(r rune) {
+	e.buf = append(e.buf, 0)
+	copy(e.buf[e.pos+1:], e.buf[e.pos:])
+	e.buf[e.pos] = r
+	e.pos++
+}
+
+func (e *Editor) deleteAt(i int) {
+	if i < len(e.buf) {
+		e.buf = append(e.buf[:i], e.buf[i+1:]...)
+	}
+}
+
+func (e *Editor) move(delta int) {
+	if pos := e.pos + delta; pos >= 0 && pos <= len(e.buf) {
+		e.pos = pos
+	}
+}
+
+// wordStart is where the word before the cursor begins.
+func (e *Editor) wordStart() int {
+	i := e.pos
+	for i > 0 && unicode.IsSpace(e.buf[i-1]) {
+		i--
+	}
+	for i > 0 && !unicode.IsSpace(e.buf[i-1]) {
+		i--
+	}
+	return i
+}
+
+// wordEnd is where the word after the cursor ends.
+func (e *Editor) wordEnd() int {
+	i := e.pos
+	for i < len(e.buf) && unicode.IsSpace(e.buf[i]) {
+		i++
+	}
+	for i < len(e.buf) && !unicode.IsSpace(e.buf[i]) {
+		i++
+	}
+	return i
+}
+
+// complete replaces the word before the cursor with its only candidate, or
+// with the longest prefix the candidates share, listing them when that does
+// not get any further.
+func (e *Editor) complete(prompt string) {
+	if e.Complete == nil {
+		return
+	}
+	start := e.pos
+	for start > 0 && !unicode.IsSpace(e.buf[start-1]) {
+		start--
+	}
+	word := string(e.buf[start:e.pos])
+	candidates := e.Complete(string(e.buf[:e.pos]))
+	if len(candidates) == 0 {
+		return
+	}
+	replacement := candidates[0]
+	if len(candidates) == 1 {
+		replacement += " "
+	} else {
+		for _, c := range candidates[1:] {
+			replacement = commonPrefix(replacement, c)
+		}
+	}
+	if len(candidates) > 1 && (replacement == word || !strings.HasPrefix(replacement, word)) {
+		e.list(prompt, candidates)
+		return
+	}
+	rest := append([]rune(replacement), e.buf[e.pos:]...)
+	e.buf = append(e.buf[:start], rest...)
+	e.pos = start + len([]rune(replacement))
+}
+
+// list prints completion candidates below the line; the next refresh draws
+// the line again under them.
+func (e *Editor) list(prompt string, candidates []string) {
+	e.pos = len(e.buf)
+	e.refresh(prompt)
+	fmt.Fprintf(e.out, "\r\n%s\r\n", strings.Join(candidates, "  "))
+	e.cursorRow = 0
+}
+
+func commonPrefix(a, b string) string {
+	ar, br := []rune(a), []rune(b)
+	n := 0
+	for n < len(ar) && n < len(br) && ar[n] == br[n] {
+		n++
+	}
+	return string(ar[:n])
+}
+
+// refresh redraws the prompt and the line, which may wrap over several rows,
+// and puts the cursor at pos.
+func (e *Editor) refresh(prompt string) {
+	width := e.width
+	var b strings.Builder
+	if e.cursorRow > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", e.cursorRow)
+	}
+	b.WriteString("\r\x1b[J")
+	b.WriteString(prompt)
+	b.WriteString(string(e.buf))
+
+	promptWidth := len([]rune(prompt))
+	total := promptWidth + len(e.buf)
+	if total > 0 && total%width == 0 {
+		// The terminal waits at the last column before wrapping; move on
+		// so the rows below are counted the same way in every case.
+		b.WriteString("\r\n")
+	}
+	endRow := total / width
+	cursor := promptWidth + e.pos
+	row, col := cursor/width, cursor%width
+	if endRow > row {
+		fmt.Fprintf(&b, "\x1b[%dA", endRow-row)
+	}
+	b.WriteString("\r")
+	if col > 0 {
+		fmt.Fprintf(&b, "\x1b[%dC", col)
+	}
+	e.cursorRow = row
+	fmt.Fprint(e.out, b.String())
+}
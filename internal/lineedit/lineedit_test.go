@@ -0,0 +1,163 @@
+package lineedit
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testEditor(keys string) *Editor {
+	return &Editor{in: bufio.NewReader(strings.NewReader(keys)), out: io.Discard, fd: -1}
+}
+
+func TestEditKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		keys string
+		want string
+	}{
+		{"plain", "найди кремль\r", "найди кремль"},
+		{"backspace", "кремлб\x7fь\r", "кремль"},
+		{"insert after left arrow", "найди кремль\x1b[D\x1b[D\x1b[D\x1b[D\x1b[D\x1b[D\x1b[Dи\r", "найдии кремль"},
+		{"home and delete", "xgo\x1b[H\x1b[3~\r", "go"},
+		{"ctrl-a and ctrl-k", "go https://ya.ru\x01\x06\x06\x0b\r", "go"},
+		{"ctrl-w", "task one two\x17three\r", "task one three"},
+		{"ctrl-u keeps the rest", "junk task\x1b[D\x1b[D\x1b[D\x1b[D\x15\r", "task"},
+		{"word jumps", "go to page\x1b[1;5D\x1b[1;5D\x17\r", "to page"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testEditor(tt.keys).edit("> ", 80)
+			if err != nil {
+				t.Fatalf("edit: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditControlKeys(t *testing.T) {
+	if _, err := testEditor("abc\x03").edit("> ", 80); !errors.Is(err, ErrInterrupt) {
+		t.Errorf("Ctrl-C: got %v, want ErrInterrupt", err)
+	}
+	if _, err := testEditor("\x04").edit("> ", 80); !errors.Is(err, io.EOF) {
+		t.Errorf("Ctrl-D on an empty line: got %v, want io.EOF", err)
+	}
+	if got, _ := testEditor("ab\x01\x04\r").edit("> ", 80); got != "b" {
+		t.Errorf("Ctrl-D inside a line should delete, got %q", got)
+	}
+}
+
+func TestEditHistory(t *testing.T) {
+	e := testEditor("\x1b[A\x1b[A\r" + "new\x1b[A\x1b[B\r")
+	e.history = []string{"first", "second"}
+
+	if got, _ := e.edit("> ", 80); got != "first" {
+		t.Errorf("two ups: got %q, want %q", got, "first")
+	}
+	if got, _ := e.edit("> ", 80); got != "new" {
+		t.Errorf("up and down should bring back the edited line, got %q", got)
+	}
+}
+
+func TestEditComplete(t *testing.T) {
+	complete := func(before string) []string {
+		var out []string
+		for _, c := range []string{"save_macro", "save_state", "save_har", "search"} {
+			if strings.HasPrefix(c, before) {
+				out = append(out, c)
+			}
+		}
+		return out
+	}
+
+	e := testEditor("sea\tкремль\r")
+	e.Complete = complete
+	if got, _ := e.edit("> ", 80); got != "search кремль" {
+		t.Errorf("single candidate: got %q", got)
+	}
+
+	e = testEditor("sa\t\r")
+	e.Complete = complete
+	if got, _ := e.edit("> ", 80); got != "save_" {
+		t.Errorf("common prefix: got %q", got)
+	}
+}
+
+func TestHistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "history")
+	e, err := New(bufio.NewReader(strings.NewReader("")), path)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for _, line := range []string{"go https://ya.ru", "go https://ya.ru", " ", "task найди кремль"} {
+		if err := e.AddHistory(line); err != nil {
+			t.Fatalf("AddHistory: %v", err)
+		}
+	}
+
+	e, err = New(bufio.NewReader(strings.NewReader("")), path)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	want := []string{"go https://ya.ru", "task найди кремль"}
+	if got := e.History(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("history = %q, want %q", got, want)
+	}
+
+	if err := os.WriteFile(path, []byte("a\nb\nc\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	e, _ = New(bufio.NewReader(strings.NewReader("")), path)
+	e.MaxHistory = 2
+	_ = e.AddHistory("d")
+	if got := e.History(); !reflect.DeepEqual(got, []string{"c", "d"}) {
+		t.Errorf("history should keep the last MaxHistory lines, got %q", got)
+	}
+}
+
+func TestReadLineWithoutTerminal(t *testing.T) {
+	e := testEditor("go https://ya.ru\r\nlast")
+	if got, err := e.ReadLine("> "); err != nil || got != "go https://ya.ru" {
+		t.Errorf("got %q, %v", got, err)
+	}
+	if got, err := e.ReadLine("> "); err != nil || got != "last" {
+		t.Errorf("a last line without newline: got %q, %v", got, err)
+	}
+	if _, err := e.ReadLine("> "); !errors.Is(err, io.EOF) {
+		t.Errorf("got %v, want io.EOF", err)
+	}
+}
+
+func TestReadLineContextGivesUp(t *testing.T) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	defer pr.Close()
+	defer pw.Close()
+	in := bufio.NewReader(pr)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := ReadLineContext(ctx, in, pr); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the read to give up, got %v", err)
+	}
+
+	if _, err := pw.Write([]byte("next\n")); err != nil {
+		t.Fatalf("failed to type: %v", err)
+	}
+	line, err := ReadLineContext(context.Background(), in, pr)
+	if err != nil || line != "next" {
+		t.Fatalf("got %q, %v", line, err)
+	}
+}
@@ -0,0 +1,12 @@
+package lineedit
+
+import "syscall"
+
+const (
+	ioctlGetTermios = syscall.TIOCGETA
+	ioctlSetTermios = syscall.TIOCSETA
+)
+
+func selectRead(nfd int, set *syscall.FdSet, timeout *syscall.Timeval) error {
+	return syscall.Select(nfd, set, nil, nil, timeout)
+}
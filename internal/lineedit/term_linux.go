@@ -0,0 +1,13 @@
+package lineedit
+
+import "syscall"
+
+const (
+	ioctlGetTermios = syscall.TCGETS
+	ioctlSetTermios = syscall.TCSETS
+)
+
+func selectRead(nfd int, set *syscall.FdSet, timeout *syscall.Timeval) error {
+	_, err := syscall.Select(nfd, set, nil, nil, timeout)
+	return err
+}
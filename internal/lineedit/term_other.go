@@ -0,0 +1,18 @@
+//go:build !linux && !darwin
+
+package lineedit
+
+import (
+	"errors"
+	"time"
+)
+
+func isTerminal(fd int) bool { return false }
+
+func makeRaw(fd int) (func(), error) {
+	return nil, errors.New("line editing is not supported on this platform")
+}
+
+func terminalWidth(fd int) int { return 0 }
+
+func waitInput(fd int, timeout time.Duration) (bool, error) { return true, nil }
@@ -0,0 +1,71 @@
+//go:build linux || darwin
+
+package lineedit
+
+import (
+	"errors"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+func getTermios(fd int) (*syscall.Termios, error) {
+	var t syscall.Termios
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), ioctlGetTermios, uintptr(unsafe.Pointer(&t))); errno != 0 {
+		return nil, errno
+	}
+	return &t, nil
+}
+
+func setTermios(fd int, t *syscall.Termios) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), ioctlSetTermios, uintptr(unsafe.Pointer(t))); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func isTerminal(fd int) bool {
+	_, err := getTermios(fd)
+	return err == nil
+}
+
+// makeRaw turns off line buffering, echo and the signal keys, so every key
+// reaches the editor; Enter still arrives as a newline.
+func makeRaw(fd int) (restore func(), err error) {
+	old, err := getTermios(fd)
+	if err != nil {
+		return nil, err
+	}
+	raw := *old
+	raw.Lflag &^= syscall.ICANON | syscall.ECHO | syscall.ISIG | syscall.IEXTEN
+	raw.Iflag &^= syscall.IXON
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := setTermios(fd, &raw); err != nil {
+		return nil, err
+	}
+	return func() { _ = setTermios(fd, old) }, nil
+}
+
+func terminalWidth(fd int) int {
+	var size struct{ rows, cols, x, y uint16 }
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(&size))); errno != 0 {
+		return 0
+	}
+	return int(size.cols)
+}
+
+// waitInput waits up to timeout for fd to have input to read.
+func waitInput(fd int, timeout time.Duration) (bool, error) {
+	var set syscall.FdSet
+	bits := int(unsafe.Sizeof(set.Bits[0]) * 8)
+	set.Bits[fd/bits] |= 1 << (uint(fd) % uint(bits))
+	tv := syscall.NsecToTimeval(timeout.Nanoseconds())
+	if err := selectRead(fd+1, &set, &tv); err != nil {
+		if errors.Is(err, syscall.EINTR) {
+			return false, nil
+		}
+		return false, err
+	}
+	return set.Bits[fd/bits]&(1<<(uint(fd)%uint(bits))) != 0, nil
+}
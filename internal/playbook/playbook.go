@@ -0,0 +1,290 @@
+// Package playbook loads user-written playbooks: fixed action sequences for a
+// site, such as searching Yandex Maps, that the agent runs instead of asking
+// the model to plan when a task matches one. A playbook is a YAML file:
+//
+//	name: yandex-maps-search
+//	domains: [yandex.ru, yandex.com]
+//	match: [yandex maps, яндекс карт]
+//	params:
+//	  query:
+//	    pattern: '(?i)(?:find|search for|найди)\s+(.+?)(?:\s+on yandex maps)?$'
+//	steps:
+//	  - action: navigate
+//	    url: 'https://yandex.ru/maps/?text={{urlquery .query}}'
+//	  - action: wait_for
+//	    selector: .search-snippet-view
+//	done_when:
+//	  selector_present: .search-snippet-view
+//
+// The selector, url and text of a step are Go text/template strings over the
+// playbook's parameters.
+package playbook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/VolodyaPopov923/AIBot/internal/ai"
+)
+
+// Playbook is a declarative action sequence for one site.
+type Playbook struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Domains are the sites the playbook is for; subdomains match too.
+	Domains []string `json:"domains"`
+	// Match, if set, are phrases one of which the task must contain,
+	// compared case-insensitively.
+	Match    []string          `json:"match,omitempty"`
+	Params   map[string]Param  `json:"params,omitempty"`
+	Steps    []Step            `json:"steps"`
+	DoneWhen *ai.StepCondition `json:"done_when,omitempty"`
+
+	Path string `json:"-"` // file the playbook was loaded from
+}
+
+// Param is a value taken from the task text.
+type Param struct {
+	// Pattern is a regular expression matched against the task; the first
+	// group, or the whole match without groups, is the value.
+	Pattern string `json:"pattern,omitempty"`
+	// Default is used when Pattern is empty or does not match. A parameter
+	// without a value makes the playbook not apply.
+	Default string `json:"default,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// Step is one action, with the fields of a model decision.
+type Step struct {
+	Action       string `json:"action"`
+	Description  string `json:"description,omitempty"`
+	Selector     string `json:"selector,omitempty"`
+	URL          string `json:"url,omitempty"`
+	Text         string `json:"text,omitempty"`
+	Optional     bool   `json:"optional,omitempty"`
+	NeedsConfirm bool   `json:"needs_confirm,omitempty"`
+	MinCount     int    `json:"min_count,omitempty"`
+	MaxPages     int    `json:"max_pages,omitempty"`
+	Timeout      int    `json:"timeout,omitempty"`
+}
+
+// Load reads and validates a playbook file.
+func Load(path string) (Playbook, error) {
+	var pb Playbook
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return pb, fmt.Errorf("failed to read playbook: %w", err)
+	}
+	if err := parse(string(data), &pb); err != nil {
+		return pb, fmt.Errorf("invalid playbook %s: %w", path, err)
+	}
+	pb.Path = path
+	if pb.Name == "" {
+		pb.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return pb, nil
+}
+
+// LoadDir loads the *.yaml and *.yml files in dir, sorted by name. Any
+// invalid file is an error, so mistakes surface at startup.
+func LoadDir(dir string) ([]Playbook, error) {
+	var paths []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list playbooks: %w", err)
+		}
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+	playbooks := make([]Playbook, 0, len(paths))
+	for _, path := range paths {
+		pb, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		playbooks = append(playbooks, pb)
+	}
+	return playbooks, nil
+}
+
+func parse(data string, pb *Playbook) error {
+	doc, err := decodeYAML(data)
+	if err != nil {
+		return err
+	}
+	// Going through JSON reuses the field tags and reports unknown fields.
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(pb); err != nil {
+		return err
+	}
+	return pb.validate()
+}
+
+func (pb *Playbook) validate() error {
+	if len(pb.Domains) == 0 {
+		return fmt.Errorf("no domains")
+	}
+	if len(pb.Steps) == 0 {
+		return fmt.Errorf("no steps")
+	}
+	for name, param := range pb.Params {
+		if param.Pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(param.Pattern)
+		if err != nil {
+			return fmt.Errorf("param %s: %w", name, err)
+		}
+		param.re = re
+		pb.Params[name] = param
+	}
+	for i, step := range pb.Steps {
+		if !knownAction(step.Action) {
+			return fmt.Errorf("step %d: unknown action %q", i+1, step.Action)
+		}
+		for _, field := range []string{step.Selector, step.URL, step.Text} {
+			if _, err := newTemplate(field); err != nil {
+				return fmt.Errorf("step %d: %w", i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Match returns the first playbook that applies to task on the page at
+// pageURL, with its parameter values. A playbook applies when the page is on
+// one of its sites, or the task names one of them, or the browser is still on
+// a blank page and the task contains one of its Match phrases; Match phrases,
+// if any, must be in the task either way, and every parameter needs a value.
+func Match(playbooks []Playbook, task, pageURL string) (Playbook, map[string]string, bool) {
+	for _, pb := range playbooks {
+		if params, ok := pb.matches(task, pageURL); ok {
+			return pb, params, true
+		}
+	}
+	return Playbook{}, nil, false
+}
+
+func (pb Playbook) matches(task, pageURL string) (map[string]string, bool) {
+	lowerTask := strings.ToLower(task)
+	phrase := len(pb.Match) == 0
+	for _, m := range pb.Match {
+		if strings.Contains(lowerTask, strings.ToLower(m)) {
+			phrase = true
+			break
+		}
+	}
+	if !phrase {
+		return nil, false
+	}
+
+	host := ""
+	if parsed, err := url.Parse(pageURL); err == nil {
+		host = strings.ToLower(parsed.Hostname())
+	}
+	site := host == "" && len(pb.Match) > 0
+	for _, domain := range pb.Domains {
+		domain = strings.ToLower(domain)
+		if host == domain || strings.HasSuffix(host, "."+domain) || strings.Contains(lowerTask, domain) {
+			site = true
+			break
+		}
+	}
+	if !site {
+		return nil, false
+	}
+
+	params := make(map[string]string, len(pb.Params))
+	for name, param := range pb.Params {
+		value := param.Default
+		if param.re != nil {
+			if m := param.re.FindStringSubmatch(task); m != nil {
+				value = m[0]
+				if len(m) > 1 {
+					value = m[1]
+				}
+			}
+		}
+		if value = strings.TrimSpace(value); value == "" {
+			return nil, false
+		}
+		params[name] = value
+	}
+	return params, true
+}
+
+// Decisions fills the parameters into the steps and returns them as the
+// decisions to execute.
+func (pb Playbook) Decisions(params map[string]string) ([]ai.DecisionResponse, error) {
+	decisions := make([]ai.DecisionResponse, 0, len(pb.Steps))
+	for i, step := range pb.Steps {
+		decision := ai.DecisionResponse{
+			Action:       step.Action,
+			Reasoning:    step.Description,
+			Optional:     step.Optional,
+			NeedsConfirm: step.NeedsConfirm,
+			MinCount:     step.MinCount,
+			MaxPages:     step.MaxPages,
+			Timeout:      step.Timeout,
+		}
+		if decision.Reasoning == "" {
+			decision.Reasoning = fmt.Sprintf("Playbook %s, step %d", pb.Name, i+1)
+		}
+		for _, field := range []struct {
+			dst *string
+			src string
+		}{{&decision.Selector, step.Selector}, {&decision.URL, step.URL}, {&decision.Text, step.Text}} {
+			value, err := expand(field.src, params)
+			if err != nil {
+				return nil, fmt.Errorf("playbook %s, step %d: %w", pb.Name, i+1, err)
+			}
+			*field.dst = value
+		}
+		decisions = append(decisions, decision)
+	}
+	return decisions, nil
+}
+
+func knownAction(action string) bool {
+	normalized := ai.NormalizeAction(action)
+	for _, known := range ai.Actions() {
+		if normalized == known {
+			return true
+		}
+	}
+	return false
+}
+
+func newTemplate(text string) (*template.Template, error) {
+	return template.New("").Option("missingkey=error").Parse(text)
+}
+
+func expand(text string, params map[string]string) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := newTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, params); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
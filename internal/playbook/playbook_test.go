@@ -0,0 +1,74 @@
+package playbook
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadExamplePlaybook(t *testing.T) {
+	playbooks, err := LoadDir(filepath.Join("..", "..", "playbooks"))
+	if err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+	if len(playbooks) == 0 {
+		t.Fatal("expected the example playbooks to load")
+	}
+
+	pb, params, ok := Match(playbooks, "найди аптеку на Яндекс Картах", "about:blank")
+	if !ok || pb.Name != "yandex-maps-search" || params["query"] != "аптеку" {
+		t.Fatalf("Match = %s, %v, %v", pb.Name, params, ok)
+	}
+	decisions, err := pb.Decisions(params)
+	if err != nil {
+		t.Fatalf("Decisions: %v", err)
+	}
+	if decisions[0].URL != "https://yandex.ru/maps/?text=%D0%B0%D0%BF%D1%82%D0%B5%D0%BA%D1%83" {
+		t.Fatalf("unexpected URL %q", decisions[0].URL)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	pb := Playbook{
+		Name:    "shop-search",
+		Domains: []string{"shop.example"},
+		Params:  map[string]Param{"item": {Pattern: `(?i)buy (.+)`}},
+		Steps:   []Step{{Action: "fill", Selector: "#q", Text: "{{.item}}"}},
+	}
+	if err := pb.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	playbooks := []Playbook{pb}
+
+	if _, params, ok := Match(playbooks, "buy milk", "https://www.shop.example/"); !ok || params["item"] != "milk" {
+		t.Fatalf("expected a match on the site, got %v, %v", params, ok)
+	}
+	if _, _, ok := Match(playbooks, "buy milk on shop.example", "about:blank"); !ok {
+		t.Fatal("expected a match when the task names the site")
+	}
+	if _, _, ok := Match(playbooks, "buy milk", "https://other.example/"); ok {
+		t.Fatal("a playbook should not apply on another site")
+	}
+	if _, _, ok := Match(playbooks, "show the cart", "https://shop.example/"); ok {
+		t.Fatal("a playbook should not apply without its parameters")
+	}
+}
+
+func TestLoadRejectsInvalidPlaybooks(t *testing.T) {
+	dir := t.TempDir()
+	for name, doc := range map[string]string{
+		"unknown field":  "domains: [a.example]\nstepz:\n  - action: click\n",
+		"unknown action": "domains: [a.example]\nsteps:\n  - action: teleport\n",
+		"no domains":     "steps:\n  - action: click\n    selector: '#a'\n",
+		"bad template":   "domains: [a.example]\nsteps:\n  - action: fill\n    text: '{{.x'\n",
+	} {
+		path := filepath.Join(dir, strings.ReplaceAll(name, " ", "-")+".yaml")
+		if err := os.WriteFile(path, []byte(doc), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package playbook
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeYAML(t *testing.T) {
+	doc := `---
+# comment
+name: demo   # trailing comment
+tags: [a, "b, c", 'it''s']
+enabled: true
+count: 3
+quoted: "3"
+url: https://example.com/#top
+empty:
+steps:
+- action: fill
+  selector: '#q'
+  text: "say \"hi\""
+- action: press
+  text: Enter
+nested:
+  list:
+    - one
+    -
+      - two
+`
+	got, err := decodeYAML(doc)
+	if err != nil {
+		t.Fatalf("decodeYAML: %v", err)
+	}
+	want := map[string]any{
+		"name":    "demo",
+		"tags":    []any{"a", "b, c", "it's"},
+		"enabled": true,
+		"count":   3,
+		"quoted":  "3",
+		"url":     "https://example.com/#top",
+		"empty":   nil,
+		"steps": []any{
+			map[string]any{"action": "fill", "selector": "#q", "text": `say "hi"`},
+			map[string]any{"action": "press", "text": "Enter"},
+		},
+		"nested": map[string]any{"list": []any{"one", []any{"two"}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("decodeYAML =\n%#v\nwant\n%#v", got, want)
+	}
+}
+
+func TestDecodeYAMLErrors(t *testing.T) {
+	for name, doc := range map[string]string{
+		"bad indent":    "a: 1\n   b: 2\n",
+		"duplicate key": "a: 1\na: 2\n",
+		"tab":           "a:\n\tb: 1\n",
+		"not a pair":    "a: 1\njust text\n",
+		"flow mapping":  "a: {b: 1}\n",
+	} {
+		if _, err := decodeYAML(doc); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
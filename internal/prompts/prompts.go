@@ -0,0 +1,159 @@
+// Package prompts holds the system prompts sent to the model as Go text
+// templates. The defaults are built in; a deployment can override any of them
+// with a file of the same name, e.g. to add site-specific guidance or to have
+// the model answer in another language.
+package prompts
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Template names. An override file is the name plus ".tmpl".
+const (
+	// Decide is the system prompt of the iterative decision loop; data is
+	// DecideData.
+	Decide = "decide"
+	// DecideStep is the system prompt of a decision for one plan step; data
+	// is DecideData.
+	DecideStep = "decide_step"
+	// Plan is the planning request; data is PlanData.
+	Plan = "plan"
+	// PlanSystem is the system prompt of planning; data is PlanData.
+	PlanSystem = "plan_system"
+	// ParseRequest is the system prompt that parses the user's request; it
+	// gets no data.
+	ParseRequest = "parse_request"
+	// Verify is the system prompt that checks whether an action achieved its
+	// plan step; it gets no data.
+	Verify = "verify"
+)
+
+// DecideData is passed to the Decide and DecideStep templates.
+type DecideData struct {
+	Actions string // list of valid actions with descriptions
+	Task    string
+	URL     string // of the current page
+}
+
+// PlanData is passed to the Plan and PlanSystem templates.
+type PlanData struct {
+	Task string
+	Page string // brief context of the current page
+}
+
+//go:embed templates/*.tmpl
+var defaultFiles embed.FS
+
+var funcs = template.FuncMap{
+	"contains":  strings.Contains,
+	"hasPrefix": strings.HasPrefix,
+}
+
+var (
+	defaults = mustParseDefaults()
+
+	mu     sync.RWMutex
+	active = defaults
+)
+
+func mustParseDefaults() map[string]*template.Template {
+	files, err := defaultFiles.ReadDir("templates")
+	if err != nil {
+		panic(err)
+	}
+	set := make(map[string]*template.Template, len(files))
+	for _, file := range files {
+		data, err := defaultFiles.ReadFile("templates/" + file.Name())
+		if err != nil {
+			panic(err)
+		}
+		name := strings.TrimSuffix(file.Name(), ".tmpl")
+		set[name] = template.Must(parse(name, string(data)))
+	}
+	return set
+}
+
+func parse(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(funcs).Option("missingkey=zero").Parse(text)
+}
+
+// LoadOverrides replaces the built-in templates with the *.tmpl files in dir.
+// Templates without a file there keep their default. A file that does not
+// name a known template or does not parse is an error, so typos surface at
+// startup rather than as silently ignored prompts.
+func LoadOverrides(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return fmt.Errorf("failed to list prompt templates: %w", err)
+	}
+	set := make(map[string]*template.Template, len(defaults))
+	for name, tmpl := range defaults {
+		set[name] = tmpl
+	}
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".tmpl")
+		if _, ok := defaults[name]; !ok {
+			return fmt.Errorf("unknown prompt template %q (known: %s)", filepath.Base(path), strings.Join(Names(), ", "))
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read prompt template: %w", err)
+		}
+		tmpl, err := parse(name, string(data))
+		if err != nil {
+			return fmt.Errorf("failed to parse prompt template %s: %w", path, err)
+		}
+		set[name] = tmpl
+	}
+
+	mu.Lock()
+	active = set
+	mu.Unlock()
+	return nil
+}
+
+// ResetOverrides restores the built-in templates.
+func ResetOverrides() {
+	mu.Lock()
+	active = defaults
+	mu.Unlock()
+}
+
+// Names lists the known template names.
+func Names() []string {
+	return []string{Decide, DecideStep, Plan, PlanSystem, ParseRequest, Verify}
+}
+
+// Render executes the named template. An override that fails to execute,
+// e.g. by referring to a missing field, falls back to the built-in template
+// with a warning rather than failing the task.
+func Render(name string, data any) string {
+	mu.RLock()
+	tmpl := active[name]
+	mu.RUnlock()
+	if tmpl == nil {
+		panic("prompts: unknown template " + name)
+	}
+	text, err := execute(tmpl, data)
+	if err != nil {
+		log.Printf("Warning: prompt template %s failed, using the default: %v\n", name, err)
+		text, _ = execute(defaults[name], data)
+	}
+	return text
+}
+
+func execute(tmpl *template.Template, data any) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
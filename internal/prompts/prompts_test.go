@@ -0,0 +1,89 @@
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultsRender(t *testing.T) {
+	for _, name := range Names() {
+		if _, ok := defaults[name]; !ok {
+			t.Fatalf("no built-in template %s", name)
+		}
+	}
+
+	got := Render(Decide, DecideData{Actions: "Valid actions:\n- click: Click\n"})
+	if !strings.HasPrefix(got, "You are an intelligent web automation agent.") {
+		t.Errorf("unexpected decide prompt start: %q", got)
+	}
+	if !strings.Contains(got, "- click: Click\n\nIMPORTANT INSTRUCTIONS:") {
+		t.Errorf("actions not rendered into decide prompt: %q", got)
+	}
+	if strings.HasSuffix(got, "\n") {
+		t.Errorf("trailing newline not trimmed: %q", got)
+	}
+
+	plan := Render(Plan, PlanData{Task: "buy milk", Page: "Title: Shop"})
+	if !strings.Contains(plan, `Given the high-level task: "buy milk"`) || !strings.Contains(plan, "Title: Shop") {
+		t.Errorf("plan data not rendered: %q", plan)
+	}
+}
+
+func TestLoadOverrides(t *testing.T) {
+	t.Cleanup(ResetOverrides)
+	dir := t.TempDir()
+	override := `{{.Actions}}{{if contains .URL "shop.example"}}Apply the coupon first.{{end}}`
+	if err := os.WriteFile(filepath.Join(dir, "decide.tmpl"), []byte(override), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadOverrides(dir); err != nil {
+		t.Fatalf("LoadOverrides failed: %v", err)
+	}
+
+	if got := Render(Decide, DecideData{Actions: "A.", URL: "https://shop.example/cart"}); got != "A.Apply the coupon first." {
+		t.Errorf("override = %q", got)
+	}
+	if got := Render(Decide, DecideData{Actions: "A.", URL: "https://other.example"}); got != "A." {
+		t.Errorf("override = %q", got)
+	}
+	if got := Render(DecideStep, DecideData{}); !strings.Contains(got, "single concise action") {
+		t.Errorf("template without override lost its default: %q", got)
+	}
+
+	ResetOverrides()
+	if got := Render(Decide, DecideData{}); !strings.Contains(got, "IMPORTANT INSTRUCTIONS") {
+		t.Errorf("ResetOverrides kept the override: %q", got)
+	}
+}
+
+func TestLoadOverridesRejectsBadFiles(t *testing.T) {
+	t.Cleanup(ResetOverrides)
+	for name, text := range map[string]string{
+		"decied.tmpl": "typo",
+		"plan.tmpl":   "{{.Task",
+	} {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := LoadOverrides(dir); err == nil {
+			t.Errorf("LoadOverrides accepted %s", name)
+		}
+	}
+}
+
+func TestRenderFallsBackOnExecutionError(t *testing.T) {
+	t.Cleanup(ResetOverrides)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "plan.tmpl"), []byte("{{.Missing}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadOverrides(dir); err != nil {
+		t.Fatalf("LoadOverrides failed: %v", err)
+	}
+	if got := Render(Plan, PlanData{Task: "t"}); !strings.Contains(got, "You are a planner") {
+		t.Errorf("expected the default plan prompt, got %q", got)
+	}
+}
@@ -0,0 +1,46 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// Wednesday, 15 May 2024.
+	from := time.Date(2024, 5, 15, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, 5, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 8 * * *", time.Date(2024, 5, 16, 8, 0, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2024, 5, 16, 10, 30, 0, 0, time.UTC)},
+		{"0 9 * * mon-fri", time.Date(2024, 5, 16, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * sat,7", time.Date(2024, 5, 18, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 20 * mon", time.Date(2024, 5, 20, 12, 0, 0, 0, time.UTC)},
+		{"5/20 11 * * *", time.Date(2024, 5, 15, 11, 5, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 5, 15, 11, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, 5, 19, 0, 0, 0, 0, time.UTC)},
+		{"@every 90m", time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)},
+		{"0 0 30 feb *", time.Time{}},
+	}
+	for _, tt := range tests {
+		schedule, err := ParseSchedule(tt.spec)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q): %v", tt.spec, err)
+			continue
+		}
+		if got := schedule.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q: next run %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "0 0 0 * *", "* * * foo *", "*/0 * * * *", "5-1 * * * *", "@every 10s", "@every soon"} {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("expected %q to be rejected", spec)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+// Package scheduler runs tasks on recurring schedules, e.g. a price check
+// every morning. Schedules are kept in a JSON file, so they survive
+// restarts, and every run can leave a JSON report.
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VolodyaPopov923/AIBot/internal/agent"
+)
+
+// ErrUnknownJob is returned for an ID no job has.
+var ErrUnknownJob = errors.New("unknown scheduled task")
+
+// Runner executes a single task. *agent.Agent implements it.
+type Runner interface {
+	ExecuteTask(ctx context.Context, task string, initialURL string) (*agent.TaskResult, error)
+}
+
+// Job is a task registered to run on a schedule.
+type Job struct {
+	ID        string     `json:"id"`
+	Schedule  string     `json:"schedule"` // cron expression, see ParseSchedule
+	Task      string     `json:"task"`
+	URL       string     `json:"url,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	NextRun   time.Time  `json:"next_run"`
+	LastRun   *time.Time `json:"last_run,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+	// LastAnswer is the answer of the last run, to notice when it changes.
+	LastAnswer string `json:"last_answer,omitempty"`
+
+	schedule Schedule
+}
+
+// Report is what one scheduled run produced.
+type Report struct {
+	Job   string    `json:"job"`
+	Task  string    `json:"task"`
+	RanAt time.Time `json:"ran_at"`
+	// Changed is set when the answer differs from the previous run's, e.g.
+	// a price moved.
+	Changed bool              `json:"changed"`
+	Error   string            `json:"error,omitempty"`
+	Result  *agent.TaskResult `json:"result,omitempty"`
+}
+
+// Scheduler runs jobs when they are due, one at a time. It is safe for
+// concurrent use.
+type Scheduler struct {
+	runner Runner
+	path   string
+
+	mu   sync.Mutex
+	jobs []*Job
+	wake chan struct{}
+
+	// ReportDir, if set, receives a JSON report of every run, in a directory
+	// per job.
+	ReportDir string
+	// OnReport, if set, is called after every run.
+	OnReport func(report Report)
+
+	now func() time.Time
+}
+
+// New loads the jobs stored at path; a missing file means no jobs. Call Run
+// to start running them. A job that was due while nothing was running runs
+// once as soon as Run starts.
+func New(path string, runner Runner) (*Scheduler, error) {
+	s := &Scheduler{runner: runner, path: path, wake: make(chan struct{}, 1), now: time.Now}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedules: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.jobs); err != nil {
+		return nil, fmt.Errorf("failed to parse schedules %s: %w", path, err)
+	}
+	for _, job := range s.jobs {
+		if job.schedule, err = ParseSchedule(job.Schedule); err != nil {
+			return nil, fmt.Errorf("scheduled task %s: %w", job.ID, err)
+		}
+	}
+	return s, nil
+}
+
+// DefaultPath is the schedules file under the user's config dir.
+func DefaultPath() string {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, "aibot", "schedules.json")
+}
+
+// Add registers a task to run on schedule and saves it.
+func (s *Scheduler) Add(schedule, task, url string) (Job, error) {
+	if strings.TrimSpace(task) == "" {
+		return Job{}, errors.New("task is required")
+	}
+	parsed, err := ParseSchedule(schedule)
+	if err != nil {
+		return Job{}, err
+	}
+	now := s.now()
+	job := &Job{
+		ID:        newJobID(),
+		Schedule:  schedule,
+		Task:      task,
+		URL:       url,
+		CreatedAt: now,
+		NextRun:   parsed.Next(now),
+		schedule:  parsed,
+	}
+	if job.NextRun.IsZero() {
+		return Job{}, fmt.Errorf("schedule %q never runs", schedule)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, job)
+	if err := s.save(); err != nil {
+		s.jobs = s.jobs[:len(s.jobs)-1]
+		return Job{}, err
+	}
+	s.notify()
+	return *job, nil
+}
+
+// Remove deletes a job. A run in progress finishes.
+func (s *Scheduler) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, job := range s.jobs {
+		if job.ID == id {
+			s.jobs = append(s.jobs[:i:i], s.jobs[i+1:]...)
+			return s.save()
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownJob, id)
+}
+
+// Jobs returns the registered jobs, soonest first.
+func (s *Scheduler) Jobs() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, *job)
+	}
+	sort.SliceStable(jobs, func(i, j int) bool { return jobs[i].NextRun.Before(jobs[j].NextRun) })
+	return jobs
+}
+
+// Run runs jobs as they come due until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		next := s.runDue(ctx)
+		var timer *time.Timer
+		var fire <-chan time.Time
+		if !next.IsZero() {
+			timer = time.NewTimer(next.Sub(s.now()))
+			fire = timer.C
+		}
+		select {
+		case <-ctx.Done():
+		case <-fire:
+		case <-s.wake:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// runDue runs the jobs that are due and returns when the next one is.
+func (s *Scheduler) runDue(ctx context.Context) time.Time {
+	s.mu.Lock()
+	var due []*Job
+	now := s.now()
+	for _, job := range s.jobs {
+		if !job.NextRun.IsZero() && !job.NextRun.After(now) {
+			due = append(due, job)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, job := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		s.run(ctx, job)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var next time.Time
+	for _, job := range s.jobs {
+		if !job.NextRun.IsZero() && (next.IsZero() || job.NextRun.Before(next)) {
+			next = job.NextRun
+		}
+	}
+	return next
+}
+
+func (s *Scheduler) run(ctx context.Context, job *Job) {
+	s.mu.Lock()
+	id, task, url := job.ID, job.Task, job.URL
+	s.mu.Unlock()
+
+	log.Printf("⏰ Running scheduled task %s: %s\n", id, task)
+	ranAt := s.now()
+	result, err := s.runner.ExecuteTask(ctx, task, url)
+	report := Report{Job: id, Task: task, RanAt: ranAt, Result: result}
+	if err != nil {
+		report.Error = err.Error()
+		log.Printf("Warning: scheduled task %s failed: %v\n", id, err)
+	}
+
+	s.mu.Lock()
+	job.LastRun = &ranAt
+	job.LastError = report.Error
+	if err == nil && result != nil {
+		report.Changed = job.LastAnswer != "" && result.Answer != job.LastAnswer
+		job.LastAnswer = result.Answer
+	}
+	job.NextRun = job.schedule.Next(s.now())
+	if job.NextRun.IsZero() {
+		log.Printf("Warning: scheduled task %s will not run again\n", id)
+	}
+	if err := s.save(); err != nil {
+		log.Printf("Warning: %v\n", err)
+	}
+	s.mu.Unlock()
+
+	if report.Changed {
+		log.Printf("🔔 Scheduled task %s has a new answer: %s\n", id, result.Answer)
+	}
+	if err := s.writeReport(report); err != nil {
+		log.Printf("Warning: %v\n", err)
+	}
+	if s.OnReport != nil {
+		s.OnReport(report)
+	}
+}
+
+func (s *Scheduler) writeReport(report Report) error {
+	if s.ReportDir == "" {
+		return nil
+	}
+	dir := filepath.Join(s.ReportDir, report.Job)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to save report: %w", err)
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, report.RanAt.Format("20060102-150405")+".json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to save report: %w", err)
+	}
+	return nil
+}
+
+// save writes the jobs atomically; the caller holds mu. Jobs that will not
+// run again are kept, so their last result stays visible.
+func (s *Scheduler) save() error {
+	data, err := json.MarshalIndent(s.jobs, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("failed to save schedules: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to save schedules: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to save schedules: %w", err)
+	}
+	return nil
+}
+
+// notify wakes Run to reconsider the next due time.
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func newJobID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
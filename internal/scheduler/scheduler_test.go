@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/VolodyaPopov923/AIBot/internal/agent"
+)
+
+// priceRunner answers with the next price on every run.
+type priceRunner struct {
+	prices []string
+	tasks  []string
+}
+
+func (r *priceRunner) ExecuteTask(ctx context.Context, task, url string) (*agent.TaskResult, error) {
+	r.tasks = append(r.tasks, task)
+	price := r.prices[0]
+	r.prices = r.prices[1:]
+	return &agent.TaskResult{Task: task, Success: true, Answer: price}, nil
+}
+
+func TestSchedulerRunsAndPersistsJobs(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "schedules.json")
+	clock := time.Date(2024, 5, 15, 7, 0, 0, 0, time.UTC)
+	runner := &priceRunner{prices: []string{"100", "100", "90"}}
+
+	s, err := New(path, runner)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	s.now = func() time.Time { return clock }
+	s.ReportDir = filepath.Join(dir, "reports")
+	var reports []Report
+	s.OnReport = func(report Report) { reports = append(reports, report) }
+
+	job, err := s.Add("0 8 * * *", "check the price", "https://shop.example/item")
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if want := time.Date(2024, 5, 15, 8, 0, 0, 0, time.UTC); !job.NextRun.Equal(want) {
+		t.Fatalf("next run %v, want %v", job.NextRun, want)
+	}
+	if _, err := s.Add("not a schedule", "x", ""); err == nil {
+		t.Fatalf("expected an invalid schedule to be rejected")
+	}
+
+	if next := s.runDue(context.Background()); len(runner.tasks) != 0 || !next.Equal(job.NextRun) {
+		t.Fatalf("nothing should run before 8:00 (ran %v, next %v)", runner.tasks, next)
+	}
+	for day := 0; day < 3; day++ {
+		clock = time.Date(2024, 5, 15+day, 8, 0, 0, 0, time.UTC)
+		s.runDue(context.Background())
+	}
+	if len(reports) != 3 || reports[0].Changed || reports[1].Changed || !reports[2].Changed {
+		t.Fatalf("unexpected reports: %+v", reports)
+	}
+	files, _ := os.ReadDir(filepath.Join(dir, "reports", job.ID))
+	if len(files) != 3 {
+		t.Fatalf("expected a report file per run, got %d", len(files))
+	}
+
+	// A restart keeps the job, and a run missed while stopped happens once.
+	reloaded, err := New(path, runner)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	jobs := reloaded.Jobs()
+	if len(jobs) != 1 || jobs[0].LastAnswer != "90" || !jobs[0].NextRun.Equal(time.Date(2024, 5, 18, 8, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected reloaded jobs: %+v", jobs)
+	}
+	runner.prices = []string{"90"}
+	reloaded.now = func() time.Time { return time.Date(2024, 5, 20, 12, 0, 0, 0, time.UTC) }
+	reloaded.runDue(context.Background())
+	if len(runner.tasks) != 4 || reloaded.Jobs()[0].NextRun.Day() != 21 {
+		t.Fatalf("missed run should catch up once: ran %d, %+v", len(runner.tasks), reloaded.Jobs())
+	}
+
+	if err := reloaded.Remove(job.ID); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if err := reloaded.Remove(job.ID); !errors.Is(err, ErrUnknownJob) {
+		t.Fatalf("expected ErrUnknownJob, got %v", err)
+	}
+	if again, _ := New(path, runner); len(again.Jobs()) != 0 {
+		t.Fatalf("removed job came back: %+v", again.Jobs())
+	}
+}
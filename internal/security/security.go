@@ -3,6 +3,7 @@ package security
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 )
@@ -19,9 +20,17 @@ type Validator struct {
 }
 
 func NewValidator() *Validator {
-	return &Validator{
-		reader: bufio.NewReader(os.Stdin),
+	return NewValidatorWithReader(os.Stdin)
+}
+
+// NewValidatorWithReader creates a validator that reads confirmations from r.
+// A *bufio.Reader is read as it is, so it can be shared with other readers.
+func NewValidatorWithReader(r io.Reader) *Validator {
+	reader, ok := r.(*bufio.Reader)
+	if !ok {
+		reader = bufio.NewReader(r)
 	}
+	return &Validator{reader: reader}
 }
 
 func (v *Validator) IsDestructive(action string) bool {
@@ -0,0 +1,546 @@
+// Package server exposes the agent over HTTP so other services can submit
+// browser tasks and poll their progress.
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VolodyaPopov923/AIBot/internal/agent"
+)
+
+// queueSize bounds how many submitted tasks may wait for the browser.
+const queueSize = 64
+
+// Runner executes a single task. *agent.Agent implements it.
+type Runner interface {
+	ExecuteTask(ctx context.Context, task string, initialURL string) (*agent.TaskResult, error)
+}
+
+// IsolatedRunner can also run a task in a fresh incognito context. Runners
+// that implement it accept tasks submitted with "isolated": true.
+type IsolatedRunner interface {
+	ExecuteTaskIsolated(ctx context.Context, task string, initialURL string) (*agent.TaskResult, error)
+}
+
+// Pauser can suspend the running task between steps, e.g. so someone can log
+// in by hand. Runners that implement it accept pause and resume requests.
+type Pauser interface {
+	Pause() <-chan struct{}
+	Resume()
+}
+
+// TaskStatus is the lifecycle state of a submitted task.
+type TaskStatus string
+
+const (
+	StatusQueued    TaskStatus = "queued"
+	StatusRunning   TaskStatus = "running"
+	StatusPaused    TaskStatus = "paused"
+	StatusSucceeded TaskStatus = "succeeded"
+	StatusFailed    TaskStatus = "failed"
+	StatusCancelled TaskStatus = "cancelled"
+)
+
+// Event is a single progress entry of a task: a lifecycle change recorded by
+// the server or a step-level event published by the agent.
+type Event struct {
+	Seq     int       `json:"seq"`
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Step    int       `json:"step,omitempty"`
+	Action  string    `json:"action,omitempty"`
+	URL     string    `json:"url,omitempty"`
+	Message string    `json:"message,omitempty"`
+	// Steps are the steps of the plan in a plan_ready event.
+	Steps []string `json:"steps,omitempty"`
+}
+
+// Task is a submitted task and its current state.
+type Task struct {
+	ID         string            `json:"id"`
+	Task       string            `json:"task"`
+	URL        string            `json:"url,omitempty"`
+	Isolated   bool              `json:"isolated,omitempty"`
+	Status     TaskStatus        `json:"status"`
+	CreatedAt  time.Time         `json:"created_at"`
+	StartedAt  *time.Time        `json:"started_at,omitempty"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+	Result     *agent.TaskResult `json:"result,omitempty"`
+	// Confirmation is the action waiting for approve or deny, if any.
+	Confirmation *Confirmation `json:"confirmation,omitempty"`
+	events       []Event
+	// decided receives the answer to Confirmation.
+	decided chan bool
+	// cancel stops the running task; cancelled records that it was asked to.
+	cancel    context.CancelFunc
+	cancelled bool
+	// updated is closed and replaced whenever an event is added, waking streams.
+	updated chan struct{}
+}
+
+func (t *Task) finished() bool {
+	return t.Status == StatusSucceeded || t.Status == StatusFailed || t.Status == StatusCancelled
+}
+
+// Server queues tasks and runs them one at a time, since they share a single browser.
+type Server struct {
+	runner Runner
+	// Webhooks receive a WebhookPayload for every task event. Set them
+	// before calling Run.
+	Webhooks []string
+	// WebhookSecret, if set, signs payloads with HMAC-SHA256 in the
+	// X-Signature-256 header ("sha256=<hex>").
+	WebhookSecret string
+	webhooks      chan WebhookPayload
+	// UI serves the web dashboard; Screenshot, if set, feeds its live view
+	// of the browser. It is called while a task drives the browser, so it
+	// must only look at the page, e.g. with browser.Manager.CaptureViewport.
+	UI         bool
+	Screenshot func(ctx context.Context) ([]byte, error)
+	// Token, if set, must come with every request but the dashboard page,
+	// as "Authorization: Bearer <token>" or a token query parameter (which
+	// the dashboard uses for its screenshots). Set it before calling Handler.
+	Token string
+
+	mu      sync.Mutex
+	tasks   map[string]*Task
+	queue   chan *Task
+	current *Task // the task the agent is running, if it is one of ours
+}
+
+// New creates a server that executes tasks with runner. Call Run to start
+// processing the queue.
+func New(runner Runner) *Server {
+	return &Server{
+		runner:   runner,
+		tasks:    make(map[string]*Task),
+		queue:    make(chan *Task, queueSize),
+		webhooks: make(chan WebhookPayload, webhookQueueSize),
+	}
+}
+
+// Run processes queued tasks until ctx is cancelled.
+func (s *Server) Run(ctx context.Context) {
+	if len(s.Webhooks) > 0 {
+		go s.deliverWebhooks(ctx)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case task := <-s.queue:
+			s.execute(ctx, task)
+		}
+	}
+}
+
+func (s *Server) execute(ctx context.Context, task *Task) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	s.mu.Lock()
+	if task.cancelled {
+		// Cancelled while it was still queued.
+		s.mu.Unlock()
+		return
+	}
+	task.cancel = cancel
+	s.mu.Unlock()
+
+	// The task stays queued until the agent starts it: a scheduled task may
+	// hold the browser meanwhile, and its events are not this task's.
+	ctx = agent.WithEvents(ctx, func(event agent.Event) { s.publish(task, event) })
+	run := s.runner.ExecuteTask
+	if isolated, ok := s.runner.(IsolatedRunner); ok && task.Isolated {
+		run = isolated.ExecuteTaskIsolated
+	}
+	result, err := run(ctx, task.Task, task.URL)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current == task {
+		s.current = nil
+		if pauser, ok := s.runner.(Pauser); ok {
+			// Drop a pause that arrived too late for the task to reach it.
+			pauser.Resume()
+		}
+	}
+	task.cancel = nil
+	if task.finished() {
+		// Cancelled before the agent got to it.
+		return
+	}
+	finished := time.Now()
+	task.FinishedAt = &finished
+	task.Result = result
+	if err != nil && task.cancelled {
+		task.Status = StatusCancelled
+		s.addEventLocked(task, Event{Type: "cancelled", Message: err.Error()})
+		return
+	}
+	if err != nil {
+		task.Status = StatusFailed
+		s.addEventLocked(task, Event{Type: "failed", Message: err.Error()})
+		return
+	}
+	task.Status = StatusSucceeded
+	s.addEventLocked(task, Event{Type: "succeeded"})
+}
+
+// publish records an event of task, which the agent reports through the
+// context the task runs with. The task becomes the running one when the
+// agent starts it.
+func (s *Server) publish(task *Task, event agent.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if task.finished() {
+		return
+	}
+	switch event.Type {
+	case agent.EventTaskStarted:
+		now := time.Now()
+		task.Status = StatusRunning
+		task.StartedAt = &now
+		s.current = task
+		// The server's own started event stands for it.
+		s.addEventLocked(task, Event{Type: "started"})
+		return
+	case agent.EventPaused:
+		task.Status = StatusPaused
+	case agent.EventResumed:
+		task.Status = StatusRunning
+	}
+	s.addEventLocked(task, Event{
+		Type:    string(event.Type),
+		Step:    event.Step,
+		Action:  event.Action,
+		URL:     event.URL,
+		Message: event.Message,
+		Steps:   event.Steps,
+	})
+}
+
+// addEventLocked appends an event and wakes any streams; s.mu must be held.
+func (s *Server) addEventLocked(task *Task, event Event) {
+	event.Seq = len(task.events) + 1
+	event.Time = time.Now()
+	task.events = append(task.events, event)
+	if task.updated != nil {
+		close(task.updated)
+	}
+	task.updated = make(chan struct{})
+	s.queueWebhookLocked(task, event)
+}
+
+// Handler returns the HTTP API:
+//
+//	POST /tasks              submit {"task": "...", "url": "...", "isolated": false};
+//	                         returns the queued task. Isolated tasks run in a
+//	                         fresh incognito context instead of the profile.
+//	GET  /tasks/{id}         task status and, once finished, its result
+//	GET  /tasks/{id}/events  progress events; ?since=N returns only newer ones.
+//	                         With ?stream=1 or "Accept: text/event-stream" the
+//	                         events are streamed live (Server-Sent Events).
+//	POST /tasks/{id}/cancel  cancels a queued or running task
+//	POST /tasks/{id}/pause   pauses the running task before its next step
+//	POST /tasks/{id}/resume  resumes a paused task
+//	POST /tasks/{id}/approve lets the action awaiting confirmation run
+//	POST /tasks/{id}/deny    refuses it; the agent tries something else
+//	GET  /tasks              all tasks, newest first
+//
+// With UI set it also serves the dashboard at / and the current page at
+// GET /screenshot.
+//
+// POSTs must be JSON ("Content-Type: application/json"). A web page can only
+// send that cross-site after a CORS preflight, which this API never allows,
+// so other sites the user visits cannot submit or control tasks.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tasks", s.handleTasks)
+	mux.HandleFunc("/tasks/", s.handleTask)
+	if s.UI {
+		mux.HandleFunc("/", s.handleUI)
+		mux.HandleFunc("/screenshot", s.handleScreenshot)
+	}
+	return s.guard(mux)
+}
+
+// guard lets through only same-origin JSON POSTs and, if Token is set,
+// requests that carry it. Approving a payment from the dashboard thus needs
+// both the dashboard's own page and the token.
+func (s *Server) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+				writeError(w, http.StatusUnsupportedMediaType, "use Content-Type: application/json")
+				return
+			}
+			if !sameOrigin(r) {
+				writeError(w, http.StatusForbidden, "cross-origin requests are not allowed")
+				return
+			}
+		}
+		if s.Token != "" && r.URL.Path != "/" && !s.authorized(r) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "missing or wrong token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// sameOrigin reports whether r comes from a page served by this server, or
+// from a client that is not a browser: browsers send Origin with every POST,
+// other clients usually do not.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return r.Header.Get("Sec-Fetch-Site") != "cross-site"
+	}
+	parsed, err := url.Parse(origin)
+	return err == nil && parsed.Host == r.Host
+}
+
+// authorized reports whether r carries Token.
+func (s *Server) authorized(r *http.Request) bool {
+	token := r.URL.Query().Get("token")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		token = bearer
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) == 1
+}
+
+func (s *Server) handleTasks(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		writeJSON(w, http.StatusOK, s.list())
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "use POST to submit a task")
+		return
+	}
+
+	var req struct {
+		Task     string `json:"task"`
+		URL      string `json:"url"`
+		Isolated bool   `json:"isolated"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid JSON: %v", err))
+		return
+	}
+	if strings.TrimSpace(req.Task) == "" {
+		writeError(w, http.StatusBadRequest, "task is required")
+		return
+	}
+	if _, ok := s.runner.(IsolatedRunner); req.Isolated && !ok {
+		writeError(w, http.StatusBadRequest, "isolated tasks are not supported")
+		return
+	}
+
+	task := &Task{
+		ID:        newTaskID(),
+		Task:      req.Task,
+		URL:       req.URL,
+		Isolated:  req.Isolated,
+		Status:    StatusQueued,
+		CreatedAt: time.Now(),
+	}
+
+	s.mu.Lock()
+	select {
+	case s.queue <- task:
+	default:
+		s.mu.Unlock()
+		writeError(w, http.StatusServiceUnavailable, "task queue is full")
+		return
+	}
+	s.tasks[task.ID] = task
+	s.addEventLocked(task, Event{Type: "queued"})
+	snapshot := *task
+	s.mu.Unlock()
+
+	log.Printf("Queued task %s: %s\n", task.ID, task.Task)
+	writeJSON(w, http.StatusAccepted, snapshot)
+}
+
+func (s *Server) handleTask(w http.ResponseWriter, r *http.Request) {
+	id, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/tasks/"), "/")
+	switch rest {
+	case "cancel", "pause", "resume", "approve", "deny":
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "use POST")
+			return
+		}
+		s.controlTask(w, id, rest)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "use GET")
+		return
+	}
+
+	s.mu.Lock()
+	task, ok := s.tasks[id]
+	var snapshot Task
+	var events []Event
+	if ok {
+		snapshot = *task
+		events = append([]Event(nil), task.events...)
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "unknown task")
+		return
+	}
+
+	switch rest {
+	case "":
+		writeJSON(w, http.StatusOK, snapshot)
+	case "events":
+		since, _ := strconv.Atoi(r.URL.Query().Get("since"))
+		if r.URL.Query().Get("stream") != "" || strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+			s.streamEvents(w, r, task, since)
+			return
+		}
+		newer := make([]Event, 0, len(events))
+		for _, event := range events {
+			if event.Seq > since {
+				newer = append(newer, event)
+			}
+		}
+		writeJSON(w, http.StatusOK, newer)
+	default:
+		writeError(w, http.StatusNotFound, "unknown endpoint")
+	}
+}
+
+// controlTask cancels, pauses or resumes a task, or answers its
+// confirmation, and returns its state.
+func (s *Server) controlTask(w http.ResponseWriter, id, command string) {
+	pauser, canPause := s.runner.(Pauser)
+	if (command == "pause" || command == "resume") && !canPause {
+		writeError(w, http.StatusBadRequest, "pausing tasks is not supported")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	task, ok := s.tasks[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "unknown task")
+		return
+	}
+	if task.finished() {
+		writeError(w, http.StatusConflict, fmt.Sprintf("task already %s", task.Status))
+		return
+	}
+
+	switch command {
+	case "cancel":
+		task.cancelled = true
+		if task.Status == StatusQueued {
+			finished := time.Now()
+			task.FinishedAt = &finished
+			task.Status = StatusCancelled
+			s.addEventLocked(task, Event{Type: "cancelled"})
+		}
+		if task.cancel != nil {
+			task.cancel()
+		}
+	case "pause", "resume":
+		if task != s.current {
+			writeError(w, http.StatusConflict, "task is not running")
+			return
+		}
+		if command == "pause" {
+			pauser.Pause()
+		} else {
+			pauser.Resume()
+		}
+	case "approve", "deny":
+		if task.Confirmation == nil {
+			writeError(w, http.StatusConflict, "no action is awaiting confirmation")
+			return
+		}
+		task.decided <- command == "approve"
+		task.Confirmation = nil
+	}
+	log.Printf("Task %s: %s requested\n", task.ID, command)
+	writeJSON(w, http.StatusAccepted, *task)
+}
+
+// streamEvents sends the task's events as Server-Sent Events until the task
+// finishes or the client disconnects.
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request, task *Task, since int) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	for {
+		s.mu.Lock()
+		pending := make([]Event, 0)
+		for _, event := range task.events {
+			if event.Seq > since {
+				pending = append(pending, event)
+			}
+		}
+		done := task.finished()
+		updated := task.updated
+		s.mu.Unlock()
+
+		for _, event := range pending {
+			data, _ := json.Marshal(event)
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Type, data)
+			since = event.Seq
+		}
+		flusher.Flush()
+		if done {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-updated:
+		}
+	}
+}
+
+func newTaskID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Warning: failed to write response: %v\n", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
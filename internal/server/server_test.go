@@ -0,0 +1,588 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/VolodyaPopov923/AIBot/internal/agent"
+	"github.com/VolodyaPopov923/AIBot/internal/security"
+)
+
+type fakeRunner struct{}
+
+func (fakeRunner) ExecuteTask(ctx context.Context, task, url string) (*agent.TaskResult, error) {
+	agent.Emit(ctx, agent.Event{Type: agent.EventTaskStarted, Message: task})
+	if task == "fail" {
+		return &agent.TaskResult{Task: task, Error: "boom"}, errors.New("boom")
+	}
+	return &agent.TaskResult{Task: task, Success: true, Answer: "Red Square"}, nil
+}
+
+func submit(t *testing.T, ts *httptest.Server, body string) (int, Task) {
+	t.Helper()
+	resp, err := http.Post(ts.URL+"/tasks", "application/json", bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("POST /tasks failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var task Task
+	_ = json.NewDecoder(resp.Body).Decode(&task)
+	return resp.StatusCode, task
+}
+
+func waitForStatus(t *testing.T, ts *httptest.Server, id string, want TaskStatus) Task {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		resp, err := http.Get(ts.URL + "/tasks/" + id)
+		if err != nil {
+			t.Fatalf("GET task failed: %v", err)
+		}
+		var task Task
+		_ = json.NewDecoder(resp.Body).Decode(&task)
+		resp.Body.Close()
+		if task.Status == want {
+			return task
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("task %s never reached status %s", id, want)
+	return Task{}
+}
+
+func TestServerRunsSubmittedTasks(t *testing.T) {
+	srv := New(fakeRunner{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.Run(ctx)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	status, queued := submit(t, ts, `{"task": "find the Kremlin", "url": "https://yandex.ru/maps"}`)
+	if status != http.StatusAccepted || queued.ID == "" || queued.Status != StatusQueued {
+		t.Fatalf("unexpected submit response: %d %+v", status, queued)
+	}
+	done := waitForStatus(t, ts, queued.ID, StatusSucceeded)
+	if done.Result == nil || done.Result.Answer != "Red Square" || done.FinishedAt == nil {
+		t.Fatalf("unexpected finished task: %+v", done)
+	}
+
+	resp, err := http.Get(ts.URL + "/tasks/" + queued.ID + "/events?since=1")
+	if err != nil {
+		t.Fatalf("GET events failed: %v", err)
+	}
+	var events []Event
+	_ = json.NewDecoder(resp.Body).Decode(&events)
+	resp.Body.Close()
+	if len(events) != 2 || events[0].Type != "started" || events[1].Type != "succeeded" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+
+	_, failing := submit(t, ts, `{"task": "fail"}`)
+	if failed := waitForStatus(t, ts, failing.ID, StatusFailed); failed.Result == nil || failed.Result.Error != "boom" {
+		t.Fatalf("failed task should keep its result: %+v", failed)
+	}
+}
+
+func TestServerRejectsBadRequests(t *testing.T) {
+	ts := httptest.NewServer(New(fakeRunner{}).Handler())
+	defer ts.Close()
+
+	if status, _ := submit(t, ts, `{"url": "https://example.com"}`); status != http.StatusBadRequest {
+		t.Fatalf("missing task should be rejected, got %d", status)
+	}
+	if status, _ := submit(t, ts, `not json`); status != http.StatusBadRequest {
+		t.Fatalf("invalid JSON should be rejected, got %d", status)
+	}
+	resp, err := http.Get(ts.URL + "/tasks/unknown")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("unknown task should be 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestServerRequiresJSONAndToken(t *testing.T) {
+	srv := New(fakeRunner{})
+	srv.Token = "secret"
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	send := func(contentType, auth string) int {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/tasks", bytes.NewBufferString(`{"task": "find the Kremlin"}`))
+		req.Header.Set("Content-Type", contentType)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST /tasks failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	// A cross-site form can post text/plain without a preflight.
+	if status := send("text/plain", "Bearer secret"); status != http.StatusUnsupportedMediaType {
+		t.Fatalf("a text/plain POST should be rejected, got %d", status)
+	}
+	if status := send("application/json", ""); status != http.StatusUnauthorized {
+		t.Fatalf("a POST without the token should be rejected, got %d", status)
+	}
+	if status := send("application/json", "Bearer wrong"); status != http.StatusUnauthorized {
+		t.Fatalf("a POST with a wrong token should be rejected, got %d", status)
+	}
+	if status := send("application/json; charset=utf-8", "Bearer secret"); status != http.StatusAccepted {
+		t.Fatalf("a JSON POST with the token should be accepted, got %d", status)
+	}
+
+	resp, err := http.Get(ts.URL + "/tasks?token=secret")
+	if err != nil {
+		t.Fatalf("GET /tasks failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("the token should also be accepted as a query parameter, got %d", resp.StatusCode)
+	}
+}
+
+// publishingRunner reports agent events while it runs, like the agent does.
+type publishingRunner struct {
+	release chan struct{}
+}
+
+func (p *publishingRunner) ExecuteTask(ctx context.Context, task, url string) (*agent.TaskResult, error) {
+	agent.Emit(ctx, agent.Event{Type: agent.EventTaskStarted, Message: task})
+	<-p.release
+	agent.Emit(ctx, agent.Event{Type: agent.EventDecision, Action: "click", Message: "Open search"})
+	agent.Emit(ctx, agent.Event{Type: agent.EventPageChanged, URL: "https://example.com/results"})
+	return &agent.TaskResult{Task: task, Success: true}, nil
+}
+
+func TestServerStreamsEvents(t *testing.T) {
+	runner := &publishingRunner{release: make(chan struct{})}
+	srv := New(runner)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.Run(ctx)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	_, task := submit(t, ts, `{"task": "search"}`)
+	resp, err := http.Get(ts.URL + "/tasks/" + task.ID + "/events?stream=1")
+	if err != nil {
+		t.Fatalf("stream request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	close(runner.release)
+
+	var types []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if name, ok := strings.CutPrefix(scanner.Text(), "event: "); ok {
+			types = append(types, name)
+		}
+	}
+	want := []string{"queued", "started", "decision", "page_changed", "succeeded"}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Fatalf("streamed events = %v, want %v", types, want)
+	}
+}
+
+// isolatingRunner records which entry point ran each task.
+type isolatingRunner struct {
+	isolated chan bool
+}
+
+func (r isolatingRunner) ExecuteTask(ctx context.Context, task, url string) (*agent.TaskResult, error) {
+	agent.Emit(ctx, agent.Event{Type: agent.EventTaskStarted, Message: task})
+	r.isolated <- false
+	return &agent.TaskResult{Task: task, Success: true}, nil
+}
+
+func (r isolatingRunner) ExecuteTaskIsolated(ctx context.Context, task, url string) (*agent.TaskResult, error) {
+	agent.Emit(ctx, agent.Event{Type: agent.EventTaskStarted, Message: task})
+	r.isolated <- true
+	return &agent.TaskResult{Task: task, Success: true}, nil
+}
+
+func TestServerIsolatedTasks(t *testing.T) {
+	ts := httptest.NewServer(New(fakeRunner{}).Handler())
+	defer ts.Close()
+	if status, _ := submit(t, ts, `{"task": "check prices", "isolated": true}`); status != http.StatusBadRequest {
+		t.Fatalf("expected 400 when the runner cannot isolate tasks, got %d", status)
+	}
+
+	runner := isolatingRunner{isolated: make(chan bool, 2)}
+	srv := New(runner)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.Run(ctx)
+	ts2 := httptest.NewServer(srv.Handler())
+	defer ts2.Close()
+
+	for _, isolated := range []bool{true, false} {
+		body, _ := json.Marshal(map[string]any{"task": "check prices", "isolated": isolated})
+		status, task := submit(t, ts2, string(body))
+		if status != http.StatusAccepted || task.Isolated != isolated {
+			t.Fatalf("submit returned %d, %+v", status, task)
+		}
+		if got := <-runner.isolated; got != isolated {
+			t.Fatalf("task with isolated=%v ran with isolated=%v", isolated, got)
+		}
+	}
+}
+
+// pausingRunner runs until cancelled and, like the agent, reports pauses
+// from its own goroutine.
+type pausingRunner struct {
+	control chan agent.EventType
+}
+
+func (p *pausingRunner) ExecuteTask(ctx context.Context, task, url string) (*agent.TaskResult, error) {
+	agent.Emit(ctx, agent.Event{Type: agent.EventTaskStarted, Message: task})
+	for {
+		select {
+		case <-ctx.Done():
+			return &agent.TaskResult{Task: task, Error: ctx.Err().Error()}, ctx.Err()
+		case event := <-p.control:
+			agent.Emit(ctx, agent.Event{Type: event})
+		}
+	}
+}
+
+func (p *pausingRunner) Pause() <-chan struct{} {
+	p.control <- agent.EventPaused
+	return nil
+}
+
+func (p *pausingRunner) Resume() {
+	select {
+	case p.control <- agent.EventResumed:
+	default:
+	}
+}
+
+func post(t *testing.T, ts *httptest.Server, path string) int {
+	t.Helper()
+	resp, err := http.Post(ts.URL+path, "application/json", nil)
+	if err != nil {
+		t.Fatalf("POST %s failed: %v", path, err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// sharingRunner runs one task at a time like the agent, including tasks
+// started by others, such as the scheduler, that the server knows nothing of.
+type sharingRunner struct {
+	running sync.Mutex
+	hold    chan struct{}
+}
+
+func (r *sharingRunner) ExecuteTask(ctx context.Context, task, url string) (*agent.TaskResult, error) {
+	r.running.Lock()
+	defer r.running.Unlock()
+	agent.Emit(ctx, agent.Event{Type: agent.EventTaskStarted, Message: task})
+	agent.Emit(ctx, agent.Event{Type: agent.EventDecision, Action: "click", Message: task})
+	if task == "scheduled" {
+		<-r.hold
+	}
+	return &agent.TaskResult{Task: task, Success: true}, nil
+}
+
+func (r *sharingRunner) Pause() <-chan struct{} { return nil }
+func (r *sharingRunner) Resume()                {}
+
+func TestServerWaitsForTheBrowser(t *testing.T) {
+	runner := &sharingRunner{hold: make(chan struct{})}
+	srv := New(runner)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.Run(ctx)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	scheduled := make(chan struct{})
+	go func() {
+		defer close(scheduled)
+		_, _ = runner.ExecuteTask(context.Background(), "scheduled", "")
+	}()
+	for runner.running.TryLock() {
+		runner.running.Unlock()
+		time.Sleep(time.Millisecond)
+	}
+
+	_, task := submit(t, ts, `{"task": "mine"}`)
+	time.Sleep(50 * time.Millisecond)
+	if got := waitForStatus(t, ts, task.ID, StatusQueued); got.StartedAt != nil {
+		t.Fatalf("a task waiting for the browser should stay queued: %+v", got)
+	}
+	if status := post(t, ts, "/tasks/"+task.ID+"/pause"); status != http.StatusConflict {
+		t.Fatalf("pausing a task that waits for the browser should conflict, got %d", status)
+	}
+
+	close(runner.hold)
+	<-scheduled
+	waitForStatus(t, ts, task.ID, StatusSucceeded)
+	resp, err := http.Get(ts.URL + "/tasks/" + task.ID + "/events")
+	if err != nil {
+		t.Fatalf("GET events failed: %v", err)
+	}
+	var events []Event
+	_ = json.NewDecoder(resp.Body).Decode(&events)
+	resp.Body.Close()
+	for _, event := range events {
+		if event.Type == string(agent.EventDecision) && event.Message != "mine" {
+			t.Errorf("the task got an event of another: %+v", event)
+		}
+	}
+}
+
+func TestServerControlsTasks(t *testing.T) {
+	runner := &pausingRunner{control: make(chan agent.EventType, 1)}
+	srv := New(runner)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.Run(ctx)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	_, running := submit(t, ts, `{"task": "log in and export"}`)
+	_, queued := submit(t, ts, `{"task": "later"}`)
+	waitForStatus(t, ts, running.ID, StatusRunning)
+
+	if status := post(t, ts, "/tasks/"+queued.ID+"/pause"); status != http.StatusConflict {
+		t.Fatalf("pausing a queued task should conflict, got %d", status)
+	}
+	if status := post(t, ts, "/tasks/"+running.ID+"/pause"); status != http.StatusAccepted {
+		t.Fatalf("pause returned %d", status)
+	}
+	waitForStatus(t, ts, running.ID, StatusPaused)
+	if status := post(t, ts, "/tasks/"+running.ID+"/resume"); status != http.StatusAccepted {
+		t.Fatalf("resume returned %d", status)
+	}
+	waitForStatus(t, ts, running.ID, StatusRunning)
+
+	if status := post(t, ts, "/tasks/"+queued.ID+"/cancel"); status != http.StatusAccepted {
+		t.Fatalf("cancelling a queued task returned %d", status)
+	}
+	waitForStatus(t, ts, queued.ID, StatusCancelled)
+	if status := post(t, ts, "/tasks/"+running.ID+"/cancel"); status != http.StatusAccepted {
+		t.Fatalf("cancel returned %d", status)
+	}
+	if done := waitForStatus(t, ts, running.ID, StatusCancelled); done.Result == nil || done.FinishedAt == nil {
+		t.Fatalf("cancelled task should keep its result: %+v", done)
+	}
+	if status := post(t, ts, "/tasks/"+running.ID+"/cancel"); status != http.StatusConflict {
+		t.Fatalf("cancelling a finished task should conflict, got %d", status)
+	}
+
+	plain := httptest.NewServer(New(fakeRunner{}).Handler())
+	defer plain.Close()
+	if status := post(t, plain, "/tasks/"+running.ID+"/pause"); status != http.StatusBadRequest {
+		t.Fatalf("expected 400 when the runner cannot pause, got %d", status)
+	}
+}
+
+func TestServerWebhooks(t *testing.T) {
+	received := make(chan WebhookPayload, 10)
+	failures := 1
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		mac.Write(body)
+		if r.Header.Get("X-Signature-256") != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+			t.Errorf("bad signature %q", r.Header.Get("X-Signature-256"))
+		}
+		if failures > 0 {
+			// The first delivery fails and must be retried.
+			failures--
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		var payload WebhookPayload
+		_ = json.Unmarshal(body, &payload)
+		received <- payload
+	}))
+	defer hook.Close()
+	webhookRetryDelay = time.Millisecond
+
+	srv := New(fakeRunner{})
+	srv.Webhooks = []string{hook.URL}
+	srv.WebhookSecret = "s3cret"
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.Run(ctx)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	_, task := submit(t, ts, `{"task": "fail"}`)
+	var types []string
+	for len(types) < 3 {
+		select {
+		case payload := <-received:
+			if payload.TaskID != task.ID || payload.Task != "fail" {
+				t.Errorf("payload of another task: %+v", payload)
+			}
+			types = append(types, payload.Event.Type)
+			if payload.Event.Type == "failed" && (payload.Status != StatusFailed || payload.Result == nil || payload.Result.Error != "boom") {
+				t.Errorf("the failed event should carry the result: %+v", payload)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("webhook got only %v", types)
+		}
+	}
+	if strings.Join(types, ",") != "queued,started,failed" {
+		t.Errorf("webhook got %v, want queued, started and failed in order", types)
+	}
+}
+
+func TestParseWebhooks(t *testing.T) {
+	urls, err := ParseWebhooks(" https://a.example/hook, ,http://b.example ")
+	if err != nil || len(urls) != 2 || urls[1] != "http://b.example" {
+		t.Errorf("ParseWebhooks = %v, %v", urls, err)
+	}
+	if _, err := ParseWebhooks("ftp://c.example"); err == nil {
+		t.Error("non-HTTP webhooks should be rejected")
+	}
+}
+
+// confirmingRunner asks the server to confirm one action per task.
+type confirmingRunner struct{ srv *Server }
+
+func (r *confirmingRunner) ExecuteTask(ctx context.Context, task, url string) (*agent.TaskResult, error) {
+	agent.Emit(ctx, agent.Event{Type: agent.EventTaskStarted, Message: task})
+	approved, err := r.srv.Confirm(ctx, security.DestructiveAction{Type: "click", Description: "Pay 100 ₽", Target: "#pay"})
+	if err != nil {
+		return &agent.TaskResult{Task: task}, err
+	}
+	if !approved {
+		return &agent.TaskResult{Task: task, Error: "action denied by user"}, errors.New("action denied by user")
+	}
+	return &agent.TaskResult{Task: task, Success: true, Answer: "paid"}, nil
+}
+
+func TestServerConfirmations(t *testing.T) {
+	runner := &confirmingRunner{}
+	srv := New(runner)
+	runner.srv = srv
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.Run(ctx)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	awaitConfirmation := func(id string) Task {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			task := waitForStatus(t, ts, id, StatusRunning)
+			if task.Confirmation != nil {
+				return task
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("task %s never asked for confirmation", id)
+		return Task{}
+	}
+
+	_, paying := submit(t, ts, `{"task": "pay the bill"}`)
+	task := awaitConfirmation(paying.ID)
+	if c := task.Confirmation; c.Action != "click" || c.Description != "Pay 100 ₽" || c.Target != "#pay" {
+		t.Fatalf("unexpected confirmation: %+v", c)
+	}
+	// Another site the user has open must not be able to approve it.
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/tasks/"+paying.ID+"/approve", nil)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Origin", "https://evil.example")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST approve failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("a cross-origin approve should be refused, got %d", resp.StatusCode)
+	}
+	if task := awaitConfirmation(paying.ID); task.Confirmation == nil {
+		t.Fatalf("the refused approve should leave the action waiting")
+	}
+	req.Header.Set("Origin", ts.URL)
+	if resp, err = http.DefaultClient.Do(req); err != nil {
+		t.Fatalf("POST approve failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("approve from the dashboard's origin returned %d", resp.StatusCode)
+	}
+	if done := waitForStatus(t, ts, paying.ID, StatusSucceeded); done.Result.Answer != "paid" || done.Confirmation != nil {
+		t.Fatalf("approved task should finish: %+v", done)
+	}
+	if status := post(t, ts, "/tasks/"+paying.ID+"/approve"); status != http.StatusConflict {
+		t.Fatalf("approving a finished task should conflict, got %d", status)
+	}
+
+	_, denied := submit(t, ts, `{"task": "pay again"}`)
+	awaitConfirmation(denied.ID)
+	if status := post(t, ts, "/tasks/"+denied.ID+"/deny"); status != http.StatusAccepted {
+		t.Fatalf("deny returned %d", status)
+	}
+	waitForStatus(t, ts, denied.ID, StatusFailed)
+
+	resp, err = http.Get(ts.URL + "/tasks")
+	if err != nil {
+		t.Fatalf("GET /tasks failed: %v", err)
+	}
+	var tasks []Task
+	_ = json.NewDecoder(resp.Body).Decode(&tasks)
+	resp.Body.Close()
+	if len(tasks) != 2 || tasks[0].ID != denied.ID {
+		t.Fatalf("tasks should be listed newest first: %+v", tasks)
+	}
+}
+
+func TestServerUI(t *testing.T) {
+	srv := New(fakeRunner{})
+	off := httptest.NewServer(srv.Handler())
+	defer off.Close()
+	resp, err := http.Get(off.URL + "/")
+	if err != nil {
+		t.Fatalf("GET / failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("the dashboard should be off by default, got %d", resp.StatusCode)
+	}
+
+	srv.UI = true
+	srv.Screenshot = func(ctx context.Context) ([]byte, error) { return []byte("\x89PNG"), nil }
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+	for path, contentType := range map[string]string{"/": "text/html; charset=utf-8", "/screenshot": "image/png"} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != contentType {
+			t.Errorf("GET %s: %d %s", path, resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+	}
+}
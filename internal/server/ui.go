@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+
+	"github.com/VolodyaPopov923/AIBot/internal/security"
+)
+
+//go:embed ui/index.html
+var dashboardPage []byte
+
+// Confirmation is an action of the running task that waits for a person to
+// approve or deny it.
+type Confirmation struct {
+	Action      string `json:"action"`
+	Description string `json:"description"`
+	Target      string `json:"target,omitempty"`
+	Severity    string `json:"severity,omitempty"`
+}
+
+// Confirm holds the running task's action until it is approved or denied
+// over the API or the dashboard; it fits agent.Agent.Confirm.
+func (s *Server) Confirm(ctx context.Context, action security.DestructiveAction) (bool, error) {
+	s.mu.Lock()
+	task := s.current
+	if task == nil {
+		s.mu.Unlock()
+		return false, errors.New("no running task to confirm an action of")
+	}
+	decided := make(chan bool, 1)
+	task.decided = decided
+	task.Confirmation = &Confirmation{
+		Action:      action.Type,
+		Description: action.Description,
+		Target:      action.Target,
+		Severity:    action.Severity,
+	}
+	s.addEventLocked(task, Event{Type: "confirmation_required", Action: action.Type, Message: action.Description})
+	s.mu.Unlock()
+	log.Printf("Task %s: waiting for approval of %s\n", task.ID, action.Type)
+
+	var approved bool
+	select {
+	case approved = <-decided:
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	task.Confirmation = nil
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	verdict := "denied"
+	if approved {
+		verdict = "approved"
+	}
+	s.addEventLocked(task, Event{Type: verdict, Action: action.Type})
+	return approved, nil
+}
+
+// list returns snapshots of all tasks, newest first.
+func (s *Server) list() []Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tasks := make([]Task, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		tasks = append(tasks, *task)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].CreatedAt.After(tasks[j].CreatedAt) })
+	return tasks
+}
+
+func (s *Server) handleUI(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		writeError(w, http.StatusNotFound, "unknown endpoint")
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(dashboardPage)
+}
+
+// handleScreenshot returns a PNG of the browser's current page.
+func (s *Server) handleScreenshot(w http.ResponseWriter, r *http.Request) {
+	if s.Screenshot == nil {
+		writeError(w, http.StatusNotFound, "screenshots are not available")
+		return
+	}
+	image, err := s.Screenshot(r.Context())
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write(image)
+}
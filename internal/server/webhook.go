@@ -0,0 +1,136 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/VolodyaPopov923/AIBot/internal/agent"
+)
+
+// webhookQueueSize bounds how many payloads may wait for delivery; when the
+// receivers fall that far behind, newer payloads are dropped.
+const webhookQueueSize = 256
+
+// webhookAttempts is how often a payload is posted before it is given up.
+const webhookAttempts = 3
+
+// webhookRetryDelay is the wait before the first retry; it doubles after.
+var webhookRetryDelay = time.Second
+
+// WebhookPayload is the JSON body posted to webhooks for every event of a
+// task: its lifecycle (queued, started, succeeded, failed, cancelled) and
+// the agent's steps in between.
+type WebhookPayload struct {
+	TaskID string     `json:"task_id"`
+	Task   string     `json:"task"`
+	Status TaskStatus `json:"status"`
+	Event  Event      `json:"event"`
+	// Result is set once the task has finished.
+	Result *agent.TaskResult `json:"result,omitempty"`
+}
+
+// ParseWebhooks splits a comma-separated list of webhook URLs.
+func ParseWebhooks(list string) ([]string, error) {
+	var urls []string
+	for _, raw := range strings.Split(list, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		if !strings.HasPrefix(raw, "http://") && !strings.HasPrefix(raw, "https://") {
+			return nil, fmt.Errorf("webhook %q is not an http(s) URL", raw)
+		}
+		urls = append(urls, raw)
+	}
+	return urls, nil
+}
+
+// queueWebhookLocked hands an event of task to the delivery loop; s.mu must
+// be held.
+func (s *Server) queueWebhookLocked(task *Task, event Event) {
+	if len(s.Webhooks) == 0 {
+		return
+	}
+	payload := WebhookPayload{TaskID: task.ID, Task: task.Task, Status: task.Status, Event: event}
+	if task.finished() {
+		payload.Result = task.Result
+	}
+	select {
+	case s.webhooks <- payload:
+	default:
+		log.Printf("Warning: webhook queue is full, dropping %s event of task %s\n", event.Type, task.ID)
+	}
+}
+
+// deliverWebhooks posts queued payloads to every webhook, in order, until
+// ctx is cancelled.
+func (s *Server) deliverWebhooks(ctx context.Context) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case payload := <-s.webhooks:
+			body, err := json.Marshal(payload)
+			if err != nil {
+				log.Printf("Warning: failed to encode webhook payload: %v\n", err)
+				continue
+			}
+			for _, url := range s.Webhooks {
+				if err := s.postWebhook(ctx, client, url, body); err != nil {
+					log.Printf("Warning: webhook %s: %v\n", url, err)
+				}
+			}
+		}
+	}
+}
+
+// postWebhook posts body to url, retrying failed attempts with backoff.
+func (s *Server) postWebhook(ctx context.Context, client *http.Client, url string, body []byte) error {
+	delay := webhookRetryDelay
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = s.postWebhookOnce(ctx, client, url, body); err == nil || attempt == webhookAttempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (s *Server) postWebhookOnce(ctx context.Context, client *http.Client, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.WebhookSecret != "" {
+		mac := hmac.New(sha256.New, []byte(s.WebhookSecret))
+		mac.Write(body)
+		req.Header.Set("X-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
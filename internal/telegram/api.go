@@ -0,0 +1,192 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the address of the Telegram Bot API.
+const DefaultAPIURL = "https://api.telegram.org"
+
+// pollTimeout is how long a getUpdates call waits for new updates.
+const pollTimeout = 30 * time.Second
+
+// Client calls the methods of the Bot API that the bot needs.
+type Client struct {
+	token string
+	// BaseURL is the API address, DefaultAPIURL unless testing.
+	BaseURL string
+	HTTP    *http.Client
+}
+
+// NewClient creates a client for the bot with token.
+func NewClient(token string) *Client {
+	return &Client{
+		token:   token,
+		BaseURL: DefaultAPIURL,
+		HTTP:    &http.Client{Timeout: pollTimeout + 30*time.Second},
+	}
+}
+
+// Update is an incoming message or button press.
+type Update struct {
+	UpdateID      int64          `json:"update_id"`
+	Message       *Message       `json:"message,omitempty"`
+	CallbackQuery *CallbackQuery `json:"callback_query,omitempty"`
+}
+
+// Message is a chat message.
+type Message struct {
+	MessageID int64  `json:"message_id"`
+	From      *User  `json:"from,omitempty"`
+	Chat      Chat   `json:"chat"`
+	Text      string `json:"text,omitempty"`
+}
+
+// User is a Telegram account.
+type User struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username,omitempty"`
+}
+
+// Chat is where a message was sent.
+type Chat struct {
+	ID int64 `json:"id"`
+}
+
+// CallbackQuery is a press of an inline button.
+type CallbackQuery struct {
+	ID      string   `json:"id"`
+	From    User     `json:"from"`
+	Message *Message `json:"message,omitempty"`
+	Data    string   `json:"data,omitempty"`
+}
+
+// InlineKeyboardMarkup is a row-wise set of buttons under a message.
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
+
+// InlineKeyboardButton sends its callback data back when pressed.
+type InlineKeyboardButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data"`
+}
+
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	Description string          `json:"description"`
+}
+
+// GetUpdates waits for updates after offset, the ID of the last update seen
+// plus one.
+func (c *Client) GetUpdates(ctx context.Context, offset int64) ([]Update, error) {
+	params := map[string]any{
+		"offset":          offset,
+		"timeout":         int(pollTimeout / time.Second),
+		"allowed_updates": []string{"message", "callback_query"},
+	}
+	var updates []Update
+	err := c.call(ctx, "getUpdates", params, &updates)
+	return updates, err
+}
+
+// SendMessage sends text to a chat, with buttons under it if markup is set.
+func (c *Client) SendMessage(ctx context.Context, chatID int64, text string, markup *InlineKeyboardMarkup) (Message, error) {
+	params := map[string]any{"chat_id": chatID, "text": text}
+	if markup != nil {
+		params["reply_markup"] = markup
+	}
+	var sent Message
+	err := c.call(ctx, "sendMessage", params, &sent)
+	return sent, err
+}
+
+// EditMessageText replaces the text of a message and drops its buttons.
+func (c *Client) EditMessageText(ctx context.Context, chatID, messageID int64, text string) error {
+	params := map[string]any{"chat_id": chatID, "message_id": messageID, "text": text}
+	return c.call(ctx, "editMessageText", params, nil)
+}
+
+// AnswerCallbackQuery acknowledges a button press, showing text briefly.
+func (c *Client) AnswerCallbackQuery(ctx context.Context, id, text string) error {
+	params := map[string]any{"callback_query_id": id, "text": text}
+	return c.call(ctx, "answerCallbackQuery", params, nil)
+}
+
+// SendPhoto uploads a PNG or JPEG image to a chat.
+func (c *Client) SendPhoto(ctx context.Context, chatID int64, name string, image []byte, caption string) error {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	_ = form.WriteField("chat_id", fmt.Sprint(chatID))
+	if caption != "" {
+		_ = form.WriteField("caption", caption)
+	}
+	part, err := form.CreateFormFile("photo", name)
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(image); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+	return c.post(ctx, "sendPhoto", form.FormDataContentType(), &body, nil)
+}
+
+func (c *Client) call(ctx context.Context, method string, params any, result any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+	return c.post(ctx, method, "application/json", bytes.NewReader(data), result)
+}
+
+func (c *Client) post(ctx context.Context, method, contentType string, body io.Reader, result any) error {
+	endpoint := fmt.Sprintf("%s/bot%s/%s", strings.TrimRight(c.BaseURL, "/"), c.token, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return c.redact(fmt.Errorf("telegram %s failed: %w", method, err))
+	}
+	defer resp.Body.Close()
+
+	var decoded apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return fmt.Errorf("telegram %s: invalid response (HTTP %d): %w", method, resp.StatusCode, err)
+	}
+	if !decoded.OK {
+		return fmt.Errorf("telegram %s: %s", method, decoded.Description)
+	}
+	if result != nil {
+		if err := json.Unmarshal(decoded.Result, result); err != nil {
+			return fmt.Errorf("telegram %s: invalid result: %w", method, err)
+		}
+	}
+	return nil
+}
+
+// redact removes the token from transport errors, which quote the URL, so
+// it does not end up in logs.
+func (c *Client) redact(err error) error {
+	var urlErr *url.Error
+	if c.token == "" || !errors.As(err, &urlErr) {
+		return err
+	}
+	return errors.New(strings.ReplaceAll(err.Error(), c.token, "<token>"))
+}
@@ -0,0 +1,512 @@
+// Package telegram lets people give the agent tasks through a Telegram bot.
+// A message is a task; the bot answers with the result and screenshots,
+// asks for confirmation of destructive actions with inline buttons, and
+// takes the next message of the chat as the answer to a question.
+package telegram
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/VolodyaPopov923/AIBot/internal/agent"
+	"github.com/VolodyaPopov923/AIBot/internal/security"
+)
+
+// queueSize bounds how many tasks may wait for the browser.
+const queueSize = 16
+
+// maxMessageLength is what Telegram accepts in one message.
+const maxMessageLength = 4096
+
+// maxScreenshots bounds the screenshots of a task sent back to the chat.
+const maxScreenshots = 5
+
+// retryDelay is the pause after a failed poll.
+const retryDelay = 5 * time.Second
+
+// noticeQueueSize bounds the progress messages waiting to be sent; more are
+// dropped rather than hold up the task.
+const noticeQueueSize = 32
+
+// Runner executes a single task. *agent.Agent implements it.
+type Runner interface {
+	ExecuteTask(ctx context.Context, task string, initialURL string) (*agent.TaskResult, error)
+}
+
+// Bot runs the tasks sent to a Telegram bot, one at a time.
+type Bot struct {
+	client  *Client
+	runner  Runner
+	allowed map[string]bool // user IDs and lower-case usernames
+
+	// ParseRequest, if set, turns a message into a task and its start URL.
+	// Without it the message is the task, and a URL it starts with is where
+	// the task starts.
+	ParseRequest func(ctx context.Context, text string) (task, url string, err error)
+	// Screenshot, if set, captures the page a task ended on for the reply.
+	Screenshot func(ctx context.Context) ([]byte, error)
+
+	jobs    chan *job
+	notices chan notice
+
+	mu       sync.Mutex
+	current  *job
+	answers  map[int64]chan string // chats waiting for an answer
+	confirms map[string]chan bool  // open confirmations by button token
+}
+
+// notice is a progress message for a chat.
+type notice struct {
+	chatID int64
+	text   string
+}
+
+type job struct {
+	chatID int64
+	text   string
+	cancel context.CancelFunc
+	// started is set once the agent starts the task; until then it may
+	// wait for a scheduled task to let go of the browser.
+	started bool
+}
+
+// New creates a bot that runs tasks with runner for the allowed users, given
+// by numeric ID or username. Everybody else is told their ID, so the owner
+// can find theirs.
+func New(client *Client, runner Runner, allowed []string) *Bot {
+	b := &Bot{
+		client:   client,
+		runner:   runner,
+		allowed:  map[string]bool{},
+		jobs:     make(chan *job, queueSize),
+		notices:  make(chan notice, noticeQueueSize),
+		answers:  map[int64]chan string{},
+		confirms: map[string]chan bool{},
+	}
+	for _, user := range allowed {
+		if user = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(user), "@")); user != "" {
+			b.allowed[user] = true
+		}
+	}
+	return b
+}
+
+// ParseAllowedUsers splits a comma-separated list of user IDs and usernames.
+func ParseAllowedUsers(list string) []string {
+	var users []string
+	for _, user := range strings.Split(list, ",") {
+		if user = strings.TrimSpace(user); user != "" {
+			users = append(users, user)
+		}
+	}
+	return users
+}
+
+// Run polls for messages and runs their tasks until ctx is cancelled.
+func (b *Bot) Run(ctx context.Context) error {
+	go b.work(ctx)
+	go b.sendNotices(ctx)
+	var offset int64
+	for {
+		updates, err := b.client.GetUpdates(ctx, offset)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Printf("Warning: %v\n", err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(retryDelay):
+			}
+			continue
+		}
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+			switch {
+			case update.Message != nil:
+				b.handleMessage(ctx, update.Message)
+			case update.CallbackQuery != nil:
+				b.handleCallback(ctx, update.CallbackQuery)
+			}
+		}
+	}
+}
+
+func (b *Bot) isAllowed(user *User) bool {
+	if user == nil {
+		return false
+	}
+	return b.allowed[strconv.FormatInt(user.ID, 10)] || (user.Username != "" && b.allowed[strings.ToLower(user.Username)])
+}
+
+func (b *Bot) handleMessage(ctx context.Context, m *Message) {
+	text := strings.TrimSpace(m.Text)
+	if text == "" {
+		return
+	}
+	chatID := m.Chat.ID
+	if !b.isAllowed(m.From) {
+		id := int64(0)
+		if m.From != nil {
+			id = m.From.ID
+		}
+		b.reply(ctx, chatID, fmt.Sprintf("⛔ This bot is private. Your user ID is %d; its owner can add it to TELEGRAM_ALLOWED_USERS.", id))
+		return
+	}
+
+	command, _, _ := strings.Cut(text, " ")
+	switch strings.ToLower(strings.Split(command, "@")[0]) {
+	case "/start", "/help":
+		b.reply(ctx, chatID, helpText)
+		return
+	case "/cancel":
+		b.mu.Lock()
+		current := b.current
+		b.mu.Unlock()
+		if current == nil || current.chatID != chatID {
+			b.reply(ctx, chatID, "Nothing of yours is running.")
+			return
+		}
+		b.reply(ctx, chatID, "⏹ Cancelling the task...")
+		current.cancel()
+		return
+	case "/status":
+		b.reply(ctx, chatID, b.status())
+		return
+	}
+
+	b.mu.Lock()
+	waiting, ok := b.answers[chatID]
+	if ok {
+		delete(b.answers, chatID)
+	}
+	busy := b.current != nil
+	b.mu.Unlock()
+	if ok {
+		waiting <- text
+		return
+	}
+
+	select {
+	case b.jobs <- &job{chatID: chatID, text: text}:
+		if busy {
+			b.reply(ctx, chatID, "⏳ Queued; another task is running.")
+		}
+	default:
+		b.reply(ctx, chatID, "Too many tasks are waiting; try again later.")
+	}
+}
+
+const helpText = `Send me a task in your own words, e.g. "зайди на яндекс карты и найди кремль" or "https://news.ycombinator.com find the top story".
+
+I reply with the result and screenshots. Before anything destructive, such as a payment, I ask with buttons; when I need to know something, answer with your next message.
+
+/status - what is running
+/cancel - stop your running task`
+
+func (b *Bot) status() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.current == nil {
+		return "💤 Idle."
+	}
+	if !b.current.started {
+		return fmt.Sprintf("⏳ Waiting for the browser, busy with a scheduled task: %s\n%d more task(s) waiting.", b.current.text, len(b.jobs))
+	}
+	return fmt.Sprintf("🏃 Running: %s\n%d task(s) waiting.", b.current.text, len(b.jobs))
+}
+
+func (b *Bot) handleCallback(ctx context.Context, q *CallbackQuery) {
+	if !b.isAllowed(&q.From) {
+		_ = b.client.AnswerCallbackQuery(ctx, q.ID, "This bot is private.")
+		return
+	}
+	token, answer, ok := strings.Cut(strings.TrimPrefix(q.Data, "confirm:"), ":")
+	b.mu.Lock()
+	decided, open := b.confirms[token]
+	if open {
+		delete(b.confirms, token)
+	}
+	b.mu.Unlock()
+	if !ok || !open {
+		_ = b.client.AnswerCallbackQuery(ctx, q.ID, "This question has expired.")
+		return
+	}
+
+	approved := answer == "yes"
+	decided <- approved
+	verdict := "❌ Denied"
+	if approved {
+		verdict = "✅ Approved"
+	}
+	if err := b.client.AnswerCallbackQuery(ctx, q.ID, verdict); err != nil {
+		log.Printf("Warning: %v\n", err)
+	}
+	if q.Message != nil {
+		if err := b.client.EditMessageText(ctx, q.Message.Chat.ID, q.Message.MessageID, q.Message.Text+"\n\n"+verdict); err != nil {
+			log.Printf("Warning: %v\n", err)
+		}
+	}
+}
+
+// work runs the queued tasks in order.
+func (b *Bot) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case j := <-b.jobs:
+			b.run(ctx, j)
+		}
+	}
+}
+
+type chatKey struct{}
+
+func (b *Bot) run(ctx context.Context, j *job) {
+	ctx, j.cancel = context.WithCancel(context.WithValue(ctx, chatKey{}, j.chatID))
+	defer j.cancel()
+	b.mu.Lock()
+	b.current = j
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		b.current = nil
+		b.mu.Unlock()
+	}()
+
+	task, url := splitURL(j.text)
+	if b.ParseRequest != nil {
+		var err error
+		if task, url, err = b.ParseRequest(ctx, j.text); err != nil {
+			b.reply(ctx, j.chatID, "🤔 "+err.Error())
+			return
+		}
+	}
+	b.reply(ctx, j.chatID, "📋 Working on it: "+task)
+	// Only this task's events go to the chat, not those of a scheduled task
+	// that holds the browser meanwhile.
+	result, err := b.runner.ExecuteTask(agent.WithEvents(ctx, func(event agent.Event) { b.handleEvent(j, event) }), task, url)
+
+	// Cancelled tasks still get their reply.
+	replyCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Minute)
+	defer cancel()
+	b.reply(replyCtx, j.chatID, formatResult(result, err))
+	b.sendScreenshots(replyCtx, j.chatID, result)
+}
+
+// splitURL takes a URL off the front of a message.
+func splitURL(text string) (task, url string) {
+	first, rest, _ := strings.Cut(text, " ")
+	if (strings.HasPrefix(first, "http://") || strings.HasPrefix(first, "https://")) && strings.TrimSpace(rest) != "" {
+		return strings.TrimSpace(rest), first
+	}
+	return text, ""
+}
+
+func (b *Bot) sendScreenshots(ctx context.Context, chatID int64, result *agent.TaskResult) {
+	if result != nil {
+		for i, path := range result.Screenshots {
+			if i == maxScreenshots {
+				break
+			}
+			image, err := os.ReadFile(path)
+			if err == nil {
+				err = b.client.SendPhoto(ctx, chatID, filepath.Base(path), image, filepath.Base(path))
+			}
+			if err != nil {
+				log.Printf("Warning: failed to send screenshot %s: %v\n", path, err)
+			}
+		}
+	}
+	if b.Screenshot == nil {
+		return
+	}
+	image, err := b.Screenshot(ctx)
+	if err == nil {
+		err = b.client.SendPhoto(ctx, chatID, "page.png", image, "The page the task ended on")
+	}
+	if err != nil {
+		log.Printf("Warning: failed to send the final screenshot: %v\n", err)
+	}
+}
+
+// Confirm asks the chat of the running task whether an action may run, with
+// Yes and No buttons; it fits agent.Agent.Confirm.
+func (b *Bot) Confirm(ctx context.Context, action security.DestructiveAction) (bool, error) {
+	chatID, ok := ctx.Value(chatKey{}).(int64)
+	if !ok {
+		return false, errors.New("no chat to ask for confirmation")
+	}
+	token := newToken()
+	decided := make(chan bool, 1)
+	b.mu.Lock()
+	b.confirms[token] = decided
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		delete(b.confirms, token)
+		b.mu.Unlock()
+	}()
+
+	text := fmt.Sprintf("⚠️ May I %s?\n%s", action.Type, action.Description)
+	if action.Target != "" {
+		text += "\nTarget: " + action.Target
+	}
+	buttons := &InlineKeyboardMarkup{InlineKeyboard: [][]InlineKeyboardButton{{
+		{Text: "✅ Yes", CallbackData: "confirm:" + token + ":yes"},
+		{Text: "❌ No", CallbackData: "confirm:" + token + ":no"},
+	}}}
+	if _, err := b.client.SendMessage(ctx, chatID, truncate(text), buttons); err != nil {
+		return false, err
+	}
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	case approved := <-decided:
+		return approved, nil
+	}
+}
+
+// Ask puts a question to the chat of the running task and returns its next
+// message; it fits agent.Agent.Ask.
+func (b *Bot) Ask(ctx context.Context, question string) (string, error) {
+	chatID, ok := ctx.Value(chatKey{}).(int64)
+	if !ok {
+		return "", errors.New("no chat to ask")
+	}
+	answer := make(chan string, 1)
+	b.mu.Lock()
+	b.answers[chatID] = answer
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		if b.answers[chatID] == answer {
+			delete(b.answers, chatID)
+		}
+		b.mu.Unlock()
+	}()
+
+	if _, err := b.client.SendMessage(ctx, chatID, truncate(question+"\n\nReply to continue."), nil); err != nil {
+		return "", err
+	}
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case text := <-answer:
+		return text, nil
+	}
+}
+
+// handleEvent tells the chat of j about the plan of its task and about
+// CAPTCHAs it waits on.
+func (b *Bot) handleEvent(j *job, event agent.Event) {
+	var text string
+	switch {
+	case event.Type == agent.EventTaskStarted:
+		b.mu.Lock()
+		j.started = true
+		b.mu.Unlock()
+		return
+	case event.Type == agent.EventPlanReady && len(event.Steps) > 0:
+		lines := []string{"🗺 Plan:"}
+		for i, step := range event.Steps {
+			lines = append(lines, fmt.Sprintf("%d. %s", i+1, step))
+		}
+		text = strings.Join(lines, "\n")
+	case event.Type == agent.EventCaptchaWait:
+		text = "🧩 Waiting for a CAPTCHA on " + event.URL + " to be solved in the browser."
+	default:
+		return
+	}
+	// The event comes from the task's goroutine, which a slow or unreachable
+	// Telegram must not hold up.
+	select {
+	case b.notices <- notice{chatID: j.chatID, text: text}:
+	default:
+		log.Printf("Warning: dropped a message to chat %d; too many are waiting to be sent\n", j.chatID)
+	}
+}
+
+// sendNotices sends the queued progress messages until ctx is cancelled.
+func (b *Bot) sendNotices(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-b.notices:
+			sendCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			b.reply(sendCtx, n.chatID, n.text)
+			cancel()
+		}
+	}
+}
+
+func (b *Bot) reply(ctx context.Context, chatID int64, text string) {
+	if _, err := b.client.SendMessage(ctx, chatID, truncate(text), nil); err != nil {
+		log.Printf("Warning: %v\n", err)
+	}
+}
+
+// formatResult describes how a task ended.
+func formatResult(result *agent.TaskResult, err error) string {
+	var lines []string
+	var halted *agent.HaltedActionError
+	switch {
+	case errors.Is(err, context.Canceled):
+		lines = append(lines, "⏹ Task cancelled")
+	case errors.As(err, &halted):
+		lines = append(lines, fmt.Sprintf("⏸ Stopped before %s: %s", halted.Decision.Action, halted.Decision.Reasoning))
+	case err != nil:
+		lines = append(lines, "❌ Task failed: "+err.Error())
+	default:
+		lines = append(lines, "✅ Done")
+	}
+	if result == nil {
+		return strings.Join(lines, "\n")
+	}
+	if result.Answer != "" {
+		lines = append(lines, "💬 "+result.Answer)
+	}
+	for _, item := range result.Extracted {
+		lines = append(lines, "• "+item)
+	}
+	if result.FinalURL != "" {
+		lines = append(lines, "🔗 "+result.FinalURL)
+	}
+	usage := result.TokenUsage
+	cost := "no cost"
+	if usage.CostUSD > 0 {
+		cost = fmt.Sprintf("$%.4f", usage.CostUSD)
+	}
+	lines = append(lines, fmt.Sprintf("📈 %d step(s) · %d tokens · %s · %s", len(result.Steps), usage.TotalTokens, cost, result.Duration.Round(time.Second)))
+	return strings.Join(lines, "\n")
+}
+
+// truncate keeps text within a single message.
+func truncate(text string) string {
+	if utf8.RuneCountInString(text) <= maxMessageLength {
+		return text
+	}
+	return string([]rune(text)[:maxMessageLength-1]) + "…"
+}
+
+func newToken() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,228 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/VolodyaPopov923/AIBot/internal/agent"
+	"github.com/VolodyaPopov923/AIBot/internal/security"
+)
+
+// fakeAPI serves the Bot API methods the bot uses.
+type fakeAPI struct {
+	updates chan Update
+	sent    chan map[string]any // sendMessage parameters
+	// stall, if set, holds up sending the messages containing it until
+	// released is closed.
+	stall    string
+	released chan struct{}
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reply := func(result any) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+	}
+	switch path.Base(r.URL.Path) {
+	case "getUpdates":
+		select {
+		case update := <-f.updates:
+			reply([]Update{update})
+		case <-time.After(20 * time.Millisecond):
+			reply([]Update{})
+		}
+	case "sendMessage":
+		var params map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		if f.stall != "" && strings.Contains(params["text"].(string), f.stall) {
+			select {
+			case <-f.released:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		f.sent <- params
+		reply(Message{MessageID: 1, Chat: Chat{ID: int64(params["chat_id"].(float64))}, Text: params["text"].(string)})
+	default:
+		reply(true)
+	}
+}
+
+// next returns the next sent message containing want.
+func (f *fakeAPI) next(t *testing.T, want string) map[string]any {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case params := <-f.sent:
+			if strings.Contains(params["text"].(string), want) {
+				return params
+			}
+		case <-timeout:
+			t.Fatalf("no message containing %q was sent", want)
+		}
+	}
+}
+
+type runnerFunc func(ctx context.Context, task, url string) (*agent.TaskResult, error)
+
+func (f runnerFunc) ExecuteTask(ctx context.Context, task, url string) (*agent.TaskResult, error) {
+	return f(ctx, task, url)
+}
+
+func startBot(t *testing.T, runner Runner) (*Bot, *fakeAPI) {
+	t.Helper()
+	return startBotWith(t, &fakeAPI{}, runner)
+}
+
+func startBotWith(t *testing.T, api *fakeAPI, runner Runner) (*Bot, *fakeAPI) {
+	t.Helper()
+	api.updates = make(chan Update, 10)
+	api.sent = make(chan map[string]any, 100)
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+
+	client := NewClient("123:secret")
+	client.BaseURL = srv.URL
+	bot := New(client, runner, ParseAllowedUsers("42, @Owner"))
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go bot.Run(ctx)
+	return bot, api
+}
+
+func message(userID int64, text string) Update {
+	return Update{Message: &Message{From: &User{ID: userID}, Chat: Chat{ID: 7}, Text: text}}
+}
+
+func TestBotRunsTasks(t *testing.T) {
+	type call struct{ task, url string }
+	calls := make(chan call, 1)
+	_, api := startBot(t, runnerFunc(func(ctx context.Context, task, url string) (*agent.TaskResult, error) {
+		calls <- call{task, url}
+		return &agent.TaskResult{Answer: "Example Domain", FinalURL: url}, nil
+	}))
+
+	api.updates <- message(99, "find the title")
+	api.next(t, "Your user ID is 99")
+
+	api.updates <- message(42, "https://example.com find the title")
+	api.next(t, "Working on it: find the title")
+	reply := api.next(t, "✅ Done")
+	if !strings.Contains(reply["text"].(string), "💬 Example Domain") {
+		t.Errorf("reply lacks the answer: %q", reply["text"])
+	}
+	if got := <-calls; got != (call{"find the title", "https://example.com"}) {
+		t.Errorf("runner got %+v", got)
+	}
+
+	api.updates <- Update{Message: &Message{From: &User{ID: 5, Username: "owner"}, Chat: Chat{ID: 7}, Text: "/help"}}
+	api.next(t, "Send me a task")
+}
+
+func TestBotConfirmsWithButtons(t *testing.T) {
+	var bot *Bot
+	bot, api := startBot(t, runnerFunc(func(ctx context.Context, task, url string) (*agent.TaskResult, error) {
+		approved, err := bot.Confirm(ctx, security.DestructiveAction{Type: "click", Description: "Pay 100 ₽"})
+		if err != nil || !approved {
+			return nil, errors.New("action denied by user")
+		}
+		answer, err := bot.Ask(ctx, "❓ Which card?")
+		if err != nil {
+			return nil, err
+		}
+		return &agent.TaskResult{Answer: "paid with " + answer}, nil
+	}))
+
+	api.updates <- message(42, "pay the bill")
+	question := api.next(t, "May I click?")
+	markup, _ := json.Marshal(question["reply_markup"])
+	var buttons InlineKeyboardMarkup
+	if err := json.Unmarshal(markup, &buttons); err != nil || len(buttons.InlineKeyboard) != 1 || len(buttons.InlineKeyboard[0]) != 2 {
+		t.Fatalf("confirmation should have Yes and No buttons, got %s", markup)
+	}
+	yes := buttons.InlineKeyboard[0][0].CallbackData
+	api.updates <- Update{CallbackQuery: &CallbackQuery{ID: "q1", From: User{ID: 42}, Data: yes, Message: &Message{Chat: Chat{ID: 7}, Text: "May I click?"}}}
+
+	api.next(t, "Which card?")
+	api.updates <- message(42, "the Mir one")
+	api.next(t, "paid with the Mir one")
+}
+
+func TestBotCancel(t *testing.T) {
+	_, api := startBot(t, runnerFunc(func(ctx context.Context, task, url string) (*agent.TaskResult, error) {
+		<-ctx.Done()
+		return &agent.TaskResult{}, ctx.Err()
+	}))
+	api.updates <- message(42, "wait forever")
+	api.next(t, "Working on it")
+	api.updates <- message(42, "/cancel")
+	api.next(t, "Cancelling")
+	api.next(t, "Task cancelled")
+}
+
+func TestBotWaitsForTheBrowser(t *testing.T) {
+	// The browser is held, as by a scheduled task, when the chat's task comes.
+	var browser sync.Mutex
+	browser.Lock()
+	_, api := startBot(t, runnerFunc(func(ctx context.Context, task, url string) (*agent.TaskResult, error) {
+		browser.Lock()
+		defer browser.Unlock()
+		agent.Emit(ctx, agent.Event{Type: agent.EventTaskStarted, Message: task})
+		agent.Emit(ctx, agent.Event{Type: agent.EventPlanReady, Steps: []string{"Open the map"}})
+		return &agent.TaskResult{Answer: "found"}, nil
+	}))
+
+	api.updates <- message(42, "find the kremlin")
+	api.next(t, "Working on it")
+	api.updates <- message(42, "/status")
+	api.next(t, "Waiting for the browser")
+
+	browser.Unlock()
+	// The plan goes out on its own, so it may come after the reply.
+	var plan, reply bool
+	for !plan || !reply {
+		text := api.next(t, "")["text"].(string)
+		plan = plan || strings.Contains(text, "1. Open the map")
+		reply = reply || strings.Contains(text, "found")
+	}
+}
+
+func TestBotNoticesDoNotHoldUpTheTask(t *testing.T) {
+	api := &fakeAPI{stall: "Open the map", released: make(chan struct{})}
+	done := make(chan struct{})
+	startBotWith(t, api, runnerFunc(func(ctx context.Context, task, url string) (*agent.TaskResult, error) {
+		agent.Emit(ctx, agent.Event{Type: agent.EventTaskStarted, Message: task})
+		for i := 0; i < 2*noticeQueueSize; i++ {
+			agent.Emit(ctx, agent.Event{Type: agent.EventPlanReady, Steps: []string{"Open the map"}})
+		}
+		close(done)
+		return &agent.TaskResult{Answer: "found"}, nil
+	}))
+
+	api.updates <- message(42, "find the kremlin")
+	api.next(t, "Working on it")
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the task waited for Telegram to take its notices")
+	}
+	close(api.released)
+	api.next(t, "found")
+}
+
+func TestClientHidesToken(t *testing.T) {
+	client := NewClient("123:secret")
+	client.BaseURL = "http://127.0.0.1:1"
+	_, err := client.SendMessage(context.Background(), 7, "hi", nil)
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("error should not reveal the token: %v", err)
+	}
+}
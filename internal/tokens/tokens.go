@@ -0,0 +1,68 @@
+// Package tokens counts the tokens a text takes up in the model's context.
+//
+// Count follows the pre-tokenization of tiktoken's cl100k_base encoding (the
+// GPT-4 family): text is split into the same pieces (words with their leading
+// space, runs of up to three digits, punctuation, whitespace), and each piece
+// is charged what the encoding typically spends on it. Shipping the encoding's
+// 100k-entry vocabulary is not worth it for budgeting, but unlike a flat
+// "4 bytes per token" this gets non-Latin text right: Cyrillic words take
+// about one token per two letters, CJK one per character. Exact counts come
+// from the usage the API reports (see ai.WithUsage).
+package tokens
+
+import (
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+)
+
+// pieceRE is cl100k_base's pre-tokenization pattern, minus the lookahead Go's
+// regexp lacks (it only decides which piece a run of spaces joins).
+var pieceRE = regexp.MustCompile(`(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+`)
+
+// Letters per token within a word, by script.
+const (
+	latinLetters    = 8 // common English words are a single token
+	cyrillicLetters = 2
+)
+
+// Count returns the number of tokens text is expected to take.
+func Count(text string) int {
+	if text == "" {
+		return 0
+	}
+	total := 0
+	for _, piece := range pieceRE.FindAllString(text, -1) {
+		total += pieceTokens(piece)
+	}
+	return total
+}
+
+// pieceTokens estimates the tokens of one pre-tokenized piece.
+func pieceTokens(piece string) int {
+	var latin, cyrillic, other, symbolBytes int
+	for _, r := range piece {
+		switch {
+		case r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			latin++
+		case unicode.IsLetter(r) && r <= 0x024F:
+			latin++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			other++ // CJK and other scripts: about a token per character
+		case unicode.IsSpace(r):
+		default:
+			symbolBytes += utf8.RuneLen(r)
+		}
+	}
+	tokens := ceilDiv(latin, latinLetters) + ceilDiv(cyrillic, cyrillicLetters) + other + ceilDiv(symbolBytes, 2)
+	if tokens == 0 {
+		return 1 // whitespace
+	}
+	return tokens
+}
+
+func ceilDiv(n, d int) int {
+	return (n + d - 1) / d
+}
@@ -0,0 +1,30 @@
+package tokens
+
+import "testing"
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		text     string
+		min, max int
+	}{
+		{"", 0, 0},
+		{"Hello, world!", 3, 5},
+		{"The quick brown fox jumps over the lazy dog.", 9, 12},
+		{"Найди кремль на Яндекс Картах", 12, 20},
+		{"12345678", 3, 3},
+		{"東京タワー", 4, 6},
+	}
+	for _, tt := range tests {
+		if got := Count(tt.text); got < tt.min || got > tt.max {
+			t.Errorf("Count(%q) = %d, want %d..%d", tt.text, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestCountCyrillicCostsMoreThanLatin(t *testing.T) {
+	english := Count("Find the Kremlin on Yandex Maps and open its opening hours")
+	russian := Count("Найди Кремль на Яндекс Картах и открой часы его работы")
+	if russian <= english {
+		t.Fatalf("Cyrillic text should take more tokens: russian=%d english=%d", russian, english)
+	}
+}